@@ -26,20 +26,49 @@ import (
 type MockClient struct {
 	projectClient *projectClient
 	zoneClient    *zoneClient
-
-	networkClient        *networkClient
-	subnetworkClient     *subnetworkClient
-	routeClient          *routeClient
-	forwardingRuleClient *forwardingRuleClient
-	addressClient        *addressClient
-	firewallClient       *firewallClient
-	routerClient         *routerClient
-
-	instanceTemplateClient     *instanceTemplateClient
-	instanceGroupManagerClient *instanceGroupManagerClient
-	targetPoolClient           *targetPoolClient
-
-	diskClient *diskClient
+	regionClient  *regionClient
+
+	networkClient              *networkClient
+	subnetworkClient           *subnetworkClient
+	routeClient                *routeClient
+	forwardingRuleClient       *forwardingRuleClient
+	globalForwardingRuleClient *globalForwardingRuleClient
+	addressClient              *addressClient
+	globalAddressClient        *globalAddressClient
+	firewallClient             *firewallClient
+	routerClient               *routerClient
+
+	instanceTemplateClient           *instanceTemplateClient
+	instanceGroupManagerClient       *instanceGroupManagerClient
+	regionInstanceGroupManagerClient *regionInstanceGroupManagerClient
+	instanceGroupClient              *instanceGroupClient
+	autoscalerClient                 *autoscalerClient
+	targetPoolClient                 *targetPoolClient
+	targetInstanceClient             *targetInstanceClient
+	instanceClient                   *instanceClient
+	resourcePolicyClient             *resourcePolicyClient
+	networkEndpointGroupClient       *networkEndpointGroupClient
+	globalNetworkEndpointGroupClient *globalNetworkEndpointGroupClient
+
+	diskClient       *diskClient
+	regionDiskClient *regionDiskClient
+	snapshotClient   *snapshotClient
+
+	machineImageClient *machineImageClient
+	imageClient        *imageClient
+
+	backendServiceClient       *backendServiceClient
+	regionBackendServiceClient *regionBackendServiceClient
+	securityPolicyClient       *securityPolicyClient
+	sslPolicyClient            *sslPolicyClient
+	healthCheckClient          *healthCheckClient
+	regionHealthCheckClient    *regionHealthCheckClient
+	targetHttpProxyClient      *targetHttpProxyClient
+	targetHttpsProxyClient     *targetHttpsProxyClient
+	urlMapClient               *urlMapClient
+	sslCertificateClient       *sslCertificateClient
+	regionSslCertificateClient *regionSslCertificateClient
+	httpHealthCheckClient      *httpHealthCheckClient
 }
 
 var _ gce.ComputeClient = &MockClient{}
@@ -49,20 +78,49 @@ func NewMockClient(project string) *MockClient {
 	return &MockClient{
 		projectClient: newProjectClient(project),
 		zoneClient:    newZoneClient(project),
-
-		networkClient:        newNetworkClient(),
-		subnetworkClient:     newSubnetworkClient(),
-		routeClient:          newRouteClient(),
-		forwardingRuleClient: newForwardingRuleClient(),
-		addressClient:        newAddressClient(),
-		firewallClient:       newFirewallClient(),
-		routerClient:         newRouterClient(),
-
-		instanceTemplateClient:     newInstanceTemplateClient(),
-		instanceGroupManagerClient: newInstanceGroupManagerClient(),
-		targetPoolClient:           newTargetPoolClient(),
-
-		diskClient: newDiskClient(),
+		regionClient:  newRegionClient(project),
+
+		networkClient:              newNetworkClient(),
+		subnetworkClient:           newSubnetworkClient(),
+		routeClient:                newRouteClient(),
+		forwardingRuleClient:       newForwardingRuleClient(),
+		globalForwardingRuleClient: newGlobalForwardingRuleClient(),
+		addressClient:              newAddressClient(),
+		globalAddressClient:        newGlobalAddressClient(),
+		firewallClient:             newFirewallClient(),
+		routerClient:               newRouterClient(),
+
+		instanceTemplateClient:           newInstanceTemplateClient(),
+		instanceGroupManagerClient:       newInstanceGroupManagerClient(),
+		regionInstanceGroupManagerClient: newRegionInstanceGroupManagerClient(),
+		instanceGroupClient:              newInstanceGroupClient(),
+		autoscalerClient:                 newAutoscalerClient(),
+		targetPoolClient:                 newTargetPoolClient(),
+		targetInstanceClient:             newTargetInstanceClient(),
+		instanceClient:                   newInstanceClient(),
+		resourcePolicyClient:             newResourcePolicyClient(),
+		networkEndpointGroupClient:       newNetworkEndpointGroupClient(),
+		globalNetworkEndpointGroupClient: newGlobalNetworkEndpointGroupClient(),
+
+		diskClient:       newDiskClient(),
+		regionDiskClient: newRegionDiskClient(),
+		snapshotClient:   newSnapshotClient(),
+
+		machineImageClient: newMachineImageClient(),
+		imageClient:        newImageClient(),
+
+		backendServiceClient:       newBackendServiceClient(),
+		regionBackendServiceClient: newRegionBackendServiceClient(),
+		securityPolicyClient:       newSecurityPolicyClient(),
+		sslPolicyClient:            newSslPolicyClient(),
+		healthCheckClient:          newHealthCheckClient(),
+		regionHealthCheckClient:    newRegionHealthCheckClient(),
+		targetHttpProxyClient:      newTargetHttpProxyClient(),
+		targetHttpsProxyClient:     newTargetHttpsProxyClient(),
+		urlMapClient:               newUrlMapClient(),
+		sslCertificateClient:       newSslCertificateClient(),
+		regionSslCertificateClient: newRegionSslCertificateClient(),
+		httpHealthCheckClient:      newHttpHealthCheckClient(),
 	}
 }
 
@@ -71,19 +129,46 @@ func (c *MockClient) AllResources() map[string]interface{} {
 	fs := []func() map[string]interface{}{
 		c.projectClient.All,
 		c.zoneClient.All,
-		// Do not call c.networkClient.All() as currently pkg/resources/gce/gce.go
-		// does not delete a network.
+		// Do not call c.networkClient.All() as pkg/resources/gce/gce.go only
+		// deletes a network named after the cluster, and clusters default to
+		// the shared "default" network.
 		// TODO(kenji): Fix this.
 		c.subnetworkClient.All,
 		c.routeClient.All,
 		c.forwardingRuleClient.All,
+		c.globalForwardingRuleClient.All,
 		c.addressClient.All,
+		c.globalAddressClient.All,
 		c.firewallClient.All,
 		c.routerClient.All,
 		c.instanceTemplateClient.All,
 		c.instanceGroupManagerClient.All,
+		c.regionInstanceGroupManagerClient.All,
+		c.instanceGroupClient.All,
+		c.autoscalerClient.All,
 		c.targetPoolClient.All,
+		c.targetInstanceClient.All,
+		c.instanceClient.All,
+		c.networkEndpointGroupClient.All,
+		c.globalNetworkEndpointGroupClient.All,
 		c.diskClient.All,
+		c.regionDiskClient.All,
+		c.resourcePolicyClient.All,
+		c.snapshotClient.All,
+		c.machineImageClient.All,
+		c.imageClient.All,
+		c.backendServiceClient.All,
+		c.regionBackendServiceClient.All,
+		c.securityPolicyClient.All,
+		c.sslPolicyClient.All,
+		c.healthCheckClient.All,
+		c.regionHealthCheckClient.All,
+		c.targetHttpProxyClient.All,
+		c.targetHttpsProxyClient.All,
+		c.urlMapClient.All,
+		c.sslCertificateClient.All,
+		c.regionSslCertificateClient.All,
+		c.httpHealthCheckClient.All,
 	}
 	for _, f := range fs {
 		m := f()
@@ -99,9 +184,12 @@ func (c *MockClient) Projects() gce.ProjectClient {
 	return c.projectClient
 }
 
+func (c *MockClient) Operations() gce.OperationClient {
+	return &operationClient{}
+}
+
 func (c *MockClient) Regions() gce.RegionClient {
-	// Not implemented.
-	return nil
+	return c.regionClient
 }
 
 func (c *MockClient) Zones() gce.ZoneClient {
@@ -124,10 +212,18 @@ func (c *MockClient) ForwardingRules() gce.ForwardingRuleClient {
 	return c.forwardingRuleClient
 }
 
+func (c *MockClient) GlobalForwardingRules() gce.GlobalForwardingRuleClient {
+	return c.globalForwardingRuleClient
+}
+
 func (c *MockClient) Addresses() gce.AddressClient {
 	return c.addressClient
 }
 
+func (c *MockClient) GlobalAddresses() gce.GlobalAddressClient {
+	return c.globalAddressClient
+}
+
 func (c *MockClient) Firewalls() gce.FirewallClient {
 	return c.firewallClient
 }
@@ -137,8 +233,7 @@ func (c *MockClient) Routers() gce.RouterClient {
 }
 
 func (c *MockClient) Instances() gce.InstanceClient {
-	// Not implemented.
-	return nil
+	return c.instanceClient
 }
 
 func (c *MockClient) InstanceTemplates() gce.InstanceTemplateClient {
@@ -149,14 +244,111 @@ func (c *MockClient) InstanceGroupManagers() gce.InstanceGroupManagerClient {
 	return c.instanceGroupManagerClient
 }
 
+func (c *MockClient) RegionInstanceGroupManagers() gce.RegionInstanceGroupManagerClient {
+	return c.regionInstanceGroupManagerClient
+}
+
+// AddRegionManagedInstance records an instance as managed by the regional InstanceGroupManager.
+func (c *MockClient) AddRegionManagedInstance(igm *compute.InstanceGroupManager, instance *compute.ManagedInstance) {
+	c.regionInstanceGroupManagerClient.AddManagedInstance(igm, instance)
+}
+
+func (c *MockClient) InstanceGroups() gce.InstanceGroupClient {
+	return c.instanceGroupClient
+}
+
+func (c *MockClient) Autoscalers() gce.AutoscalerClient {
+	return c.autoscalerClient
+}
+
 func (c *MockClient) TargetPools() gce.TargetPoolClient {
 	return c.targetPoolClient
 }
 
+func (c *MockClient) TargetInstances() gce.TargetInstanceClient {
+	return c.targetInstanceClient
+}
+
+func (c *MockClient) NetworkEndpointGroups() gce.NetworkEndpointGroupClient {
+	return c.networkEndpointGroupClient
+}
+
+func (c *MockClient) GlobalNetworkEndpointGroups() gce.GlobalNetworkEndpointGroupClient {
+	return c.globalNetworkEndpointGroupClient
+}
+
 func (c *MockClient) Disks() gce.DiskClient {
 	return c.diskClient
 }
 
+func (c *MockClient) RegionDisks() gce.RegionDiskClient {
+	return c.regionDiskClient
+}
+
+func (c *MockClient) ResourcePolicies() gce.ResourcePolicyClient {
+	return c.resourcePolicyClient
+}
+
+func (c *MockClient) Snapshots() gce.SnapshotClient {
+	return c.snapshotClient
+}
+
+func (c *MockClient) MachineImages() gce.MachineImageClient {
+	return c.machineImageClient
+}
+
+func (c *MockClient) Images() gce.ImageClient {
+	return c.imageClient
+}
+
+func (c *MockClient) BackendServices() gce.BackendServiceClient {
+	return c.backendServiceClient
+}
+
+func (c *MockClient) RegionBackendServices() gce.RegionBackendServiceClient {
+	return c.regionBackendServiceClient
+}
+
+func (c *MockClient) SecurityPolicies() gce.SecurityPolicyClient {
+	return c.securityPolicyClient
+}
+
+func (c *MockClient) SslPolicies() gce.SslPolicyClient {
+	return c.sslPolicyClient
+}
+
+func (c *MockClient) HealthChecks() gce.HealthCheckClient {
+	return c.healthCheckClient
+}
+
+func (c *MockClient) SslCertificates() gce.SslCertificateClient {
+	return c.sslCertificateClient
+}
+
+func (c *MockClient) RegionHealthChecks() gce.RegionHealthCheckClient {
+	return c.regionHealthCheckClient
+}
+
+func (c *MockClient) RegionSslCertificates() gce.RegionSslCertificateClient {
+	return c.regionSslCertificateClient
+}
+
+func (c *MockClient) TargetHttpProxies() gce.TargetHttpProxyClient {
+	return c.targetHttpProxyClient
+}
+
+func (c *MockClient) TargetHttpsProxies() gce.TargetHttpsProxyClient {
+	return c.targetHttpsProxyClient
+}
+
+func (c *MockClient) UrlMaps() gce.UrlMapClient {
+	return c.urlMapClient
+}
+
+func (c *MockClient) HttpHealthChecks() gce.HttpHealthCheckClient {
+	return c.httpHealthCheckClient
+}
+
 func notFoundError() error {
 	return &googleapi.Error{
 		Code: 404,
@@ -0,0 +1,127 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mockcompute
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	compute "google.golang.org/api/compute/v1"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+type autoscalerClient struct {
+	// autoscalers are autoscalers keyed by project, zone, and autoscaler name.
+	autoscalers map[string]map[string]map[string]*compute.Autoscaler
+	sync.Mutex
+}
+
+var _ gce.AutoscalerClient = &autoscalerClient{}
+
+func newAutoscalerClient() *autoscalerClient {
+	return &autoscalerClient{
+		autoscalers: map[string]map[string]map[string]*compute.Autoscaler{},
+	}
+}
+
+func (c *autoscalerClient) All() map[string]interface{} {
+	c.Lock()
+	defer c.Unlock()
+	m := map[string]interface{}{}
+	for _, zones := range c.autoscalers {
+		for _, autoscalers := range zones {
+			for n, autoscaler := range autoscalers {
+				m[n] = autoscaler
+			}
+		}
+	}
+	return m
+}
+
+func (c *autoscalerClient) Insert(project, zone string, autoscaler *compute.Autoscaler) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	zones, ok := c.autoscalers[project]
+	if !ok {
+		zones = map[string]map[string]*compute.Autoscaler{}
+		c.autoscalers[project] = zones
+	}
+	autoscalers, ok := zones[zone]
+	if !ok {
+		autoscalers = map[string]*compute.Autoscaler{}
+		zones[zone] = autoscalers
+	}
+	autoscaler.SelfLink = fmt.Sprintf("https://www.googleapis.com/compute/v1/projects/%s/zones/%s/autoscalers/%s", project, zone, autoscaler.Name)
+	autoscaler.Zone = zone
+	autoscalers[autoscaler.Name] = autoscaler
+	return doneOperation(), nil
+}
+
+func (c *autoscalerClient) Delete(project, zone, name string) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	zones, ok := c.autoscalers[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	autoscalers, ok := zones[zone]
+	if !ok {
+		return nil, notFoundError()
+	}
+	if _, ok := autoscalers[name]; !ok {
+		return nil, notFoundError()
+	}
+	delete(autoscalers, name)
+	return doneOperation(), nil
+}
+
+func (c *autoscalerClient) Get(project, zone, name string) (*compute.Autoscaler, error) {
+	c.Lock()
+	defer c.Unlock()
+	zones, ok := c.autoscalers[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	autoscalers, ok := zones[zone]
+	if !ok {
+		return nil, notFoundError()
+	}
+	autoscaler, ok := autoscalers[name]
+	if !ok {
+		return nil, notFoundError()
+	}
+	return autoscaler, nil
+}
+
+func (c *autoscalerClient) List(ctx context.Context, project, zone string) ([]*compute.Autoscaler, error) {
+	c.Lock()
+	defer c.Unlock()
+	zones, ok := c.autoscalers[project]
+	if !ok {
+		return nil, nil
+	}
+	autoscalers, ok := zones[zone]
+	if !ok {
+		return nil, nil
+	}
+	var l []*compute.Autoscaler
+	for _, autoscaler := range autoscalers {
+		l = append(l, autoscaler)
+	}
+	return l, nil
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mockcompute
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	compute "google.golang.org/api/compute/v1"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+type backendServiceClient struct {
+	// backendServices are backendServices keyed by project and name.
+	backendServices map[string]map[string]*compute.BackendService
+	sync.Mutex
+}
+
+var _ gce.BackendServiceClient = &backendServiceClient{}
+
+func newBackendServiceClient() *backendServiceClient {
+	return &backendServiceClient{
+		backendServices: map[string]map[string]*compute.BackendService{},
+	}
+}
+
+func (c *backendServiceClient) All() map[string]interface{} {
+	c.Lock()
+	defer c.Unlock()
+	m := map[string]interface{}{}
+	for _, objs := range c.backendServices {
+		for n, bs := range objs {
+			m[n] = bs
+		}
+	}
+	return m
+}
+
+func (c *backendServiceClient) Insert(project string, bs *compute.BackendService) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.backendServices[project]
+	if !ok {
+		objs = map[string]*compute.BackendService{}
+		c.backendServices[project] = objs
+	}
+	bs.SelfLink = fmt.Sprintf("https://www.googleapis.com/compute/v1/projects/%s/global/backendServices/%s", project, bs.Name)
+	objs[bs.Name] = bs
+	return doneOperation(), nil
+}
+
+func (c *backendServiceClient) Delete(project, name string) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.backendServices[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	if _, ok := objs[name]; !ok {
+		return nil, notFoundError()
+	}
+	delete(objs, name)
+	return doneOperation(), nil
+}
+
+func (c *backendServiceClient) Get(project, name string) (*compute.BackendService, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.backendServices[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	bs, ok := objs[name]
+	if !ok {
+		return nil, notFoundError()
+	}
+	return bs, nil
+}
+
+func (c *backendServiceClient) List(ctx context.Context, project string) ([]*compute.BackendService, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.backendServices[project]
+	if !ok {
+		return nil, nil
+	}
+	var l []*compute.BackendService
+	for _, bs := range objs {
+		l = append(l, bs)
+	}
+	return l, nil
+}
+
+type regionBackendServiceClient struct {
+	// backendServices are backendServices keyed by project, region, and name.
+	backendServices map[string]map[string]map[string]*compute.BackendService
+	sync.Mutex
+}
+
+var _ gce.RegionBackendServiceClient = &regionBackendServiceClient{}
+
+func newRegionBackendServiceClient() *regionBackendServiceClient {
+	return &regionBackendServiceClient{
+		backendServices: map[string]map[string]map[string]*compute.BackendService{},
+	}
+}
+
+func (c *regionBackendServiceClient) All() map[string]interface{} {
+	c.Lock()
+	defer c.Unlock()
+	m := map[string]interface{}{}
+	for _, regions := range c.backendServices {
+		for _, objs := range regions {
+			for n, bs := range objs {
+				m[n] = bs
+			}
+		}
+	}
+	return m
+}
+
+func (c *regionBackendServiceClient) Insert(project, region string, bs *compute.BackendService) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	regions, ok := c.backendServices[project]
+	if !ok {
+		regions = map[string]map[string]*compute.BackendService{}
+		c.backendServices[project] = regions
+	}
+	objs, ok := regions[region]
+	if !ok {
+		objs = map[string]*compute.BackendService{}
+		regions[region] = objs
+	}
+	bs.SelfLink = fmt.Sprintf("https://www.googleapis.com/compute/v1/projects/%s/regions/%s/backendServices/%s", project, region, bs.Name)
+	objs[bs.Name] = bs
+	return doneOperation(), nil
+}
+
+func (c *regionBackendServiceClient) Delete(project, region, name string) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	regions, ok := c.backendServices[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	objs, ok := regions[region]
+	if !ok {
+		return nil, notFoundError()
+	}
+	if _, ok := objs[name]; !ok {
+		return nil, notFoundError()
+	}
+	delete(objs, name)
+	return doneOperation(), nil
+}
+
+func (c *regionBackendServiceClient) Get(project, region, name string) (*compute.BackendService, error) {
+	c.Lock()
+	defer c.Unlock()
+	regions, ok := c.backendServices[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	objs, ok := regions[region]
+	if !ok {
+		return nil, notFoundError()
+	}
+	bs, ok := objs[name]
+	if !ok {
+		return nil, notFoundError()
+	}
+	return bs, nil
+}
+
+func (c *regionBackendServiceClient) List(ctx context.Context, project, region string) ([]*compute.BackendService, error) {
+	c.Lock()
+	defer c.Unlock()
+	regions, ok := c.backendServices[project]
+	if !ok {
+		return nil, nil
+	}
+	objs, ok := regions[region]
+	if !ok {
+		return nil, nil
+	}
+	var l []*compute.BackendService
+	for _, bs := range objs {
+		l = append(l, bs)
+	}
+	return l, nil
+}
@@ -126,7 +126,7 @@ func (c *diskClient) List(ctx context.Context, project, zone string) ([]*compute
 	return l, nil
 }
 
-func (c *diskClient) AggregatedList(ctx context.Context, project string) ([]compute.DisksScopedList, error) {
+func (c *diskClient) AggregatedList(ctx context.Context, project string, maxResults int64) ([]compute.DisksScopedList, error) {
 	c.Lock()
 	defer c.Unlock()
 	zones, ok := c.disks[project]
@@ -19,6 +19,7 @@ package mockcompute
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 
 	compute "google.golang.org/api/compute/v1"
@@ -117,5 +118,9 @@ func (c *firewallClient) List(ctx context.Context, project string) ([]*compute.F
 	for _, fw := range firewalls {
 		l = append(l, fw)
 	}
+	// Sort by name, like the GCE API, so tests see a stable order
+	sort.Slice(l, func(i, j int) bool {
+		return l[i].Name < l[j].Name
+	})
 	return l, nil
 }
@@ -0,0 +1,107 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mockcompute
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	compute "google.golang.org/api/compute/v1"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+type globalAddressClient struct {
+	// addresses are global addresses keyed by project and name.
+	addresses map[string]map[string]*compute.Address
+	sync.Mutex
+}
+
+var _ gce.GlobalAddressClient = &globalAddressClient{}
+
+func newGlobalAddressClient() *globalAddressClient {
+	return &globalAddressClient{
+		addresses: map[string]map[string]*compute.Address{},
+	}
+}
+
+func (c *globalAddressClient) All() map[string]interface{} {
+	c.Lock()
+	defer c.Unlock()
+	m := map[string]interface{}{}
+	for _, objs := range c.addresses {
+		for n, addr := range objs {
+			m[n] = addr
+		}
+	}
+	return m
+}
+
+func (c *globalAddressClient) Insert(project string, addr *compute.Address) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.addresses[project]
+	if !ok {
+		objs = map[string]*compute.Address{}
+		c.addresses[project] = objs
+	}
+	addr.SelfLink = fmt.Sprintf("https://www.googleapis.com/compute/v1/projects/%s/global/addresses/%s", project, addr.Name)
+	objs[addr.Name] = addr
+	return doneOperation(), nil
+}
+
+func (c *globalAddressClient) Delete(project, name string) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.addresses[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	if _, ok := objs[name]; !ok {
+		return nil, notFoundError()
+	}
+	delete(objs, name)
+	return doneOperation(), nil
+}
+
+func (c *globalAddressClient) Get(project, name string) (*compute.Address, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.addresses[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	addr, ok := objs[name]
+	if !ok {
+		return nil, notFoundError()
+	}
+	return addr, nil
+}
+
+func (c *globalAddressClient) List(ctx context.Context, project string) ([]*compute.Address, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.addresses[project]
+	if !ok {
+		return nil, nil
+	}
+	var l []*compute.Address
+	for _, addr := range objs {
+		l = append(l, addr)
+	}
+	return l, nil
+}
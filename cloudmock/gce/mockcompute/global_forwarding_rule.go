@@ -0,0 +1,107 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mockcompute
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	compute "google.golang.org/api/compute/v1"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+type globalForwardingRuleClient struct {
+	// forwardingRules are forwardingRules keyed by project and name.
+	forwardingRules map[string]map[string]*compute.ForwardingRule
+	sync.Mutex
+}
+
+var _ gce.GlobalForwardingRuleClient = &globalForwardingRuleClient{}
+
+func newGlobalForwardingRuleClient() *globalForwardingRuleClient {
+	return &globalForwardingRuleClient{
+		forwardingRules: map[string]map[string]*compute.ForwardingRule{},
+	}
+}
+
+func (c *globalForwardingRuleClient) All() map[string]interface{} {
+	c.Lock()
+	defer c.Unlock()
+	m := map[string]interface{}{}
+	for _, objs := range c.forwardingRules {
+		for n, fr := range objs {
+			m[n] = fr
+		}
+	}
+	return m
+}
+
+func (c *globalForwardingRuleClient) Insert(project string, fr *compute.ForwardingRule) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.forwardingRules[project]
+	if !ok {
+		objs = map[string]*compute.ForwardingRule{}
+		c.forwardingRules[project] = objs
+	}
+	fr.SelfLink = fmt.Sprintf("https://www.googleapis.com/compute/v1/projects/%s/global/forwardingRules/%s", project, fr.Name)
+	objs[fr.Name] = fr
+	return doneOperation(), nil
+}
+
+func (c *globalForwardingRuleClient) Delete(project, name string) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.forwardingRules[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	if _, ok := objs[name]; !ok {
+		return nil, notFoundError()
+	}
+	delete(objs, name)
+	return doneOperation(), nil
+}
+
+func (c *globalForwardingRuleClient) Get(project, name string) (*compute.ForwardingRule, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.forwardingRules[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	fr, ok := objs[name]
+	if !ok {
+		return nil, notFoundError()
+	}
+	return fr, nil
+}
+
+func (c *globalForwardingRuleClient) List(ctx context.Context, project string) ([]*compute.ForwardingRule, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.forwardingRules[project]
+	if !ok {
+		return nil, nil
+	}
+	var l []*compute.ForwardingRule
+	for _, fr := range objs {
+		l = append(l, fr)
+	}
+	return l, nil
+}
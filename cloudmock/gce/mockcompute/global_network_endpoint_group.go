@@ -0,0 +1,107 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mockcompute
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	compute "google.golang.org/api/compute/v1"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+type globalNetworkEndpointGroupClient struct {
+	// networkEndpointGroups are global networkEndpointGroups keyed by project and name.
+	networkEndpointGroups map[string]map[string]*compute.NetworkEndpointGroup
+	sync.Mutex
+}
+
+var _ gce.GlobalNetworkEndpointGroupClient = &globalNetworkEndpointGroupClient{}
+
+func newGlobalNetworkEndpointGroupClient() *globalNetworkEndpointGroupClient {
+	return &globalNetworkEndpointGroupClient{
+		networkEndpointGroups: map[string]map[string]*compute.NetworkEndpointGroup{},
+	}
+}
+
+func (c *globalNetworkEndpointGroupClient) All() map[string]interface{} {
+	c.Lock()
+	defer c.Unlock()
+	m := map[string]interface{}{}
+	for _, objs := range c.networkEndpointGroups {
+		for n, neg := range objs {
+			m[n] = neg
+		}
+	}
+	return m
+}
+
+func (c *globalNetworkEndpointGroupClient) Insert(project string, neg *compute.NetworkEndpointGroup) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.networkEndpointGroups[project]
+	if !ok {
+		objs = map[string]*compute.NetworkEndpointGroup{}
+		c.networkEndpointGroups[project] = objs
+	}
+	neg.SelfLink = fmt.Sprintf("https://www.googleapis.com/compute/v1/projects/%s/global/networkEndpointGroups/%s", project, neg.Name)
+	objs[neg.Name] = neg
+	return doneOperation(), nil
+}
+
+func (c *globalNetworkEndpointGroupClient) Delete(project, name string) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.networkEndpointGroups[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	if _, ok := objs[name]; !ok {
+		return nil, notFoundError()
+	}
+	delete(objs, name)
+	return doneOperation(), nil
+}
+
+func (c *globalNetworkEndpointGroupClient) Get(project, name string) (*compute.NetworkEndpointGroup, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.networkEndpointGroups[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	neg, ok := objs[name]
+	if !ok {
+		return nil, notFoundError()
+	}
+	return neg, nil
+}
+
+func (c *globalNetworkEndpointGroupClient) List(ctx context.Context, project string) ([]*compute.NetworkEndpointGroup, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.networkEndpointGroups[project]
+	if !ok {
+		return nil, nil
+	}
+	var l []*compute.NetworkEndpointGroup
+	for _, neg := range objs {
+		l = append(l, neg)
+	}
+	return l, nil
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mockcompute
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	compute "google.golang.org/api/compute/v1"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+type healthCheckClient struct {
+	// healthChecks are healthChecks keyed by project and name.
+	healthChecks map[string]map[string]*compute.HealthCheck
+	sync.Mutex
+}
+
+var _ gce.HealthCheckClient = &healthCheckClient{}
+
+func newHealthCheckClient() *healthCheckClient {
+	return &healthCheckClient{
+		healthChecks: map[string]map[string]*compute.HealthCheck{},
+	}
+}
+
+func (c *healthCheckClient) All() map[string]interface{} {
+	c.Lock()
+	defer c.Unlock()
+	m := map[string]interface{}{}
+	for _, objs := range c.healthChecks {
+		for n, hc := range objs {
+			m[n] = hc
+		}
+	}
+	return m
+}
+
+func (c *healthCheckClient) Insert(project string, hc *compute.HealthCheck) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.healthChecks[project]
+	if !ok {
+		objs = map[string]*compute.HealthCheck{}
+		c.healthChecks[project] = objs
+	}
+	hc.SelfLink = fmt.Sprintf("https://www.googleapis.com/compute/v1/projects/%s/global/healthChecks/%s", project, hc.Name)
+	objs[hc.Name] = hc
+	return doneOperation(), nil
+}
+
+func (c *healthCheckClient) Delete(project, name string) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.healthChecks[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	if _, ok := objs[name]; !ok {
+		return nil, notFoundError()
+	}
+	delete(objs, name)
+	return doneOperation(), nil
+}
+
+func (c *healthCheckClient) Get(project, name string) (*compute.HealthCheck, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.healthChecks[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	hc, ok := objs[name]
+	if !ok {
+		return nil, notFoundError()
+	}
+	return hc, nil
+}
+
+func (c *healthCheckClient) List(ctx context.Context, project string) ([]*compute.HealthCheck, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.healthChecks[project]
+	if !ok {
+		return nil, nil
+	}
+	var l []*compute.HealthCheck
+	for _, hc := range objs {
+		l = append(l, hc)
+	}
+	return l, nil
+}
+
+type regionHealthCheckClient struct {
+	// healthChecks are healthChecks keyed by project, region, and name.
+	healthChecks map[string]map[string]map[string]*compute.HealthCheck
+	sync.Mutex
+}
+
+var _ gce.RegionHealthCheckClient = &regionHealthCheckClient{}
+
+func newRegionHealthCheckClient() *regionHealthCheckClient {
+	return &regionHealthCheckClient{
+		healthChecks: map[string]map[string]map[string]*compute.HealthCheck{},
+	}
+}
+
+func (c *regionHealthCheckClient) All() map[string]interface{} {
+	c.Lock()
+	defer c.Unlock()
+	m := map[string]interface{}{}
+	for _, regions := range c.healthChecks {
+		for _, objs := range regions {
+			for n, hc := range objs {
+				m[n] = hc
+			}
+		}
+	}
+	return m
+}
+
+func (c *regionHealthCheckClient) Insert(project, region string, hc *compute.HealthCheck) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	regions, ok := c.healthChecks[project]
+	if !ok {
+		regions = map[string]map[string]*compute.HealthCheck{}
+		c.healthChecks[project] = regions
+	}
+	objs, ok := regions[region]
+	if !ok {
+		objs = map[string]*compute.HealthCheck{}
+		regions[region] = objs
+	}
+	hc.SelfLink = fmt.Sprintf("https://www.googleapis.com/compute/v1/projects/%s/regions/%s/healthChecks/%s", project, region, hc.Name)
+	objs[hc.Name] = hc
+	return doneOperation(), nil
+}
+
+func (c *regionHealthCheckClient) Delete(project, region, name string) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	regions, ok := c.healthChecks[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	objs, ok := regions[region]
+	if !ok {
+		return nil, notFoundError()
+	}
+	if _, ok := objs[name]; !ok {
+		return nil, notFoundError()
+	}
+	delete(objs, name)
+	return doneOperation(), nil
+}
+
+func (c *regionHealthCheckClient) Get(project, region, name string) (*compute.HealthCheck, error) {
+	c.Lock()
+	defer c.Unlock()
+	regions, ok := c.healthChecks[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	objs, ok := regions[region]
+	if !ok {
+		return nil, notFoundError()
+	}
+	hc, ok := objs[name]
+	if !ok {
+		return nil, notFoundError()
+	}
+	return hc, nil
+}
+
+func (c *regionHealthCheckClient) List(ctx context.Context, project, region string) ([]*compute.HealthCheck, error) {
+	c.Lock()
+	defer c.Unlock()
+	regions, ok := c.healthChecks[project]
+	if !ok {
+		return nil, nil
+	}
+	objs, ok := regions[region]
+	if !ok {
+		return nil, nil
+	}
+	var l []*compute.HealthCheck
+	for _, hc := range objs {
+		l = append(l, hc)
+	}
+	return l, nil
+}
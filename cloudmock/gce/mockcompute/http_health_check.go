@@ -0,0 +1,107 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mockcompute
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	compute "google.golang.org/api/compute/v1"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+type httpHealthCheckClient struct {
+	// httpHealthChecks are httpHealthChecks keyed by project and name.
+	httpHealthChecks map[string]map[string]*compute.HttpHealthCheck
+	sync.Mutex
+}
+
+var _ gce.HttpHealthCheckClient = &httpHealthCheckClient{}
+
+func newHttpHealthCheckClient() *httpHealthCheckClient {
+	return &httpHealthCheckClient{
+		httpHealthChecks: map[string]map[string]*compute.HttpHealthCheck{},
+	}
+}
+
+func (c *httpHealthCheckClient) All() map[string]interface{} {
+	c.Lock()
+	defer c.Unlock()
+	m := map[string]interface{}{}
+	for _, objs := range c.httpHealthChecks {
+		for n, hc := range objs {
+			m[n] = hc
+		}
+	}
+	return m
+}
+
+func (c *httpHealthCheckClient) Insert(project string, hc *compute.HttpHealthCheck) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.httpHealthChecks[project]
+	if !ok {
+		objs = map[string]*compute.HttpHealthCheck{}
+		c.httpHealthChecks[project] = objs
+	}
+	hc.SelfLink = fmt.Sprintf("https://www.googleapis.com/compute/v1/projects/%s/global/httpHealthChecks/%s", project, hc.Name)
+	objs[hc.Name] = hc
+	return doneOperation(), nil
+}
+
+func (c *httpHealthCheckClient) Delete(project, name string) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.httpHealthChecks[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	if _, ok := objs[name]; !ok {
+		return nil, notFoundError()
+	}
+	delete(objs, name)
+	return doneOperation(), nil
+}
+
+func (c *httpHealthCheckClient) Get(project, name string) (*compute.HttpHealthCheck, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.httpHealthChecks[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	hc, ok := objs[name]
+	if !ok {
+		return nil, notFoundError()
+	}
+	return hc, nil
+}
+
+func (c *httpHealthCheckClient) List(ctx context.Context, project string) ([]*compute.HttpHealthCheck, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.httpHealthChecks[project]
+	if !ok {
+		return nil, nil
+	}
+	var l []*compute.HttpHealthCheck
+	for _, hc := range objs {
+		l = append(l, hc)
+	}
+	return l, nil
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mockcompute
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	compute "google.golang.org/api/compute/v1"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+type imageClient struct {
+	// images are images keyed by project and name.
+	images map[string]map[string]*compute.Image
+	sync.Mutex
+}
+
+var _ gce.ImageClient = &imageClient{}
+
+func newImageClient() *imageClient {
+	return &imageClient{
+		images: map[string]map[string]*compute.Image{},
+	}
+}
+
+func (c *imageClient) All() map[string]interface{} {
+	c.Lock()
+	defer c.Unlock()
+	m := map[string]interface{}{}
+	for _, objs := range c.images {
+		for n, image := range objs {
+			m[n] = image
+		}
+	}
+	return m
+}
+
+func (c *imageClient) Insert(project string, image *compute.Image) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.images[project]
+	if !ok {
+		objs = map[string]*compute.Image{}
+		c.images[project] = objs
+	}
+	image.SelfLink = fmt.Sprintf("https://www.googleapis.com/compute/v1/projects/%s/global/images/%s", project, image.Name)
+	objs[image.Name] = image
+	return doneOperation(), nil
+}
+
+func (c *imageClient) Delete(project, name string) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.images[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	if _, ok := objs[name]; !ok {
+		return nil, notFoundError()
+	}
+	delete(objs, name)
+	return doneOperation(), nil
+}
+
+func (c *imageClient) Get(project, name string) (*compute.Image, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.images[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	image, ok := objs[name]
+	if !ok {
+		return nil, notFoundError()
+	}
+	return image, nil
+}
+
+func (c *imageClient) SetLabels(project, name string, req *compute.GlobalSetLabelsRequest) error {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.images[project]
+	if !ok {
+		return notFoundError()
+	}
+	image, ok := objs[name]
+	if !ok {
+		return notFoundError()
+	}
+	image.Labels = req.Labels
+	return nil
+}
+
+func (c *imageClient) List(ctx context.Context, project string) ([]*compute.Image, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.images[project]
+	if !ok {
+		return nil, nil
+	}
+	var l []*compute.Image
+	for _, image := range objs {
+		l = append(l, image)
+	}
+	return l, nil
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mockcompute
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	compute "google.golang.org/api/compute/v1"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+type instanceClient struct {
+	// instances are instances keyed by project, zone, and instance name.
+	instances map[string]map[string]map[string]*compute.Instance
+	sync.Mutex
+}
+
+var _ gce.InstanceClient = &instanceClient{}
+
+func newInstanceClient() *instanceClient {
+	return &instanceClient{
+		instances: map[string]map[string]map[string]*compute.Instance{},
+	}
+}
+
+func (c *instanceClient) All() map[string]interface{} {
+	c.Lock()
+	defer c.Unlock()
+	m := map[string]interface{}{}
+	for _, zones := range c.instances {
+		for _, insts := range zones {
+			for n, i := range insts {
+				m[n] = i
+			}
+		}
+	}
+	return m
+}
+
+func (c *instanceClient) Insert(project, zone string, i *compute.Instance) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	zones, ok := c.instances[project]
+	if !ok {
+		zones = map[string]map[string]*compute.Instance{}
+		c.instances[project] = zones
+	}
+	insts, ok := zones[zone]
+	if !ok {
+		insts = map[string]*compute.Instance{}
+		zones[zone] = insts
+	}
+	i.SelfLink = fmt.Sprintf("https://www.googleapis.com/compute/v1/projects/%s/zones/%s/instances/%s", project, zone, i.Name)
+	insts[i.Name] = i
+	return doneOperation(), nil
+}
+
+func (c *instanceClient) Delete(project, zone, name string) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	zones, ok := c.instances[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	insts, ok := zones[zone]
+	if !ok {
+		return nil, notFoundError()
+	}
+	if _, ok := insts[name]; !ok {
+		return nil, notFoundError()
+	}
+	delete(insts, name)
+	return doneOperation(), nil
+}
+
+func (c *instanceClient) Get(project, zone, name string) (*compute.Instance, error) {
+	c.Lock()
+	defer c.Unlock()
+	zones, ok := c.instances[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	insts, ok := zones[zone]
+	if !ok {
+		return nil, notFoundError()
+	}
+	i, ok := insts[name]
+	if !ok {
+		return nil, notFoundError()
+	}
+	return i, nil
+}
+
+func (c *instanceClient) List(ctx context.Context, project, zone string) ([]*compute.Instance, error) {
+	c.Lock()
+	defer c.Unlock()
+	zones, ok := c.instances[project]
+	if !ok {
+		return nil, nil
+	}
+	insts, ok := zones[zone]
+	if !ok {
+		return nil, nil
+	}
+	var l []*compute.Instance
+	for _, i := range insts {
+		l = append(l, i)
+	}
+	return l, nil
+}
+
+func (c *instanceClient) SetMetadata(project, zone, name string, metadata *compute.Metadata) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	zones, ok := c.instances[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	insts, ok := zones[zone]
+	if !ok {
+		return nil, notFoundError()
+	}
+	i, ok := insts[name]
+	if !ok {
+		return nil, notFoundError()
+	}
+	i.Metadata = metadata
+	return doneOperation(), nil
+}
+
+func (c *instanceClient) DetachDisk(project, zone, name, deviceName string) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	zones, ok := c.instances[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	insts, ok := zones[zone]
+	if !ok {
+		return nil, notFoundError()
+	}
+	i, ok := insts[name]
+	if !ok {
+		return nil, notFoundError()
+	}
+	for n, d := range i.Disks {
+		if d.DeviceName == deviceName {
+			i.Disks = append(i.Disks[:n], i.Disks[n+1:]...)
+			return doneOperation(), nil
+		}
+	}
+	return nil, notFoundError()
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mockcompute
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	compute "google.golang.org/api/compute/v1"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+type instanceGroupClient struct {
+	// instanceGroups are instanceGroups keyed by project, zone, and instanceGroup name.
+	instanceGroups map[string]map[string]map[string]*compute.InstanceGroup
+	sync.Mutex
+}
+
+var _ gce.InstanceGroupClient = &instanceGroupClient{}
+
+func newInstanceGroupClient() *instanceGroupClient {
+	return &instanceGroupClient{
+		instanceGroups: map[string]map[string]map[string]*compute.InstanceGroup{},
+	}
+}
+
+func (c *instanceGroupClient) All() map[string]interface{} {
+	c.Lock()
+	defer c.Unlock()
+	m := map[string]interface{}{}
+	for _, zones := range c.instanceGroups {
+		for _, igs := range zones {
+			for n, ig := range igs {
+				m[n] = ig
+			}
+		}
+	}
+	return m
+}
+
+func (c *instanceGroupClient) Insert(project, zone string, ig *compute.InstanceGroup) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	zones, ok := c.instanceGroups[project]
+	if !ok {
+		zones = map[string]map[string]*compute.InstanceGroup{}
+		c.instanceGroups[project] = zones
+	}
+	igs, ok := zones[zone]
+	if !ok {
+		igs = map[string]*compute.InstanceGroup{}
+		zones[zone] = igs
+	}
+	ig.SelfLink = fmt.Sprintf("https://www.googleapis.com/compute/v1/projects/%s/zones/%s/instanceGroups/%s", project, zone, ig.Name)
+	ig.Zone = zone
+	igs[ig.Name] = ig
+	return doneOperation(), nil
+}
+
+func (c *instanceGroupClient) Delete(project, zone, name string) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	zones, ok := c.instanceGroups[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	igs, ok := zones[zone]
+	if !ok {
+		return nil, notFoundError()
+	}
+	if _, ok := igs[name]; !ok {
+		return nil, notFoundError()
+	}
+	delete(igs, name)
+	return doneOperation(), nil
+}
+
+func (c *instanceGroupClient) Get(project, zone, name string) (*compute.InstanceGroup, error) {
+	c.Lock()
+	defer c.Unlock()
+	zones, ok := c.instanceGroups[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	igs, ok := zones[zone]
+	if !ok {
+		return nil, notFoundError()
+	}
+	ig, ok := igs[name]
+	if !ok {
+		return nil, notFoundError()
+	}
+	return ig, nil
+}
+
+func (c *instanceGroupClient) List(ctx context.Context, project, zone string) ([]*compute.InstanceGroup, error) {
+	c.Lock()
+	defer c.Unlock()
+	zones, ok := c.instanceGroups[project]
+	if !ok {
+		return nil, nil
+	}
+	igs, ok := zones[zone]
+	if !ok {
+		return nil, nil
+	}
+	var l []*compute.InstanceGroup
+	for _, ig := range igs {
+		l = append(l, ig)
+	}
+	return l, nil
+}
@@ -67,6 +67,7 @@ func (c *instanceGroupManagerClient) Insert(project, zone string, igm *compute.I
 		zones[zone] = igms
 	}
 	igm.SelfLink = fmt.Sprintf("https://www.googleapis.com/compute/v1/projects/%s/zones/%s/instanceGroupManagers/%s", project, zone, igm.Name)
+	igm.InstanceGroup = fmt.Sprintf("https://www.googleapis.com/compute/v1/projects/%s/zones/%s/instanceGroups/%s", project, zone, igm.Name)
 	igms[igm.Name] = igm
 	return doneOperation(), nil
 }
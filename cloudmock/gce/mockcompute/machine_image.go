@@ -0,0 +1,108 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mockcompute
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	computebeta "google.golang.org/api/compute/v0.beta"
+	compute "google.golang.org/api/compute/v1"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+type machineImageClient struct {
+	// machineImages are machine images keyed by project and name.
+	machineImages map[string]map[string]*computebeta.MachineImage
+	sync.Mutex
+}
+
+var _ gce.MachineImageClient = &machineImageClient{}
+
+func newMachineImageClient() *machineImageClient {
+	return &machineImageClient{
+		machineImages: map[string]map[string]*computebeta.MachineImage{},
+	}
+}
+
+func (c *machineImageClient) All() map[string]interface{} {
+	c.Lock()
+	defer c.Unlock()
+	m := map[string]interface{}{}
+	for _, objs := range c.machineImages {
+		for n, mi := range objs {
+			m[n] = mi
+		}
+	}
+	return m
+}
+
+func (c *machineImageClient) Insert(project string, mi *computebeta.MachineImage) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.machineImages[project]
+	if !ok {
+		objs = map[string]*computebeta.MachineImage{}
+		c.machineImages[project] = objs
+	}
+	mi.SelfLink = fmt.Sprintf("https://www.googleapis.com/compute/beta/projects/%s/global/machineImages/%s", project, mi.Name)
+	objs[mi.Name] = mi
+	return doneOperation(), nil
+}
+
+func (c *machineImageClient) Delete(project, name string) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.machineImages[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	if _, ok := objs[name]; !ok {
+		return nil, notFoundError()
+	}
+	delete(objs, name)
+	return doneOperation(), nil
+}
+
+func (c *machineImageClient) Get(project, name string) (*computebeta.MachineImage, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.machineImages[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	mi, ok := objs[name]
+	if !ok {
+		return nil, notFoundError()
+	}
+	return mi, nil
+}
+
+func (c *machineImageClient) List(ctx context.Context, project string) ([]*computebeta.MachineImage, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.machineImages[project]
+	if !ok {
+		return nil, nil
+	}
+	var l []*computebeta.MachineImage
+	for _, mi := range objs {
+		l = append(l, mi)
+	}
+	return l, nil
+}
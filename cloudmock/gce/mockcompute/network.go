@@ -90,3 +90,30 @@ func (c *networkClient) Get(project, name string) (*compute.Network, error) {
 	}
 	return network, nil
 }
+
+func (c *networkClient) RemovePeering(project, network string, req *compute.NetworksRemovePeeringRequest) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	networks, ok := c.networks[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	nw, ok := networks[network]
+	if !ok {
+		return nil, notFoundError()
+	}
+	var peerings []*compute.NetworkPeering
+	found := false
+	for _, p := range nw.Peerings {
+		if p.Name == req.Name {
+			found = true
+			continue
+		}
+		peerings = append(peerings, p)
+	}
+	if !found {
+		return nil, notFoundError()
+	}
+	nw.Peerings = peerings
+	return doneOperation(), nil
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mockcompute
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	compute "google.golang.org/api/compute/v1"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+type networkEndpointGroupClient struct {
+	// networkEndpointGroups are networkEndpointGroups keyed by project, zone, and networkEndpointGroup name.
+	networkEndpointGroups map[string]map[string]map[string]*compute.NetworkEndpointGroup
+	sync.Mutex
+}
+
+var _ gce.NetworkEndpointGroupClient = &networkEndpointGroupClient{}
+
+func newNetworkEndpointGroupClient() *networkEndpointGroupClient {
+	return &networkEndpointGroupClient{
+		networkEndpointGroups: map[string]map[string]map[string]*compute.NetworkEndpointGroup{},
+	}
+}
+
+func (c *networkEndpointGroupClient) All() map[string]interface{} {
+	c.Lock()
+	defer c.Unlock()
+	m := map[string]interface{}{}
+	for _, zones := range c.networkEndpointGroups {
+		for _, negs := range zones {
+			for n, neg := range negs {
+				m[n] = neg
+			}
+		}
+	}
+	return m
+}
+
+func (c *networkEndpointGroupClient) Insert(project, zone string, neg *compute.NetworkEndpointGroup) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	zones, ok := c.networkEndpointGroups[project]
+	if !ok {
+		zones = map[string]map[string]*compute.NetworkEndpointGroup{}
+		c.networkEndpointGroups[project] = zones
+	}
+	negs, ok := zones[zone]
+	if !ok {
+		negs = map[string]*compute.NetworkEndpointGroup{}
+		zones[zone] = negs
+	}
+	neg.SelfLink = fmt.Sprintf("https://www.googleapis.com/compute/v1/projects/%s/zones/%s/networkEndpointGroups/%s", project, zone, neg.Name)
+	negs[neg.Name] = neg
+	return doneOperation(), nil
+}
+
+func (c *networkEndpointGroupClient) Delete(project, zone, name string) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	zones, ok := c.networkEndpointGroups[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	negs, ok := zones[zone]
+	if !ok {
+		return nil, notFoundError()
+	}
+	if _, ok := negs[name]; !ok {
+		return nil, notFoundError()
+	}
+	delete(negs, name)
+	return doneOperation(), nil
+}
+
+func (c *networkEndpointGroupClient) Get(project, zone, name string) (*compute.NetworkEndpointGroup, error) {
+	c.Lock()
+	defer c.Unlock()
+	zones, ok := c.networkEndpointGroups[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	negs, ok := zones[zone]
+	if !ok {
+		return nil, notFoundError()
+	}
+	neg, ok := negs[name]
+	if !ok {
+		return nil, notFoundError()
+	}
+	return neg, nil
+}
+
+func (c *networkEndpointGroupClient) List(ctx context.Context, project, zone string) ([]*compute.NetworkEndpointGroup, error) {
+	c.Lock()
+	defer c.Unlock()
+	zones, ok := c.networkEndpointGroups[project]
+	if !ok {
+		return nil, nil
+	}
+	negs, ok := zones[zone]
+	if !ok {
+		return nil, nil
+	}
+	var l []*compute.NetworkEndpointGroup
+	for _, neg := range negs {
+		l = append(l, neg)
+	}
+	return l, nil
+}
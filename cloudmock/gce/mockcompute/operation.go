@@ -0,0 +1,36 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mockcompute
+
+import (
+	"context"
+
+	compute "google.golang.org/api/compute/v1"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+// operationClient gets mock operations, which are always done
+type operationClient struct{}
+
+var _ gce.OperationClient = &operationClient{}
+
+func (c *operationClient) Get(ctx context.Context, op *compute.Operation) (*compute.Operation, error) {
+	done := doneOperation()
+	done.Name = op.Name
+	done.SelfLink = op.SelfLink
+	return done, nil
+}
@@ -17,12 +17,19 @@ limitations under the License.
 package mockcompute
 
 import (
+	"fmt"
+	"sync"
+
 	compute "google.golang.org/api/compute/v1"
+	"google.golang.org/api/googleapi"
 	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
 )
 
 type projectClient struct {
 	projects map[string]*compute.Project
+	// metadataVersion is bumped on each change of the project metadata, for its fingerprint
+	metadataVersion int
+	sync.Mutex
 }
 
 var _ gce.ProjectClient = &projectClient{}
@@ -38,6 +45,8 @@ func newProjectClient(project string) *projectClient {
 }
 
 func (c *projectClient) All() map[string]interface{} {
+	c.Lock()
+	defer c.Unlock()
 	m := map[string]interface{}{}
 	for n, p := range c.projects {
 		m[n] = p
@@ -46,9 +55,37 @@ func (c *projectClient) All() map[string]interface{} {
 }
 
 func (c *projectClient) Get(project string) (*compute.Project, error) {
+	c.Lock()
+	defer c.Unlock()
 	p, ok := c.projects[project]
 	if !ok {
 		return nil, notFoundError()
 	}
 	return p, nil
 }
+
+// SetCommonInstanceMetadata replaces the project metadata, failing as GCE does if the fingerprint is not that of the current metadata
+func (c *projectClient) SetCommonInstanceMetadata(project string, metadata *compute.Metadata) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	p, ok := c.projects[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	fingerprint := ""
+	if p.CommonInstanceMetadata != nil {
+		fingerprint = p.CommonInstanceMetadata.Fingerprint
+	}
+	if metadata.Fingerprint != fingerprint {
+		return nil, &googleapi.Error{
+			Code:    412,
+			Message: "Supplied fingerprint does not match current metadata fingerprint.",
+		}
+	}
+	c.metadataVersion++
+	p.CommonInstanceMetadata = &compute.Metadata{
+		Fingerprint: fmt.Sprintf("fingerprint-%d", c.metadataVersion),
+		Items:       metadata.Items,
+	}
+	return doneOperation(), nil
+}
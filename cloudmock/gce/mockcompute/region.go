@@ -0,0 +1,82 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mockcompute
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	compute "google.golang.org/api/compute/v1"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+type regionClient struct {
+	// regions are regions keyed by project and region name.
+	regions map[string]map[string]*compute.Region
+}
+
+var _ gce.RegionClient = &regionClient{}
+
+func newRegionClient(project string) *regionClient {
+	return &regionClient{
+		regions: map[string]map[string]*compute.Region{
+			project: {
+				"us-test1": {
+					Name:     "us-test1",
+					SelfLink: "https://www.googleapis.com/compute/v1/projects/testproject/regions/us-test1",
+				},
+			},
+		},
+	}
+}
+
+func (c *regionClient) List(ctx context.Context, project string) ([]*compute.Region, error) {
+	regions, ok := c.regions[project]
+	if !ok {
+		return nil, nil
+	}
+	var l []*compute.Region
+	for _, r := range regions {
+		l = append(l, r)
+	}
+	sort.Slice(l, func(i, j int) bool {
+		return l[i].Name < l[j].Name
+	})
+	return l, nil
+}
+
+// AddRegion adds a region with the given zones to the project.
+func (c *MockClient) AddRegion(project, region string, zones ...string) {
+	selfLink := fmt.Sprintf("https://www.googleapis.com/compute/v1/projects/%s/regions/%s", project, region)
+	if c.regionClient.regions[project] == nil {
+		c.regionClient.regions[project] = map[string]*compute.Region{}
+	}
+	c.regionClient.regions[project][region] = &compute.Region{
+		Name:     region,
+		SelfLink: selfLink,
+	}
+	if c.zoneClient.zones[project] == nil {
+		c.zoneClient.zones[project] = map[string]*compute.Zone{}
+	}
+	for _, zone := range zones {
+		c.zoneClient.zones[project][zone] = &compute.Zone{
+			Name:   zone,
+			Region: selfLink,
+		}
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mockcompute
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	compute "google.golang.org/api/compute/v1"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+type regionDiskClient struct {
+	// disks are disks keyed by project, region, and name.
+	disks map[string]map[string]map[string]*compute.Disk
+	sync.Mutex
+}
+
+var _ gce.RegionDiskClient = &regionDiskClient{}
+
+func newRegionDiskClient() *regionDiskClient {
+	return &regionDiskClient{
+		disks: map[string]map[string]map[string]*compute.Disk{},
+	}
+}
+
+func (c *regionDiskClient) All() map[string]interface{} {
+	c.Lock()
+	defer c.Unlock()
+	m := map[string]interface{}{}
+	for _, regions := range c.disks {
+		for _, objs := range regions {
+			for n, disk := range objs {
+				m[n] = disk
+			}
+		}
+	}
+	return m
+}
+
+func (c *regionDiskClient) Insert(project, region string, disk *compute.Disk) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	regions, ok := c.disks[project]
+	if !ok {
+		regions = map[string]map[string]*compute.Disk{}
+		c.disks[project] = regions
+	}
+	objs, ok := regions[region]
+	if !ok {
+		objs = map[string]*compute.Disk{}
+		regions[region] = objs
+	}
+	disk.SelfLink = fmt.Sprintf("https://www.googleapis.com/compute/v1/projects/%s/regions/%s/disks/%s", project, region, disk.Name)
+	objs[disk.Name] = disk
+	return doneOperation(), nil
+}
+
+func (c *regionDiskClient) Delete(project, region, name string) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	regions, ok := c.disks[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	objs, ok := regions[region]
+	if !ok {
+		return nil, notFoundError()
+	}
+	if _, ok := objs[name]; !ok {
+		return nil, notFoundError()
+	}
+	delete(objs, name)
+	return doneOperation(), nil
+}
+
+func (c *regionDiskClient) Get(project, region, name string) (*compute.Disk, error) {
+	c.Lock()
+	defer c.Unlock()
+	regions, ok := c.disks[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	objs, ok := regions[region]
+	if !ok {
+		return nil, notFoundError()
+	}
+	disk, ok := objs[name]
+	if !ok {
+		return nil, notFoundError()
+	}
+	return disk, nil
+}
+
+func (c *regionDiskClient) List(ctx context.Context, project, region string) ([]*compute.Disk, error) {
+	c.Lock()
+	defer c.Unlock()
+	regions, ok := c.disks[project]
+	if !ok {
+		return nil, nil
+	}
+	objs, ok := regions[region]
+	if !ok {
+		return nil, nil
+	}
+	var l []*compute.Disk
+	for _, disk := range objs {
+		l = append(l, disk)
+	}
+	return l, nil
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mockcompute
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	compute "google.golang.org/api/compute/v1"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+type regionInstanceGroupManagerClient struct {
+	// instanceGroupManagers are regional instanceGroupManagers keyed by project, region, and name.
+	instanceGroupManagers map[string]map[string]map[string]*compute.InstanceGroupManager
+	// managedInstances are the instances of each instanceGroupManager, keyed by its self link.
+	managedInstances map[string][]*compute.ManagedInstance
+	sync.Mutex
+}
+
+var _ gce.RegionInstanceGroupManagerClient = &regionInstanceGroupManagerClient{}
+
+func newRegionInstanceGroupManagerClient() *regionInstanceGroupManagerClient {
+	return &regionInstanceGroupManagerClient{
+		instanceGroupManagers: map[string]map[string]map[string]*compute.InstanceGroupManager{},
+		managedInstances:      map[string][]*compute.ManagedInstance{},
+	}
+}
+
+func (c *regionInstanceGroupManagerClient) All() map[string]interface{} {
+	c.Lock()
+	defer c.Unlock()
+	m := map[string]interface{}{}
+	for _, regions := range c.instanceGroupManagers {
+		for _, igms := range regions {
+			for n, igm := range igms {
+				m[n] = igm
+			}
+		}
+	}
+	return m
+}
+
+func (c *regionInstanceGroupManagerClient) Insert(project, region string, igm *compute.InstanceGroupManager) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	regions, ok := c.instanceGroupManagers[project]
+	if !ok {
+		regions = map[string]map[string]*compute.InstanceGroupManager{}
+		c.instanceGroupManagers[project] = regions
+	}
+	igms, ok := regions[region]
+	if !ok {
+		igms = map[string]*compute.InstanceGroupManager{}
+		regions[region] = igms
+	}
+	igm.SelfLink = fmt.Sprintf("https://www.googleapis.com/compute/v1/projects/%s/regions/%s/instanceGroupManagers/%s", project, region, igm.Name)
+	igm.InstanceGroup = fmt.Sprintf("https://www.googleapis.com/compute/v1/projects/%s/regions/%s/instanceGroups/%s", project, region, igm.Name)
+	igm.Region = region
+	igms[igm.Name] = igm
+	return doneOperation(), nil
+}
+
+func (c *regionInstanceGroupManagerClient) Delete(project, region, name string) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	regions, ok := c.instanceGroupManagers[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	igms, ok := regions[region]
+	if !ok {
+		return nil, notFoundError()
+	}
+	if _, ok := igms[name]; !ok {
+		return nil, notFoundError()
+	}
+	delete(igms, name)
+	return doneOperation(), nil
+}
+
+func (c *regionInstanceGroupManagerClient) Get(project, region, name string) (*compute.InstanceGroupManager, error) {
+	c.Lock()
+	defer c.Unlock()
+	regions, ok := c.instanceGroupManagers[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	igms, ok := regions[region]
+	if !ok {
+		return nil, notFoundError()
+	}
+	igm, ok := igms[name]
+	if !ok {
+		return nil, notFoundError()
+	}
+	return igm, nil
+}
+
+func (c *regionInstanceGroupManagerClient) List(ctx context.Context, project, region string) ([]*compute.InstanceGroupManager, error) {
+	c.Lock()
+	defer c.Unlock()
+	regions, ok := c.instanceGroupManagers[project]
+	if !ok {
+		return nil, nil
+	}
+	igms, ok := regions[region]
+	if !ok {
+		return nil, nil
+	}
+	var l []*compute.InstanceGroupManager
+	for _, igm := range igms {
+		l = append(l, igm)
+	}
+	return l, nil
+}
+
+func (c *regionInstanceGroupManagerClient) ListManagedInstances(ctx context.Context, project, region, name string) ([]*compute.ManagedInstance, error) {
+	c.Lock()
+	defer c.Unlock()
+	selfLink := fmt.Sprintf("https://www.googleapis.com/compute/v1/projects/%s/regions/%s/instanceGroupManagers/%s", project, region, name)
+	return c.managedInstances[selfLink], nil
+}
+
+// AddManagedInstance records an instance as managed by the instanceGroupManager, so it is returned by ListManagedInstances.
+func (c *regionInstanceGroupManagerClient) AddManagedInstance(igm *compute.InstanceGroupManager, instance *compute.ManagedInstance) {
+	c.Lock()
+	defer c.Unlock()
+	c.managedInstances[igm.SelfLink] = append(c.managedInstances[igm.SelfLink], instance)
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mockcompute
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	compute "google.golang.org/api/compute/v1"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+type resourcePolicyClient struct {
+	// resourcePolicies are resourcePolicies keyed by project, region, and resource policy name.
+	resourcePolicies map[string]map[string]map[string]*compute.ResourcePolicy
+	sync.Mutex
+}
+
+var _ gce.ResourcePolicyClient = &resourcePolicyClient{}
+
+func newResourcePolicyClient() *resourcePolicyClient {
+	return &resourcePolicyClient{
+		resourcePolicies: map[string]map[string]map[string]*compute.ResourcePolicy{},
+	}
+}
+
+func (c *resourcePolicyClient) All() map[string]interface{} {
+	c.Lock()
+	defer c.Unlock()
+	m := map[string]interface{}{}
+	for _, regions := range c.resourcePolicies {
+		for _, rps := range regions {
+			for n, rp := range rps {
+				m[n] = rp
+			}
+		}
+	}
+	return m
+}
+
+func (c *resourcePolicyClient) Insert(project, region string, rp *compute.ResourcePolicy) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	regions, ok := c.resourcePolicies[project]
+	if !ok {
+		regions = map[string]map[string]*compute.ResourcePolicy{}
+		c.resourcePolicies[project] = regions
+	}
+	rps, ok := regions[region]
+	if !ok {
+		rps = map[string]*compute.ResourcePolicy{}
+		regions[region] = rps
+	}
+	rp.SelfLink = fmt.Sprintf("https://www.googleapis.com/compute/v1/projects/%s/regions/%s/resourcePolicies/%s", project, region, rp.Name)
+	rps[rp.Name] = rp
+	return doneOperation(), nil
+}
+
+func (c *resourcePolicyClient) Delete(project, region, name string) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	regions, ok := c.resourcePolicies[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	rps, ok := regions[region]
+	if !ok {
+		return nil, notFoundError()
+	}
+	if _, ok := rps[name]; !ok {
+		return nil, notFoundError()
+	}
+	delete(rps, name)
+	return doneOperation(), nil
+}
+
+func (c *resourcePolicyClient) Get(project, region, name string) (*compute.ResourcePolicy, error) {
+	c.Lock()
+	defer c.Unlock()
+	regions, ok := c.resourcePolicies[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	rps, ok := regions[region]
+	if !ok {
+		return nil, notFoundError()
+	}
+	rp, ok := rps[name]
+	if !ok {
+		return nil, notFoundError()
+	}
+	return rp, nil
+}
+
+func (c *resourcePolicyClient) List(ctx context.Context, project, region string) ([]*compute.ResourcePolicy, error) {
+	c.Lock()
+	defer c.Unlock()
+	regions, ok := c.resourcePolicies[project]
+	if !ok {
+		return nil, nil
+	}
+	rps, ok := regions[region]
+	if !ok {
+		return nil, nil
+	}
+	var l []*compute.ResourcePolicy
+	for _, rp := range rps {
+		l = append(l, rp)
+	}
+	return l, nil
+}
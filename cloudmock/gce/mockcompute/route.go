@@ -18,6 +18,7 @@ package mockcompute
 
 import (
 	"context"
+	"fmt"
 	"sync"
 
 	compute "google.golang.org/api/compute/v1"
@@ -50,6 +51,19 @@ func (c *routeClient) All() map[string]interface{} {
 	return m
 }
 
+func (c *routeClient) Insert(project string, r *compute.Route) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	routes, ok := c.routes[project]
+	if !ok {
+		routes = map[string]*compute.Route{}
+		c.routes[project] = routes
+	}
+	r.SelfLink = fmt.Sprintf("https://www.googleapis.com/compute/v1/projects/%s/global/routes/%s", project, r.Name)
+	routes[r.Name] = r
+	return doneOperation(), nil
+}
+
 func (c *routeClient) Delete(project, name string) (*compute.Operation, error) {
 	c.Lock()
 	defer c.Unlock()
@@ -107,6 +107,34 @@ func (c *routerClient) Get(project, region, name string) (*compute.Router, error
 	return r, nil
 }
 
+func (c *routerClient) Patch(project, region, name string, r *compute.Router) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	regions, ok := c.routers[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	rs, ok := regions[region]
+	if !ok {
+		return nil, notFoundError()
+	}
+	existing, ok := rs[name]
+	if !ok {
+		return nil, notFoundError()
+	}
+	// We only support patching the NATs, BGP peers and BGP settings for now; as in GCE, fields that are not sent are left alone
+	if r.Nats != nil || forceSent(r.ForceSendFields, "Nats") {
+		existing.Nats = r.Nats
+	}
+	if r.BgpPeers != nil || forceSent(r.ForceSendFields, "BgpPeers") {
+		existing.BgpPeers = r.BgpPeers
+	}
+	if r.Bgp != nil {
+		existing.Bgp = r.Bgp
+	}
+	return doneOperation(), nil
+}
+
 func (c *routerClient) List(ctx context.Context, project, region string) ([]*compute.Router, error) {
 	c.Lock()
 	defer c.Unlock()
@@ -124,3 +152,13 @@ func (c *routerClient) List(ctx context.Context, project, region string) ([]*com
 	}
 	return l, nil
 }
+
+// forceSent returns true if the field is in the ForceSendFields of a patch
+func forceSent(forceSendFields []string, field string) bool {
+	for _, f := range forceSendFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mockcompute
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	compute "google.golang.org/api/compute/v1"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+type securityPolicyClient struct {
+	// securityPolicies are securityPolicies keyed by project and name.
+	securityPolicies map[string]map[string]*compute.SecurityPolicy
+	sync.Mutex
+}
+
+var _ gce.SecurityPolicyClient = &securityPolicyClient{}
+
+func newSecurityPolicyClient() *securityPolicyClient {
+	return &securityPolicyClient{
+		securityPolicies: map[string]map[string]*compute.SecurityPolicy{},
+	}
+}
+
+func (c *securityPolicyClient) All() map[string]interface{} {
+	c.Lock()
+	defer c.Unlock()
+	m := map[string]interface{}{}
+	for _, objs := range c.securityPolicies {
+		for n, sp := range objs {
+			m[n] = sp
+		}
+	}
+	return m
+}
+
+func (c *securityPolicyClient) Insert(project string, sp *compute.SecurityPolicy) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.securityPolicies[project]
+	if !ok {
+		objs = map[string]*compute.SecurityPolicy{}
+		c.securityPolicies[project] = objs
+	}
+	sp.SelfLink = fmt.Sprintf("https://www.googleapis.com/compute/v1/projects/%s/global/securityPolicies/%s", project, sp.Name)
+	objs[sp.Name] = sp
+	return doneOperation(), nil
+}
+
+func (c *securityPolicyClient) Delete(project, name string) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.securityPolicies[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	if _, ok := objs[name]; !ok {
+		return nil, notFoundError()
+	}
+	delete(objs, name)
+	return doneOperation(), nil
+}
+
+func (c *securityPolicyClient) Get(project, name string) (*compute.SecurityPolicy, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.securityPolicies[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	sp, ok := objs[name]
+	if !ok {
+		return nil, notFoundError()
+	}
+	return sp, nil
+}
+
+func (c *securityPolicyClient) List(ctx context.Context, project string) ([]*compute.SecurityPolicy, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.securityPolicies[project]
+	if !ok {
+		return nil, nil
+	}
+	var l []*compute.SecurityPolicy
+	for _, sp := range objs {
+		l = append(l, sp)
+	}
+	return l, nil
+}
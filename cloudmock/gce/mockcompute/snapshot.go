@@ -0,0 +1,122 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mockcompute
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	compute "google.golang.org/api/compute/v1"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+type snapshotClient struct {
+	// snapshots are snapshots keyed by project and name.
+	snapshots map[string]map[string]*compute.Snapshot
+	sync.Mutex
+}
+
+var _ gce.SnapshotClient = &snapshotClient{}
+
+func newSnapshotClient() *snapshotClient {
+	return &snapshotClient{
+		snapshots: map[string]map[string]*compute.Snapshot{},
+	}
+}
+
+func (c *snapshotClient) All() map[string]interface{} {
+	c.Lock()
+	defer c.Unlock()
+	m := map[string]interface{}{}
+	for _, objs := range c.snapshots {
+		for n, s := range objs {
+			m[n] = s
+		}
+	}
+	return m
+}
+
+func (c *snapshotClient) Insert(project string, s *compute.Snapshot) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.snapshots[project]
+	if !ok {
+		objs = map[string]*compute.Snapshot{}
+		c.snapshots[project] = objs
+	}
+	s.SelfLink = fmt.Sprintf("https://www.googleapis.com/compute/v1/projects/%s/global/snapshots/%s", project, s.Name)
+	objs[s.Name] = s
+	return doneOperation(), nil
+}
+
+func (c *snapshotClient) Delete(project, name string) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.snapshots[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	if _, ok := objs[name]; !ok {
+		return nil, notFoundError()
+	}
+	delete(objs, name)
+	return doneOperation(), nil
+}
+
+func (c *snapshotClient) Get(project, name string) (*compute.Snapshot, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.snapshots[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	s, ok := objs[name]
+	if !ok {
+		return nil, notFoundError()
+	}
+	return s, nil
+}
+
+func (c *snapshotClient) SetLabels(project, name string, req *compute.GlobalSetLabelsRequest) error {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.snapshots[project]
+	if !ok {
+		return notFoundError()
+	}
+	s, ok := objs[name]
+	if !ok {
+		return notFoundError()
+	}
+	s.Labels = req.Labels
+	return nil
+}
+
+func (c *snapshotClient) List(ctx context.Context, project string) ([]*compute.Snapshot, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.snapshots[project]
+	if !ok {
+		return nil, nil
+	}
+	var l []*compute.Snapshot
+	for _, s := range objs {
+		l = append(l, s)
+	}
+	return l, nil
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mockcompute
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	compute "google.golang.org/api/compute/v1"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+type sslCertificateClient struct {
+	// sslCertificates are sslCertificates keyed by project and name.
+	sslCertificates map[string]map[string]*compute.SslCertificate
+	sync.Mutex
+}
+
+var _ gce.SslCertificateClient = &sslCertificateClient{}
+
+func newSslCertificateClient() *sslCertificateClient {
+	return &sslCertificateClient{
+		sslCertificates: map[string]map[string]*compute.SslCertificate{},
+	}
+}
+
+func (c *sslCertificateClient) All() map[string]interface{} {
+	c.Lock()
+	defer c.Unlock()
+	m := map[string]interface{}{}
+	for _, objs := range c.sslCertificates {
+		for n, cert := range objs {
+			m[n] = cert
+		}
+	}
+	return m
+}
+
+func (c *sslCertificateClient) Insert(project string, cert *compute.SslCertificate) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.sslCertificates[project]
+	if !ok {
+		objs = map[string]*compute.SslCertificate{}
+		c.sslCertificates[project] = objs
+	}
+	cert.SelfLink = fmt.Sprintf("https://www.googleapis.com/compute/v1/projects/%s/global/sslCertificates/%s", project, cert.Name)
+	objs[cert.Name] = cert
+	return doneOperation(), nil
+}
+
+func (c *sslCertificateClient) Delete(project, name string) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.sslCertificates[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	if _, ok := objs[name]; !ok {
+		return nil, notFoundError()
+	}
+	delete(objs, name)
+	return doneOperation(), nil
+}
+
+func (c *sslCertificateClient) Get(project, name string) (*compute.SslCertificate, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.sslCertificates[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	cert, ok := objs[name]
+	if !ok {
+		return nil, notFoundError()
+	}
+	return cert, nil
+}
+
+func (c *sslCertificateClient) List(ctx context.Context, project string) ([]*compute.SslCertificate, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.sslCertificates[project]
+	if !ok {
+		return nil, nil
+	}
+	var l []*compute.SslCertificate
+	for _, cert := range objs {
+		l = append(l, cert)
+	}
+	return l, nil
+}
+
+type regionSslCertificateClient struct {
+	// sslCertificates are sslCertificates keyed by project, region, and name.
+	sslCertificates map[string]map[string]map[string]*compute.SslCertificate
+	sync.Mutex
+}
+
+var _ gce.RegionSslCertificateClient = &regionSslCertificateClient{}
+
+func newRegionSslCertificateClient() *regionSslCertificateClient {
+	return &regionSslCertificateClient{
+		sslCertificates: map[string]map[string]map[string]*compute.SslCertificate{},
+	}
+}
+
+func (c *regionSslCertificateClient) All() map[string]interface{} {
+	c.Lock()
+	defer c.Unlock()
+	m := map[string]interface{}{}
+	for _, regions := range c.sslCertificates {
+		for _, objs := range regions {
+			for n, cert := range objs {
+				m[n] = cert
+			}
+		}
+	}
+	return m
+}
+
+func (c *regionSslCertificateClient) Insert(project, region string, cert *compute.SslCertificate) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	regions, ok := c.sslCertificates[project]
+	if !ok {
+		regions = map[string]map[string]*compute.SslCertificate{}
+		c.sslCertificates[project] = regions
+	}
+	objs, ok := regions[region]
+	if !ok {
+		objs = map[string]*compute.SslCertificate{}
+		regions[region] = objs
+	}
+	cert.SelfLink = fmt.Sprintf("https://www.googleapis.com/compute/v1/projects/%s/regions/%s/sslCertificates/%s", project, region, cert.Name)
+	objs[cert.Name] = cert
+	return doneOperation(), nil
+}
+
+func (c *regionSslCertificateClient) Delete(project, region, name string) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	regions, ok := c.sslCertificates[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	objs, ok := regions[region]
+	if !ok {
+		return nil, notFoundError()
+	}
+	if _, ok := objs[name]; !ok {
+		return nil, notFoundError()
+	}
+	delete(objs, name)
+	return doneOperation(), nil
+}
+
+func (c *regionSslCertificateClient) Get(project, region, name string) (*compute.SslCertificate, error) {
+	c.Lock()
+	defer c.Unlock()
+	regions, ok := c.sslCertificates[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	objs, ok := regions[region]
+	if !ok {
+		return nil, notFoundError()
+	}
+	cert, ok := objs[name]
+	if !ok {
+		return nil, notFoundError()
+	}
+	return cert, nil
+}
+
+func (c *regionSslCertificateClient) List(ctx context.Context, project, region string) ([]*compute.SslCertificate, error) {
+	c.Lock()
+	defer c.Unlock()
+	regions, ok := c.sslCertificates[project]
+	if !ok {
+		return nil, nil
+	}
+	objs, ok := regions[region]
+	if !ok {
+		return nil, nil
+	}
+	var l []*compute.SslCertificate
+	for _, cert := range objs {
+		l = append(l, cert)
+	}
+	return l, nil
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mockcompute
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	compute "google.golang.org/api/compute/v1"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+type sslPolicyClient struct {
+	// sslPolicies are sslPolicies keyed by project and name.
+	sslPolicies map[string]map[string]*compute.SslPolicy
+	sync.Mutex
+}
+
+var _ gce.SslPolicyClient = &sslPolicyClient{}
+
+func newSslPolicyClient() *sslPolicyClient {
+	return &sslPolicyClient{
+		sslPolicies: map[string]map[string]*compute.SslPolicy{},
+	}
+}
+
+func (c *sslPolicyClient) All() map[string]interface{} {
+	c.Lock()
+	defer c.Unlock()
+	m := map[string]interface{}{}
+	for _, objs := range c.sslPolicies {
+		for n, sp := range objs {
+			m[n] = sp
+		}
+	}
+	return m
+}
+
+func (c *sslPolicyClient) Insert(project string, sp *compute.SslPolicy) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.sslPolicies[project]
+	if !ok {
+		objs = map[string]*compute.SslPolicy{}
+		c.sslPolicies[project] = objs
+	}
+	sp.SelfLink = fmt.Sprintf("https://www.googleapis.com/compute/v1/projects/%s/global/sslPolicies/%s", project, sp.Name)
+	objs[sp.Name] = sp
+	return doneOperation(), nil
+}
+
+func (c *sslPolicyClient) Delete(project, name string) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.sslPolicies[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	if _, ok := objs[name]; !ok {
+		return nil, notFoundError()
+	}
+	delete(objs, name)
+	return doneOperation(), nil
+}
+
+func (c *sslPolicyClient) Get(project, name string) (*compute.SslPolicy, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.sslPolicies[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	sp, ok := objs[name]
+	if !ok {
+		return nil, notFoundError()
+	}
+	return sp, nil
+}
+
+func (c *sslPolicyClient) List(ctx context.Context, project string) ([]*compute.SslPolicy, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.sslPolicies[project]
+	if !ok {
+		return nil, nil
+	}
+	var l []*compute.SslPolicy
+	for _, sp := range objs {
+		l = append(l, sp)
+	}
+	return l, nil
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mockcompute
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	compute "google.golang.org/api/compute/v1"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+type targetHttpProxyClient struct {
+	// targetHttpProxies are targetHttpProxies keyed by project and name.
+	targetHttpProxies map[string]map[string]*compute.TargetHttpProxy
+	sync.Mutex
+}
+
+var _ gce.TargetHttpProxyClient = &targetHttpProxyClient{}
+
+func newTargetHttpProxyClient() *targetHttpProxyClient {
+	return &targetHttpProxyClient{
+		targetHttpProxies: map[string]map[string]*compute.TargetHttpProxy{},
+	}
+}
+
+func (c *targetHttpProxyClient) All() map[string]interface{} {
+	c.Lock()
+	defer c.Unlock()
+	m := map[string]interface{}{}
+	for _, objs := range c.targetHttpProxies {
+		for n, p := range objs {
+			m[n] = p
+		}
+	}
+	return m
+}
+
+func (c *targetHttpProxyClient) Insert(project string, p *compute.TargetHttpProxy) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.targetHttpProxies[project]
+	if !ok {
+		objs = map[string]*compute.TargetHttpProxy{}
+		c.targetHttpProxies[project] = objs
+	}
+	p.SelfLink = fmt.Sprintf("https://www.googleapis.com/compute/v1/projects/%s/global/targetHttpProxies/%s", project, p.Name)
+	objs[p.Name] = p
+	return doneOperation(), nil
+}
+
+func (c *targetHttpProxyClient) Delete(project, name string) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.targetHttpProxies[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	if _, ok := objs[name]; !ok {
+		return nil, notFoundError()
+	}
+	delete(objs, name)
+	return doneOperation(), nil
+}
+
+func (c *targetHttpProxyClient) Get(project, name string) (*compute.TargetHttpProxy, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.targetHttpProxies[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	p, ok := objs[name]
+	if !ok {
+		return nil, notFoundError()
+	}
+	return p, nil
+}
+
+func (c *targetHttpProxyClient) List(ctx context.Context, project string) ([]*compute.TargetHttpProxy, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.targetHttpProxies[project]
+	if !ok {
+		return nil, nil
+	}
+	var l []*compute.TargetHttpProxy
+	for _, p := range objs {
+		l = append(l, p)
+	}
+	return l, nil
+}
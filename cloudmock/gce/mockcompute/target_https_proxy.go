@@ -0,0 +1,107 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mockcompute
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	compute "google.golang.org/api/compute/v1"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+type targetHttpsProxyClient struct {
+	// targetHttpsProxies are targetHttpsProxies keyed by project and name.
+	targetHttpsProxies map[string]map[string]*compute.TargetHttpsProxy
+	sync.Mutex
+}
+
+var _ gce.TargetHttpsProxyClient = &targetHttpsProxyClient{}
+
+func newTargetHttpsProxyClient() *targetHttpsProxyClient {
+	return &targetHttpsProxyClient{
+		targetHttpsProxies: map[string]map[string]*compute.TargetHttpsProxy{},
+	}
+}
+
+func (c *targetHttpsProxyClient) All() map[string]interface{} {
+	c.Lock()
+	defer c.Unlock()
+	m := map[string]interface{}{}
+	for _, objs := range c.targetHttpsProxies {
+		for n, p := range objs {
+			m[n] = p
+		}
+	}
+	return m
+}
+
+func (c *targetHttpsProxyClient) Insert(project string, p *compute.TargetHttpsProxy) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.targetHttpsProxies[project]
+	if !ok {
+		objs = map[string]*compute.TargetHttpsProxy{}
+		c.targetHttpsProxies[project] = objs
+	}
+	p.SelfLink = fmt.Sprintf("https://www.googleapis.com/compute/v1/projects/%s/global/targetHttpsProxies/%s", project, p.Name)
+	objs[p.Name] = p
+	return doneOperation(), nil
+}
+
+func (c *targetHttpsProxyClient) Delete(project, name string) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.targetHttpsProxies[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	if _, ok := objs[name]; !ok {
+		return nil, notFoundError()
+	}
+	delete(objs, name)
+	return doneOperation(), nil
+}
+
+func (c *targetHttpsProxyClient) Get(project, name string) (*compute.TargetHttpsProxy, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.targetHttpsProxies[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	p, ok := objs[name]
+	if !ok {
+		return nil, notFoundError()
+	}
+	return p, nil
+}
+
+func (c *targetHttpsProxyClient) List(ctx context.Context, project string) ([]*compute.TargetHttpsProxy, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.targetHttpsProxies[project]
+	if !ok {
+		return nil, nil
+	}
+	var l []*compute.TargetHttpsProxy
+	for _, p := range objs {
+		l = append(l, p)
+	}
+	return l, nil
+}
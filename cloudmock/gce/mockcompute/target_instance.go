@@ -0,0 +1,126 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mockcompute
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	compute "google.golang.org/api/compute/v1"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+type targetInstanceClient struct {
+	// targetInstances are targetInstances keyed by project, zone, and targetInstance name.
+	targetInstances map[string]map[string]map[string]*compute.TargetInstance
+	sync.Mutex
+}
+
+var _ gce.TargetInstanceClient = &targetInstanceClient{}
+
+func newTargetInstanceClient() *targetInstanceClient {
+	return &targetInstanceClient{
+		targetInstances: map[string]map[string]map[string]*compute.TargetInstance{},
+	}
+}
+
+func (c *targetInstanceClient) All() map[string]interface{} {
+	c.Lock()
+	defer c.Unlock()
+	m := map[string]interface{}{}
+	for _, zones := range c.targetInstances {
+		for _, tis := range zones {
+			for n, ti := range tis {
+				m[n] = ti
+			}
+		}
+	}
+	return m
+}
+
+func (c *targetInstanceClient) Insert(project, zone string, ti *compute.TargetInstance) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	zones, ok := c.targetInstances[project]
+	if !ok {
+		zones = map[string]map[string]*compute.TargetInstance{}
+		c.targetInstances[project] = zones
+	}
+	tis, ok := zones[zone]
+	if !ok {
+		tis = map[string]*compute.TargetInstance{}
+		zones[zone] = tis
+	}
+	ti.SelfLink = fmt.Sprintf("https://www.googleapis.com/compute/v1/projects/%s/zones/%s/targetInstances/%s", project, zone, ti.Name)
+	tis[ti.Name] = ti
+	return doneOperation(), nil
+}
+
+func (c *targetInstanceClient) Delete(project, zone, name string) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	zones, ok := c.targetInstances[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	tis, ok := zones[zone]
+	if !ok {
+		return nil, notFoundError()
+	}
+	if _, ok := tis[name]; !ok {
+		return nil, notFoundError()
+	}
+	delete(tis, name)
+	return doneOperation(), nil
+}
+
+func (c *targetInstanceClient) Get(project, zone, name string) (*compute.TargetInstance, error) {
+	c.Lock()
+	defer c.Unlock()
+	zones, ok := c.targetInstances[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	tis, ok := zones[zone]
+	if !ok {
+		return nil, notFoundError()
+	}
+	ti, ok := tis[name]
+	if !ok {
+		return nil, notFoundError()
+	}
+	return ti, nil
+}
+
+func (c *targetInstanceClient) List(ctx context.Context, project, zone string) ([]*compute.TargetInstance, error) {
+	c.Lock()
+	defer c.Unlock()
+	zones, ok := c.targetInstances[project]
+	if !ok {
+		return nil, nil
+	}
+	tis, ok := zones[zone]
+	if !ok {
+		return nil, nil
+	}
+	var l []*compute.TargetInstance
+	for _, ti := range tis {
+		l = append(l, ti)
+	}
+	return l, nil
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mockcompute
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	compute "google.golang.org/api/compute/v1"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+type urlMapClient struct {
+	// urlMaps are urlMaps keyed by project and name.
+	urlMaps map[string]map[string]*compute.UrlMap
+	sync.Mutex
+}
+
+var _ gce.UrlMapClient = &urlMapClient{}
+
+func newUrlMapClient() *urlMapClient {
+	return &urlMapClient{
+		urlMaps: map[string]map[string]*compute.UrlMap{},
+	}
+}
+
+func (c *urlMapClient) All() map[string]interface{} {
+	c.Lock()
+	defer c.Unlock()
+	m := map[string]interface{}{}
+	for _, objs := range c.urlMaps {
+		for n, um := range objs {
+			m[n] = um
+		}
+	}
+	return m
+}
+
+func (c *urlMapClient) Insert(project string, um *compute.UrlMap) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.urlMaps[project]
+	if !ok {
+		objs = map[string]*compute.UrlMap{}
+		c.urlMaps[project] = objs
+	}
+	um.SelfLink = fmt.Sprintf("https://www.googleapis.com/compute/v1/projects/%s/global/urlMaps/%s", project, um.Name)
+	objs[um.Name] = um
+	return doneOperation(), nil
+}
+
+func (c *urlMapClient) Delete(project, name string) (*compute.Operation, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.urlMaps[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	if _, ok := objs[name]; !ok {
+		return nil, notFoundError()
+	}
+	delete(objs, name)
+	return doneOperation(), nil
+}
+
+func (c *urlMapClient) Get(project, name string) (*compute.UrlMap, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.urlMaps[project]
+	if !ok {
+		return nil, notFoundError()
+	}
+	um, ok := objs[name]
+	if !ok {
+		return nil, notFoundError()
+	}
+	return um, nil
+}
+
+func (c *urlMapClient) List(ctx context.Context, project string) ([]*compute.UrlMap, error) {
+	c.Lock()
+	defer c.Unlock()
+	objs, ok := c.urlMaps[project]
+	if !ok {
+		return nil, nil
+	}
+	var l []*compute.UrlMap
+	for _, um := range objs {
+		l = append(l, um)
+	}
+	return l, nil
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package mockdns
 
 import (
+	dns "google.golang.org/api/dns/v1"
 	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
 )
 
@@ -49,3 +50,35 @@ func (c *MockClient) ResourceRecordSets() gce.ResourceRecordSetClient {
 func (c *MockClient) Changes() gce.ChangeClient {
 	return c.changeClient
 }
+
+// InsertManagedZone adds a managed zone to the mock, so that tests can set up DNS state.
+func (c *MockClient) InsertManagedZone(project string, mz *dns.ManagedZone) {
+	mzs, ok := c.managedZoneClient.managedZones[project]
+	if !ok {
+		mzs = map[string]*dns.ManagedZone{}
+		c.managedZoneClient.managedZones[project] = mzs
+	}
+	mzs[mz.Name] = mz
+}
+
+// InsertResourceRecordSet adds a record set to a managed zone in the mock, so that tests can set up DNS state.
+func (c *MockClient) InsertResourceRecordSet(project, zone string, rrs *dns.ResourceRecordSet) {
+	zones, ok := c.resourceRecordSetClient.resourceRecordSets[project]
+	if !ok {
+		zones = map[string]map[string]*dns.ResourceRecordSet{}
+		c.resourceRecordSetClient.resourceRecordSets[project] = zones
+	}
+	rs, ok := zones[zone]
+	if !ok {
+		rs = map[string]*dns.ResourceRecordSet{}
+		zones[zone] = rs
+	}
+	rs[rrs.Type+"/"+rrs.Name] = rrs
+}
+
+// CreatedChanges returns the changes created in a managed zone, in the order they were created.
+func (c *MockClient) CreatedChanges(project, zone string) []*dns.Change {
+	c.changeClient.Lock()
+	defer c.changeClient.Unlock()
+	return c.changeClient.changes[project][zone]
+}
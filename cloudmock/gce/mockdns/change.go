@@ -17,19 +17,50 @@ limitations under the License.
 package mockdns
 
 import (
+	"context"
+	"strconv"
+	"sync"
+
 	dns "google.golang.org/api/dns/v1"
+	"google.golang.org/api/googleapi"
 	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
 )
 
 type changeClient struct {
+	// changes are the created changes keyed by project and zone, in the order they were created.
+	changes map[string]map[string][]*dns.Change
+	sync.Mutex
 }
 
 var _ gce.ChangeClient = &changeClient{}
 
 func newChangeClient() *changeClient {
-	return &changeClient{}
+	return &changeClient{
+		changes: map[string]map[string][]*dns.Change{},
+	}
 }
 
-func (c *changeClient) Create(project, zone string, ch *dns.Change) (*dns.Change, error) {
+func (c *changeClient) Create(ctx context.Context, project, zone string, ch *dns.Change) (*dns.Change, error) {
+	c.Lock()
+	defer c.Unlock()
+	zones, ok := c.changes[project]
+	if !ok {
+		zones = map[string][]*dns.Change{}
+		c.changes[project] = zones
+	}
+	ch.Id = strconv.Itoa(len(zones[zone]))
+	ch.Status = "done"
+	zones[zone] = append(zones[zone], ch)
 	return ch, nil
 }
+
+func (c *changeClient) Get(ctx context.Context, project, zone, id string) (*dns.Change, error) {
+	c.Lock()
+	defer c.Unlock()
+	for _, ch := range c.changes[project][zone] {
+		if ch.Id == id {
+			return ch, nil
+		}
+	}
+	return nil, &googleapi.Error{Code: 404}
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package mockdns
 
 import (
+	"context"
+
 	dns "google.golang.org/api/dns/v1"
 	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
 )
@@ -34,7 +36,7 @@ func newManagedZoneClient() *managedZoneClient {
 	}
 }
 
-func (c *managedZoneClient) List(project string) ([]*dns.ManagedZone, error) {
+func (c *managedZoneClient) List(ctx context.Context, project string) ([]*dns.ManagedZone, error) {
 	mzs, ok := c.managedZones[project]
 	if !ok {
 		return nil, nil
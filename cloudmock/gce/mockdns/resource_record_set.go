@@ -17,6 +17,8 @@ limitations under the License.
 package mockdns
 
 import (
+	"context"
+
 	dns "google.golang.org/api/dns/v1"
 	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
 )
@@ -34,7 +36,7 @@ func newResourceRecordSetClient() *resourceRecordSetClient {
 	}
 }
 
-func (c *resourceRecordSetClient) List(project, zone string) ([]*dns.ResourceRecordSet, error) {
+func (c *resourceRecordSetClient) List(ctx context.Context, project, zone string) ([]*dns.ResourceRecordSet, error) {
 	zones, ok := c.resourceRecordSets[project]
 	if !ok {
 		return nil, nil
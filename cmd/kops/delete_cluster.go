@@ -125,55 +125,25 @@ func RunDeleteCluster(ctx context.Context, f *util.Factory, out io.Writer, optio
 			}
 		}
 
-		allResources, err := resourceops.ListResources(cloud, cluster, options.Region)
+		allResources, listErr := resourceops.ListResources(cloud, cluster, options.Region)
+		if listErr != nil {
+			if len(allResources) == 0 {
+				return listErr
+			}
+			// Let the user make progress on the resources we did find
+			fmt.Fprintf(out, "found %d resources, but %v\n\n", len(allResources), listErr)
+		}
+
+		wouldDeleteCloudResources, err = deleteClusterResources(out, cloud, allResources, listErr, options.Yes)
 		if err != nil {
 			return err
 		}
-
-		clusterResources := make(map[string]*resources.Resource)
-		for k, resource := range allResources {
-			if resource.Shared {
-				continue
-			}
-			clusterResources[k] = resource
+		if wouldDeleteCloudResources && !options.Yes {
+			return nil
 		}
 
-		if len(clusterResources) == 0 {
-			fmt.Fprintf(out, "No cloud resources to delete\n")
-		} else {
-			wouldDeleteCloudResources = true
-
-			t := &tables.Table{}
-			t.AddColumn("TYPE", func(r *resources.Resource) string {
-				return r.Type
-			})
-			t.AddColumn("ID", func(r *resources.Resource) string {
-				return r.ID
-			})
-			t.AddColumn("NAME", func(r *resources.Resource) string {
-				return r.Name
-			})
-			var l []*resources.Resource
-			for _, v := range clusterResources {
-				l = append(l, v)
-			}
-
-			err := t.Render(l, out, "TYPE", "NAME", "ID")
-			if err != nil {
-				return err
-			}
-
-			if !options.Yes {
-				fmt.Fprintf(out, "\nMust specify --yes to delete cluster\n")
-				return nil
-			}
-
-			fmt.Fprintf(out, "\n")
-
-			err = resourceops.DeleteResources(cloud, clusterResources)
-			if err != nil {
-				return err
-			}
+		if listErr != nil {
+			return fmt.Errorf("not all cloud resources could be listed, not unregistering cluster: %v", listErr)
 		}
 	}
 
@@ -207,6 +177,77 @@ func RunDeleteCluster(ctx context.Context, f *util.Factory, out io.Writer, optio
 	return nil
 }
 
+// deleteClusterResources deletes the resources of the cluster that are not shared, after listing them.
+// If listErr is set, not all resources could be listed: the dependencies on resources that were not listed are dropped,
+// as they would otherwise never be deleted, and neither would the resources that depend on them.
+// It returns whether there were cloud resources to delete.
+func deleteClusterResources(out io.Writer, cloud fi.Cloud, allResources map[string]*resources.Resource, listErr error, yes bool) (bool, error) {
+	clusterResources := make(map[string]*resources.Resource)
+	for k, resource := range allResources {
+		if resource.Shared {
+			continue
+		}
+		clusterResources[k] = resource
+	}
+
+	if len(clusterResources) == 0 {
+		fmt.Fprintf(out, "No cloud resources to delete\n")
+		return false, nil
+	}
+
+	t := &tables.Table{}
+	t.AddColumn("TYPE", func(r *resources.Resource) string {
+		return r.Type
+	})
+	t.AddColumn("ID", func(r *resources.Resource) string {
+		return r.ID
+	})
+	t.AddColumn("NAME", func(r *resources.Resource) string {
+		return r.Name
+	})
+	var l []*resources.Resource
+	for _, v := range clusterResources {
+		l = append(l, v)
+	}
+
+	err := t.Render(l, out, "TYPE", "NAME", "ID")
+	if err != nil {
+		return true, err
+	}
+
+	if !yes {
+		fmt.Fprintf(out, "\nMust specify --yes to delete cluster\n")
+		return true, nil
+	}
+
+	fmt.Fprintf(out, "\n")
+
+	if listErr != nil {
+		removeUnlistedDependencies(clusterResources)
+	}
+
+	return true, resourceops.DeleteResources(cloud, clusterResources)
+}
+
+// removeUnlistedDependencies drops the Blocks and Blocked edges to resources that are not in the map
+func removeUnlistedDependencies(resourceMap map[string]*resources.Resource) {
+	listed := func(keys []string) []string {
+		var l []string
+		for _, k := range keys {
+			if _, found := resourceMap[k]; found {
+				l = append(l, k)
+			} else {
+				klog.V(2).Infof("ignoring dependency on %s, which was not listed", k)
+			}
+		}
+		return l
+	}
+	for _, r := range resourceMap {
+		r.Blocks = listed(r.Blocks)
+		r.Blocked = listed(r.Blocked)
+	}
+}
+
 func completeRegion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	// TODO call into cloud provider(s) to get list of valid regions
 	return nil, cobra.ShellCompDirectiveNoFileComp
@@ -0,0 +1,94 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/kops/pkg/resources"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+// TestDeleteClusterResourcesPartialList checks that when listing failed part way, the resources that depend on
+// resources that were not listed are still deleted
+func TestDeleteClusterResourcesPartialList(t *testing.T) {
+	var mutex sync.Mutex
+	var deleted []string
+	deleter := func(cloud fi.Cloud, r *resources.Resource) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		deleted = append(deleted, r.Type+":"+r.ID)
+		return nil
+	}
+
+	// Listing the target pools failed, after the instances were found to be in one
+	allResources := map[string]*resources.Resource{
+		"Instance:us-test1-a/nodes-abcd": {
+			Type:    "Instance",
+			ID:      "us-test1-a/nodes-abcd",
+			Deleter: deleter,
+			Blocked: []string{"TargetPool:us-test1/api-cluster-example-com"},
+		},
+		"Disk:us-test1-a/nodes-abcd": {
+			Type:    "Disk",
+			ID:      "us-test1-a/nodes-abcd",
+			Deleter: deleter,
+			Blocked: []string{"Instance:us-test1-a/nodes-abcd"},
+		},
+		"Network:default": {
+			Type:    "Network",
+			ID:      "default",
+			Shared:  true,
+			Deleter: deleter,
+		},
+	}
+
+	var out bytes.Buffer
+	errCh := make(chan error, 1)
+	go func() {
+		wouldDelete, err := deleteClusterResources(&out, nil, allResources, fmt.Errorf("error listing TargetPools"), true)
+		if err == nil && !wouldDelete {
+			err = fmt.Errorf("expected resources to delete")
+		}
+		errCh <- err
+	}()
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatalf("error deleting resources: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("resources depending on resources that were not listed were not deleted")
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	sort.Strings(deleted)
+	if expected := []string{"Disk:us-test1-a/nodes-abcd", "Instance:us-test1-a/nodes-abcd"}; !reflect.DeepEqual(deleted, expected) {
+		t.Errorf("unexpected deletions, got %v, expected %v", deleted, expected)
+	}
+	if blocked := allResources["Disk:us-test1-a/nodes-abcd"].Blocked; !reflect.DeepEqual(blocked, []string{"Instance:us-test1-a/nodes-abcd"}) {
+		t.Errorf("expected the dependencies on listed resources to be kept, got %v", blocked)
+	}
+}
@@ -0,0 +1,300 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	compute "google.golang.org/api/compute/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/resources"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+// maxConcurrentDeletions is the maximum number of deleters DeleteResources runs in parallel
+const maxConcurrentDeletions = 10
+
+// DeleteResourcesOptions holds the options for DeleteResourcesWithOptions
+type DeleteResourcesOptions struct {
+	// OnDeleted, if set, is called once for each resource after its Deleter or GroupDeleter has succeeded,
+	// e.g. to report progress. It is never called for resources that were not deleted, or that were already Done or Shared.
+	// Calls are serialized, but they come from the goroutines running the deleters.
+	OnDeleted func(r *resources.Resource)
+	// Events, if set, receives a ResourceDeleted or ResourceDeleteFailed event for each resource that was attempted
+	// or skipped. ResourceDeleteFailed events are sent once the deletion passes stop, for the resources of the last pass.
+	// The caller must drain the channel until the package closes it, which it does on return.
+	// Sends block the deleters, so a slow consumer slows down deletion unless the channel is buffered.
+	Events chan<- Event
+}
+
+var (
+	// deletePassInterval is how long we wait before retrying the resources that a deletion pass did not delete.
+	// The interval doubles after each pass, up to maxDeletePassInterval.
+	deletePassInterval    = 10 * time.Second
+	maxDeletePassInterval = time.Minute
+)
+
+// DeleteResources deletes the resources in the order given by their Blocks and Blocked fields (see BuildDependencyGraph).
+// Resources that don't depend on each other are deleted concurrently, and resources with a GroupDeleter are deleted
+// together with the other ready resources of their GroupKey. If a deletion fails, the resources that depend on it are
+// not deleted in that pass, but the others are. As GCE refuses to delete resources that are still in use (e.g. by
+// resources being deleted asynchronously, or outside the graph), the resources that were not deleted are retried in
+// further passes, with backoff, for as long as each pass deletes something. The errors of the last pass are returned
+// as an aggregate. Resources that are Done or Shared are not deleted, and do not hold up the resources that depend on them.
+// The deleters stop waiting for their operations once ctx is done.
+func DeleteResources(ctx context.Context, cloud fi.Cloud, resourceMap map[string]*resources.Resource) error {
+	return DeleteResourcesWithOptions(ctx, cloud, resourceMap, DeleteResourcesOptions{})
+}
+
+// DeleteResourcesWithOptions deletes the resources, see DeleteResources
+func DeleteResourcesWithOptions(ctx context.Context, cloud fi.Cloud, resourceMap map[string]*resources.Resource, options DeleteResourcesOptions) error {
+	if options.Events != nil {
+		defer close(options.Events)
+	}
+
+	var errs []error
+
+	g, err := BuildDependencyGraph(resourceMap)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	dependencies := make(map[string][]string)
+	for _, e := range g.Edges {
+		dependencies[e.To] = append(dependencies[e.To], e.From)
+	}
+
+	done := make(map[string]bool)
+	for k, r := range resourceMap {
+		if r.Done || r.Shared {
+			done[k] = true
+		}
+	}
+
+	// The deleters get the context through the cloud, as the Deleter signature is shared with the other clouds
+	if c, ok := cloud.(gce.GCECloud); ok {
+		cloud = &contextCloud{GCECloud: c, ctx: ctx}
+	}
+
+	var passErrs []error
+	var failed map[string]error
+	interval := deletePassInterval
+	for {
+		deleted := len(done)
+		passErrs, failed = deletePass(ctx, cloud, resourceMap, g, dependencies, done, options)
+		if len(failed) == 0 || len(done) == deleted || ctx.Err() != nil {
+			break
+		}
+
+		klog.Infof("Not all resources deleted; waiting %v before reattempting deletion of %d resources", interval, len(failed))
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+		interval *= 2
+		if interval > maxDeletePassInterval {
+			interval = maxDeletePassInterval
+		}
+	}
+	errs = append(errs, passErrs...)
+
+	var failedKeys []string
+	for k := range failed {
+		failedKeys = append(failedKeys, k)
+	}
+	sort.Strings(failedKeys)
+	for _, k := range failedKeys {
+		publishEvent(ctx, options.Events, Event{Type: ResourceDeleteFailed, Resource: resourceMap[k], Err: failed[k]})
+	}
+
+	var remaining []string
+	for _, k := range g.Nodes {
+		if !done[k] && failed[k] == nil {
+			remaining = append(remaining, k)
+		}
+	}
+	if len(remaining) != 0 {
+		errs = append(errs, fmt.Errorf("resources not deleted, as they depend on resources that block each other: %s", strings.Join(remaining, ", ")))
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// deletePass deletes the resources that are not done, in dependency order, marking them done as they are deleted.
+// It returns the errors of the pass, and the resources that were not deleted (or were skipped) with their error.
+func deletePass(ctx context.Context, cloud fi.Cloud, resourceMap map[string]*resources.Resource, g *Graph, dependencies map[string][]string, done map[string]bool, options DeleteResourcesOptions) ([]error, map[string]error) {
+	var errs []error
+	failed := make(map[string]error)
+
+	var mutex sync.Mutex
+	for {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, fmt.Errorf("error deleting resources: %v", err))
+			break
+		}
+
+		// The resources that depend on a resource that was not deleted are skipped in turn
+		for skipped := true; skipped; {
+			skipped = false
+			for _, k := range g.Nodes {
+				if done[k] || failed[k] != nil {
+					continue
+				}
+				for _, dep := range dependencies[k] {
+					if failed[dep] != nil {
+						err := fmt.Errorf("not deleting %s, as %s was not deleted", k, dep)
+						failed[k] = err
+						skipped = true
+						errs = append(errs, err)
+						break
+					}
+				}
+			}
+		}
+
+		var ready []string
+		for _, k := range g.Nodes {
+			if done[k] || failed[k] != nil {
+				continue
+			}
+			isReady := true
+			for _, dep := range dependencies[k] {
+				if !done[dep] {
+					isReady = false
+					break
+				}
+			}
+			if isReady {
+				ready = append(ready, k)
+			}
+		}
+		if len(ready) == 0 {
+			break
+		}
+
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, maxConcurrentDeletions)
+		for _, group := range groupResourcesForDeletion(resourceMap, ready) {
+			group := group // avoid closure-in-loop go-tcha
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				human := group[0].Type + ":" + group[0].ID
+				var err error
+				if group[0].GroupDeleter != nil {
+					err = group[0].GroupDeleter(cloud, group)
+				} else if group[0].Deleter != nil {
+					err = group[0].Deleter(cloud, group[0])
+				} else {
+					err = fmt.Errorf("resource has no deleter")
+				}
+				if err != nil && isAlreadyGoneErr(err) {
+					// e.g. the operation of an earlier deletion of the resource completed in the meantime
+					klog.Infof("%s not found or already being deleted: %v", human, err)
+					err = nil
+				}
+
+				mutex.Lock()
+				defer mutex.Unlock()
+				if err != nil {
+					if isResourceInUse(err) {
+						klog.Infof("%s	still in use", human)
+					}
+					errs = append(errs, fmt.Errorf("error deleting %s: %v", human, err))
+					for _, r := range group {
+						failed[r.Type+":"+r.ID] = err
+					}
+					return
+				}
+				klog.Infof("%s	ok", human)
+				for _, r := range group {
+					done[r.Type+":"+r.ID] = true
+					if options.OnDeleted != nil {
+						options.OnDeleted(r)
+					}
+					publishEvent(ctx, options.Events, Event{Type: ResourceDeleted, Resource: r})
+				}
+			}()
+		}
+		wg.Wait()
+	}
+
+	return errs, failed
+}
+
+// contextCloud wraps a GCECloud to pass the context of DeleteResources to the deleters, see deletionContext
+type contextCloud struct {
+	gce.GCECloud
+
+	ctx context.Context
+}
+
+var _ gce.GCECloud = &contextCloud{}
+
+// WaitForOp implements GCECloud::WaitForOp, for the deleters shared with the rest of kops (e.g. gce.DeleteInstance)
+func (c *contextCloud) WaitForOp(op *compute.Operation) error {
+	return waitForOp(c.ctx, c.GCECloud, op)
+}
+
+// deletionContext returns the context deleters should use to wait for their operations: the context of DeleteResources,
+// or context.Background if the deleter is called with a cloud that does not carry one (e.g. by another package)
+func deletionContext(cloud fi.Cloud) context.Context {
+	switch c := cloud.(type) {
+	case *contextCloud:
+		return c.ctx
+	case *opTimeoutCloud:
+		return deletionContext(c.GCECloud)
+	}
+	return context.Background()
+}
+
+// groupResourcesForDeletion splits the resources into the sets deleted by a single deleter call:
+// the resources with a GroupDeleter are grouped by their GroupKey, the others are deleted one by one
+func groupResourcesForDeletion(resourceMap map[string]*resources.Resource, keys []string) [][]*resources.Resource {
+	groups := make(map[string][]*resources.Resource)
+	for _, k := range keys {
+		r := resourceMap[k]
+		groupKey := "_" + k
+		if r.GroupDeleter != nil {
+			groupKey = r.Type + "/" + r.GroupKey
+		}
+		groups[groupKey] = append(groups[groupKey], r)
+	}
+
+	var groupKeys []string
+	for groupKey := range groups {
+		groupKeys = append(groupKeys, groupKey)
+	}
+	sort.Strings(groupKeys)
+
+	var sorted [][]*resources.Resource
+	for _, groupKey := range groupKeys {
+		sorted = append(sorted, groups[groupKey])
+	}
+	return sorted
+}
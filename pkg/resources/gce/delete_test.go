@@ -0,0 +1,319 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/api/googleapi"
+	gcemock "k8s.io/kops/cloudmock/gce"
+	"k8s.io/kops/pkg/resources"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+// deletionRecorder builds resources whose deleters record the order they are called in
+type deletionRecorder struct {
+	mutex   sync.Mutex
+	deleted []string
+}
+
+func (d *deletionRecorder) record(keys ...string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.deleted = append(d.deleted, strings.Join(keys, ","))
+}
+
+func (d *deletionRecorder) resource(resourceType, id string, err error) *resources.Resource {
+	return &resources.Resource{
+		Name: id,
+		ID:   id,
+		Type: resourceType,
+		Deleter: func(cloud fi.Cloud, r *resources.Resource) error {
+			d.record(r.Type + ":" + r.ID)
+			return err
+		},
+	}
+}
+
+func toResourceMap(rs ...*resources.Resource) map[string]*resources.Resource {
+	resourceMap := make(map[string]*resources.Resource)
+	for _, r := range rs {
+		resourceMap[r.Type+":"+r.ID] = r
+	}
+	return resourceMap
+}
+
+func TestDeleteResourcesLinear(t *testing.T) {
+	d := &deletionRecorder{}
+	instance := d.resource(typeInstance, "us-test1-a/master", nil)
+	disk := d.resource(typeDisk, "etcd", nil)
+	disk.Blocked = []string{typeInstance + ":us-test1-a/master"}
+	subnet := d.resource(typeSubnet, "us-test1/cluster", nil)
+	instance.Blocks = []string{typeSubnet + ":us-test1/cluster"}
+	network := d.resource(typeNetwork, "cluster", nil)
+	network.Blocked = []string{typeSubnet + ":us-test1/cluster", typeInstance + ":missing"}
+
+	if err := DeleteResources(context.Background(), nil, toResourceMap(instance, disk, subnet, network)); err != nil {
+		t.Fatalf("error deleting resources: %v", err)
+	}
+
+	position := make(map[string]int)
+	for i, k := range d.deleted {
+		position[k] = i
+	}
+	if len(d.deleted) != 4 {
+		t.Fatalf("expected 4 deletions, got %v", d.deleted)
+	}
+	for _, e := range []Edge{
+		{From: "Instance:us-test1-a/master", To: "Disk:etcd"},
+		{From: "Instance:us-test1-a/master", To: "Subnet:us-test1/cluster"},
+		{From: "Subnet:us-test1/cluster", To: "Network:cluster"},
+	} {
+		if position[e.From] > position[e.To] {
+			t.Errorf("expected %s to be deleted before %s, got %v", e.From, e.To, d.deleted)
+		}
+	}
+}
+
+func TestDeleteResourcesParallel(t *testing.T) {
+	// Each deleter waits for the others to start, so this only completes if they run concurrently
+	const n = 3
+	var started sync.WaitGroup
+	started.Add(n)
+	var rs []*resources.Resource
+	for i := 0; i < n; i++ {
+		rs = append(rs, &resources.Resource{
+			Name: fmt.Sprintf("disk-%d", i),
+			ID:   fmt.Sprintf("disk-%d", i),
+			Type: typeDisk,
+			Deleter: func(cloud fi.Cloud, r *resources.Resource) error {
+				started.Done()
+				waited := make(chan struct{})
+				go func() {
+					started.Wait()
+					close(waited)
+				}()
+				select {
+				case <-waited:
+					return nil
+				case <-time.After(10 * time.Second):
+					return fmt.Errorf("timed out waiting for the other deletions to start")
+				}
+			},
+		})
+	}
+
+	if err := DeleteResources(context.Background(), nil, toResourceMap(rs...)); err != nil {
+		t.Fatalf("error deleting resources: %v", err)
+	}
+}
+
+func TestDeleteResourcesGrouped(t *testing.T) {
+	d := &deletionRecorder{}
+	groupDeleter := func(cloud fi.Cloud, rs []*resources.Resource) error {
+		var keys []string
+		for _, r := range rs {
+			keys = append(keys, r.Type+":"+r.ID)
+		}
+		sort.Strings(keys)
+		d.record(keys...)
+		return nil
+	}
+	var rs []*resources.Resource
+	for _, id := range []string{"example-com/A/api.cluster.example.com.", "example-com/A/api.internal.cluster.example.com.", "other-com/A/api.cluster.other.com."} {
+		rs = append(rs, &resources.Resource{
+			Name:         id,
+			ID:           id,
+			Type:         typeDNSRecord,
+			GroupKey:     strings.Split(id, "/")[0],
+			GroupDeleter: groupDeleter,
+		})
+	}
+
+	if err := DeleteResources(context.Background(), nil, toResourceMap(rs...)); err != nil {
+		t.Fatalf("error deleting resources: %v", err)
+	}
+
+	sort.Strings(d.deleted)
+	expected := []string{
+		"DNSRecord:example-com/A/api.cluster.example.com.,DNSRecord:example-com/A/api.internal.cluster.example.com.",
+		"DNSRecord:other-com/A/api.cluster.other.com.",
+	}
+	if !reflect.DeepEqual(d.deleted, expected) {
+		t.Errorf("unexpected group deletions, got %v, expected %v", d.deleted, expected)
+	}
+}
+
+func TestDeleteResourcesFailures(t *testing.T) {
+	defer func(d time.Duration) { deletePassInterval = d }(deletePassInterval)
+	deletePassInterval = time.Millisecond
+
+	d := &deletionRecorder{}
+	instance := d.resource(typeInstance, "us-test1-a/master", fmt.Errorf("quota exceeded"))
+	disk := d.resource(typeDisk, "etcd", nil)
+	disk.Blocked = []string{typeInstance + ":us-test1-a/master"}
+	policy := d.resource(typeResourcePolicy, "snapshots", nil)
+	disk.Blocks = []string{typeResourcePolicy + ":snapshots"}
+	address := d.resource(typeAddress, "api", nil)
+	shared := d.resource(typeNetwork, "default", nil)
+	shared.Shared = true
+	address.Blocked = []string{typeNetwork + ":default"}
+	a := d.resource(typeFirewallRule, "a", nil)
+	b := d.resource(typeFirewallRule, "b", nil)
+	a.Blocks = []string{typeFirewallRule + ":b"}
+	b.Blocks = []string{typeFirewallRule + ":a"}
+
+	err := DeleteResources(context.Background(), nil, toResourceMap(instance, disk, policy, address, shared, a, b))
+	if err == nil {
+		t.Fatalf("expected an error deleting resources")
+	}
+	for _, expected := range []string{
+		"error deleting Instance:us-test1-a/master: quota exceeded",
+		"not deleting Disk:etcd, as Instance:us-test1-a/master was not deleted",
+		"not deleting ResourcePolicy:snapshots, as Disk:etcd was not deleted",
+		"FirewallRule:a -> FirewallRule:b -> FirewallRule:a",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error to contain %q, got %q", expected, err.Error())
+		}
+	}
+
+	// The instance is retried after the pass that deleted the address, and given up on after a pass that deletes nothing
+	sort.Strings(d.deleted)
+	if expected := []string{"Address:api", "Instance:us-test1-a/master", "Instance:us-test1-a/master"}; !reflect.DeepEqual(d.deleted, expected) {
+		t.Errorf("unexpected deletions, got %v, expected %v", d.deleted, expected)
+	}
+}
+
+func TestDeleteResourcesRetriesInUse(t *testing.T) {
+	defer func(d time.Duration) { deletePassInterval = d }(deletePassInterval)
+	deletePassInterval = time.Millisecond
+
+	// The firewall rule is still in use the first time, e.g. by an instance outside the graph being deleted
+	d := &deletionRecorder{}
+	attempts := 0
+	rule := &resources.Resource{
+		Name: "node-to-node",
+		ID:   "node-to-node",
+		Type: typeFirewallRule,
+		Deleter: func(cloud fi.Cloud, r *resources.Resource) error {
+			attempts++
+			if attempts == 1 {
+				return &googleapi.Error{Code: 400, Errors: []googleapi.ErrorItem{{Reason: "resourceInUseByAnotherResource"}}}
+			}
+			d.record(r.Type + ":" + r.ID)
+			return nil
+		},
+	}
+	address := d.resource(typeAddress, "api", nil)
+
+	if err := DeleteResources(context.Background(), nil, toResourceMap(rule, address)); err != nil {
+		t.Fatalf("error deleting resources: %v", err)
+	}
+	sort.Strings(d.deleted)
+	if expected := []string{"Address:api", "FirewallRule:node-to-node"}; !reflect.DeepEqual(d.deleted, expected) {
+		t.Errorf("unexpected deletions, got %v, expected %v", d.deleted, expected)
+	}
+}
+
+func TestDeleteResourcesContext(t *testing.T) {
+	type contextKey struct{}
+	ctx := context.WithValue(context.Background(), contextKey{}, "delete cluster")
+
+	var values []interface{}
+	var mutex sync.Mutex
+	deleter := func(cloud fi.Cloud, r *resources.Resource) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		values = append(values, deletionContext(cloud).Value(contextKey{}))
+		return nil
+	}
+	disk := &resources.Resource{Name: "etcd", ID: "etcd", Type: typeDisk, Deleter: deleter}
+	address := &resources.Resource{Name: "api", ID: "api", Type: typeAddress, Deleter: deleter}
+	withOpTimeout(address, time.Minute)
+
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+	if err := DeleteResources(ctx, cloud, toResourceMap(disk, address)); err != nil {
+		t.Fatalf("error deleting resources: %v", err)
+	}
+	if expected := []interface{}{"delete cluster", "delete cluster"}; !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected the deleters to get the context of DeleteResources, got values %v", values)
+	}
+
+	if deletionContext(cloud) != context.Background() {
+		t.Errorf("expected the background context for a cloud that does not carry one")
+	}
+}
+
+func TestDeleteResourcesOnDeleted(t *testing.T) {
+	defer func(d time.Duration) { deletePassInterval = d }(deletePassInterval)
+	deletePassInterval = time.Millisecond
+
+	d := &deletionRecorder{}
+	instance := d.resource(typeInstance, "us-test1-a/master", nil)
+	disk := d.resource(typeDisk, "etcd", nil)
+	disk.Blocked = []string{typeInstance + ":us-test1-a/master"}
+	address := d.resource(typeAddress, "api", fmt.Errorf("quota exceeded"))
+	forwardingRule := d.resource(typeForwardingRule, "api", nil)
+	forwardingRule.Blocked = []string{typeAddress + ":api"}
+	done := d.resource(typeFirewallRule, "done", nil)
+	done.Done = true
+	var rs []*resources.Resource
+	for _, id := range []string{"example-com/A/api.cluster.example.com.", "example-com/A/api.internal.cluster.example.com."} {
+		rs = append(rs, &resources.Resource{
+			Name:     id,
+			ID:       id,
+			Type:     typeDNSRecord,
+			GroupKey: "example-com",
+			GroupDeleter: func(cloud fi.Cloud, rs []*resources.Resource) error {
+				return nil
+			},
+		})
+	}
+	rs = append(rs, instance, disk, address, forwardingRule, done)
+
+	var mutex sync.Mutex
+	var deleted []string
+	options := DeleteResourcesOptions{
+		OnDeleted: func(r *resources.Resource) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			deleted = append(deleted, r.Type+":"+r.ID)
+		},
+	}
+	if err := DeleteResourcesWithOptions(context.Background(), nil, toResourceMap(rs...), options); err == nil {
+		t.Fatalf("expected an error deleting resources")
+	}
+
+	sort.Strings(deleted)
+	expected := []string{
+		"DNSRecord:example-com/A/api.cluster.example.com.",
+		"DNSRecord:example-com/A/api.internal.cluster.example.com.",
+		"Disk:etcd",
+		"Instance:us-test1-a/master",
+	}
+	if !reflect.DeepEqual(deleted, expected) {
+		t.Errorf("unexpected deleted resources, got %v, expected %v", deleted, expected)
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"strconv"
+	"time"
+
+	compute "google.golang.org/api/compute/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/resources"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+// deletingLabel is set on resources just before we delete them, with the unix time as its value,
+// so that resources left behind by an interrupted deletion can be identified
+const deletingLabel = "kops-deleting"
+
+// withDeletingLabel wraps the deleter of the resource so that it labels the resource before deleting it.
+// Only zonal disks, images and snapshots are labelled; the other types have no labels, or (like addresses)
+// only have them in the beta API.
+// Failing to set the label is logged, but does not stop the deletion.
+func withDeletingLabel(t *resources.Resource) {
+	deleter := t.Deleter
+	if deleter == nil {
+		return
+	}
+	switch t.Obj.(type) {
+	case *compute.Disk, *compute.Image, *compute.Snapshot:
+	default:
+		return
+	}
+
+	t.Deleter = func(cloud fi.Cloud, r *resources.Resource) error {
+		if err := setDeletingLabel(cloud.(gce.GCECloud), r.Obj, time.Now()); err != nil {
+			if gce.IsNotFound(err) {
+				klog.V(2).Infof("%s:%s not found while labelling it for deletion", r.Type, r.ID)
+			} else {
+				klog.Warningf("error labelling %s:%s for deletion: %v", r.Type, r.ID, err)
+			}
+		}
+		return deleter(cloud, r)
+	}
+}
+
+// setDeletingLabel adds the deletingLabel to the labels of the object
+func setDeletingLabel(c gce.GCECloud, obj interface{}, now time.Time) error {
+	value := strconv.FormatInt(now.Unix(), 10)
+
+	switch o := obj.(type) {
+	case *compute.Disk:
+		u, err := gce.ParseGoogleCloudURL(o.SelfLink)
+		if err != nil {
+			return err
+		}
+		if u.Zone == "" {
+			// The region disk client cannot set labels
+			return nil
+		}
+		klog.V(2).Infof("Labelling GCE Disk %s for deletion", o.SelfLink)
+		return c.Compute().Disks().SetLabels(u.Project, u.Zone, u.Name, &compute.ZoneSetLabelsRequest{
+			Labels:           withLabel(o.Labels, deletingLabel, value),
+			LabelFingerprint: o.LabelFingerprint,
+		})
+
+	case *compute.Image:
+		u, err := gce.ParseGoogleCloudURL(o.SelfLink)
+		if err != nil {
+			return err
+		}
+		klog.V(2).Infof("Labelling GCE Image %s for deletion", o.SelfLink)
+		return c.Compute().Images().SetLabels(u.Project, u.Name, &compute.GlobalSetLabelsRequest{
+			Labels:           withLabel(o.Labels, deletingLabel, value),
+			LabelFingerprint: o.LabelFingerprint,
+		})
+
+	case *compute.Snapshot:
+		u, err := gce.ParseGoogleCloudURL(o.SelfLink)
+		if err != nil {
+			return err
+		}
+		klog.V(2).Infof("Labelling GCE Snapshot %s for deletion", o.SelfLink)
+		return c.Compute().Snapshots().SetLabels(u.Project, u.Name, &compute.GlobalSetLabelsRequest{
+			Labels:           withLabel(o.Labels, deletingLabel, value),
+			LabelFingerprint: o.LabelFingerprint,
+		})
+	}
+	return nil
+}
+
+// withLabel returns a copy of the labels with the label set, as SetLabels replaces all the labels of a resource
+func withLabel(labels map[string]string, key, value string) map[string]string {
+	l := make(map[string]string, len(labels)+1)
+	for k, v := range labels {
+		l[k] = v
+	}
+	l[key] = value
+	return l
+}
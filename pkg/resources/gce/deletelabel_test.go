@@ -0,0 +1,95 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"testing"
+
+	compute "google.golang.org/api/compute/v1"
+	gcemock "k8s.io/kops/cloudmock/gce"
+	"k8s.io/kops/pkg/resources"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+func TestTagBeforeDeleteDisk(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	if _, err := cloud.Compute().Disks().Insert("testproject", "us-test1-a", &compute.Disk{
+		Name:   "a-etcd-main-cluster-example-com",
+		Labels: map[string]string{"k8s-io-cluster-name": "cluster-example-com"},
+	}); err != nil {
+		t.Fatalf("error creating disk: %v", err)
+	}
+
+	resourceMap, err := listResourcesForTest(cloud, "cluster.example.com", "us-test1")
+	if err != nil {
+		t.Fatalf("error listing resources: %v", err)
+	}
+	r := resourceMap["Disk:us-test1-a/a-etcd-main-cluster-example-com"]
+	if r == nil {
+		t.Fatalf("expected disk to be tracked, got %v", resourceMap)
+	}
+
+	// Check the labels as the delete is issued, wrapping the deleter as TagBeforeDelete does
+	var labelsAtDelete map[string]string
+	r.Deleter = func(cloud fi.Cloud, r *resources.Resource) error {
+		disk, err := cloud.(gce.GCECloud).Compute().Disks().Get("testproject", "us-test1-a", "a-etcd-main-cluster-example-com")
+		if err != nil {
+			return err
+		}
+		labelsAtDelete = disk.Labels
+		return deleteGCEDisk(cloud, r)
+	}
+	withDeletingLabel(r)
+
+	if err := r.Deleter(cloud, r); err != nil {
+		t.Fatalf("error deleting disk: %v", err)
+	}
+	if labelsAtDelete[deletingLabel] == "" {
+		t.Errorf("expected the %s label to be set before the disk was deleted, got labels %v", deletingLabel, labelsAtDelete)
+	}
+	if labelsAtDelete["k8s-io-cluster-name"] != "cluster-example-com" {
+		t.Errorf("expected the existing labels to be kept, got labels %v", labelsAtDelete)
+	}
+	if _, err := cloud.Compute().Disks().Get("testproject", "us-test1-a", "a-etcd-main-cluster-example-com"); !gce.IsNotFound(err) {
+		t.Errorf("expected disk to be deleted, got %v", err)
+	}
+}
+
+func TestTagBeforeDeleteSkipsUnlabelledTypes(t *testing.T) {
+	called := false
+	r := &resources.Resource{
+		Name: "node-to-node-cluster-example-com",
+		ID:   "node-to-node-cluster-example-com",
+		Type: typeFirewallRule,
+		Deleter: func(cloud fi.Cloud, r *resources.Resource) error {
+			called = true
+			return nil
+		},
+		Obj: &compute.Firewall{Name: "node-to-node-cluster-example-com"},
+	}
+	withDeletingLabel(r)
+
+	// A nil cloud would panic if we tried to set labels
+	if err := r.Deleter(nil, r); err != nil {
+		t.Fatalf("error deleting firewall rule: %v", err)
+	}
+	if !called {
+		t.Errorf("expected the firewall rule deleter to be called")
+	}
+}
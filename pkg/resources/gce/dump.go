@@ -79,6 +79,32 @@ func DumpManagedInstance(op *resources.DumpOperation, r *resources.Resource) err
 	return nil
 }
 
+// DumpResource is responsible for dumping a GCE resource, recording the API object
+func DumpResource(op *resources.DumpOperation, r *resources.Resource) error {
+	data := make(map[string]interface{})
+	data["id"] = r.ID
+	data["type"] = r.Type
+	data["raw"] = r.Obj
+	op.Dump.Resources = append(op.Dump.Resources, data)
+	return nil
+}
+
+// DumpSubnet is responsible for dumping a resource for a Subnetwork
+func DumpSubnet(op *resources.DumpOperation, r *resources.Resource) error {
+	if err := DumpResource(op, r); err != nil {
+		return err
+	}
+
+	subnet := r.Obj.(*compute.Subnetwork)
+	s := &resources.Subnet{
+		ID:   subnet.Name,
+		Zone: gce.LastComponent(subnet.Region),
+	}
+	op.Dump.Subnets = append(op.Dump.Subnets, s)
+
+	return nil
+}
+
 // getDumpState gets the dumpState from the dump context, or creates one if not yet initialized
 func getDumpState(dumpContext *resources.DumpOperation) *dumpState {
 	if dumpContext.CloudState == nil {
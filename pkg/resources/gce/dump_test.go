@@ -0,0 +1,97 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	compute "google.golang.org/api/compute/v1"
+	"k8s.io/kops/pkg/resources"
+	"k8s.io/kops/pkg/testutils/golden"
+)
+
+func TestDumpResources(t *testing.T) {
+	grid := []*resources.Resource{
+		{
+			Name:   "a-etcd-main-cluster-example-com",
+			ID:     "a-etcd-main-cluster-example-com",
+			Type:   typeDisk,
+			Dumper: DumpResource,
+			Obj: &compute.Disk{
+				Name:     "a-etcd-main-cluster-example-com",
+				SelfLink: "https://www.googleapis.com/compute/v1/projects/testproject/zones/us-test1-a/disks/a-etcd-main-cluster-example-com",
+				SizeGb:   20,
+				Labels:   map[string]string{"k8s-io-cluster-name": "cluster-example-com"},
+			},
+		},
+		{
+			Name:   "ssh-external-to-master-cluster-example-com",
+			ID:     "ssh-external-to-master-cluster-example-com",
+			Type:   typeFirewallRule,
+			Dumper: DumpResource,
+			Obj: &compute.Firewall{
+				Name:         "ssh-external-to-master-cluster-example-com",
+				SelfLink:     "https://www.googleapis.com/compute/v1/projects/testproject/global/firewalls/ssh-external-to-master-cluster-example-com",
+				SourceRanges: []string{"0.0.0.0/0"},
+				TargetTags:   []string{"cluster-example-com-k8s-io-role-master"},
+			},
+		},
+		{
+			Name:   "api-cluster-example-com",
+			ID:     "api-cluster-example-com",
+			Type:   typeForwardingRule,
+			Dumper: DumpResource,
+			Obj: &compute.ForwardingRule{
+				Name:      "api-cluster-example-com",
+				SelfLink:  "https://www.googleapis.com/compute/v1/projects/testproject/regions/us-test1/forwardingRules/api-cluster-example-com",
+				IPAddress: "1.2.3.4",
+				Target:    "https://www.googleapis.com/compute/v1/projects/testproject/regions/us-test1/targetPools/api-cluster-example-com",
+			},
+		},
+		{
+			Name:   "us-test1-cluster-example-com",
+			ID:     "us-test1-cluster-example-com",
+			Type:   typeSubnet,
+			Dumper: DumpSubnet,
+			Obj: &compute.Subnetwork{
+				Name:        "us-test1-cluster-example-com",
+				SelfLink:    "https://www.googleapis.com/compute/v1/projects/testproject/regions/us-test1/subnetworks/us-test1-cluster-example-com",
+				Region:      "https://www.googleapis.com/compute/v1/projects/testproject/regions/us-test1",
+				IpCidrRange: "10.0.16.0/20",
+			},
+		},
+	}
+
+	for _, r := range grid {
+		t.Run(r.Type, func(t *testing.T) {
+			dump, err := resources.BuildDump(context.Background(), nil, map[string]*resources.Resource{r.Type + ":" + r.ID: r})
+			if err != nil {
+				t.Fatalf("error building dump: %v", err)
+			}
+
+			actual, err := json.MarshalIndent(dump, "", "  ")
+			if err != nil {
+				t.Fatalf("error marshaling dump: %v", err)
+			}
+
+			golden.AssertMatchesFile(t, string(actual), filepath.Join("testdata", "dump", r.Type+".json"))
+		})
+	}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/resources"
+)
+
+// EtcdBackupStoresForCluster returns the etcd backup stores configured in the cluster spec.
+// etcd clusters without a backup store keep their backups under the state store, see ListResourcesGCEOptions.StateStorePath.
+func EtcdBackupStoresForCluster(cluster *kops.Cluster) []string {
+	var backupStores []string
+	for _, etcdCluster := range cluster.Spec.EtcdClusters {
+		if etcdCluster.Backups != nil && etcdCluster.Backups.BackupStore != "" {
+			backupStores = append(backupStores, etcdCluster.Backups.BackupStore)
+		}
+	}
+	return backupStores
+}
+
+// parseEtcdBackupStorePath splits a gs://bucket/path etcd backup store into the bucket and the object prefix.
+// The path must have the cluster name as one of its components (as in the default backup store,
+// <state store>/<cluster>/backups/etcd/main), so we never match backups of other clusters or the bucket root.
+func parseEtcdBackupStorePath(backupStore string, clusterName string) (string, string, error) {
+	if !strings.HasPrefix(backupStore, "gs://") {
+		return "", "", fmt.Errorf("etcd backup store %q is not a gs:// path", backupStore)
+	}
+	tokens := strings.SplitN(strings.TrimPrefix(backupStore, "gs://"), "/", 2)
+	bucket := tokens[0]
+	if bucket == "" {
+		return "", "", fmt.Errorf("etcd backup store %q has no bucket", backupStore)
+	}
+	path := ""
+	if len(tokens) == 2 {
+		path = strings.Trim(tokens[1], "/")
+	}
+	if path == "" {
+		return "", "", fmt.Errorf("refusing to delete the root of bucket %q, etcd backup store %q does not name the cluster", bucket, backupStore)
+	}
+	for _, component := range strings.Split(path, "/") {
+		if component == clusterName {
+			return bucket, path + "/", nil
+		}
+	}
+	return "", "", fmt.Errorf("etcd backup store %q is not for cluster %q", backupStore, clusterName)
+}
+
+// listEtcdBackupBucket finds the etcd backups of the cluster in the opt-in etcd backup stores.
+// Only the objects under the backup store path are deleted, never the bucket, which may hold the backups of other clusters.
+func (d *clusterDiscoveryGCE) listEtcdBackupBucket(ctx context.Context) ([]*resources.Resource, error) {
+	var resourceTrackers []*resources.Resource
+	for _, backupStore := range d.etcdBackupStores {
+		bucket, prefix, err := parseEtcdBackupStorePath(backupStore, d.clusterName)
+		if err != nil {
+			return nil, err
+		}
+
+		l, err := d.listGCSObjects(ctx, bucket, prefix)
+		if err != nil {
+			return nil, err
+		}
+		resourceTrackers = append(resourceTrackers, l...)
+	}
+	return resourceTrackers, nil
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+func TestListEtcdBackupBucket(t *testing.T) {
+	client := &fakeObjectClient{
+		objects: map[string][]string{
+			"backups": {
+				"cluster.example.com/main/2021-01-01T00:00:00Z/etcd.backup.gz",
+				"cluster.example.com/main/control/etcd-cluster-spec",
+				"cluster.example.com/events/2021-01-01T00:00:00Z/etcd.backup.gz",
+				"cluster.example.com/mainx/etcd.backup.gz",
+				"other.example.com/main/2021-01-01T00:00:00Z/etcd.backup.gz",
+				"README",
+			},
+		},
+	}
+
+	d := &clusterDiscoveryGCE{
+		clusterName:      "cluster.example.com",
+		etcdBackupStores: []string{"gs://backups/cluster.example.com/main"},
+		gcsObjects:       client,
+	}
+	resourceTrackers, err := d.listEtcdBackupBucket(context.Background())
+	if err != nil {
+		t.Fatalf("error listing objects: %v", err)
+	}
+
+	for _, r := range resourceTrackers {
+		if err := r.Deleter(nil, r); err != nil {
+			t.Fatalf("error deleting object: %v", err)
+		}
+	}
+	sort.Strings(client.deleted)
+	expected := []string{
+		"backups/cluster.example.com/main/2021-01-01T00:00:00Z/etcd.backup.gz",
+		"backups/cluster.example.com/main/control/etcd-cluster-spec",
+	}
+	if !reflect.DeepEqual(client.deleted, expected) {
+		t.Errorf("unexpected deleted objects, got %v, expected %v", client.deleted, expected)
+	}
+}
+
+func TestParseEtcdBackupStorePath(t *testing.T) {
+	grid := []struct {
+		BackupStore string
+		Bucket      string
+		Prefix      string
+		Error       bool
+	}{
+		{
+			BackupStore: "gs://state/cluster.example.com/backups/etcd/main",
+			Bucket:      "state",
+			Prefix:      "cluster.example.com/backups/etcd/main/",
+		},
+		{
+			BackupStore: "gs://backups/cluster.example.com/",
+			Bucket:      "backups",
+			Prefix:      "cluster.example.com/",
+		},
+		{
+			BackupStore: "gs://backups",
+			Error:       true,
+		},
+		{
+			BackupStore: "gs://backups/etcd/main",
+			Error:       true,
+		},
+		{
+			BackupStore: "gs://backups/cluster.example.com.other/main",
+			Error:       true,
+		},
+		{
+			BackupStore: "s3://backups/cluster.example.com/main",
+			Error:       true,
+		},
+	}
+	for _, g := range grid {
+		bucket, prefix, err := parseEtcdBackupStorePath(g.BackupStore, "cluster.example.com")
+		if g.Error {
+			if err == nil {
+				t.Errorf("expected an error for %q", g.BackupStore)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error for %q: %v", g.BackupStore, err)
+			continue
+		}
+		if bucket != g.Bucket || prefix != g.Prefix {
+			t.Errorf("unexpected result for %q, got %q %q, expected %q %q", g.BackupStore, bucket, prefix, g.Bucket, g.Prefix)
+		}
+	}
+}
+
+func TestEtcdBackupStoresForCluster(t *testing.T) {
+	cluster := &kops.Cluster{}
+	cluster.Spec.EtcdClusters = []kops.EtcdClusterSpec{
+		{Name: "main", Backups: &kops.EtcdBackupSpec{BackupStore: "gs://backups/cluster.example.com/main"}},
+		{Name: "events"},
+	}
+
+	actual := EtcdBackupStoresForCluster(cluster)
+	expected := []string{"gs://backups/cluster.example.com/main"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected backup stores, got %v, expected %v", actual, expected)
+	}
+}
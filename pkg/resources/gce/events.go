@@ -0,0 +1,72 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"context"
+	"sort"
+
+	"k8s.io/kops/pkg/resources"
+)
+
+// EventType is the type of an Event
+type EventType string
+
+const (
+	// ResourceFound is published by discovery for each resource it returns
+	ResourceFound EventType = "ResourceFound"
+	// ResourceDeleted is published by DeleteResourcesWithOptions for each resource it has deleted
+	ResourceDeleted EventType = "ResourceDeleted"
+	// ResourceDeleteFailed is published by DeleteResourcesWithOptions for each resource it could not delete,
+	// either as its deleter failed or as it depends on a resource that was not deleted
+	ResourceDeleteFailed EventType = "ResourceDeleteFailed"
+)
+
+// Event is published to the Events channel of ListResourcesGCEOptions and DeleteResourcesOptions
+type Event struct {
+	Type     EventType
+	Resource *resources.Resource
+	// Err is why the resource was not deleted, for ResourceDeleteFailed events
+	Err error
+}
+
+// publishEvent sends the event on the channel, if there is one.
+// It blocks until the event is received, unless the context is cancelled, in which case the event is dropped.
+func publishEvent(ctx context.Context, events chan<- Event, event Event) {
+	if events == nil {
+		return
+	}
+	select {
+	case events <- event:
+	case <-ctx.Done():
+	}
+}
+
+// publishResourcesFound publishes a ResourceFound event for each of the resources, in the order of their keys
+func publishResourcesFound(ctx context.Context, events chan<- Event, resourceMap map[string]*resources.Resource) {
+	if events == nil {
+		return
+	}
+	var keys []string
+	for k := range resourceMap {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		publishEvent(ctx, events, Event{Type: ResourceFound, Resource: resourceMap[k]})
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	compute "google.golang.org/api/compute/v1"
+	gcemock "k8s.io/kops/cloudmock/gce"
+)
+
+// consumeEvents drains the channel until it is closed, returning the events as "Type Type:ID"
+func consumeEvents(events <-chan Event) <-chan []string {
+	result := make(chan []string)
+	go func() {
+		var received []string
+		for e := range events {
+			received = append(received, string(e.Type)+" "+e.Resource.Type+":"+e.Resource.ID)
+		}
+		result <- received
+	}()
+	return result
+}
+
+func TestListResourcesEvents(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	for _, name := range []string{"b-etcd-main-cluster-example-com", "a-etcd-main-cluster-example-com"} {
+		if _, err := cloud.Compute().Disks().Insert("testproject", "us-test1-a", &compute.Disk{
+			Name:   name,
+			Labels: map[string]string{"k8s-io-cluster-name": "cluster-example-com"},
+		}); err != nil {
+			t.Fatalf("error creating disk: %v", err)
+		}
+	}
+
+	// The channel is unbuffered, so discovery only finishes if the events are received
+	events := make(chan Event)
+	result := consumeEvents(events)
+	options := ListResourcesGCEOptions{
+		QPS:          testQPS,
+		IncludeTypes: []string{typeDisk},
+		Events:       events,
+	}
+	if _, err := ListResourcesGCEWithOptions(context.Background(), cloud, "cluster.example.com", "us-test1", options); err != nil {
+		t.Fatalf("error listing resources: %v", err)
+	}
+
+	expected := []string{
+		"ResourceFound Disk:us-test1-a/a-etcd-main-cluster-example-com",
+		"ResourceFound Disk:us-test1-a/b-etcd-main-cluster-example-com",
+	}
+	if received := <-result; !reflect.DeepEqual(received, expected) {
+		t.Errorf("unexpected events, got %v, expected %v", received, expected)
+	}
+}
+
+func TestDeleteResourcesEvents(t *testing.T) {
+	defer func(d time.Duration) { deletePassInterval = d }(deletePassInterval)
+	deletePassInterval = time.Millisecond
+
+	d := &deletionRecorder{}
+	instance := d.resource(typeInstance, "us-test1-a/master", nil)
+	disk := d.resource(typeDisk, "etcd", fmt.Errorf("disk in use"))
+	disk.Blocked = []string{typeInstance + ":us-test1-a/master"}
+	snapshot := d.resource(typeSnapshot, "etcd", nil)
+	snapshot.Blocked = []string{typeDisk + ":etcd"}
+
+	events := make(chan Event)
+	result := consumeEvents(events)
+	options := DeleteResourcesOptions{Events: events}
+	if err := DeleteResourcesWithOptions(context.Background(), nil, toResourceMap(instance, disk, snapshot), options); err == nil {
+		t.Fatalf("expected an error deleting resources")
+	}
+
+	expected := []string{
+		"ResourceDeleted Instance:us-test1-a/master",
+		"ResourceDeleteFailed Disk:etcd",
+		"ResourceDeleteFailed Snapshot:etcd",
+	}
+	if received := <-result; !reflect.DeepEqual(received, expected) {
+		t.Errorf("unexpected events, got %v, expected %v", received, expected)
+	}
+}
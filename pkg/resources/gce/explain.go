@@ -0,0 +1,113 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"reflect"
+	"sort"
+
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/resources"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+// explainMatches sets the Reason of each resource to why it was matched to the cluster, and logs it
+func (d *clusterDiscoveryGCE) explainMatches(resourceMap map[string]*resources.Resource) {
+	var keys []string
+	for k := range resourceMap {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		r := resourceMap[k]
+		r.Reason = d.explainMatch(r, resourceMap)
+		klog.Infof("matched %s: %s", k, r.Reason)
+	}
+}
+
+// explainMatch returns why the resource was matched to the cluster.
+// This mirrors the matching done by the list functions; it does not affect which resources are matched.
+func (d *clusterDiscoveryGCE) explainMatch(r *resources.Resource, resourceMap map[string]*resources.Resource) string {
+	switch r.Type {
+	case typeGCSObject:
+		return "object under the cluster state store or etcd backup store"
+	case typeDNSRecord:
+		return "DNS name of the cluster"
+	case typeRouterAdvertisement:
+		return "advertised IP range of the cluster"
+	case typeRoute:
+		if d.matchesCluster(typeRoute, r.Name, nil) {
+			return "name prefix matches cluster"
+		}
+	}
+
+	labels := objectLabels(r.Obj)
+	if _, found := labels[gce.GceLabelNameKubernetesCluster]; found && d.matchesClusterLabel(labels) {
+		return "label matches cluster"
+	}
+	if r.Type == typeDisk && d.matchesDiskClusterLabel(labels) {
+		return "label matches cluster"
+	}
+	if d.matchesCluster(r.Type, r.Name, nil) {
+		return "name matches cluster"
+	}
+
+	key := r.Type + ":" + r.ID
+	for _, blocked := range r.Blocked {
+		if _, found := resourceMap[blocked]; found {
+			return "used by cluster resource " + blocked
+		}
+	}
+	var others []string
+	for k := range resourceMap {
+		others = append(others, k)
+	}
+	sort.Strings(others)
+	for _, k := range others {
+		for _, blocks := range resourceMap[k].Blocks {
+			if blocks == key {
+				return "used by cluster resource " + k
+			}
+		}
+	}
+	for _, blocks := range r.Blocks {
+		if _, found := resourceMap[blocks]; found {
+			return "uses cluster resource " + blocks
+		}
+	}
+
+	return "matched by discovery of " + r.Type
+}
+
+// objectLabels returns the labels of an API object, or nil if it has none
+func objectLabels(obj interface{}) map[string]string {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return nil
+	}
+	v = v.Elem()
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	f := v.FieldByName("Labels")
+	if !f.IsValid() {
+		return nil
+	}
+	labels, _ := f.Interface().(map[string]string)
+	return labels
+}
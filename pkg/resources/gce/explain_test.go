@@ -0,0 +1,85 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	compute "google.golang.org/api/compute/v1"
+	gcemock "k8s.io/kops/cloudmock/gce"
+	"k8s.io/kops/pkg/resources"
+)
+
+func TestExplainMatches(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	if _, err := cloud.Compute().Disks().Insert("testproject", "us-test1-a", &compute.Disk{
+		Name:   "a-etcd-main",
+		Labels: map[string]string{"k8s-io-cluster-name": "cluster-example-com"},
+	}); err != nil {
+		t.Fatalf("error creating disk: %v", err)
+	}
+	if _, err := cloud.Compute().Addresses().Insert("testproject", "us-test1", &compute.Address{Name: "api-cluster-example-com"}); err != nil {
+		t.Fatalf("error creating address: %v", err)
+	}
+
+	for _, explain := range []bool{false, true} {
+		options := ListResourcesGCEOptions{
+			QPS:          testQPS,
+			IncludeTypes: []string{typeDisk, typeAddress},
+			Explain:      explain,
+		}
+		resourceMap, err := ListResourcesGCEWithOptions(context.Background(), cloud, "cluster.example.com", "us-test1", options)
+		if err != nil {
+			t.Fatalf("error listing resources: %v", err)
+		}
+
+		actual := make(map[string]string)
+		for k, r := range resourceMap {
+			actual[k] = r.Reason
+		}
+		expected := map[string]string{
+			"Disk:us-test1-a/a-etcd-main":              "",
+			"Address:us-test1/api-cluster-example-com": "",
+		}
+		if explain {
+			expected = map[string]string{
+				"Disk:us-test1-a/a-etcd-main":              "label matches cluster",
+				"Address:us-test1/api-cluster-example-com": "name matches cluster",
+			}
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("unexpected reasons with Explain=%v, got %v, expected %v", explain, actual, expected)
+		}
+	}
+}
+
+func TestExplainMatchUsedBy(t *testing.T) {
+	d := &clusterDiscoveryGCE{
+		clusterName: "cluster.example.com",
+	}
+	template := &resources.Resource{Type: typeInstanceTemplate, ID: "nodes-cluster-example-com-1234", Name: "nodes-cluster-example-com-1234"}
+	disk := &resources.Resource{Type: typeDisk, ID: "shared-data", Name: "shared-data", Obj: &compute.Disk{Name: "shared-data"}}
+	disk.Blocked = []string{typeInstanceTemplate + ":nodes-cluster-example-com-1234"}
+	resourceMap := toResourceMap(template, disk)
+
+	if reason := d.explainMatch(disk, resourceMap); reason != "used by cluster resource InstanceTemplate:nodes-cluster-example-com-1234" {
+		t.Errorf("unexpected reason %q", reason)
+	}
+}
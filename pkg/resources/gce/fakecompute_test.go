@@ -0,0 +1,163 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"context"
+	"fmt"
+
+	compute "google.golang.org/api/compute/v1"
+	"google.golang.org/api/googleapi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+// fakeComputeClient fakes single resource types of the compute API for the list functions, see clusterDiscoveryGCE.computeClient.
+// The other resource types use the embedded ComputeClient; if that is nil, using a resource type that isn't faked panics.
+type fakeComputeClient struct {
+	gce.ComputeClient
+
+	targetPools *fakeTargetPoolClient
+	disks       gce.DiskClient
+	zones       *fakeZoneClient
+	projects    gce.ProjectClient
+}
+
+var _ gce.ComputeClient = &fakeComputeClient{}
+
+func (c *fakeComputeClient) TargetPools() gce.TargetPoolClient {
+	if c.targetPools == nil {
+		return c.ComputeClient.TargetPools()
+	}
+	return c.targetPools
+}
+
+func (c *fakeComputeClient) Disks() gce.DiskClient {
+	if c.disks == nil {
+		return c.ComputeClient.Disks()
+	}
+	return c.disks
+}
+
+func (c *fakeComputeClient) Projects() gce.ProjectClient {
+	if c.projects == nil {
+		return c.ComputeClient.Projects()
+	}
+	return c.projects
+}
+
+func (c *fakeComputeClient) Zones() gce.ZoneClient {
+	if c.zones == nil {
+		return c.ComputeClient.Zones()
+	}
+	return c.zones
+}
+
+// fakeComputeCloud is a cloud whose compute client is replaced, for deleters, which use the compute client of the cloud
+type fakeComputeCloud struct {
+	gce.GCECloud
+
+	compute gce.ComputeClient
+}
+
+func (c *fakeComputeCloud) Compute() gce.ComputeClient {
+	return c.compute
+}
+
+// fakeTargetPoolClient lists the target pools it holds; its other methods are not implemented
+type fakeTargetPoolClient struct {
+	gce.TargetPoolClient
+
+	// targetPools are keyed by region
+	targetPools map[string][]*compute.TargetPool
+	// listed are the regions that were listed
+	listed []string
+}
+
+func (c *fakeTargetPoolClient) List(ctx context.Context, project, region string) ([]*compute.TargetPool, error) {
+	c.listed = append(c.listed, region)
+	return c.targetPools[region], nil
+}
+
+// fakeInUseDiskClient refuses to delete disks that are still attached to an instance, as GCE does
+type fakeInUseDiskClient struct {
+	gce.DiskClient
+
+	instances gce.InstanceClient
+}
+
+func (c *fakeInUseDiskClient) Delete(project, zone, name string) (*compute.Operation, error) {
+	disk, err := c.DiskClient.Get(project, zone, name)
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range disk.Users {
+		u, err := gce.ParseGoogleCloudURL(user)
+		if err != nil {
+			return nil, err
+		}
+		instance, err := c.instances.Get(u.Project, u.Zone, u.Name)
+		if err != nil {
+			continue
+		}
+		for _, attached := range instance.Disks {
+			if attached.Source == disk.SelfLink {
+				return nil, &googleapi.Error{
+					Code:    400,
+					Message: fmt.Sprintf("The disk resource '%s' is already being used by '%s'", disk.SelfLink, instance.SelfLink),
+					Errors:  []googleapi.ErrorItem{{Reason: "resourceInUseByAnotherResource"}},
+				}
+			}
+		}
+	}
+	return c.DiskClient.Delete(project, zone, name)
+}
+
+// fakeNotReadyDiskClient refuses to delete disks, as GCE does while another operation on them is in progress
+type fakeNotReadyDiskClient struct {
+	gce.DiskClient
+}
+
+func (c *fakeNotReadyDiskClient) Delete(project, zone, name string) (*compute.Operation, error) {
+	return nil, &googleapi.Error{
+		Code:    400,
+		Message: fmt.Sprintf("The resource 'projects/%s/zones/%s/disks/%s' is not ready; delete it once the snapshot is done", project, zone, name),
+		Errors:  []googleapi.ErrorItem{{Reason: "resourceNotReady"}},
+	}
+}
+
+// fakeZoneClient counts the calls listing the zones
+type fakeZoneClient struct {
+	gce.ZoneClient
+
+	listed int
+}
+
+func (c *fakeZoneClient) List(ctx context.Context, project string) ([]*compute.Zone, error) {
+	c.listed++
+	return c.ZoneClient.List(ctx, project)
+}
+
+// fakeProjectClient returns the project it holds; its other methods are not implemented
+type fakeProjectClient struct {
+	gce.ProjectClient
+
+	project *compute.Project
+}
+
+func (c *fakeProjectClient) Get(project string) (*compute.Project, error) {
+	return c.project, nil
+}
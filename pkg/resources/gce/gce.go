@@ -19,601 +19,3695 @@ package gce
 import (
 	"context"
 	"fmt"
+	"net"
+	"regexp"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/time/rate"
+	computebeta "google.golang.org/api/compute/v0.beta"
 	compute "google.golang.org/api/compute/v1"
 	clouddns "google.golang.org/api/dns/v1"
+	"google.golang.org/api/googleapi"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/dns"
 	"k8s.io/kops/pkg/resources"
 	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
 )
 
-type gceListFn func() ([]*resources.Resource, error)
+type gceListFn func(ctx context.Context) ([]*resources.Resource, error)
 
 const (
 	typeInstance             = "Instance"
 	typeInstanceTemplate     = "InstanceTemplate"
 	typeDisk                 = "Disk"
+	typeSnapshot             = "Snapshot"
+	typeMachineImage         = "MachineImage"
+	typeImage                = "Image"
 	typeInstanceGroupManager = "InstanceGroupManager"
+	typeInstanceGroup        = "InstanceGroup"
+	typeAutoscaler           = "Autoscaler"
 	typeTargetPool           = "TargetPool"
+	typeTargetInstance       = "TargetInstance"
+	typeNEG                  = "NetworkEndpointGroup"
+	typeResourcePolicy       = "ResourcePolicy"
 	typeFirewallRule         = "FirewallRule"
 	typeForwardingRule       = "ForwardingRule"
 	typeAddress              = "Address"
 	typeRoute                = "Route"
 	typeSubnet               = "Subnet"
+	typeNetwork              = "Network"
+	typeNetworkPeering       = "NetworkPeering"
 	typeRouter               = "Router"
+	typeRouterNAT            = "RouterNAT"
+	typeRouterPeer           = "RouterPeer"
+	typeRouterAdvertisement  = "RouterAdvertisement"
 	typeDNSRecord            = "DNSRecord"
+	typeBackendService       = "BackendService"
+	typeHealthCheck          = "HealthCheck"
+	typeSecurityPolicy       = "SecurityPolicy"
+	typeHttpHealthCheck      = "HttpHealthCheck"
+	typeTargetHttpProxy      = "TargetHttpProxy"
+	typeTargetHttpsProxy     = "TargetHttpsProxy"
+	typeUrlMap               = "UrlMap"
+	typeSslCertificate       = "SslCertificate"
+	typeSslPolicy            = "SslPolicy"
+	typeProjectMetadata      = "ProjectMetadata"
 )
 
-// Maximum number of `-` separated tokens in a name
-// Example: nodeport-external-to-node-ipv6
-const maxPrefixTokens = 5
+// The names kops generates are gce.SafeObjectName(id, clusterName), i.e. <id>-<cluster-name>.
+// These are the maximum number of `-` separated tokens we expect in the id, for each kind of resource.
+const (
+	// Firewall rules are named for the traffic they allow, e.g. nodeport-external-to-node,
+	// and the IPv6 rules add one of firewallRuleNameSuffixes, e.g. nodeport-external-to-node-ipv6
+	maxFirewallRuleTokens = 5
+	// Health checks are named like the firewall rules that let their probes through
+	maxHealthCheckTokens = 5
+	// MIGs are named for the zone and the instance group, e.g. a-master-us-test1-a, see gce.NameForInstanceGroupManager
+	maxInstanceGroupManagerTokens = 5
+	// Other resources are named for their role, e.g. api, nat or nodes
+	maxRoleNameTokens = 1
+
+	// maxGCENameLength is the limit GCE imposes on resource names and label values.
+	// Cleanup of a cluster is only reliable if its sanitized name (see gce.SafeClusterName) is no longer than this,
+	// as longer names cannot be used as the value of the cluster label.
+	maxGCENameLength = 63
+)
+
+// firewallRuleNameSuffixes are the suffixes kops appends to the id of a firewall rule for its variants
+var firewallRuleNameSuffixes = []string{"ipv6"}
+
+// maxConcurrentListCalls is the maximum number of list functions we run in parallel
+const maxConcurrentListCalls = 4
+
+// defaultDiscoveryQPS is the default rate of GCE API calls made during discovery,
+// matching the default per-project read quota of 2000 requests per 100 seconds
+const defaultDiscoveryQPS = 20
+
+// ListResourcesGCE lists the resources for the cluster, see ListResourcesGCEWithContext
+func ListResourcesGCE(gceCloud gce.GCECloud, clusterName string, region string) (map[string]*resources.Resource, error) {
+	return ListResourcesGCEWithContext(context.Background(), gceCloud, clusterName, region)
+}
+
+// ListResourcesGCEOptions holds the options for ListResourcesGCEWithOptions
+type ListResourcesGCEOptions struct {
+	// DryRun replaces the deleters of the returned resources with ones that only log what would be deleted
+	DryRun bool
+	// DeleteTimeout bounds how long each deleter waits for its GCE operation; 0 means no additional bound
+	DeleteTimeout time.Duration
+	// IncludeTypes restricts discovery to these resource types; all types are discovered if empty
+	IncludeTypes []string
+	// ExcludeTypes skips discovery of these resource types
+	ExcludeTypes []string
+	// Progress, if set, is called once for each resource type when discovery of that type has finished.
+	// It may be called concurrently from the goroutines running discovery.
+	Progress func(resourceType string, found int)
+	// StateStorePath, if set, is the gs:// path of the cluster state (the cluster's ConfigBase).
+	// The objects under it are discovered so they are deleted along with the cluster.
+	// This is opt-in, as the state store bucket may be shared with other clusters.
+	StateStorePath string
+	// EtcdBackupStores are the gs:// paths of the etcd backups of the cluster, see EtcdBackupStoresForCluster.
+	// The objects under them are discovered, but not the buckets. Each path must have the cluster name as a component.
+	// This is opt-in, as the backups may be needed to restore the cluster.
+	EtcdBackupStores []string
+	// IncludeTPUNodes discovers the TPU nodes carrying the cluster label.
+	// This is opt-in as the TPU API is a separate service, which may not be enabled in the project.
+	IncludeTPUNodes bool
+	// DeleteServiceAccounts discovers the IAM service accounts created for the cluster, along with their keys.
+	// This must be set explicitly, as deleting a service account that is still in use breaks its users.
+	DeleteServiceAccounts bool
+	// Protected are the Type:ID keys of resources that must never be deleted, e.g. a shared network
+	// or a production static IP that happens to match the cluster name.
+	// They are left out of the discovered resources.
+	Protected []string
+	// TagBeforeDelete sets the kops-deleting label, with the time of deletion, on disks, images and snapshots
+	// just before they are deleted, so resources left behind by an interrupted deletion can be identified
+	TagBeforeDelete bool
+	// MinAge skips resources created less than MinAge ago, which may have been created by a concurrent
+	// kops update rather than belong to the cluster being deleted. Resources without a creation time are kept.
+	MinAge time.Duration
+	// NameFilter, if set, only keeps the discovered resources whose name contains it, e.g. to delete a single
+	// instance group. It narrows the resources matched for the cluster; it never matches resources of other clusters.
+	NameFilter string
+	// SkipDNS leaves the cluster DNS records alone, for clusters whose DNS is managed outside kops
+	// (e.g. by external-dns, or in a shared zone)
+	SkipDNS bool
+	// DNSNamePrefixes are the names, relative to the cluster DNS name, of additional records to delete, see DNSNamePrefixesForCluster
+	DNSNamePrefixes []string
+	// QPS limits the rate of GCE API list calls made during discovery; defaults to defaultDiscoveryQPS if 0
+	QPS float64
+	// MaxResults is a hint for the page size of the aggregated disk list, which can be large in big projects.
+	// All pages are always read; 0 uses the server default.
+	MaxResults int64
+	// NamePrefix, if set, is a custom cluster part of object names (as in <id>-<prefix>, and <prefix>-<id> for
+	// routes and firewall tags), for clusters whose objects are not named after the sanitized cluster name.
+	// Names are matched against both the prefix and the cluster name; dots are replaced as in gce.SafeClusterName.
+	NamePrefix string
+	// Metrics, if set, receives the duration of each list function and of each deletion
+	Metrics Metrics
+	// CheckPendingOperations warns about operations that have not completed on the discovered resources,
+	// e.g. deletions left running by an earlier attempt, which can make deleting those resources fail
+	CheckPendingOperations bool
+	// WaitForPendingOperations waits for those operations to complete before returning the resources; it implies CheckPendingOperations
+	WaitForPendingOperations bool
+	// Zones, if set, are the zones to scan (e.g. the zones of the cluster spec), instead of every zone in the region.
+	// They must be in the region. ListResourcesGCEAllRegions ignores them.
+	Zones []string
+	// Network, if set, is the name of the cluster network. It is used along with the networks of the cluster instance
+	// templates to scope discovery, e.g. of private DNS zones, which matters once the templates are gone.
+	Network string
+	// DeleteProjectMetadata discovers the project-wide metadata entries kops added for the cluster, e.g. SSH keys.
+	// Only entries named for the cluster are removed; the other entries are kept. This is opt-in, as the project
+	// metadata applies to every instance in the project.
+	DeleteProjectMetadata bool
+	// CheckQuotas warns, before discovery, about project quotas of the discovered resource types that are nearly used up.
+	// Projects with that many resources take long to list, and discovery may fail with rateLimitExceeded errors.
+	CheckQuotas bool
+	// Force detaches a disk from the instances still using it when GCE refuses to delete the disk as in use,
+	// e.g. for orphaned disks attached to instances outside the cluster naming. The instances are not deleted.
+	Force bool
+	// AdvertisedIPRanges are the IP ranges of the cluster that Cloud Routers may advertise, e.g. the pod CIDR.
+	// They are removed from the advertised ranges of routers that are not the cluster's; those routers are kept.
+	AdvertisedIPRanges []string
+	// Explain sets the Reason of each discovered resource to why it was matched to the cluster (e.g. by its name, its
+	// labels, or as it is used by another cluster resource), and logs it. This helps to debug unexpected matches.
+	Explain bool
+	// InstanceGroup, if set, scopes discovery to the resources of the named kops instance group: its MIGs, instance
+	// templates, instances and their disks. The other resources of the cluster, including shared ones, are left alone.
+	InstanceGroup string
+	// HostProject, if set, is the Shared VPC host project that holds the cluster network: its subnets, firewall rules,
+	// routers and peerings are discovered there, while the instances, disks and other resources are in the project
+	// of the cloud (the service project).
+	HostProject string
+	// Events, if set, receives a ResourceFound event for each resource discovery returns. The caller must drain the
+	// channel until the package closes it, which it does once discovery has finished, whether or not it failed.
+	// Sends block, so an unbuffered channel holds up discovery until each event is received; a buffered channel
+	// lets discovery finish ahead of a slow consumer. Use another channel for DeleteResourcesOptions, as each is closed.
+	Events chan<- Event
+}
+
+// ListResourcesGCEWithContext lists the resources for the cluster, aborting if the context is cancelled.
+// If some resource types cannot be listed, the resources that were found are returned along with an aggregate error.
+func ListResourcesGCEWithContext(ctx context.Context, gceCloud gce.GCECloud, clusterName string, region string) (map[string]*resources.Resource, error) {
+	return ListResourcesGCEWithOptions(ctx, gceCloud, clusterName, region, ListResourcesGCEOptions{})
+}
+
+// ListResourcesGCEWithOptions lists the resources for the cluster, see ListResourcesGCEWithContext
+func ListResourcesGCEWithOptions(ctx context.Context, gceCloud gce.GCECloud, clusterName string, region string, options ListResourcesGCEOptions) (map[string]*resources.Resource, error) {
+	if options.Events != nil {
+		defer close(options.Events)
+	}
+
+	if region == "" {
+		region = gceCloud.Region()
+	}
+
+	d, err := newClusterDiscoveryGCE(ctx, gceCloud, clusterName, options)
+	if err != nil {
+		return nil, err
+	}
+	if len(options.Zones) != 0 {
+		if err := d.setZones(region, options.Zones); err != nil {
+			return nil, err
+		}
+	} else if err := d.findZones(ctx, []string{region}); err != nil {
+		return nil, err
+	}
+
+	return d.listResources(ctx, options)
+}
+
+// ListResourcesForCluster lists the resources for the cluster, scoping discovery with its spec: the region of its subnets,
+// the zones of its zonal subnets (instead of every zone in the region), its network and the DNS names of its etcd members.
+// Parts of the spec that are not set fall back to the defaults of ListResourcesGCEWithOptions.
+func ListResourcesForCluster(ctx context.Context, gceCloud gce.GCECloud, cluster *kops.Cluster) (map[string]*resources.Resource, error) {
+	options := ListResourcesGCEOptions{
+		DNSNamePrefixes: DNSNamePrefixesForCluster(cluster),
+		Network:         cluster.Spec.NetworkID,
+	}
+	if cluster.Spec.PodCIDR != "" {
+		options.AdvertisedIPRanges = []string{cluster.Spec.PodCIDR}
+	}
+
+	region := ""
+	zones := sets.NewString()
+	for _, subnet := range cluster.Spec.Subnets {
+		if subnet.Region != "" {
+			if region != "" && region != subnet.Region {
+				return nil, fmt.Errorf("cluster %q has subnets in several regions: %q and %q", cluster.Name, region, subnet.Region)
+			}
+			region = subnet.Region
+		}
+		if subnet.Zone != "" {
+			zones.Insert(subnet.Zone)
+		}
+	}
+	options.Zones = zones.List()
+
+	return ListResourcesGCEWithOptions(ctx, gceCloud, cluster.Name, region, options)
+}
+
+// ListResourcesGCEAllRegions lists the resources for the cluster in every region of the project,
+// for clusters spanning several regions or when the region of the cluster is not known.
+// Global resources are only discovered once. Regional resources are qualified by their region (see regionalID)
+// and zonal resources, e.g. Instances and Disks, by their zone (see diskID), so resources with the same name in
+// different regions or zones are all discovered.
+func ListResourcesGCEAllRegions(ctx context.Context, gceCloud gce.GCECloud, clusterName string, options ListResourcesGCEOptions) (map[string]*resources.Resource, error) {
+	if options.Events != nil {
+		defer close(options.Events)
+	}
+
+	d, err := newClusterDiscoveryGCE(ctx, gceCloud, clusterName, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := d.waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	gceRegions, err := d.compute().Regions().List(ctx, gceCloud.Project())
+	if err != nil {
+		return nil, fmt.Errorf("error listing regions: %v", err)
+	}
+	var regions []string
+	for _, gceRegion := range gceRegions {
+		regions = append(regions, gceRegion.Name)
+	}
+	if err := d.findZones(ctx, regions); err != nil {
+		return nil, err
+	}
+	if len(d.zones) == 0 {
+		return nil, fmt.Errorf("unable to determine zones in regions %v", regions)
+	}
+
+	return d.listResources(ctx, options)
+}
+
+// newClusterDiscoveryGCE builds the discovery state from the options, without calling GCE
+func newClusterDiscoveryGCE(ctx context.Context, gceCloud gce.GCECloud, clusterName string, options ListResourcesGCEOptions) (*clusterDiscoveryGCE, error) {
+	d := &clusterDiscoveryGCE{
+		cloud:       gceCloud,
+		gceCloud:    gceCloud,
+		clusterName: clusterName,
+		DryRun:      options.DryRun,
+		progress:    options.Progress,
+		metrics:     options.Metrics,
+
+		skipDNS:         options.SkipDNS,
+		dnsNamePrefixes: options.DNSNamePrefixes,
+		maxResults:      options.MaxResults,
+		force:           options.Force,
+		network:         options.Network,
+
+		deleteProjectMetadata: options.DeleteProjectMetadata,
+		advertisedIPRanges:    options.AdvertisedIPRanges,
+		instanceGroup:         options.InstanceGroup,
+		hostProject:           options.HostProject,
+	}
+
+	if options.NamePrefix != "" {
+		namePrefix := gce.SafeClusterName(options.NamePrefix)
+		if !namePrefixRegex.MatchString(namePrefix) {
+			return nil, fmt.Errorf("invalid name prefix %q: must be lowercase letters, digits and dashes, starting and ending with a letter or digit", options.NamePrefix)
+		}
+		d.namePrefix = namePrefix
+	}
+
+	qps := options.QPS
+	if qps == 0 {
+		qps = defaultDiscoveryQPS
+	}
+	// Allow a second's worth of calls at once, so short discoveries are not slowed down
+	burst := int(qps)
+	if burst < 1 {
+		burst = 1
+	}
+	d.limiter = rate.NewLimiter(rate.Limit(qps), burst)
+
+	if options.IncludeTPUNodes {
+		tpuNodes, err := newTPUNodeClient(ctx)
+		if err != nil {
+			klog.Warningf("skipping discovery of TPU nodes: %v", err)
+		} else {
+			d.tpuNodes = tpuNodes
+		}
+	}
+
+	if options.CheckPendingOperations || options.WaitForPendingOperations {
+		operations, err := newOperationLister(ctx)
+		if err != nil {
+			klog.Warningf("skipping check for pending operations: %v", err)
+		} else {
+			d.operations = operations
+		}
+	}
+
+	if options.DeleteServiceAccounts {
+		serviceAccounts, err := newServiceAccountClient(ctx)
+		if err != nil {
+			klog.Warningf("skipping discovery of service accounts: %v", err)
+		} else {
+			d.serviceAccounts = serviceAccounts
+		}
+	}
+
+	if options.StateStorePath != "" || len(options.EtcdBackupStores) != 0 {
+		d.stateStorePath = options.StateStorePath
+		d.etcdBackupStores = options.EtcdBackupStores
+		d.gcsObjects = &gcsObjectClientImpl{srv: gceCloud.Storage()}
+	}
+
+	return d, nil
+}
+
+// NoZonesInRegionError is returned when the project has no zones in the region of the cluster,
+// usually because the region is misspelled. AvailableRegions are the regions the project does have zones in.
+type NoZonesInRegionError struct {
+	Region           string
+	AvailableRegions []string
+}
+
+func (e *NoZonesInRegionError) Error() string {
+	return fmt.Sprintf("unable to determine zones in region %q; available regions are %s", e.Region, strings.Join(e.AvailableRegions, ", "))
+}
+
+// findZones sets the regions to scan, and finds the zones in those regions.
+// A NoZonesInRegionError is returned if we are scanning a single region and it has no zones.
+func (d *clusterDiscoveryGCE) findZones(ctx context.Context, regions []string) error {
+	if err := d.waitForRateLimit(ctx); err != nil {
+		return err
+	}
+	gceZones, err := d.compute().Zones().List(ctx, d.gceCloud.Project())
+	if err != nil {
+		return fmt.Errorf("error listing zones: %v", err)
+	}
+
+	regionNames := sets.NewString(regions...)
+	seenRegions := sets.NewString()
+	for _, gceZone := range gceZones {
+		u, err := gce.ParseGoogleCloudURL(gceZone.Region)
+		if err != nil {
+			return err
+		}
+		seenRegions.Insert(u.Name)
+		if !regionNames.Has(u.Name) {
+			continue
+		}
+		d.zones = append(d.zones, gceZone.Name)
+	}
+	if len(d.zones) == 0 && len(regions) == 1 {
+		return &NoZonesInRegionError{Region: regions[0], AvailableRegions: seenRegions.List()}
+	}
+	sort.Strings(d.zones)
+	d.regions = regions
+	klog.Infof("Scanning zones: %v", d.zones)
+	return nil
+}
+
+// setZones sets the zones to scan to the given zones of the region, without listing the zones of the region
+func (d *clusterDiscoveryGCE) setZones(region string, zones []string) error {
+	for _, zone := range zones {
+		if !zoneInRegion(zone, region) {
+			return fmt.Errorf("zone %q is not in region %q", zone, region)
+		}
+	}
+	d.zones = sets.NewString(zones...).List()
+	d.regions = []string{region}
+	klog.Infof("Scanning zones: %v", d.zones)
+	return nil
+}
+
+// zoneInRegion returns true if the zone is named for the region, as GCE zones are, e.g. us-central1-a in us-central1
+func zoneInRegion(zone, region string) bool {
+	suffix := strings.TrimPrefix(zone, region+"-")
+	return suffix != zone && suffix != "" && !strings.Contains(suffix, "-")
+}
+
+// listResources runs discovery in the regions and zones found by findZones or set by setZones
+func (d *clusterDiscoveryGCE) listResources(ctx context.Context, options ListResourcesGCEOptions) (map[string]*resources.Resource, error) {
+	selectedTypes, err := d.selectResourceTypes(options)
+	if err != nil {
+		return nil, err
+	}
+	// Routes are found via the instances they point at, so we need to list instances to find them
+	var listTypes sets.String
+	if selectedTypes != nil {
+		listTypes = sets.NewString(selectedTypes.List()...)
+		if listTypes.Has(typeRoute) {
+			listTypes.Insert(typeInstance)
+		}
+	}
+
+	if options.CheckQuotas {
+		d.checkQuotas(ctx, listTypes)
+	}
+
+	var errs []error
+	resources, err := runListFunctions(ctx, d.listFunctionsFor(listTypes), maxConcurrentListCalls)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	// We try to clean up orphaned routes.
+	// Technically we still have a race condition here - until the master(s) are terminated, they will keep
+	// creating routes.  Another option might be to have a post-destroy cleanup, and only remove routes with no target.
+	if selectedTypes == nil || selectedTypes.Has(typeRoute) {
+		start := time.Now()
+		resourceTrackers, err := d.listRoutes(ctx, resources)
+		if d.metrics != nil {
+			d.metrics.ObserveList([]string{typeRoute}, time.Since(start), err)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+		d.reportProgress(typeRoute, len(resourceTrackers))
+		for _, t := range resourceTrackers {
+			resources[t.Type+":"+t.ID] = t
+		}
+	}
+
+	// The network can only be deleted once everything in it is gone, so we find it last
+	if selectedTypes == nil || selectedTypes.Has(typeNetwork) {
+		start := time.Now()
+		resourceTrackers, err := d.listNetworks(ctx, resources)
+		if d.metrics != nil {
+			d.metrics.ObserveList([]string{typeNetwork}, time.Since(start), err)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+		d.reportProgress(typeNetwork, len(resourceTrackers))
+		for _, t := range resourceTrackers {
+			resources[t.Type+":"+t.ID] = t
+		}
+	}
+
+	for k, t := range resources {
+		if t.Done {
+			delete(resources, k)
+		}
+	}
+
+	linkForwardingRuleAddresses(resources)
+	linkBackendServiceInstanceGroups(resources)
+
+	if selectedTypes != nil {
+		filterResourceTypes(resources, selectedTypes)
+	}
+
+	if options.NameFilter != "" {
+		filterResourceNames(resources, options.NameFilter)
+	}
+
+	removeProtectedResources(resources, options.Protected)
+
+	for _, t := range resources {
+		populateMetadata(t)
+	}
+
+	if options.Explain {
+		d.explainMatches(resources)
+	}
+
+	if options.MinAge > 0 {
+		removeNewResources(resources, time.Now().Add(-options.MinAge))
+	}
+
+	d.blockStateObjects(resources)
+
+	if err := validateSelfLinks(resources); err != nil {
+		errs = append(errs, err)
+	}
+
+	pendingOps, err := d.listPendingOperations(ctx, resources)
+	if err != nil {
+		errs = append(errs, err)
+	} else if options.WaitForPendingOperations {
+		if err := d.waitForPendingOperations(ctx, pendingOps); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, t := range resources {
+		withOpTimeout(t, options.DeleteTimeout)
+	}
+
+	if options.TagBeforeDelete {
+		for _, t := range resources {
+			withDeletingLabel(t)
+		}
+	}
+
+	if d.metrics != nil {
+		for _, t := range resources {
+			withMetrics(t, d.metrics)
+		}
+	}
+
+	if d.DryRun {
+		for _, t := range resources {
+			dryRunDeleters(t)
+		}
+	}
+
+	publishResourcesFound(ctx, options.Events, resources)
+
+	return resources, utilerrors.NewAggregate(errs)
+}
+
+// linkForwardingRuleAddresses makes the forwarding rules that hold the literal IP of a discovered address block that address.
+// The IP of an internal load balancer is reserved as an INTERNAL address, which cannot be deleted while the rule uses it.
+func linkForwardingRuleAddresses(resourceMap map[string]*resources.Resource) {
+	addressKeys := make(map[string]string)
+	for k, t := range resourceMap {
+		a, ok := t.Obj.(*compute.Address)
+		if !ok || a.Address == "" {
+			continue
+		}
+		addressKeys[gce.LastComponent(a.Region)+"/"+a.Address] = k
+	}
+
+	for _, t := range resourceMap {
+		fr, ok := t.Obj.(*compute.ForwardingRule)
+		if !ok || net.ParseIP(fr.IPAddress) == nil {
+			continue
+		}
+		if k, found := addressKeys[gce.LastComponent(fr.Region)+"/"+fr.IPAddress]; found {
+			t.Blocks = append(t.Blocks, k)
+		}
+	}
+}
+
+// linkBackendServiceInstanceGroups makes the backend services that use the instance group of a discovered MIG block that MIG.
+// The MIG owns its instance group, so deleting the MIG first would leave the backend service referring to a missing group.
+// MIGs are matched by the self link of their instance group, which covers zonal and regional MIGs whatever the group is named.
+func linkBackendServiceInstanceGroups(resourceMap map[string]*resources.Resource) {
+	migKeys := make(map[string]string)
+	for k, t := range resourceMap {
+		mig, ok := t.Obj.(*compute.InstanceGroupManager)
+		if !ok || mig.InstanceGroup == "" {
+			continue
+		}
+		if groupKey := computeResourceKey(mig.InstanceGroup); groupKey != "" {
+			migKeys[groupKey] = k
+		}
+	}
+
+	for _, t := range resourceMap {
+		bs, ok := t.Obj.(*compute.BackendService)
+		if !ok {
+			continue
+		}
+		for _, backend := range bs.Backends {
+			if k, found := migKeys[computeResourceKey(backend.Group)]; found {
+				t.Blocks = append(t.Blocks, k)
+			}
+		}
+	}
+}
+
+// filterResourceTypes removes the resources that are not of the given types.
+// Resources that were blocked on a removed resource are no longer blocked, otherwise they would never be deleted.
+func filterResourceTypes(resourceMap map[string]*resources.Resource, types sets.String) {
+	for k, t := range resourceMap {
+		if !types.Has(t.Type) {
+			delete(resourceMap, k)
+		}
+	}
+	pruneBlocked(resourceMap)
+}
+
+// filterResourceNames removes the resources whose name does not contain the filter.
+// As with filterResourceTypes, resources that were blocked on a removed resource are no longer blocked.
+func filterResourceNames(resourceMap map[string]*resources.Resource, filter string) {
+	for k, t := range resourceMap {
+		if !strings.Contains(t.Name, filter) {
+			delete(resourceMap, k)
+		}
+	}
+	pruneBlocked(resourceMap)
+}
+
+// removeProtectedResources removes the resources with the given Type:ID keys, so they are never deleted
+func removeProtectedResources(resourceMap map[string]*resources.Resource, protected []string) {
+	removed := false
+	for _, k := range protected {
+		if _, found := resourceMap[k]; found {
+			klog.Infof("not deleting protected resource %s", k)
+			delete(resourceMap, k)
+			removed = true
+		}
+	}
+	if removed {
+		pruneBlocked(resourceMap)
+	}
+}
+
+// removeNewResources removes the resources created after the cutoff, which may belong to a concurrent kops update.
+// Resources without a creation timestamp are kept.
+func removeNewResources(resourceMap map[string]*resources.Resource, cutoff time.Time) {
+	removed := false
+	for k, t := range resourceMap {
+		if t.CreationTimestamp.After(cutoff) {
+			klog.Infof("not deleting %s, which was only created at %s", k, t.CreationTimestamp.Format(time.RFC3339))
+			delete(resourceMap, k)
+			removed = true
+		}
+	}
+	if removed {
+		pruneBlocked(resourceMap)
+	}
+}
+
+// pruneBlocked removes the Blocked edges to resources that are not in the map, as they would never be unblocked
+func pruneBlocked(resourceMap map[string]*resources.Resource) {
+	for _, t := range resourceMap {
+		var blocked []string
+		for _, b := range t.Blocked {
+			if _, found := resourceMap[b]; found {
+				blocked = append(blocked, b)
+			}
+		}
+		t.Blocked = blocked
+	}
+}
+
+// dryRunDeleters replaces the deleters of the resource with ones that log instead of calling the GCE API
+func dryRunDeleters(t *resources.Resource) {
+	if t.Deleter != nil {
+		t.Deleter = func(cloud fi.Cloud, r *resources.Resource) error {
+			klog.Infof("dry-run: would delete %s:%s", r.Type, r.ID)
+			return nil
+		}
+	}
+	if t.GroupDeleter != nil {
+		t.GroupDeleter = func(cloud fi.Cloud, trackers []*resources.Resource) error {
+			for _, r := range trackers {
+				klog.Infof("dry-run: would delete %s:%s", r.Type, r.ID)
+			}
+			return nil
+		}
+	}
+}
+
+// runListFunctions runs the list functions, at most concurrency at a time, and collects the results.
+// A failing list function does not stop the others; the trackers that were found are returned
+// alongside an aggregate of the errors.
+func runListFunctions(ctx context.Context, listFunctions []gceListFn, concurrency int) (map[string]*resources.Resource, error) {
+	resourceMap := make(map[string]*resources.Resource)
+
+	var mutex sync.Mutex
+	var errs []error
+	sem := make(chan struct{}, concurrency)
+
+	var g errgroup.Group
+	for _, fn := range listFunctions {
+		fn := fn // avoid closure-in-loop go-tcha
+		g.Go(func() error {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			resourceTrackers, err := fn(ctx)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err != nil {
+				errs = append(errs, err)
+				return nil
+			}
+			for _, t := range resourceTrackers {
+				resourceMap[t.Type+":"+t.ID] = t
+			}
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	return resourceMap, utilerrors.NewAggregate(errs)
+}
+
+type clusterDiscoveryGCE struct {
+	cloud       fi.Cloud
+	gceCloud    gce.GCECloud
+	clusterName string
+
+	// computeClient, if set, is used by discovery in place of gceCloud.Compute(), so tests can fake single resource types
+	computeClient gce.ComputeClient
+
+	// regions are the regions we scan for regional resources, and zones are the zones in those regions
+	regions []string
+	zones   []string
+
+	// DryRun makes the deleters of discovered resources log what they would delete, without calling GCE
+	DryRun bool
+
+	// progress is called with the number of resources found of each type, see ListResourcesGCEOptions
+	progress func(resourceType string, found int)
+
+	// metrics receives the duration of list functions, see ListResourcesGCEOptions
+	metrics Metrics
+
+	// limiter is shared by the concurrently running list functions, so together they stay within the GCE quota
+	limiter *rate.Limiter
+
+	// skipDNS disables discovery of DNS records, see ListResourcesGCEOptions
+	skipDNS bool
+
+	// dnsNamePrefixes are the additional DNS record names to delete, see ListResourcesGCEOptions
+	dnsNamePrefixes []string
+
+	// maxResults is the page size hint for large list calls, see ListResourcesGCEOptions
+	maxResults int64
+
+	// namePrefix is the custom cluster part of object names, see ListResourcesGCEOptions
+	namePrefix string
+
+	// tpuNodes is the opt-in client for discovering TPU nodes, see ListResourcesGCEOptions
+	tpuNodes tpuNodeClient
+
+	// operations is the opt-in client for finding pending operations, see ListResourcesGCEOptions
+	operations operationLister
+
+	// serviceAccounts is the opt-in client for discovering service accounts, see ListResourcesGCEOptions
+	serviceAccounts serviceAccountClient
+
+	// stateStorePath is the opt-in state store path whose objects are discovered, see ListResourcesGCEOptions
+	stateStorePath string
+	// etcdBackupStores are the opt-in etcd backup stores whose objects are discovered, see ListResourcesGCEOptions
+	etcdBackupStores []string
+	gcsObjects       gcsObjectClient
+
+	// force detaches disks from the instances using them before deleting them, see ListResourcesGCEOptions
+	force bool
+	// network is the name of the cluster network from the cluster spec, if known
+	network string
+	// deleteProjectMetadata enables discovery of the cluster entries of the project metadata, see ListResourcesGCEOptions
+	deleteProjectMetadata bool
+	// advertisedIPRanges are the cluster IP ranges removed from the advertisements of shared routers, see ListResourcesGCEOptions
+	advertisedIPRanges []string
+	// instanceGroup is the kops instance group discovery is scoped to, if any, see ListResourcesGCEOptions
+	instanceGroup string
+	// hostProject is the Shared VPC host project of the cluster network, if any, see ListResourcesGCEOptions
+	hostProject string
+
+	// mutex protects the cached instanceTemplates, backendServices and targetPools, as list functions run concurrently
+	mutex             sync.Mutex
+	instanceTemplates []*compute.InstanceTemplate
+	backendServices   []*compute.BackendService
+	targetPools       []*compute.TargetPool
+}
+
+// scanRegions returns the regions to scan for regional resources, defaulting to the region of the cloud
+func (d *clusterDiscoveryGCE) scanRegions() []string {
+	if len(d.regions) == 0 {
+		return []string{d.gceCloud.Region()}
+	}
+	return d.regions
+}
+
+// compute returns the client the list functions use to call the compute API
+func (d *clusterDiscoveryGCE) compute() gce.ComputeClient {
+	if d.computeClient != nil {
+		return d.computeClient
+	}
+	return d.gceCloud.Compute()
+}
+
+// waitForRateLimit blocks until the limiter allows another GCE API call, or the context is cancelled
+func (d *clusterDiscoveryGCE) waitForRateLimit(ctx context.Context) error {
+	if d.limiter == nil {
+		return nil
+	}
+	return d.limiter.Wait(ctx)
+}
+
+// typedListFn is a list function along with the resource types it discovers
+type typedListFn struct {
+	types []string
+	list  gceListFn
+}
+
+// typedListFunctions returns the functions that discover the cluster resources; they may run concurrently
+func (d *clusterDiscoveryGCE) typedListFunctions() []typedListFn {
+	return []typedListFn{
+		{[]string{typeInstanceTemplate}, d.listGCEInstanceTemplates},
+		{[]string{typeInstanceGroupManager, typeInstance, typeAutoscaler}, d.listInstanceGroupManagersAndInstances},
+		{[]string{typeInstanceGroup}, d.listInstanceGroups},
+		{[]string{typeTargetPool, typeHttpHealthCheck}, d.listTargetPools},
+		{[]string{typeTargetInstance}, d.listTargetInstances},
+		{[]string{typeForwardingRule}, d.listForwardingRules},
+		{[]string{typeForwardingRule}, d.listGlobalForwardingRules},
+		{[]string{typeBackendService}, d.listBackendServices},
+		{[]string{typeNEG}, d.listNetworkEndpointGroups},
+		{[]string{typeHealthCheck}, d.listHealthChecks},
+		{[]string{typeSecurityPolicy}, d.listSecurityPolicies},
+		{[]string{typeSslPolicy}, d.listSslPolicies},
+		{[]string{typeTargetHttpProxy}, d.listTargetHttpProxies},
+		{[]string{typeTargetHttpsProxy}, d.listTargetHttpsProxies},
+		{[]string{typeUrlMap}, d.listUrlMaps},
+		{[]string{typeSslCertificate}, d.listSslCertificates},
+		{[]string{typeFirewallRule}, d.listFirewallRules},
+		{[]string{typeDisk}, d.listGCEDisks},
+		{[]string{typeDisk}, d.listGCERegionDisks},
+		{[]string{typeResourcePolicy}, d.listResourcePolicies},
+		{[]string{typeSnapshot}, d.listGCESnapshots},
+		{[]string{typeMachineImage}, d.listGCEMachineImages},
+		{[]string{typeImage}, d.listGCEImages},
+		{[]string{typeDNSRecord}, d.listGCEDNSZone},
+		// TODO: Find routes via instances (via instance groups)
+		{[]string{typeAddress}, d.listAddresses},
+		{[]string{typeAddress}, d.listGlobalAddresses},
+		{[]string{typeSubnet}, d.listSubnets},
+		{[]string{typeNetworkPeering}, d.listNetworkPeerings},
+		{[]string{typeRouter, typeRouterNAT, typeRouterPeer, typeRouterAdvertisement}, d.listRouters},
+		{[]string{typeGCSObject}, d.listGCSStateObjects},
+		{[]string{typeGCSObject}, d.listEtcdBackupBucket},
+		{[]string{typeTPUNode}, d.listTPUNodes},
+		{[]string{typeServiceAccount}, d.listServiceAccounts},
+		{[]string{typeProjectMetadata}, d.listProjectMetadataKeys},
+	}
+}
+
+// listFunctions returns all the functions that discover the cluster resources
+func (d *clusterDiscoveryGCE) listFunctions() []gceListFn {
+	return d.listFunctionsFor(nil)
+}
+
+// listFunctionsFor returns the functions that discover any of the given types, or all functions if types is nil
+func (d *clusterDiscoveryGCE) listFunctionsFor(types sets.String) []gceListFn {
+	var selected []typedListFn
+	for _, f := range d.typedListFunctions() {
+		if types == nil || types.HasAny(f.types...) {
+			if d.metrics != nil {
+				f.list = timedList(d.metrics, f.types, f.list)
+			}
+			selected = append(selected, f)
+		}
+	}
+
+	if d.progress == nil {
+		var fns []gceListFn
+		for _, f := range selected {
+			fns = append(fns, f.list)
+		}
+		return fns
+	}
+
+	// Some types are found by more than one function, so we only report a type once all of them are done
+	var mutex sync.Mutex
+	pending := make(map[string]int)
+	found := make(map[string]int)
+	for _, f := range selected {
+		for _, t := range f.types {
+			pending[t]++
+		}
+	}
+
+	var fns []gceListFn
+	for _, f := range selected {
+		f := f // avoid closure-in-loop go-tcha
+		fns = append(fns, func(ctx context.Context) ([]*resources.Resource, error) {
+			resourceTrackers, err := f.list(ctx)
+
+			mutex.Lock()
+			for _, r := range resourceTrackers {
+				found[r.Type]++
+			}
+			var done []string
+			for _, t := range f.types {
+				pending[t]--
+				if pending[t] == 0 {
+					done = append(done, t)
+				}
+			}
+			counts := make(map[string]int)
+			for _, t := range done {
+				counts[t] = found[t]
+			}
+			mutex.Unlock()
+
+			for _, t := range done {
+				d.reportProgress(t, counts[t])
+			}
+			return resourceTrackers, err
+		})
+	}
+	return fns
+}
+
+// reportProgress calls the progress callback, if there is one
+func (d *clusterDiscoveryGCE) reportProgress(resourceType string, found int) {
+	if d.progress != nil {
+		d.progress(resourceType, found)
+	}
+}
+
+// resourceTypes returns all the resource types that discovery can find
+func (d *clusterDiscoveryGCE) resourceTypes() sets.String {
+	types := sets.NewString(typeRoute, typeNetwork)
+	for _, f := range d.typedListFunctions() {
+		types.Insert(f.types...)
+	}
+	return types
+}
+
+// selectResourceTypes returns the resource types selected by the options, or nil if all types are selected
+func (d *clusterDiscoveryGCE) selectResourceTypes(options ListResourcesGCEOptions) (sets.String, error) {
+	if len(options.IncludeTypes) == 0 && len(options.ExcludeTypes) == 0 {
+		if options.InstanceGroup != "" {
+			return sets.NewString(instanceGroupResourceTypes...), nil
+		}
+		return nil, nil
+	}
+
+	valid := d.resourceTypes()
+	for _, t := range append(append([]string{}, options.IncludeTypes...), options.ExcludeTypes...) {
+		if !valid.Has(t) {
+			return nil, fmt.Errorf("unknown resource type %q, valid types are: %s", t, strings.Join(valid.List(), ", "))
+		}
+	}
+
+	selected := valid
+	if len(options.IncludeTypes) != 0 {
+		selected = sets.NewString(options.IncludeTypes...)
+	}
+	if options.InstanceGroup != "" {
+		selected = selected.Intersection(sets.NewString(instanceGroupResourceTypes...))
+	}
+	return selected.Delete(options.ExcludeTypes...), nil
+}
+
+func (d *clusterDiscoveryGCE) findInstanceTemplates(ctx context.Context) ([]*compute.InstanceTemplate, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.instanceTemplates != nil {
+		return d.instanceTemplates, nil
+	}
+
+	// We don't use gce.FindInstanceTemplates, as we match templates more strictly (see matchesInstanceTemplate)
+	if err := d.waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	templates, err := d.compute().InstanceTemplates().List(ctx, d.gceCloud.Project())
+	if err != nil {
+		return nil, fmt.Errorf("error listing instance templates: %v", err)
+	}
+
+	instanceTemplates := []*compute.InstanceTemplate{}
+	for _, t := range templates {
+		if d.matchesInstanceTemplate(t) {
+			instanceTemplates = append(instanceTemplates, t)
+		}
+	}
+
+	d.instanceTemplates = instanceTemplates
+	return d.instanceTemplates, nil
+}
+
+func (d *clusterDiscoveryGCE) listGCEInstanceTemplates(ctx context.Context) ([]*resources.Resource, error) {
+	var resourceTrackers []*resources.Resource
+
+	templates, err := d.findInstanceTemplates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range templates {
+		if !d.templateInInstanceGroup(t) {
+			continue
+		}
+
+		selfLink := t.SelfLink // avoid closure-in-loop go-tcha
+		resourceTracker := &resources.Resource{
+			Name: t.Name,
+			ID:   t.Name,
+			Type: typeInstanceTemplate,
+			Deleter: func(cloud fi.Cloud, r *resources.Resource) error {
+				return gce.DeleteInstanceTemplate(cloud.(gce.GCECloud), selfLink)
+			},
+			Dumper: DumpResource,
+			Obj:    t,
+		}
+
+		klog.V(4).Infof("Found resource: %s", t.SelfLink)
+		resourceTrackers = append(resourceTrackers, resourceTracker)
+	}
+
+	return resourceTrackers, nil
+}
+
+func (d *clusterDiscoveryGCE) listInstanceGroupManagersAndInstances(ctx context.Context) ([]*resources.Resource, error) {
+	c := d.gceCloud
+	project := c.Project()
+
+	var resourceTrackers []*resources.Resource
+
+	instanceTemplates := make(map[string]*compute.InstanceTemplate)
+	{
+		templates, err := d.findInstanceTemplates(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range templates {
+			instanceTemplates[t.SelfLink] = t
+		}
+	}
+
+	// migNames are the names of all the MIGs we found, and migInstances are the instances we track via a MIG
+	migNames := sets.NewString()
+	migInstances := sets.NewString()
+
+	// addMIG adds the trackers for a MIG, and its autoscaler and instances.
+	// scope is the zone or region of the MIG, so zonal and regional MIGs with the same name don't collide.
+	addMIG := func(scope string, mig *compute.InstanceGroupManager, autoscaler *compute.Autoscaler) error {
+		migNames.Insert(mig.Name)
+
+		// A MIG whose template was deleted out-of-band is still ours if it is named for the cluster
+		instanceTemplate := instanceTemplates[mig.InstanceTemplate]
+		if instanceTemplate == nil && !d.matchesCluster(typeInstanceGroupManager, mig.Name, nil) {
+			klog.V(2).Infof("Ignoring MIG with unmanaged InstanceTemplate: %s", mig.InstanceTemplate)
+			return nil
+		}
+		if !d.migInInstanceGroup(mig, instanceTemplate) {
+			return nil
+		}
+
+		resourceTracker := &resources.Resource{
+			Name: mig.Name,
+			ID:   scope + "/" + mig.Name,
+			Type: typeInstanceGroupManager,
+			Deleter: func(cloud fi.Cloud, r *resources.Resource) error {
+				return gce.DeleteInstanceGroupManager(cloud.(gce.GCECloud), mig)
+			},
+			Dumper: DumpResource,
+			Obj:    mig,
+		}
+
+		if instanceTemplate != nil {
+			resourceTracker.Blocks = append(resourceTracker.Blocks, typeInstanceTemplate+":"+instanceTemplate.Name)
+		} else {
+			klog.V(2).Infof("Found MIG %s named for the cluster, whose InstanceTemplate is not one of ours: %s", mig.Name, mig.InstanceTemplate)
+		}
+
+		klog.V(4).Infof("Found resource: %s", mig.SelfLink)
+		resourceTrackers = append(resourceTrackers, resourceTracker)
+
+		if autoscaler != nil {
+			autoscalerTracker := &resources.Resource{
+				Name:    autoscaler.Name,
+				ID:      scope + "/" + autoscaler.Name,
+				Type:    typeAutoscaler,
+				Deleter: deleteAutoscaler,
+				Dumper:  DumpResource,
+				Obj:     autoscaler,
+				Blocks:  []string{typeInstanceGroupManager + ":" + resourceTracker.ID},
+			}
+
+			klog.V(4).Infof("Found resource: %s", autoscaler.SelfLink)
+			resourceTrackers = append(resourceTrackers, autoscalerTracker)
+		}
+
+		instanceTrackers, err := d.listManagedInstances(mig)
+		if err != nil {
+			return fmt.Errorf("error listing instances in InstanceGroupManager: %v", err)
+		}
+		resourceTrackers = append(resourceTrackers, instanceTrackers...)
+		for _, t := range instanceTrackers {
+			migInstances.Insert(t.ID)
+		}
+
+		// Stateful disks stay attached to the instances until the MIG is deleted, so they can only be deleted after it
+		for _, id := range statefulDiskIDs(mig, instanceTrackers) {
+			resourceTracker.Blocks = append(resourceTracker.Blocks, typeDisk+":"+id)
+		}
+		return nil
+	}
+
+	for _, zoneName := range d.zones {
+		if err := d.waitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+		is, err := d.compute().InstanceGroupManagers().List(ctx, project, zoneName)
+		if err != nil {
+			return nil, fmt.Errorf("error listing InstanceGroupManagers: %v", err)
+		}
+
+		// Autoscalers are orphaned when their MIG is deleted, so we find them via the MIG they target
+		autoscalers := make(map[string]*compute.Autoscaler)
+		{
+			if err := d.waitForRateLimit(ctx); err != nil {
+				return nil, err
+			}
+			l, err := d.compute().Autoscalers().List(ctx, project, zoneName)
+			if err != nil {
+				return nil, fmt.Errorf("error listing Autoscalers: %v", err)
+			}
+			for _, a := range l {
+				autoscalers[a.Target] = a
+			}
+		}
+
+		for i := range is {
+			mig := is[i] // avoid closure-in-loop go-tcha
+			if err := addMIG(zoneName, mig, autoscalers[mig.SelfLink]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Regional MIGs spread their instances across the zones of the region
+	for _, region := range d.scanRegions() {
+		if err := d.waitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+		is, err := d.compute().RegionInstanceGroupManagers().List(ctx, project, region)
+		if err != nil {
+			return nil, fmt.Errorf("error listing regional InstanceGroupManagers: %v", err)
+		}
+		for i := range is {
+			mig := is[i] // avoid closure-in-loop go-tcha
+			if err := addMIG(region, mig, nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	standaloneTrackers, err := d.listStandaloneInstances(ctx, migInstances, migNames)
+	if err != nil {
+		return nil, err
+	}
+	resourceTrackers = append(resourceTrackers, standaloneTrackers...)
+
+	return resourceTrackers, nil
+}
+
+// listStandaloneInstances finds the cluster instances that are not in a MIG, such as a bastion created by hand,
+// or instances left behind when deleting their MIG failed.
+// Instances we already track via a MIG are skipped, as are those created by a MIG that still exists,
+// which would recreate them. Instances referenced by a cluster target pool are matched even if they are not
+// named or labelled for the cluster, and are deleted after the pool.
+func (d *clusterDiscoveryGCE) listStandaloneInstances(ctx context.Context, migInstances sets.String, migNames sets.String) ([]*resources.Resource, error) {
+	c := d.gceCloud
+
+	var resourceTrackers []*resources.Resource
+
+	poolInstances, err := d.findTargetPoolInstances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, zoneName := range d.zones {
+		if err := d.waitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+		instances, err := d.compute().Instances().List(ctx, c.Project(), zoneName)
+		if err != nil {
+			return nil, fmt.Errorf("error listing Instances: %v", err)
+		}
+
+		for _, i := range instances {
+			id := zoneName + "/" + i.Name
+			if migInstances.Has(id) {
+				continue
+			}
+			if mig := createdByMIG(i); mig != "" && migNames.Has(mig) {
+				klog.V(8).Infof("Skipping Instance %q created by InstanceGroupManager %q", i.Name, mig)
+				continue
+			}
+			if !d.matchesCluster(typeInstance, i.Name, i.Labels) {
+				// Being in a cluster target pool does not make the instance ours; it may serve other load balancers too
+				if len(poolInstances[id]) != 0 {
+					klog.V(4).Infof("ignoring instance %q referenced by a target pool, as it does not match the cluster", i.Name)
+				}
+				continue
+			}
+			if !d.instanceInInstanceGroup(i) {
+				continue
+			}
+
+			resourceTracker := &resources.Resource{
+				Name:    i.Name,
+				ID:      id,
+				Type:    typeInstance,
+				Zone:    zoneName,
+				Deleter: deleteInstance,
+				Dumper:  DumpResource,
+				Obj:     i,
+				Blocked: poolInstances[id],
+			}
+
+			klog.V(4).Infof("Found resource: %s", i.SelfLink)
+			resourceTrackers = append(resourceTrackers, resourceTracker)
+		}
+	}
+
+	return resourceTrackers, nil
+}
+
+// createdByMIG returns the name of the MIG that created the instance, from the created-by metadata GCE sets on it
+func createdByMIG(i *compute.Instance) string {
+	if i.Metadata == nil {
+		return ""
+	}
+	for _, item := range i.Metadata.Items {
+		if item.Key != "created-by" || item.Value == nil {
+			continue
+		}
+		if strings.Contains(*item.Value, "/instanceGroupManagers/") {
+			return gce.LastComponent(*item.Value)
+		}
+	}
+	return ""
+}
+
+func deleteInstance(cloud fi.Cloud, r *resources.Resource) error {
+	c := cloud.(gce.GCECloud)
+	i := r.Obj.(*compute.Instance)
+
+	return gce.DeleteInstance(c, i.SelfLink)
+}
+
+func deleteAutoscaler(cloud fi.Cloud, r *resources.Resource) error {
+	c := cloud.(gce.GCECloud)
+	t := r.Obj.(*compute.Autoscaler)
+
+	klog.V(2).Infof("Deleting GCE Autoscaler %s", t.SelfLink)
+	u, err := gce.ParseGoogleCloudURL(t.SelfLink)
+	if err != nil {
+		return err
+	}
+
+	op, err := retryableDelete(func() (*compute.Operation, error) {
+		return c.Compute().Autoscalers().Delete(u.Project, u.Zone, u.Name)
+	})
+	if err != nil {
+		if isAlreadyGoneErr(err) {
+			klog.Infof("Autoscaler not found or already being deleted: %q", t.SelfLink)
+			return nil
+		}
+		return fmt.Errorf("error deleting Autoscaler %s: %v", t.SelfLink, err)
+	}
+
+	return waitForOp(deletionContext(c), c, op)
+}
+
+// listInstanceGroups discovers unmanaged InstanceGroups for the cluster.
+// The InstanceGroups that back a MIG are deleted along with the MIG, so they are skipped.
+func (d *clusterDiscoveryGCE) listInstanceGroups(ctx context.Context) ([]*resources.Resource, error) {
+	c := d.gceCloud
+	project := c.Project()
+
+	var resourceTrackers []*resources.Resource
+
+	// BackendServices reference InstanceGroups, so the InstanceGroup can only be removed after them
+	backendServices, err := d.findBackendServices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, zoneName := range d.zones {
+		if err := d.waitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+		migs, err := d.compute().InstanceGroupManagers().List(ctx, project, zoneName)
+		if err != nil {
+			return nil, fmt.Errorf("error listing InstanceGroupManagers: %v", err)
+		}
+		migNames := sets.NewString()
+		for _, mig := range migs {
+			migNames.Insert(mig.Name)
+		}
+
+		if err := d.waitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+		igs, err := d.compute().InstanceGroups().List(ctx, project, zoneName)
+		if err != nil {
+			return nil, fmt.Errorf("error listing InstanceGroups: %v", err)
+		}
+		for _, ig := range igs {
+			if !d.matchesClusterNameMultipart(ig.Name, maxRoleNameTokens) {
+				continue
+			}
+			if migNames.Has(ig.Name) {
+				klog.V(4).Infof("skipping InstanceGroup %q managed by InstanceGroupManager", ig.Name)
+				continue
+			}
+
+			resourceTracker := &resources.Resource{
+				Name:    ig.Name,
+				ID:      zoneName + "/" + ig.Name,
+				Type:    typeInstanceGroup,
+				Deleter: deleteInstanceGroup,
+				Dumper:  DumpResource,
+				Obj:     ig,
+			}
+
+			for _, bs := range backendServices {
+				for _, backend := range bs.Backends {
+					if backend.Group == ig.SelfLink {
+						resourceTracker.Blocked = append(resourceTracker.Blocked, typeBackendService+":"+regionalObjectID(bs.Name, bs.SelfLink))
+					}
+				}
+			}
+
+			klog.V(4).Infof("Found resource: %s", ig.SelfLink)
+			resourceTrackers = append(resourceTrackers, resourceTracker)
+		}
+	}
+
+	return resourceTrackers, nil
+}
+
+func deleteInstanceGroup(cloud fi.Cloud, r *resources.Resource) error {
+	c := cloud.(gce.GCECloud)
+	t := r.Obj.(*compute.InstanceGroup)
+
+	klog.V(2).Infof("Deleting GCE InstanceGroup %s", t.SelfLink)
+	u, err := gce.ParseGoogleCloudURL(t.SelfLink)
+	if err != nil {
+		return err
+	}
+
+	op, err := retryableDelete(func() (*compute.Operation, error) {
+		return c.Compute().InstanceGroups().Delete(u.Project, u.Zone, u.Name)
+	})
+	if err != nil {
+		if isAlreadyGoneErr(err) {
+			klog.Infof("InstanceGroup not found or already being deleted: %q", t.SelfLink)
+			return nil
+		}
+		return fmt.Errorf("error deleting InstanceGroup %s: %v", t.SelfLink, err)
+	}
+
+	return waitForOp(deletionContext(c), c, op)
+}
+
+// statefulDiskIDs returns the IDs (see diskID) of the disks preserved for the instances of a stateful MIG.
+// The stateful policy only names the device, so we find the disks from the preserved state of each instance.
+func statefulDiskIDs(mig *compute.InstanceGroupManager, instanceTrackers []*resources.Resource) []string {
+	if mig.StatefulPolicy == nil || mig.StatefulPolicy.PreservedState == nil || len(mig.StatefulPolicy.PreservedState.Disks) == 0 {
+		return nil
+	}
+
+	diskIDs := sets.NewString()
+	for _, t := range instanceTrackers {
+		i, ok := t.Obj.(*compute.ManagedInstance)
+		if !ok {
+			continue
+		}
+		for _, preservedState := range []*compute.PreservedState{i.PreservedStateFromPolicy, i.PreservedStateFromConfig} {
+			if preservedState == nil {
+				continue
+			}
+			for _, disk := range preservedState.Disks {
+				if disk.Source != "" {
+					diskIDs.Insert(diskID(gce.LastComponent(disk.Source), disk.Source))
+				}
+			}
+		}
+	}
+	return diskIDs.List()
+}
+
+func (d *clusterDiscoveryGCE) listManagedInstances(igm *compute.InstanceGroupManager) ([]*resources.Resource, error) {
+	c := d.gceCloud
+
+	var resourceTrackers []*resources.Resource
+
+	instances, err := gce.ListManagedInstances(c, igm)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, i := range instances {
+		url := i.Instance // avoid closure-in-loop go-tcha
+		name := gce.LastComponent(url)
+
+		// Instances of a regional MIG can be in any zone of the region, so we take the zone from the instance
+		zoneName := gce.LastComponent(igm.Zone)
+		if u, err := gce.ParseGoogleCloudURL(url); err == nil && u.Zone != "" {
+			zoneName = u.Zone
+		}
+
+		resourceTracker := &resources.Resource{
+			Name: name,
+			ID:   zoneName + "/" + name,
+			Type: typeInstance,
+			Zone: zoneName,
+			Deleter: func(cloud fi.Cloud, tracker *resources.Resource) error {
+				return gce.DeleteInstance(cloud.(gce.GCECloud), url)
+			},
+			Dumper: DumpManagedInstance,
+			Obj:    i,
+		}
+
+		// We don't block deletion of the instance group manager
+
+		resourceTrackers = append(resourceTrackers, resourceTracker)
+	}
+
+	return resourceTrackers, nil
+}
+
+// findGCEDisks finds all Disks that are associated with the current cluster
+// It matches them by looking for the cluster label
+func (d *clusterDiscoveryGCE) findGCEDisks(ctx context.Context) ([]*compute.Disk, error) {
+	c := d.gceCloud
+
+	var matches []*compute.Disk
+
+	// TODO: Push down tag filter?
+
+	if err := d.waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	diskLists, err := d.compute().Disks().AggregatedList(ctx, c.Project(), d.maxResults)
+	if err != nil {
+		return nil, fmt.Errorf("error listing disks: %v", err)
+	}
+
+	// Disks created before we labelled them can still be found via the instance templates that attach them.
+	// Instance templates reference existing disks by name; disks that are auto-deleted go away with the instance.
+	templateDisks := sets.NewString()
+	{
+		templates, err := d.findInstanceTemplates(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range templates {
+			if t.Properties == nil {
+				continue
+			}
+			for _, attached := range t.Properties.Disks {
+				if attached.Source == "" || attached.AutoDelete {
+					continue
+				}
+				templateDisks.Insert(gce.LastComponent(attached.Source))
+			}
+		}
+	}
+
+	for _, list := range diskLists {
+		for _, disk := range list.Disks {
+			if !d.matchesCluster(typeDisk, disk.Name, disk.Labels) {
+				if hasDiskClusterLabel(disk.Labels) || !templateDisks.Has(disk.Name) {
+					continue
+				}
+				klog.V(4).Infof("found unlabelled disk %q referenced by an instance template", disk.Name)
+			}
+
+			matches = append(matches, disk)
+		}
+	}
+
+	return matches, nil
+}
+
+// matchesClusterLabel checks if the labels include the cluster label with the value for our cluster
+func (d *clusterDiscoveryGCE) matchesClusterLabel(labels map[string]string) bool {
+	clusterTag := gce.SafeClusterName(d.clusterName)
+
+	match := false
+	for k, v := range labels {
+		if k == gce.GceLabelNameKubernetesCluster {
+			if v == clusterTag {
+				match = true
+			} else {
+				match = false
+				break
+			}
+		}
+	}
+	return match
+}
+
+func (d *clusterDiscoveryGCE) listGCEDisks(ctx context.Context) ([]*resources.Resource, error) {
+	var resourceTrackers []*resources.Resource
+
+	disks, err := d.findGCEDisks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var instanceGroupDisks sets.String
+	if d.instanceGroup != "" {
+		instanceGroupDisks, err = d.findInstanceGroupDisks(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, t := range disks {
+		if instanceGroupDisks != nil && !instanceGroupDisks.Has(t.Name) {
+			continue
+		}
+
+		resourceTracker := &resources.Resource{
+			Name:    t.Name,
+			ID:      diskID(t.Name, t.SelfLink),
+			Type:    typeDisk,
+			Zone:    gce.LastComponent(t.Zone),
+			Deleter: deleteGCEDisk,
+			Dumper:  DumpResource,
+			Obj:     t,
+		}
+		if d.force {
+			resourceTracker.Deleter = forceDeleteGCEDisk
+		}
+
+		for _, u := range t.Users {
+			resourceTracker.Blocked = append(resourceTracker.Blocked, typeInstance+":"+gce.LastComponent(t.Zone)+"/"+gce.LastComponent(u))
+		}
+
+		// The snapshot schedules attached to the disk can only be deleted once the disk is gone
+		for _, policy := range t.ResourcePolicies {
+			resourceTracker.Blocks = append(resourceTracker.Blocks, typeResourcePolicy+":"+regionalID(policy))
+		}
+
+		klog.V(4).Infof("Found resource: %s", t.SelfLink)
+		resourceTrackers = append(resourceTrackers, resourceTracker)
+	}
+
+	return resourceTrackers, nil
+}
+
+func deleteGCEDisk(cloud fi.Cloud, r *resources.Resource) error {
+	return deleteZonalDisk(cloud, r, false)
+}
+
+// forceDeleteGCEDisk deletes the disk as deleteGCEDisk does, but if the disk is still in use,
+// detaches it from the instances using it and retries the delete
+func forceDeleteGCEDisk(cloud fi.Cloud, r *resources.Resource) error {
+	return deleteZonalDisk(cloud, r, true)
+}
+
+func deleteZonalDisk(cloud fi.Cloud, r *resources.Resource, force bool) error {
+	c := cloud.(gce.GCECloud)
+	t := r.Obj.(*compute.Disk)
+
+	klog.V(2).Infof("Deleting GCE Disk %s", t.SelfLink)
+	u, err := gce.ParseGoogleCloudURL(t.SelfLink)
+	if err != nil {
+		return err
+	}
+
+	deleteDisk := func() (*compute.Operation, error) {
+		return c.Compute().Disks().Delete(u.Project, u.Zone, u.Name)
+	}
+	op, err := retryableDelete(deleteDisk)
+	if err != nil && force && isResourceInUse(err) {
+		if err := detachGCEDisk(c, u); err != nil {
+			return err
+		}
+		op, err = retryableDelete(deleteDisk)
+	}
+	if err != nil {
+		if isAlreadyGoneErr(err) {
+			klog.Infof("disk not found or already being deleted: %q", t.SelfLink)
+			return nil
+		}
+		return fmt.Errorf("error deleting disk %s: %v", t.SelfLink, err)
+	}
+
+	return waitForOp(deletionContext(c), c, op)
+}
+
+// detachGCEDisk detaches the zonal disk from the instances using it. It only detaches; the instances are left running.
+func detachGCEDisk(c gce.GCECloud, u *gce.GoogleCloudURL) error {
+	disk, err := c.Compute().Disks().Get(u.Project, u.Zone, u.Name)
+	if err != nil {
+		if gce.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("error getting disk %s: %v", u.Name, err)
+	}
+
+	for _, user := range disk.Users {
+		iu, err := gce.ParseGoogleCloudURL(user)
+		if err != nil {
+			return err
+		}
+		instance, err := c.Compute().Instances().Get(iu.Project, iu.Zone, iu.Name)
+		if err != nil {
+			if gce.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("error getting instance %s: %v", iu.Name, err)
+		}
+
+		for _, attached := range instance.Disks {
+			su, err := gce.ParseGoogleCloudURL(attached.Source)
+			if err != nil || su.Zone != u.Zone || su.Name != u.Name {
+				continue
+			}
+			klog.Warningf("detaching disk %s from instance %s, so the disk can be deleted", u.Name, iu.Name)
+			op, err := c.Compute().Instances().DetachDisk(iu.Project, iu.Zone, iu.Name, attached.DeviceName)
+			if err != nil {
+				if isAlreadyGoneErr(err) {
+					continue
+				}
+				return fmt.Errorf("error detaching disk %s from instance %s: %v", u.Name, iu.Name, err)
+			}
+			if err := waitForOp(deletionContext(c), c, op); err != nil {
+				return fmt.Errorf("error detaching disk %s from instance %s: %v", u.Name, iu.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// findGCERegionDisks finds all regional Disks that are associated with the current cluster
+// It matches them by looking for the cluster label
+func (d *clusterDiscoveryGCE) findGCERegionDisks(ctx context.Context) ([]*compute.Disk, error) {
+	c := d.gceCloud
+
+	var matches []*compute.Disk
+
+	var disks []*compute.Disk
+	for _, region := range d.scanRegions() {
+		if err := d.waitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+		l, err := d.compute().RegionDisks().List(ctx, c.Project(), region)
+		if err != nil {
+			return nil, fmt.Errorf("error listing regional disks: %v", err)
+		}
+		disks = append(disks, l...)
+	}
+
+	for _, disk := range disks {
+		if !d.matchesCluster(typeDisk, disk.Name, disk.Labels) {
+			continue
+		}
+
+		matches = append(matches, disk)
+	}
+
+	return matches, nil
+}
+
+func (d *clusterDiscoveryGCE) listGCERegionDisks(ctx context.Context) ([]*resources.Resource, error) {
+	var resourceTrackers []*resources.Resource
+
+	disks, err := d.findGCERegionDisks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range disks {
+		resourceTracker := &resources.Resource{
+			Name:    t.Name,
+			ID:      regionalObjectID(t.Name, t.SelfLink),
+			Type:    typeDisk,
+			Deleter: deleteGCERegionDisk,
+			Dumper:  DumpResource,
+			Obj:     t,
+		}
+
+		// Regional disks are replicated across zones, so the zone comes from the instance
+		for _, user := range t.Users {
+			u, err := gce.ParseGoogleCloudURL(user)
+			if err != nil {
+				klog.Warningf("error parsing URL for disk user %q: %v", user, err)
+				continue
+			}
+			resourceTracker.Blocked = append(resourceTracker.Blocked, typeInstance+":"+u.Zone+"/"+u.Name)
+		}
+
+		for _, policy := range t.ResourcePolicies {
+			resourceTracker.Blocks = append(resourceTracker.Blocks, typeResourcePolicy+":"+regionalID(policy))
+		}
+
+		klog.V(4).Infof("Found resource: %s", t.SelfLink)
+		resourceTrackers = append(resourceTrackers, resourceTracker)
+	}
+
+	return resourceTrackers, nil
+}
+
+func deleteGCERegionDisk(cloud fi.Cloud, r *resources.Resource) error {
+	c := cloud.(gce.GCECloud)
+	t := r.Obj.(*compute.Disk)
+
+	klog.V(2).Infof("Deleting GCE regional Disk %s", t.SelfLink)
+	u, err := gce.ParseGoogleCloudURL(t.SelfLink)
+	if err != nil {
+		return err
+	}
+
+	op, err := retryableDelete(func() (*compute.Operation, error) {
+		return c.Compute().RegionDisks().Delete(u.Project, u.Region, u.Name)
+	})
+	if err != nil {
+		if isAlreadyGoneErr(err) {
+			klog.Infof("disk not found or already being deleted: %q", t.SelfLink)
+			return nil
+		}
+		return fmt.Errorf("error deleting disk %s: %v", t.SelfLink, err)
+	}
+
+	return waitForOp(deletionContext(c), c, op)
+}
+
+// listResourcePolicies discovers the resource policies, such as snapshot schedules, created for the cluster disks.
+// Resource policies have no labels, so they are matched by name.
+// A policy cannot be deleted while a disk uses it; the disks block the policies they reference.
+func (d *clusterDiscoveryGCE) listResourcePolicies(ctx context.Context) ([]*resources.Resource, error) {
+	c := d.gceCloud
+
+	var resourceTrackers []*resources.Resource
+
+	var policies []*compute.ResourcePolicy
+	for _, region := range d.scanRegions() {
+		if err := d.waitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+		l, err := d.compute().ResourcePolicies().List(ctx, c.Project(), region)
+		if err != nil {
+			return nil, fmt.Errorf("error listing ResourcePolicies: %v", err)
+		}
+		policies = append(policies, l...)
+	}
+
+	for _, p := range policies {
+		if !d.matchesClusterNameMultipart(p.Name, maxRoleNameTokens) {
+			klog.V(8).Infof("Skipping ResourcePolicy with name %q", p.Name)
+			continue
+		}
+
+		resourceTracker := &resources.Resource{
+			Name:    p.Name,
+			ID:      regionalObjectID(p.Name, p.SelfLink),
+			Type:    typeResourcePolicy,
+			Deleter: deleteResourcePolicy,
+			Dumper:  DumpResource,
+			Obj:     p,
+		}
+
+		klog.V(4).Infof("Found resource: %s", p.SelfLink)
+		resourceTrackers = append(resourceTrackers, resourceTracker)
+	}
+
+	return resourceTrackers, nil
+}
+
+func deleteResourcePolicy(cloud fi.Cloud, r *resources.Resource) error {
+	c := cloud.(gce.GCECloud)
+	t := r.Obj.(*compute.ResourcePolicy)
+
+	klog.V(2).Infof("Deleting GCE ResourcePolicy %s", t.SelfLink)
+	u, err := gce.ParseGoogleCloudURL(t.SelfLink)
+	if err != nil {
+		return err
+	}
+
+	op, err := retryableDelete(func() (*compute.Operation, error) {
+		return c.Compute().ResourcePolicies().Delete(u.Project, u.Region, u.Name)
+	})
+	if err != nil {
+		if isAlreadyGoneErr(err) {
+			klog.Infof("ResourcePolicy not found or already being deleted: %q", t.SelfLink)
+			return nil
+		}
+		return fmt.Errorf("error deleting ResourcePolicy %s: %v", t.SelfLink, err)
+	}
+
+	return waitForOp(deletionContext(c), c, op)
+}
+
+// findGCESnapshots finds all Snapshots that are associated with the current cluster
+// It matches them by looking for the cluster label
+func (d *clusterDiscoveryGCE) findGCESnapshots(ctx context.Context) ([]*compute.Snapshot, error) {
+	c := d.gceCloud
+
+	var matches []*compute.Snapshot
+
+	if err := d.waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	snapshots, err := d.compute().Snapshots().List(ctx, c.Project())
+	if err != nil {
+		return nil, fmt.Errorf("error listing snapshots: %v", err)
+	}
+
+	for _, snapshot := range snapshots {
+		if !d.matchesCluster(typeSnapshot, snapshot.Name, snapshot.Labels) {
+			continue
+		}
+
+		matches = append(matches, snapshot)
+	}
+
+	return matches, nil
+}
+
+func (d *clusterDiscoveryGCE) listGCESnapshots(ctx context.Context) ([]*resources.Resource, error) {
+	var resourceTrackers []*resources.Resource
+
+	snapshots, err := d.findGCESnapshots(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range snapshots {
+		resourceTracker := &resources.Resource{
+			Name:    t.Name,
+			ID:      t.Name,
+			Type:    typeSnapshot,
+			Deleter: deleteGCESnapshot,
+			Dumper:  DumpResource,
+			Obj:     t,
+		}
+
+		klog.V(4).Infof("Found resource: %s", t.SelfLink)
+		resourceTrackers = append(resourceTrackers, resourceTracker)
+	}
+
+	return resourceTrackers, nil
+}
+
+func deleteGCESnapshot(cloud fi.Cloud, r *resources.Resource) error {
+	c := cloud.(gce.GCECloud)
+	t := r.Obj.(*compute.Snapshot)
+
+	klog.V(2).Infof("Deleting GCE Snapshot %s", t.SelfLink)
+	u, err := gce.ParseGoogleCloudURL(t.SelfLink)
+	if err != nil {
+		return err
+	}
+
+	op, err := retryableDelete(func() (*compute.Operation, error) {
+		return c.Compute().Snapshots().Delete(u.Project, u.Name)
+	})
+	if err != nil {
+		if isAlreadyGoneErr(err) {
+			klog.Infof("snapshot not found or already being deleted: %q", t.SelfLink)
+			return nil
+		}
+		return fmt.Errorf("error deleting snapshot %s: %v", t.SelfLink, err)
+	}
+
+	return waitForOp(deletionContext(c), c, op)
+}
+
+// findGCEMachineImages finds all MachineImages that are associated with the current cluster
+// It matches them by looking for the cluster label on the instance they were created from,
+// as the machine image does not carry labels of its own in the version of the API we use
+func (d *clusterDiscoveryGCE) findGCEMachineImages(ctx context.Context) ([]*computebeta.MachineImage, error) {
+	c := d.gceCloud
+
+	var matches []*computebeta.MachineImage
+
+	if err := d.waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	machineImages, err := d.compute().MachineImages().List(ctx, c.Project())
+	if err != nil {
+		return nil, fmt.Errorf("error listing machine images: %v", err)
+	}
+
+	for _, machineImage := range machineImages {
+		if machineImage.SourceInstanceProperties == nil || !d.matchesClusterLabel(machineImage.SourceInstanceProperties.Labels) {
+			continue
+		}
+
+		matches = append(matches, machineImage)
+	}
+
+	return matches, nil
+}
+
+func (d *clusterDiscoveryGCE) listGCEMachineImages(ctx context.Context) ([]*resources.Resource, error) {
+	var resourceTrackers []*resources.Resource
+
+	machineImages, err := d.findGCEMachineImages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range machineImages {
+		resourceTracker := &resources.Resource{
+			Name:    t.Name,
+			ID:      t.Name,
+			Type:    typeMachineImage,
+			Deleter: deleteMachineImage,
+			Dumper:  DumpResource,
+			Obj:     t,
+		}
+
+		klog.V(4).Infof("Found resource: %s", t.SelfLink)
+		resourceTrackers = append(resourceTrackers, resourceTracker)
+	}
+
+	return resourceTrackers, nil
+}
+
+// deleteMachineImage deletes the MachineImage, waiting for the operation as machine images can take a while to delete
+func deleteMachineImage(cloud fi.Cloud, r *resources.Resource) error {
+	c := cloud.(gce.GCECloud)
+	t := r.Obj.(*computebeta.MachineImage)
+
+	klog.V(2).Infof("Deleting GCE MachineImage %s", t.SelfLink)
+	u, err := gce.ParseGoogleCloudURL(t.SelfLink)
+	if err != nil {
+		return err
+	}
+
+	op, err := retryableDelete(func() (*compute.Operation, error) {
+		return c.Compute().MachineImages().Delete(u.Project, u.Name)
+	})
+	if err != nil {
+		if isAlreadyGoneErr(err) {
+			klog.Infof("machine image not found or already being deleted: %q", t.SelfLink)
+			return nil
+		}
+		return fmt.Errorf("error deleting machine image %s: %v", t.SelfLink, err)
+	}
+
+	return waitForOp(deletionContext(c), c, op)
+}
+
+// listGCEImages finds the custom Images built for the current cluster, matching them by the cluster label.
+// Images in other projects that are shared with ours are never considered, as we only list our own project.
+func (d *clusterDiscoveryGCE) listGCEImages(ctx context.Context) ([]*resources.Resource, error) {
+	c := d.gceCloud
+
+	var resourceTrackers []*resources.Resource
+
+	if err := d.waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	images, err := d.compute().Images().List(ctx, c.Project())
+	if err != nil {
+		return nil, fmt.Errorf("error listing images: %v", err)
+	}
+
+	for _, image := range images {
+		if !d.matchesCluster(typeImage, image.Name, image.Labels) {
+			continue
+		}
+
+		resourceTracker := &resources.Resource{
+			Name:    image.Name,
+			ID:      image.Name,
+			Type:    typeImage,
+			Deleter: deleteGCEImage,
+			Dumper:  DumpResource,
+			Obj:     image,
+		}
+
+		klog.V(4).Infof("Found resource: %s", image.SelfLink)
+		resourceTrackers = append(resourceTrackers, resourceTracker)
+	}
+
+	return resourceTrackers, nil
+}
+
+func deleteGCEImage(cloud fi.Cloud, r *resources.Resource) error {
+	c := cloud.(gce.GCECloud)
+	t := r.Obj.(*compute.Image)
+
+	klog.V(2).Infof("Deleting GCE Image %s", t.SelfLink)
+	u, err := gce.ParseGoogleCloudURL(t.SelfLink)
+	if err != nil {
+		return err
+	}
+
+	op, err := retryableDelete(func() (*compute.Operation, error) {
+		return c.Compute().Images().Delete(u.Project, u.Name)
+	})
+	if err != nil {
+		if isAlreadyGoneErr(err) {
+			klog.Infof("image not found or already being deleted: %q", t.SelfLink)
+			return nil
+		}
+		return fmt.Errorf("error deleting image %s: %v", t.SelfLink, err)
+	}
+
+	return waitForOp(deletionContext(c), c, op)
+}
+
+func (d *clusterDiscoveryGCE) listTargetPools(ctx context.Context) ([]*resources.Resource, error) {
+	var resourceTrackers []*resources.Resource
+
+	tps, err := d.findTargetPools(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Legacy HTTP health checks are orphaned when the target pool is deleted, so we clean them up too
+	httpHealthChecks := make(map[string]*resources.Resource)
+	// The HTTP health checks of each project, listed when a target pool first references one of them
+	projectHttpHealthChecks := make(map[string]map[string]*compute.HttpHealthCheck)
+
+	for _, tp := range tps {
+		resourceTracker := &resources.Resource{
+			Name:    tp.Name,
+			ID:      regionalObjectID(tp.Name, tp.SelfLink),
+			Type:    typeTargetPool,
+			Deleter: deleteTargetPool,
+			Dumper:  DumpResource,
+			Obj:     tp,
+		}
+
+		klog.V(4).Infof("Found resource: %s", tp.SelfLink)
+		resourceTrackers = append(resourceTrackers, resourceTracker)
+
+		for _, healthCheckLink := range tp.HealthChecks {
+			u, err := gce.ParseGoogleCloudURL(healthCheckLink)
+			if err != nil {
+				return nil, err
+			}
+			if !d.matchesClusterNameMultipart(u.Name, maxRoleNameTokens) {
+				klog.V(4).Infof("skipping HttpHealthCheck %q referenced by TargetPool %q", u.Name, tp.Name)
+				continue
+			}
+
+			hc := httpHealthChecks[u.Name]
+			if hc == nil {
+				healthChecks, found := projectHttpHealthChecks[u.Project]
+				if !found {
+					if err := d.waitForRateLimit(ctx); err != nil {
+						return nil, err
+					}
+					l, err := d.compute().HttpHealthChecks().List(ctx, u.Project)
+					if err != nil {
+						return nil, fmt.Errorf("error listing HttpHealthChecks: %v", err)
+					}
+					healthChecks = make(map[string]*compute.HttpHealthCheck)
+					for _, healthCheck := range l {
+						healthChecks[healthCheck.Name] = healthCheck
+					}
+					projectHttpHealthChecks[u.Project] = healthChecks
+				}
+				healthCheck := healthChecks[u.Name]
+				if healthCheck == nil {
+					klog.V(4).Infof("HttpHealthCheck %q referenced by TargetPool %q not found", u.Name, tp.Name)
+					continue
+				}
+
+				hc = &resources.Resource{
+					Name:    healthCheck.Name,
+					ID:      healthCheck.Name,
+					Type:    typeHttpHealthCheck,
+					Deleter: deleteHttpHealthCheck,
+					Dumper:  DumpResource,
+					Obj:     healthCheck,
+				}
+				httpHealthChecks[u.Name] = hc
+
+				klog.V(4).Infof("Found resource: %s", healthCheck.SelfLink)
+				resourceTrackers = append(resourceTrackers, hc)
+			}
+			hc.Blocked = append(hc.Blocked, typeTargetPool+":"+regionalObjectID(tp.Name, tp.SelfLink))
+		}
+	}
+
+	return resourceTrackers, nil
+}
+
+// findTargetPools returns the target pools of the cluster, which are matched by name
+func (d *clusterDiscoveryGCE) findTargetPools(ctx context.Context) ([]*compute.TargetPool, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.targetPools != nil {
+		return d.targetPools, nil
+	}
+
+	c := d.gceCloud
+
+	targetPools := []*compute.TargetPool{}
+	for _, region := range d.scanRegions() {
+		if err := d.waitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+		l, err := d.compute().TargetPools().List(ctx, c.Project(), region)
+		if err != nil {
+			return nil, fmt.Errorf("error listing TargetPools: %v", err)
+		}
+		for _, tp := range l {
+			if !d.matchesClusterNameMultipart(tp.Name, maxRoleNameTokens) {
+				continue
+			}
+			targetPools = append(targetPools, tp)
+		}
+	}
+
+	d.targetPools = targetPools
+	return d.targetPools, nil
+}
+
+// findTargetPoolInstances returns the keys of the cluster target pools that reference each instance, by zone/name,
+// so the cluster instances are deleted after the pools that send them traffic.
+// The instances of legacy load balancers may have been added to the pool by hand, rather than by a MIG.
+func (d *clusterDiscoveryGCE) findTargetPoolInstances(ctx context.Context) (map[string][]string, error) {
+	tps, err := d.findTargetPools(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	poolInstances := make(map[string][]string)
+	for _, tp := range tps {
+		for _, instanceLink := range tp.Instances {
+			u, err := gce.ParseGoogleCloudURL(instanceLink)
+			if err != nil {
+				return nil, err
+			}
+			id := u.Zone + "/" + u.Name
+			poolInstances[id] = append(poolInstances[id], typeTargetPool+":"+regionalObjectID(tp.Name, tp.SelfLink))
+		}
+	}
+	return poolInstances, nil
+}
+
+func deleteHttpHealthCheck(cloud fi.Cloud, r *resources.Resource) error {
+	c := cloud.(gce.GCECloud)
+	t := r.Obj.(*compute.HttpHealthCheck)
+
+	klog.V(2).Infof("Deleting GCE HttpHealthCheck %s", t.SelfLink)
+	u, err := gce.ParseGoogleCloudURL(t.SelfLink)
+	if err != nil {
+		return err
+	}
+
+	op, err := retryableDelete(func() (*compute.Operation, error) {
+		return c.Compute().HttpHealthChecks().Delete(u.Project, t.Name)
+	})
+	if err != nil {
+		if isAlreadyGoneErr(err) {
+			klog.Infof("HttpHealthCheck not found or already being deleted: %q", t.SelfLink)
+			return nil
+		}
+		return fmt.Errorf("error deleting HttpHealthCheck %s: %v", t.SelfLink, err)
+	}
+
+	return waitForOp(deletionContext(c), c, op)
+}
+
+func deleteTargetPool(cloud fi.Cloud, r *resources.Resource) error {
+	c := cloud.(gce.GCECloud)
+	t := r.Obj.(*compute.TargetPool)
+
+	klog.V(2).Infof("Deleting GCE TargetPool %s", t.SelfLink)
+	u, err := gce.ParseGoogleCloudURL(t.SelfLink)
+	if err != nil {
+		return err
+	}
+
+	op, err := retryableDelete(func() (*compute.Operation, error) {
+		return c.Compute().TargetPools().Delete(u.Project, u.Region, u.Name)
+	})
+	if err != nil {
+		if isAlreadyGoneErr(err) {
+			klog.Infof("TargetPool not found or already being deleted: %q", t.SelfLink)
+			return nil
+		}
+		return fmt.Errorf("error deleting TargetPool %s: %v", t.SelfLink, err)
+	}
+
+	return waitForOp(deletionContext(c), c, op)
+}
+
+// listTargetInstances discovers the TargetInstances that forwarding rules use to send traffic to a single instance
+func (d *clusterDiscoveryGCE) listTargetInstances(ctx context.Context) ([]*resources.Resource, error) {
+	c := d.gceCloud
+
+	var resourceTrackers []*resources.Resource
+
+	for _, zoneName := range d.zones {
+		if err := d.waitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+		tis, err := d.compute().TargetInstances().List(ctx, c.Project(), zoneName)
+		if err != nil {
+			return nil, fmt.Errorf("error listing TargetInstances: %v", err)
+		}
+
+		for _, ti := range tis {
+			if !d.matchesClusterNameMultipart(ti.Name, maxRoleNameTokens) {
+				continue
+			}
+
+			resourceTracker := &resources.Resource{
+				Name:    ti.Name,
+				ID:      zoneName + "/" + ti.Name,
+				Type:    typeTargetInstance,
+				Deleter: deleteTargetInstance,
+				Dumper:  DumpResource,
+				Obj:     ti,
+			}
+
+			// The instance can't be deleted while the TargetInstance still refers to it
+			if ti.Instance != "" {
+				resourceTracker.Blocks = append(resourceTracker.Blocks, typeInstance+":"+zoneName+"/"+gce.LastComponent(ti.Instance))
+			}
+
+			klog.V(4).Infof("Found resource: %s", ti.SelfLink)
+			resourceTrackers = append(resourceTrackers, resourceTracker)
+		}
+	}
+
+	return resourceTrackers, nil
+}
+
+func deleteTargetInstance(cloud fi.Cloud, r *resources.Resource) error {
+	c := cloud.(gce.GCECloud)
+	t := r.Obj.(*compute.TargetInstance)
+
+	klog.V(2).Infof("Deleting GCE TargetInstance %s", t.SelfLink)
+	u, err := gce.ParseGoogleCloudURL(t.SelfLink)
+	if err != nil {
+		return err
+	}
+
+	op, err := retryableDelete(func() (*compute.Operation, error) {
+		return c.Compute().TargetInstances().Delete(u.Project, u.Zone, u.Name)
+	})
+	if err != nil {
+		if isAlreadyGoneErr(err) {
+			klog.Infof("TargetInstance not found or already being deleted: %q", t.SelfLink)
+			return nil
+		}
+		return fmt.Errorf("error deleting TargetInstance %s: %v", t.SelfLink, err)
+	}
+
+	return waitForOp(deletionContext(c), c, op)
+}
+
+func (d *clusterDiscoveryGCE) listForwardingRules(ctx context.Context) ([]*resources.Resource, error) {
+	c := d.gceCloud
+
+	var resourceTrackers []*resources.Resource
+
+	var frs []*compute.ForwardingRule
+	for _, region := range d.scanRegions() {
+		if err := d.waitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+		l, err := d.compute().ForwardingRules().List(ctx, c.Project(), region)
+		if err != nil {
+			return nil, fmt.Errorf("error listing ForwardingRules: %v", err)
+		}
+		frs = append(frs, l...)
+	}
+
+	for _, fr := range frs {
+		if !d.matchesCluster(typeForwardingRule, fr.Name, fr.Labels) {
+			continue
+		}
+
+		resourceTracker := &resources.Resource{
+			Name:    fr.Name,
+			ID:      regionalObjectID(fr.Name, fr.SelfLink),
+			Type:    typeForwardingRule,
+			Deleter: deleteForwardingRule,
+			Dumper:  DumpResource,
+			Obj:     fr,
+		}
+
+		resourceTracker.Blocks = append(resourceTracker.Blocks, forwardingRuleBlocks(fr)...)
+
+		klog.V(4).Infof("Found resource: %s", fr.SelfLink)
+		resourceTrackers = append(resourceTrackers, resourceTracker)
+	}
+
+	return resourceTrackers, nil
+}
+
+// listGlobalForwardingRules discovers the global ForwardingRules used by global HTTP(S) load balancers
+func (d *clusterDiscoveryGCE) listGlobalForwardingRules(ctx context.Context) ([]*resources.Resource, error) {
+	c := d.gceCloud
+
+	var resourceTrackers []*resources.Resource
+
+	if err := d.waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	frs, err := d.compute().GlobalForwardingRules().List(ctx, c.Project())
+	if err != nil {
+		return nil, fmt.Errorf("error listing global ForwardingRules: %v", err)
+	}
+
+	for _, fr := range frs {
+		if !d.matchesCluster(typeForwardingRule, fr.Name, fr.Labels) {
+			continue
+		}
+
+		resourceTracker := &resources.Resource{
+			Name:    fr.Name,
+			ID:      regionalObjectID(fr.Name, fr.SelfLink),
+			Type:    typeForwardingRule,
+			Deleter: deleteGlobalForwardingRule,
+			Dumper:  DumpResource,
+			Obj:     fr,
+		}
+
+		resourceTracker.Blocks = append(resourceTracker.Blocks, forwardingRuleBlocks(fr)...)
+
+		klog.V(4).Infof("Found resource: %s", fr.SelfLink)
+		resourceTrackers = append(resourceTrackers, resourceTracker)
+	}
+
+	return resourceTrackers, nil
+}
+
+// forwardingRuleBlocks returns the keys of the resources that can only be deleted after the ForwardingRule
+func forwardingRuleBlocks(fr *compute.ForwardingRule) []string {
+	var blocks []string
+
+	if fr.Target != "" {
+		u, err := gce.ParseGoogleCloudURL(fr.Target)
+		if err != nil {
+			klog.Warningf("error parsing URL for ForwardingRule target %q: %v", fr.Target, err)
+		} else {
+			switch u.Type {
+			case "targetHttpProxies":
+				blocks = append(blocks, typeTargetHttpProxy+":"+u.Name)
+			case "targetHttpsProxies":
+				blocks = append(blocks, typeTargetHttpsProxy+":"+u.Name)
+			case "targetInstances":
+				blocks = append(blocks, typeTargetInstance+":"+u.Zone+"/"+u.Name)
+			case "targetPools":
+				blocks = append(blocks, typeTargetPool+":"+regionalID(fr.Target))
+			default:
+				// e.g. targetTcpProxies or targetVpnGateways, which we do not discover
+				klog.V(4).Infof("ignoring ForwardingRule %q target %q of unknown kind %q", fr.Name, fr.Target, u.Type)
+			}
+		}
+	}
+
+	// Internal load balancers point at a regional BackendService instead of a target
+	if fr.BackendService != "" {
+		blocks = append(blocks, typeBackendService+":"+regionalID(fr.BackendService))
+	}
+
+	// Forwarding rules usually hold the literal IP of their address, which is linked in linkForwardingRuleAddresses
+	if fr.IPAddress != "" && net.ParseIP(fr.IPAddress) == nil {
+		blocks = append(blocks, typeAddress+":"+regionalID(fr.IPAddress))
+	}
+
+	return blocks
+}
+
+func deleteForwardingRule(cloud fi.Cloud, r *resources.Resource) error {
+	c := cloud.(gce.GCECloud)
+	t := r.Obj.(*compute.ForwardingRule)
+
+	klog.V(2).Infof("Deleting GCE ForwardingRule %s", t.SelfLink)
+	u, err := gce.ParseGoogleCloudURL(t.SelfLink)
+	if err != nil {
+		return err
+	}
+
+	op, err := retryableDelete(func() (*compute.Operation, error) {
+		return c.Compute().ForwardingRules().Delete(u.Project, u.Region, u.Name)
+	})
+	if err != nil {
+		if isAlreadyGoneErr(err) {
+			klog.Infof("ForwardingRule not found or already being deleted: %q", t.SelfLink)
+			return nil
+		}
+		return fmt.Errorf("error deleting ForwardingRule %s: %v", t.SelfLink, err)
+	}
+
+	return waitForOp(deletionContext(c), c, op)
+}
+
+func deleteGlobalForwardingRule(cloud fi.Cloud, r *resources.Resource) error {
+	c := cloud.(gce.GCECloud)
+	t := r.Obj.(*compute.ForwardingRule)
+
+	klog.V(2).Infof("Deleting GCE global ForwardingRule %s", t.SelfLink)
+	u, err := gce.ParseGoogleCloudURL(t.SelfLink)
+	if err != nil {
+		return err
+	}
+
+	op, err := retryableDelete(func() (*compute.Operation, error) {
+		return c.Compute().GlobalForwardingRules().Delete(u.Project, u.Name)
+	})
+	if err != nil {
+		if isAlreadyGoneErr(err) {
+			klog.Infof("ForwardingRule not found or already being deleted: %q", t.SelfLink)
+			return nil
+		}
+		return fmt.Errorf("error deleting ForwardingRule %s: %v", t.SelfLink, err)
+	}
+
+	return waitForOp(deletionContext(c), c, op)
+}
+
+// findBackendServices finds all global and regional BackendServices that match the cluster name
+func (d *clusterDiscoveryGCE) findBackendServices(ctx context.Context) ([]*compute.BackendService, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.backendServices != nil {
+		return d.backendServices, nil
+	}
+
+	c := d.gceCloud
+
+	var all []*compute.BackendService
+	{
+		if err := d.waitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+		l, err := d.compute().BackendServices().List(ctx, c.Project())
+		if err != nil {
+			return nil, fmt.Errorf("error listing BackendServices: %v", err)
+		}
+		all = append(all, l...)
+	}
+	for _, region := range d.scanRegions() {
+		if err := d.waitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+		l, err := d.compute().RegionBackendServices().List(ctx, c.Project(), region)
+		if err != nil {
+			return nil, fmt.Errorf("error listing regional BackendServices: %v", err)
+		}
+		all = append(all, l...)
+	}
+
+	backendServices := []*compute.BackendService{}
+	for _, bs := range all {
+		if !d.matchesClusterNameMultipart(bs.Name, maxRoleNameTokens) {
+			continue
+		}
+		backendServices = append(backendServices, bs)
+	}
+
+	d.backendServices = backendServices
+	return d.backendServices, nil
+}
+
+// listBackendServices discovers global and regional BackendService objects for the cluster, see regionalID
+func (d *clusterDiscoveryGCE) listBackendServices(ctx context.Context) ([]*resources.Resource, error) {
+	var resourceTrackers []*resources.Resource
+
+	backendServices, err := d.findBackendServices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, bs := range backendServices {
+		resourceTracker := &resources.Resource{
+			Name:    bs.Name,
+			ID:      regionalObjectID(bs.Name, bs.SelfLink),
+			Type:    typeBackendService,
+			Deleter: deleteBackendService,
+			Dumper:  DumpResource,
+			Obj:     bs,
+		}
+
+		for _, hc := range bs.HealthChecks {
+			resourceTracker.Blocks = append(resourceTracker.Blocks, typeHealthCheck+":"+regionalID(hc))
+		}
+		if bs.SecurityPolicy != "" {
+			resourceTracker.Blocks = append(resourceTracker.Blocks, typeSecurityPolicy+":"+gce.LastComponent(bs.SecurityPolicy))
+		}
+
+		for _, backend := range bs.Backends {
+			if backend.Group == "" {
+				continue
+			}
+			u, err := gce.ParseGoogleCloudURL(backend.Group)
+			if err != nil {
+				klog.Warningf("error parsing URL for backend group %q: %v", backend.Group, err)
+				continue
+			}
+			// Instance groups of MIGs are linked to their MIG once all resources are found, see linkBackendServiceInstanceGroups
+			if u.Type == "networkEndpointGroups" {
+				if id := networkEndpointGroupID(u); id != "" {
+					resourceTracker.Blocks = append(resourceTracker.Blocks, typeNEG+":"+id)
+				}
+			}
+		}
+
+		klog.V(4).Infof("Found resource: %s", bs.SelfLink)
+		resourceTrackers = append(resourceTrackers, resourceTracker)
+	}
+
+	return resourceTrackers, nil
+}
+
+func deleteBackendService(cloud fi.Cloud, r *resources.Resource) error {
+	c := cloud.(gce.GCECloud)
+	t := r.Obj.(*compute.BackendService)
+
+	klog.V(2).Infof("Deleting GCE BackendService %s", t.SelfLink)
+	u, err := gce.ParseGoogleCloudURL(t.SelfLink)
+	if err != nil {
+		return err
+	}
+
+	var op *compute.Operation
+	if u.Region != "" {
+		op, err = retryableDelete(func() (*compute.Operation, error) {
+			return c.Compute().RegionBackendServices().Delete(u.Project, u.Region, u.Name)
+		})
+	} else {
+		op, err = retryableDelete(func() (*compute.Operation, error) {
+			return c.Compute().BackendServices().Delete(u.Project, u.Name)
+		})
+	}
+	if err != nil {
+		if isAlreadyGoneErr(err) {
+			klog.Infof("BackendService not found or already being deleted: %q", t.SelfLink)
+			return nil
+		}
+		return fmt.Errorf("error deleting BackendService %s: %v", t.SelfLink, err)
+	}
+
+	return waitForOp(deletionContext(c), c, op)
+}
+
+// listNetworkEndpointGroups discovers the zonal NetworkEndpointGroups that container-native load balancing creates for services,
+// and the global (internet) NetworkEndpointGroups the ingress controller can create.
+// The ingress controller doesn't name them after the cluster, so we also match the NEGs used by the cluster BackendServices.
+func (d *clusterDiscoveryGCE) listNetworkEndpointGroups(ctx context.Context) ([]*resources.Resource, error) {
+	c := d.gceCloud
+
+	backendServices, err := d.findBackendServices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// backendNEGs are the IDs (see networkEndpointGroupID) of the NEGs used by the cluster BackendServices
+	backendNEGs := sets.NewString()
+	for _, bs := range backendServices {
+		for _, backend := range bs.Backends {
+			u, err := gce.ParseGoogleCloudURL(backend.Group)
+			if err != nil || u.Type != "networkEndpointGroups" {
+				continue
+			}
+			backendNEGs.Insert(networkEndpointGroupID(u))
+		}
+	}
+
+	var resourceTrackers []*resources.Resource
+	addNEG := func(id string, neg *compute.NetworkEndpointGroup) {
+		if !backendNEGs.Has(id) && !d.matchesClusterLabelOrName(neg.Annotations, neg.Name, maxRoleNameTokens) {
+			return
+		}
+
+		resourceTracker := &resources.Resource{
+			Name:    neg.Name,
+			ID:      id,
+			Type:    typeNEG,
+			Deleter: deleteNetworkEndpointGroup,
+			Dumper:  DumpResource,
+			Obj:     neg,
+		}
+
+		klog.V(4).Infof("Found resource: %s", neg.SelfLink)
+		resourceTrackers = append(resourceTrackers, resourceTracker)
+	}
+
+	for _, zoneName := range d.zones {
+		if err := d.waitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+		negs, err := d.compute().NetworkEndpointGroups().List(ctx, c.Project(), zoneName)
+		if err != nil {
+			return nil, fmt.Errorf("error listing NetworkEndpointGroups: %v", err)
+		}
+		for _, neg := range negs {
+			addNEG(zoneName+"/"+neg.Name, neg)
+		}
+	}
+
+	if err := d.waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	negs, err := d.compute().GlobalNetworkEndpointGroups().List(ctx, c.Project())
+	if err != nil {
+		return nil, fmt.Errorf("error listing global NetworkEndpointGroups: %v", err)
+	}
+	for _, neg := range negs {
+		addNEG("global/"+neg.Name, neg)
+	}
+
+	return resourceTrackers, nil
+}
+
+// networkEndpointGroupID returns the ID we use for the NetworkEndpointGroup: zone/name for zonal NEGs and global/name for global NEGs,
+// so that zonal and global NEGs with the same name don't collide. Regional NEGs are not discovered, so they have no ID.
+func networkEndpointGroupID(u *gce.GoogleCloudURL) string {
+	switch {
+	case u.Zone != "":
+		return u.Zone + "/" + u.Name
+	case u.Global:
+		return "global/" + u.Name
+	default:
+		return ""
+	}
+}
+
+func deleteNetworkEndpointGroup(cloud fi.Cloud, r *resources.Resource) error {
+	c := cloud.(gce.GCECloud)
+	t := r.Obj.(*compute.NetworkEndpointGroup)
+
+	klog.V(2).Infof("Deleting GCE NetworkEndpointGroup %s", t.SelfLink)
+	u, err := gce.ParseGoogleCloudURL(t.SelfLink)
+	if err != nil {
+		return err
+	}
+
+	var op *compute.Operation
+	if u.Zone != "" {
+		op, err = retryableDelete(func() (*compute.Operation, error) {
+			return c.Compute().NetworkEndpointGroups().Delete(u.Project, u.Zone, u.Name)
+		})
+	} else {
+		op, err = retryableDelete(func() (*compute.Operation, error) {
+			return c.Compute().GlobalNetworkEndpointGroups().Delete(u.Project, u.Name)
+		})
+	}
+	if err != nil {
+		if isAlreadyGoneErr(err) {
+			klog.Infof("NetworkEndpointGroup not found or already being deleted: %q", t.SelfLink)
+			return nil
+		}
+		return fmt.Errorf("error deleting NetworkEndpointGroup %s: %v", t.SelfLink, err)
+	}
+
+	return waitForOp(deletionContext(c), c, op)
+}
+
+// listHealthChecks discovers the global HealthChecks and the regional HealthChecks of internal load balancers, see regionalID
+func (d *clusterDiscoveryGCE) listHealthChecks(ctx context.Context) ([]*resources.Resource, error) {
+	c := d.gceCloud
+
+	var resourceTrackers []*resources.Resource
+
+	var healthChecks []*compute.HealthCheck
+	{
+		if err := d.waitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+		l, err := d.compute().HealthChecks().List(ctx, c.Project())
+		if err != nil {
+			return nil, fmt.Errorf("error listing HealthChecks: %v", err)
+		}
+		healthChecks = append(healthChecks, l...)
+	}
+	for _, region := range d.scanRegions() {
+		if err := d.waitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+		l, err := d.compute().RegionHealthChecks().List(ctx, c.Project(), region)
+		if err != nil {
+			return nil, fmt.Errorf("error listing regional HealthChecks: %v", err)
+		}
+		healthChecks = append(healthChecks, l...)
+	}
+
+	// BackendServices reference HealthChecks, so the HealthCheck can only be removed after them
+	backendServices, err := d.findBackendServices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, hc := range healthChecks {
+		if !d.matchesCluster(typeHealthCheck, hc.Name, nil) {
+			continue
+		}
+
+		resourceTracker := &resources.Resource{
+			Name:    hc.Name,
+			ID:      regionalObjectID(hc.Name, hc.SelfLink),
+			Type:    typeHealthCheck,
+			Deleter: deleteHealthCheck,
+			Dumper:  DumpResource,
+			Obj:     hc,
+		}
+
+		for _, bs := range backendServices {
+			for _, u := range bs.HealthChecks {
+				if u == hc.SelfLink {
+					resourceTracker.Blocked = append(resourceTracker.Blocked, typeBackendService+":"+regionalObjectID(bs.Name, bs.SelfLink))
+				}
+			}
+		}
 
-func ListResourcesGCE(gceCloud gce.GCECloud, clusterName string, region string) (map[string]*resources.Resource, error) {
-	if region == "" {
-		region = gceCloud.Region()
+		klog.V(4).Infof("Found resource: %s", hc.SelfLink)
+		resourceTrackers = append(resourceTrackers, resourceTracker)
 	}
 
-	resources := make(map[string]*resources.Resource)
+	return resourceTrackers, nil
+}
 
-	d := &clusterDiscoveryGCE{
-		cloud:       gceCloud,
-		gceCloud:    gceCloud,
-		clusterName: clusterName,
+// regionalID identifies a resource that is either global or regional (e.g. a HealthCheck or BackendService) by its
+// self link: global resources by their name, and regional resources (e.g. of internal load balancers) by their region
+// and name, e.g. us-test1/api-cluster-example-com, as a global and a regional resource may have the same name
+func regionalID(selfLink string) string {
+	u, err := gce.ParseGoogleCloudURL(selfLink)
+	if err != nil {
+		return gce.LastComponent(selfLink)
+	}
+	if u.Region != "" {
+		return u.Region + "/" + u.Name
+	}
+	return u.Name
+}
+
+// diskID identifies a Disk by its zone and name, e.g. us-test1-a/a-etcd-main-cluster-example-com, as disks with the
+// same name can exist in several zones, and a regional Disk by its region and name as regionalID does.
+// It falls back to the name of the disk if its self link cannot be parsed, as validateSelfLinks reports those.
+func diskID(name string, selfLink string) string {
+	u, err := gce.ParseGoogleCloudURL(selfLink)
+	if err != nil {
+		return name
+	}
+	if u.Zone != "" {
+		return u.Zone + "/" + u.Name
 	}
+	return regionalID(selfLink)
+}
 
-	{
-		// TODO: Only zones in api.Cluster object, if we have one?
-		gceZones, err := d.gceCloud.Compute().Zones().List(context.Background(), d.gceCloud.Project())
-		if err != nil {
-			return nil, fmt.Errorf("error listing zones: %v", err)
+// regionalObjectID identifies a discovered object as regionalID does, falling back to its name if its self link cannot
+// be parsed, as validateSelfLinks reports those
+func regionalObjectID(name string, selfLink string) string {
+	if _, err := gce.ParseGoogleCloudURL(selfLink); err != nil {
+		return name
+	}
+	return regionalID(selfLink)
+}
+
+// listSecurityPolicies discovers the Cloud Armor SecurityPolicies for the cluster, which the ingress controller attaches to BackendServices.
+// A policy cannot be deleted while a BackendService uses it, so it is Blocked by the cluster BackendServices using it,
+// which detaches it when they are deleted.
+func (d *clusterDiscoveryGCE) listSecurityPolicies(ctx context.Context) ([]*resources.Resource, error) {
+	c := d.gceCloud
+
+	var resourceTrackers []*resources.Resource
+
+	if err := d.waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	policies, err := d.compute().SecurityPolicies().List(ctx, c.Project())
+	if err != nil {
+		return nil, fmt.Errorf("error listing SecurityPolicies: %v", err)
+	}
+
+	backendServices, err := d.findBackendServices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sp := range policies {
+		if !d.matchesClusterNameMultipart(sp.Name, maxRoleNameTokens) {
+			continue
 		}
-		for _, gceZone := range gceZones {
-			u, err := gce.ParseGoogleCloudURL(gceZone.Region)
-			if err != nil {
-				return nil, err
-			}
-			if u.Name != region {
-				continue
-			}
-			d.zones = append(d.zones, gceZone.Name)
+
+		resourceTracker := &resources.Resource{
+			Name:    sp.Name,
+			ID:      sp.Name,
+			Type:    typeSecurityPolicy,
+			Deleter: deleteSecurityPolicy,
+			Dumper:  DumpResource,
+			Obj:     sp,
 		}
-		if len(d.zones) == 0 {
-			return nil, fmt.Errorf("unable to determine zones in region %q", region)
+
+		for _, bs := range backendServices {
+			if bs.SecurityPolicy != "" && gce.LastComponent(bs.SecurityPolicy) == sp.Name {
+				resourceTracker.Blocked = append(resourceTracker.Blocked, typeBackendService+":"+regionalObjectID(bs.Name, bs.SelfLink))
+			}
 		}
-		klog.Infof("Scanning zones: %v", d.zones)
+
+		klog.V(4).Infof("Found resource: %s", sp.SelfLink)
+		resourceTrackers = append(resourceTrackers, resourceTracker)
 	}
 
-	listFunctions := []gceListFn{
-		d.listGCEInstanceTemplates,
-		d.listInstanceGroupManagersAndInstances,
-		d.listTargetPools,
-		d.listForwardingRules,
-		d.listFirewallRules,
-		d.listGCEDisks,
-		d.listGCEDNSZone,
-		// TODO: Find routes via instances (via instance groups)
-		d.listAddresses,
-		d.listSubnets,
-		d.listRouters,
+	return resourceTrackers, nil
+}
+
+func deleteSecurityPolicy(cloud fi.Cloud, r *resources.Resource) error {
+	c := cloud.(gce.GCECloud)
+	t := r.Obj.(*compute.SecurityPolicy)
+
+	klog.V(2).Infof("Deleting GCE SecurityPolicy %s", t.SelfLink)
+	u, err := gce.ParseGoogleCloudURL(t.SelfLink)
+	if err != nil {
+		return err
 	}
-	for _, fn := range listFunctions {
-		resourceTrackers, err := fn()
-		if err != nil {
-			return nil, err
-		}
-		for _, t := range resourceTrackers {
-			resources[t.Type+":"+t.ID] = t
+
+	op, err := retryableDelete(func() (*compute.Operation, error) {
+		return c.Compute().SecurityPolicies().Delete(u.Project, u.Name)
+	})
+	if err != nil {
+		if isAlreadyGoneErr(err) {
+			klog.Infof("SecurityPolicy not found or already being deleted: %q", t.SelfLink)
+			return nil
 		}
+		return fmt.Errorf("error deleting SecurityPolicy %s: %v", t.SelfLink, err)
 	}
 
-	// We try to clean up orphaned routes.
-	// Technically we still have a race condition here - until the master(s) are terminated, they will keep
-	// creating routes.  Another option might be to have a post-destroy cleanup, and only remove routes with no target.
-	{
-		resourceTrackers, err := d.listRoutes(resources)
-		if err != nil {
-			return nil, err
-		}
-		for _, t := range resourceTrackers {
-			resources[t.Type+":"+t.ID] = t
-		}
+	return waitForOp(deletionContext(c), c, op)
+}
+
+func deleteHealthCheck(cloud fi.Cloud, r *resources.Resource) error {
+	c := cloud.(gce.GCECloud)
+	t := r.Obj.(*compute.HealthCheck)
+
+	klog.V(2).Infof("Deleting GCE HealthCheck %s", t.SelfLink)
+	u, err := gce.ParseGoogleCloudURL(t.SelfLink)
+	if err != nil {
+		return err
 	}
 
-	for k, t := range resources {
-		if t.Done {
-			delete(resources, k)
+	var op *compute.Operation
+	if u.Region != "" {
+		op, err = retryableDelete(func() (*compute.Operation, error) {
+			return c.Compute().RegionHealthChecks().Delete(u.Project, u.Region, u.Name)
+		})
+	} else {
+		op, err = retryableDelete(func() (*compute.Operation, error) {
+			return c.Compute().HealthChecks().Delete(u.Project, u.Name)
+		})
+	}
+	if err != nil {
+		if isAlreadyGoneErr(err) {
+			klog.Infof("HealthCheck not found or already being deleted: %q", t.SelfLink)
+			return nil
 		}
+		return fmt.Errorf("error deleting HealthCheck %s: %v", t.SelfLink, err)
 	}
-	return resources, nil
+
+	return waitForOp(deletionContext(c), c, op)
 }
 
-type clusterDiscoveryGCE struct {
-	cloud       fi.Cloud
-	gceCloud    gce.GCECloud
-	clusterName string
+// listTargetHttpProxies discovers TargetHttpProxy objects for the cluster
+func (d *clusterDiscoveryGCE) listTargetHttpProxies(ctx context.Context) ([]*resources.Resource, error) {
+	c := d.gceCloud
 
-	instanceTemplates []*compute.InstanceTemplate
-	zones             []string
+	var resourceTrackers []*resources.Resource
+
+	if err := d.waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	proxies, err := d.compute().TargetHttpProxies().List(ctx, c.Project())
+	if err != nil {
+		return nil, fmt.Errorf("error listing TargetHttpProxies: %v", err)
+	}
+
+	for _, p := range proxies {
+		if !d.matchesClusterNameMultipart(p.Name, maxRoleNameTokens) {
+			continue
+		}
+
+		resourceTracker := &resources.Resource{
+			Name:    p.Name,
+			ID:      p.Name,
+			Type:    typeTargetHttpProxy,
+			Deleter: deleteTargetHttpProxy,
+			Dumper:  DumpResource,
+			Obj:     p,
+		}
+
+		if p.UrlMap != "" {
+			resourceTracker.Blocks = append(resourceTracker.Blocks, typeUrlMap+":"+gce.LastComponent(p.UrlMap))
+		}
+
+		klog.V(4).Infof("Found resource: %s", p.SelfLink)
+		resourceTrackers = append(resourceTrackers, resourceTracker)
+	}
+
+	return resourceTrackers, nil
 }
 
-func (d *clusterDiscoveryGCE) findInstanceTemplates() ([]*compute.InstanceTemplate, error) {
-	if d.instanceTemplates != nil {
-		return d.instanceTemplates, nil
+func deleteTargetHttpProxy(cloud fi.Cloud, r *resources.Resource) error {
+	c := cloud.(gce.GCECloud)
+	t := r.Obj.(*compute.TargetHttpProxy)
+
+	klog.V(2).Infof("Deleting GCE TargetHttpProxy %s", t.SelfLink)
+	u, err := gce.ParseGoogleCloudURL(t.SelfLink)
+	if err != nil {
+		return err
 	}
 
-	instanceTemplates, err := gce.FindInstanceTemplates(d.gceCloud, d.clusterName)
+	op, err := retryableDelete(func() (*compute.Operation, error) {
+		return c.Compute().TargetHttpProxies().Delete(u.Project, u.Name)
+	})
 	if err != nil {
-		return nil, err
+		if isAlreadyGoneErr(err) {
+			klog.Infof("TargetHttpProxy not found or already being deleted: %q", t.SelfLink)
+			return nil
+		}
+		return fmt.Errorf("error deleting TargetHttpProxy %s: %v", t.SelfLink, err)
 	}
 
-	d.instanceTemplates = instanceTemplates
-	return d.instanceTemplates, nil
+	return waitForOp(deletionContext(c), c, op)
 }
 
-func (d *clusterDiscoveryGCE) listGCEInstanceTemplates() ([]*resources.Resource, error) {
+// listTargetHttpsProxies discovers TargetHttpsProxy objects for the cluster
+func (d *clusterDiscoveryGCE) listTargetHttpsProxies(ctx context.Context) ([]*resources.Resource, error) {
+	c := d.gceCloud
+
 	var resourceTrackers []*resources.Resource
 
-	templates, err := d.findInstanceTemplates()
-	if err != nil {
+	if err := d.waitForRateLimit(ctx); err != nil {
 		return nil, err
 	}
-	for _, t := range templates {
-		selfLink := t.SelfLink // avoid closure-in-loop go-tcha
+	proxies, err := d.compute().TargetHttpsProxies().List(ctx, c.Project())
+	if err != nil {
+		return nil, fmt.Errorf("error listing TargetHttpsProxies: %v", err)
+	}
+
+	for _, p := range proxies {
+		if !d.matchesClusterNameMultipart(p.Name, maxRoleNameTokens) {
+			continue
+		}
+
 		resourceTracker := &resources.Resource{
-			Name: t.Name,
-			ID:   t.Name,
-			Type: typeInstanceTemplate,
-			Deleter: func(cloud fi.Cloud, r *resources.Resource) error {
-				return gce.DeleteInstanceTemplate(d.gceCloud, selfLink)
-			},
-			Obj: t,
+			Name:    p.Name,
+			ID:      p.Name,
+			Type:    typeTargetHttpsProxy,
+			Deleter: deleteTargetHttpsProxy,
+			Dumper:  DumpResource,
+			Obj:     p,
 		}
 
-		klog.V(4).Infof("Found resource: %s", t.SelfLink)
+		if p.UrlMap != "" {
+			resourceTracker.Blocks = append(resourceTracker.Blocks, typeUrlMap+":"+gce.LastComponent(p.UrlMap))
+		}
+
+		for _, sslCertificate := range p.SslCertificates {
+			resourceTracker.Blocks = append(resourceTracker.Blocks, typeSslCertificate+":"+regionalID(sslCertificate))
+		}
+
+		if p.SslPolicy != "" {
+			resourceTracker.Blocks = append(resourceTracker.Blocks, typeSslPolicy+":"+gce.LastComponent(p.SslPolicy))
+		}
+
+		klog.V(4).Infof("Found resource: %s", p.SelfLink)
 		resourceTrackers = append(resourceTrackers, resourceTracker)
 	}
 
 	return resourceTrackers, nil
 }
 
-func (d *clusterDiscoveryGCE) listInstanceGroupManagersAndInstances() ([]*resources.Resource, error) {
+func deleteTargetHttpsProxy(cloud fi.Cloud, r *resources.Resource) error {
+	c := cloud.(gce.GCECloud)
+	t := r.Obj.(*compute.TargetHttpsProxy)
+
+	klog.V(2).Infof("Deleting GCE TargetHttpsProxy %s", t.SelfLink)
+	u, err := gce.ParseGoogleCloudURL(t.SelfLink)
+	if err != nil {
+		return err
+	}
+
+	op, err := retryableDelete(func() (*compute.Operation, error) {
+		return c.Compute().TargetHttpsProxies().Delete(u.Project, u.Name)
+	})
+	if err != nil {
+		if isAlreadyGoneErr(err) {
+			klog.Infof("TargetHttpsProxy not found or already being deleted: %q", t.SelfLink)
+			return nil
+		}
+		return fmt.Errorf("error deleting TargetHttpsProxy %s: %v", t.SelfLink, err)
+	}
+
+	return waitForOp(deletionContext(c), c, op)
+}
+
+// listSslCertificates discovers global and regional SslCertificates for the cluster, see regionalID
+func (d *clusterDiscoveryGCE) listSslCertificates(ctx context.Context) ([]*resources.Resource, error) {
 	c := d.gceCloud
-	project := c.Project()
 
 	var resourceTrackers []*resources.Resource
 
-	instanceTemplates := make(map[string]*compute.InstanceTemplate)
+	var certs []*compute.SslCertificate
 	{
-		templates, err := d.findInstanceTemplates()
-		if err != nil {
+		if err := d.waitForRateLimit(ctx); err != nil {
 			return nil, err
 		}
-		for _, t := range templates {
-			instanceTemplates[t.SelfLink] = t
+		l, err := d.compute().SslCertificates().List(ctx, c.Project())
+		if err != nil {
+			return nil, fmt.Errorf("error listing SslCertificates: %v", err)
 		}
+		certs = append(certs, l...)
 	}
-
-	ctx := context.Background()
-
-	for _, zoneName := range d.zones {
-		is, err := c.Compute().InstanceGroupManagers().List(ctx, project, zoneName)
+	for _, region := range d.scanRegions() {
+		if err := d.waitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+		l, err := d.compute().RegionSslCertificates().List(ctx, c.Project(), region)
 		if err != nil {
-			return nil, fmt.Errorf("error listing InstanceGroupManagers: %v", err)
+			return nil, fmt.Errorf("error listing regional SslCertificates: %v", err)
 		}
-		for i := range is {
-			mig := is[i] // avoid closure-in-loop go-tcha
-			instanceTemplate := instanceTemplates[mig.InstanceTemplate]
-			if instanceTemplate == nil {
-				klog.V(2).Infof("Ignoring MIG with unmanaged InstanceTemplate: %s", mig.InstanceTemplate)
-				continue
-			}
+		certs = append(certs, l...)
+	}
 
-			resourceTracker := &resources.Resource{
-				Name:    mig.Name,
-				ID:      zoneName + "/" + mig.Name,
-				Type:    typeInstanceGroupManager,
-				Deleter: func(cloud fi.Cloud, r *resources.Resource) error { return gce.DeleteInstanceGroupManager(c, mig) },
-				Obj:     mig,
-			}
+	// TargetHttpsProxies reference SslCertificates, so the SslCertificate can only be removed after them
+	if err := d.waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	proxies, err := d.compute().TargetHttpsProxies().List(ctx, c.Project())
+	if err != nil {
+		return nil, fmt.Errorf("error listing TargetHttpsProxies: %v", err)
+	}
 
-			resourceTracker.Blocks = append(resourceTracker.Blocks, typeInstanceTemplate+":"+instanceTemplate.Name)
+	for _, cert := range certs {
+		if !d.matchesClusterNameMultipart(cert.Name, maxRoleNameTokens) {
+			continue
+		}
 
-			klog.V(4).Infof("Found resource: %s", mig.SelfLink)
-			resourceTrackers = append(resourceTrackers, resourceTracker)
+		resourceTracker := &resources.Resource{
+			Name:    cert.Name,
+			ID:      regionalObjectID(cert.Name, cert.SelfLink),
+			Type:    typeSslCertificate,
+			Deleter: deleteSslCertificate,
+			Dumper:  DumpResource,
+			Obj:     cert,
+		}
 
-			instanceTrackers, err := d.listManagedInstances(mig)
-			if err != nil {
-				return nil, fmt.Errorf("error listing instances in InstanceGroupManager: %v", err)
+		for _, p := range proxies {
+			if !d.matchesClusterNameMultipart(p.Name, maxRoleNameTokens) {
+				continue
+			}
+			for _, u := range p.SslCertificates {
+				if u == cert.SelfLink {
+					resourceTracker.Blocked = append(resourceTracker.Blocked, typeTargetHttpsProxy+":"+p.Name)
+				}
 			}
-			resourceTrackers = append(resourceTrackers, instanceTrackers...)
 		}
+
+		klog.V(4).Infof("Found resource: %s", cert.SelfLink)
+		resourceTrackers = append(resourceTrackers, resourceTracker)
 	}
 
 	return resourceTrackers, nil
 }
 
-func (d *clusterDiscoveryGCE) listManagedInstances(igm *compute.InstanceGroupManager) ([]*resources.Resource, error) {
+// listSslPolicies discovers the SslPolicies for the cluster, which set the TLS versions and ciphers of its TargetHttpsProxies.
+// Policies are often shared between load balancers, so only policies named for the cluster are matched.
+// A policy cannot be deleted while a TargetHttpsProxy uses it, so it is Blocked by the cluster proxies using it.
+func (d *clusterDiscoveryGCE) listSslPolicies(ctx context.Context) ([]*resources.Resource, error) {
 	c := d.gceCloud
 
 	var resourceTrackers []*resources.Resource
 
-	zoneName := gce.LastComponent(igm.Zone)
-
-	instances, err := gce.ListManagedInstances(c, igm)
+	if err := d.waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	policies, err := d.compute().SslPolicies().List(ctx, c.Project())
 	if err != nil {
+		return nil, fmt.Errorf("error listing SslPolicies: %v", err)
+	}
+
+	if err := d.waitForRateLimit(ctx); err != nil {
 		return nil, err
 	}
+	proxies, err := d.compute().TargetHttpsProxies().List(ctx, c.Project())
+	if err != nil {
+		return nil, fmt.Errorf("error listing TargetHttpsProxies: %v", err)
+	}
 
-	for _, i := range instances {
-		url := i.Instance // avoid closure-in-loop go-tcha
-		name := gce.LastComponent(url)
+	for _, sp := range policies {
+		if !d.matchesClusterNameMultipart(sp.Name, maxRoleNameTokens) {
+			continue
+		}
 
 		resourceTracker := &resources.Resource{
-			Name: name,
-			ID:   zoneName + "/" + name,
-			Type: typeInstance,
-			Deleter: func(cloud fi.Cloud, tracker *resources.Resource) error {
-				return gce.DeleteInstance(c, url)
-			},
-			Dumper: DumpManagedInstance,
-			Obj:    i,
+			Name:    sp.Name,
+			ID:      sp.Name,
+			Type:    typeSslPolicy,
+			Deleter: deleteSslPolicy,
+			Dumper:  DumpResource,
+			Obj:     sp,
 		}
 
-		// We don't block deletion of the instance group manager
+		for _, p := range proxies {
+			if !d.matchesClusterNameMultipart(p.Name, maxRoleNameTokens) {
+				continue
+			}
+			if p.SslPolicy != "" && gce.LastComponent(p.SslPolicy) == sp.Name {
+				resourceTracker.Blocked = append(resourceTracker.Blocked, typeTargetHttpsProxy+":"+p.Name)
+			}
+		}
 
+		klog.V(4).Infof("Found resource: %s", sp.SelfLink)
 		resourceTrackers = append(resourceTrackers, resourceTracker)
 	}
 
 	return resourceTrackers, nil
 }
 
-// findGCEDisks finds all Disks that are associated with the current cluster
-// It matches them by looking for the cluster label
-func (d *clusterDiscoveryGCE) findGCEDisks() ([]*compute.Disk, error) {
-	c := d.gceCloud
-
-	clusterTag := gce.SafeClusterName(d.clusterName)
-
-	var matches []*compute.Disk
-
-	ctx := context.Background()
+func deleteSslPolicy(cloud fi.Cloud, r *resources.Resource) error {
+	c := cloud.(gce.GCECloud)
+	t := r.Obj.(*compute.SslPolicy)
 
-	// TODO: Push down tag filter?
+	klog.V(2).Infof("Deleting GCE SslPolicy %s", t.SelfLink)
+	u, err := gce.ParseGoogleCloudURL(t.SelfLink)
+	if err != nil {
+		return err
+	}
 
-	diskLists, err := c.Compute().Disks().AggregatedList(ctx, c.Project())
+	op, err := retryableDelete(func() (*compute.Operation, error) {
+		return c.Compute().SslPolicies().Delete(u.Project, u.Name)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("error listing disks: %v", err)
+		if isAlreadyGoneErr(err) {
+			klog.Infof("SslPolicy not found or already being deleted: %q", t.SelfLink)
+			return nil
+		}
+		return fmt.Errorf("error deleting SslPolicy %s: %v", t.SelfLink, err)
 	}
 
-	for _, list := range diskLists {
-		for _, d := range list.Disks {
-			match := false
-			for k, v := range d.Labels {
-				if k == gce.GceLabelNameKubernetesCluster {
-					if v == clusterTag {
-						match = true
-					} else {
-						match = false
-						break
-					}
-				}
-			}
+	return waitForOp(deletionContext(c), c, op)
+}
 
-			if !match {
-				continue
-			}
+func deleteSslCertificate(cloud fi.Cloud, r *resources.Resource) error {
+	c := cloud.(gce.GCECloud)
+	t := r.Obj.(*compute.SslCertificate)
+
+	klog.V(2).Infof("Deleting GCE SslCertificate %s", t.SelfLink)
+	u, err := gce.ParseGoogleCloudURL(t.SelfLink)
+	if err != nil {
+		return err
+	}
 
-			matches = append(matches, d)
+	op, err := retryableDelete(func() (*compute.Operation, error) {
+		if u.Region != "" {
+			return c.Compute().RegionSslCertificates().Delete(u.Project, u.Region, u.Name)
+		}
+		return c.Compute().SslCertificates().Delete(u.Project, u.Name)
+	})
+	if err != nil {
+		if isAlreadyGoneErr(err) {
+			klog.Infof("SslCertificate not found or already being deleted: %q", t.SelfLink)
+			return nil
+		}
+		if isResourceInUse(err) {
+			// Managed certificates can't be deleted while they are still PROVISIONING
+			return fmt.Errorf("SslCertificate %s is still in use (attached to a proxy, or a managed certificate that is still provisioning): %v", t.SelfLink, err)
 		}
+		return fmt.Errorf("error deleting SslCertificate %s: %v", t.SelfLink, err)
 	}
 
-	return matches, nil
+	return waitForOp(deletionContext(c), c, op)
 }
 
-func (d *clusterDiscoveryGCE) listGCEDisks() ([]*resources.Resource, error) {
+// listUrlMaps discovers UrlMap objects for the cluster
+func (d *clusterDiscoveryGCE) listUrlMaps(ctx context.Context) ([]*resources.Resource, error) {
+	c := d.gceCloud
+
 	var resourceTrackers []*resources.Resource
 
-	disks, err := d.findGCEDisks()
-	if err != nil {
+	if err := d.waitForRateLimit(ctx); err != nil {
 		return nil, err
 	}
-	for _, t := range disks {
+	urlMaps, err := d.compute().UrlMaps().List(ctx, c.Project())
+	if err != nil {
+		return nil, fmt.Errorf("error listing UrlMaps: %v", err)
+	}
+
+	for _, m := range urlMaps {
+		if !d.matchesClusterNameMultipart(m.Name, maxRoleNameTokens) {
+			continue
+		}
+
 		resourceTracker := &resources.Resource{
-			Name:    t.Name,
-			ID:      t.Name,
-			Type:    typeDisk,
-			Deleter: deleteGCEDisk,
-			Obj:     t,
+			Name:    m.Name,
+			ID:      m.Name,
+			Type:    typeUrlMap,
+			Deleter: deleteUrlMap,
+			Dumper:  DumpResource,
+			Obj:     m,
 		}
 
-		for _, u := range t.Users {
-			resourceTracker.Blocked = append(resourceTracker.Blocked, typeInstance+":"+gce.LastComponent(t.Zone)+"/"+gce.LastComponent(u))
+		if m.DefaultService != "" {
+			resourceTracker.Blocks = append(resourceTracker.Blocks, typeBackendService+":"+regionalID(m.DefaultService))
 		}
 
-		klog.V(4).Infof("Found resource: %s", t.SelfLink)
+		klog.V(4).Infof("Found resource: %s", m.SelfLink)
 		resourceTrackers = append(resourceTrackers, resourceTracker)
 	}
 
 	return resourceTrackers, nil
 }
 
-func deleteGCEDisk(cloud fi.Cloud, r *resources.Resource) error {
+func deleteUrlMap(cloud fi.Cloud, r *resources.Resource) error {
 	c := cloud.(gce.GCECloud)
-	t := r.Obj.(*compute.Disk)
+	t := r.Obj.(*compute.UrlMap)
 
-	klog.V(2).Infof("Deleting GCE Disk %s", t.SelfLink)
+	klog.V(2).Infof("Deleting GCE UrlMap %s", t.SelfLink)
 	u, err := gce.ParseGoogleCloudURL(t.SelfLink)
 	if err != nil {
 		return err
 	}
 
-	op, err := c.Compute().Disks().Delete(u.Project, u.Zone, u.Name)
+	op, err := retryableDelete(func() (*compute.Operation, error) {
+		return c.Compute().UrlMaps().Delete(u.Project, u.Name)
+	})
 	if err != nil {
-		if gce.IsNotFound(err) {
-			klog.Infof("disk not found, assuming deleted: %q", t.SelfLink)
+		if isAlreadyGoneErr(err) {
+			klog.Infof("UrlMap not found or already being deleted: %q", t.SelfLink)
 			return nil
 		}
-		return fmt.Errorf("error deleting disk %s: %v", t.SelfLink, err)
+		return fmt.Errorf("error deleting UrlMap %s: %v", t.SelfLink, err)
 	}
 
-	return c.WaitForOp(op)
+	return waitForOp(deletionContext(c), c, op)
 }
 
-func (d *clusterDiscoveryGCE) listTargetPools() ([]*resources.Resource, error) {
-	c := d.gceCloud
-
+// listFirewallRules discovers the firewall rules of the cluster, which kops names for the traffic they allow.
+// Every rule built by pkg/model/gcemodel should be matched, see TestListFirewallRulesFromModel.
+func (d *clusterDiscoveryGCE) listFirewallRules(ctx context.Context) ([]*resources.Resource, error) {
 	var resourceTrackers []*resources.Resource
 
-	ctx := context.Background()
+	if err := d.waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	frs, err := d.compute().Firewalls().List(ctx, d.networkProject())
+	if err != nil {
+		return nil, fmt.Errorf("error listing FirewallRules: %v", err)
+	}
 
-	tps, err := c.Compute().TargetPools().List(ctx, c.Project(), c.Region())
+	// Rules whose names don't follow our naming (e.g. imported or renamed rules) are only
+	// considered ours if they are in the cluster network and apply only to the cluster instances
+	networkURLs, err := d.findClusterNetworkURLs(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("error listing TargetPools: %v", err)
+		return nil, err
 	}
 
-	for _, tp := range tps {
-		if !d.matchesClusterName(tp.Name) {
+	for _, fr := range frs {
+		if d.matchesCluster(typeFirewallRule, fr.Name, nil) {
+			if isUntargetedEgressRule(fr) {
+				// Egress rules select traffic by destination ranges, so they often apply to the whole network.
+				// We match them by name, but if we know the cluster networks, the rule must be in one of them.
+				if networkURLs.Len() != 0 && !networkURLs.Has(fr.Network) {
+					continue
+				}
+			} else if !d.firewallRuleMatchesTags(fr) {
+				continue
+			}
+		} else if !networkURLs.Has(fr.Network) || !d.firewallRuleTargetsOnlyCluster(fr) {
 			continue
 		}
 
 		resourceTracker := &resources.Resource{
-			Name:    tp.Name,
-			ID:      tp.Name,
-			Type:    typeTargetPool,
-			Deleter: deleteTargetPool,
-			Obj:     tp,
+			Name:    fr.Name,
+			ID:      fr.Name,
+			Type:    typeFirewallRule,
+			Deleter: deleteFirewallRule,
+			Dumper:  DumpResource,
+			Obj:     fr,
 		}
 
-		klog.V(4).Infof("Found resource: %s", tp.SelfLink)
+		klog.V(4).Infof("Found resource: %s", fr.SelfLink)
 		resourceTrackers = append(resourceTrackers, resourceTracker)
 	}
 
 	return resourceTrackers, nil
 }
 
-func deleteTargetPool(cloud fi.Cloud, r *resources.Resource) error {
+// firewallRuleMatchesTags checks that the firewall rule applies to the cluster instances.
+// Rules normally target the cluster tags; ingress rules without target tags
+// (applying to every instance in the network) are matched on their source tags instead.
+func (d *clusterDiscoveryGCE) firewallRuleMatchesTags(fr *compute.Firewall) bool {
+	tags := fr.TargetTags
+	if len(tags) == 0 {
+		tags = fr.SourceTags
+	}
+	for _, tag := range tags {
+		if d.hasClusterNamePrefix(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// isUntargetedEgressRule returns true if the firewall rule is an egress rule without target tags
+func isUntargetedEgressRule(fr *compute.Firewall) bool {
+	return fr.Direction == "EGRESS" && len(fr.TargetTags) == 0
+}
+
+// firewallRuleTargetsOnlyCluster checks that the firewall rule has target tags, and that all of them are cluster tags.
+// The tags must be those kops gives the cluster instances, as other clusters' names may start with ours.
+func (d *clusterDiscoveryGCE) firewallRuleTargetsOnlyCluster(fr *compute.Firewall) bool {
+	if len(fr.TargetTags) == 0 {
+		return false
+	}
+	clusterTags := d.clusterInstanceTags()
+	for _, tag := range fr.TargetTags {
+		if !clusterTags.Has(tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// clusterInstanceTags returns the network tags kops gives the instances of the cluster for each role,
+// see components.GCETagForRole
+func (d *clusterDiscoveryGCE) clusterInstanceTags() sets.String {
+	tags := sets.NewString()
+	for _, clusterName := range d.safeClusterNames() {
+		for _, role := range kops.AllInstanceGroupRoles {
+			tags.Insert(clusterName + "-" + gce.GceLabelNameRolePrefix + strings.ToLower(string(role)))
+		}
+	}
+	return tags
+}
+
+// deleteFirewallRule is the helper function to delete a Resource for a Firewall object
+func deleteFirewallRule(cloud fi.Cloud, r *resources.Resource) error {
 	c := cloud.(gce.GCECloud)
-	t := r.Obj.(*compute.TargetPool)
+	t := r.Obj.(*compute.Firewall)
 
-	klog.V(2).Infof("Deleting GCE TargetPool %s", t.SelfLink)
+	klog.V(2).Infof("Deleting GCE FirewallRule %s", t.SelfLink)
 	u, err := gce.ParseGoogleCloudURL(t.SelfLink)
 	if err != nil {
 		return err
 	}
 
-	op, err := c.Compute().TargetPools().Delete(u.Project, u.Region, u.Name)
+	op, err := retryableDelete(func() (*compute.Operation, error) {
+		return c.Compute().Firewalls().Delete(u.Project, u.Name)
+	})
 	if err != nil {
-		if gce.IsNotFound(err) {
-			klog.Infof("TargetPool not found, assuming deleted: %q", t.SelfLink)
+		if isAlreadyGoneErr(err) {
+			klog.Infof("FirewallRule not found or already being deleted: %q", t.SelfLink)
 			return nil
 		}
-		return fmt.Errorf("error deleting TargetPool %s: %v", t.SelfLink, err)
+		return fmt.Errorf("error deleting FirewallRule %s: %v", t.SelfLink, err)
 	}
 
-	return c.WaitForOp(op)
+	return waitForOp(deletionContext(c), c, op)
 }
 
-func (d *clusterDiscoveryGCE) listForwardingRules() ([]*resources.Resource, error) {
-	c := d.gceCloud
-
+// listNetworks discovers the network kops created for the cluster.
+// We only consider a network named exactly for the cluster (or the NamePrefix), so we never touch shared or default networks.
+func (d *clusterDiscoveryGCE) listNetworks(ctx context.Context, resourceMap map[string]*resources.Resource) ([]*resources.Resource, error) {
 	var resourceTrackers []*resources.Resource
 
-	ctx := context.Background()
-
-	frs, err := c.Compute().ForwardingRules().List(ctx, c.Project(), c.Region())
-	if err != nil {
-		return nil, fmt.Errorf("error listing ForwardingRules: %v", err)
-	}
-
-	for _, fr := range frs {
-		if !d.matchesClusterName(fr.Name) {
-			continue
+	for _, networkName := range d.safeClusterNames() {
+		if err := d.waitForRateLimit(ctx); err != nil {
+			return nil, err
 		}
-
-		resourceTracker := &resources.Resource{
-			Name:    fr.Name,
-			ID:      fr.Name,
-			Type:    typeForwardingRule,
-			Deleter: deleteForwardingRule,
-			Obj:     fr,
+		network, err := d.compute().Networks().Get(d.networkProject(), networkName)
+		if err != nil {
+			if gce.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("error getting network %q: %v", networkName, err)
 		}
 
-		if fr.Target != "" {
-			resourceTracker.Blocks = append(resourceTracker.Blocks, typeTargetPool+":"+gce.LastComponent(fr.Target))
+		// Networks and subnetworks have no labels in the compute API, so a shared network (or subnet) that
+		// kops did not create carries no kops label that would need to be removed; it is simply not matched.
+		resourceTracker := &resources.Resource{
+			Name:    network.Name,
+			ID:      network.Name,
+			Type:    typeNetwork,
+			Deleter: deleteNetwork,
+			Dumper:  DumpResource,
+			Obj:     network,
 		}
 
-		if fr.IPAddress != "" {
-			resourceTracker.Blocks = append(resourceTracker.Blocks, typeAddress+":"+gce.LastComponent(fr.IPAddress))
+		// Everything that lives in the network must be deleted first
+		for k, t := range resourceMap {
+			switch t.Type {
+			case typeSubnet, typeFirewallRule, typeRouter, typeRoute, typeNetworkPeering:
+				resourceTracker.Blocked = append(resourceTracker.Blocked, k)
+			}
 		}
+		sort.Strings(resourceTracker.Blocked)
 
-		klog.V(4).Infof("Found resource: %s", fr.SelfLink)
+		klog.V(4).Infof("Found resource: %s", network.SelfLink)
 		resourceTrackers = append(resourceTrackers, resourceTracker)
 	}
 
 	return resourceTrackers, nil
 }
 
-func deleteForwardingRule(cloud fi.Cloud, r *resources.Resource) error {
+func deleteNetwork(cloud fi.Cloud, r *resources.Resource) error {
 	c := cloud.(gce.GCECloud)
-	t := r.Obj.(*compute.ForwardingRule)
+	t := r.Obj.(*compute.Network)
 
-	klog.V(2).Infof("Deleting GCE ForwardingRule %s", t.SelfLink)
+	klog.V(2).Infof("Deleting GCE Network %s", t.SelfLink)
 	u, err := gce.ParseGoogleCloudURL(t.SelfLink)
 	if err != nil {
 		return err
 	}
 
-	op, err := c.Compute().ForwardingRules().Delete(u.Project, u.Region, u.Name)
+	op, err := retryableDelete(func() (*compute.Operation, error) {
+		return c.Compute().Networks().Delete(u.Project, u.Name)
+	})
 	if err != nil {
-		if gce.IsNotFound(err) {
-			klog.Infof("ForwardingRule not found, assuming deleted: %q", t.SelfLink)
+		if isAlreadyGoneErr(err) {
+			klog.Infof("Network not found or already being deleted: %q", t.SelfLink)
 			return nil
 		}
-		return fmt.Errorf("error deleting ForwardingRule %s: %v", t.SelfLink, err)
+		return fmt.Errorf("error deleting Network %s: %v", t.SelfLink, err)
 	}
 
-	return c.WaitForOp(op)
+	return waitForOp(deletionContext(c), c, op)
 }
 
-// listFirewallRules discovers Firewall objects for the cluster
-func (d *clusterDiscoveryGCE) listFirewallRules() ([]*resources.Resource, error) {
-	c := d.gceCloud
-
-	var resourceTrackers []*resources.Resource
-
-	ctx := context.Background()
-
-	frs, err := c.Compute().Firewalls().List(ctx, c.Project())
+// listNetworkPeerings discovers the VPC peerings for the cluster on the networks the cluster uses,
+// e.g. to a services network for private service access. They outlive the cluster otherwise.
+// Only peerings named for the cluster are matched, as the networks (and their other peerings) may be shared.
+func (d *clusterDiscoveryGCE) listNetworkPeerings(ctx context.Context) ([]*resources.Resource, error) {
+	networks, err := d.findClusterNetworks(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("error listing FirewallRules: %v", err)
+		return nil, err
 	}
+	networks.Insert(d.safeClusterNames()...)
 
-	for _, fr := range frs {
-		if !d.matchesClusterNameMultipart(fr.Name, maxPrefixTokens) {
-			continue
+	var resourceTrackers []*resources.Resource
+	for _, networkName := range networks.List() {
+		if err := d.waitForRateLimit(ctx); err != nil {
+			return nil, err
 		}
-
-		foundMatchingTarget := false
-		tagPrefix := gce.SafeClusterName(d.clusterName) + "-"
-		for _, target := range fr.TargetTags {
-			if strings.HasPrefix(target, tagPrefix) {
-				foundMatchingTarget = true
+		network, err := d.compute().Networks().Get(d.networkProject(), networkName)
+		if err != nil {
+			if gce.IsNotFound(err) {
+				continue
 			}
-		}
-		if !foundMatchingTarget {
-			break
+			return nil, fmt.Errorf("error getting network %q: %v", networkName, err)
 		}
 
-		resourceTracker := &resources.Resource{
-			Name:    fr.Name,
-			ID:      fr.Name,
-			Type:    typeFirewallRule,
-			Deleter: deleteFirewallRule,
-			Obj:     fr,
-		}
+		for _, peering := range network.Peerings {
+			if !d.matchesClusterNameMultipart(peering.Name, maxRoleNameTokens) {
+				continue
+			}
 
-		klog.V(4).Infof("Found resource: %s", fr.SelfLink)
-		resourceTrackers = append(resourceTrackers, resourceTracker)
+			network := network
+			peeringName := peering.Name
+			resourceTracker := &resources.Resource{
+				Name: peeringName,
+				ID:   network.Name + "/" + peeringName,
+				Type: typeNetworkPeering,
+				Deleter: func(cloud fi.Cloud, r *resources.Resource) error {
+					return deleteNetworkPeering(cloud, network, peeringName)
+				},
+				Dumper: DumpResource,
+				Obj:    peering,
+				// The peering must be removed before the network can be deleted
+				Blocks: []string{typeNetwork + ":" + network.Name},
+			}
+
+			klog.V(4).Infof("Found resource: %s (peering %s)", network.SelfLink, peeringName)
+			resourceTrackers = append(resourceTrackers, resourceTracker)
+		}
 	}
 
 	return resourceTrackers, nil
 }
 
-// deleteFirewallRule is the helper function to delete a Resource for a Firewall object
-func deleteFirewallRule(cloud fi.Cloud, r *resources.Resource) error {
+func deleteNetworkPeering(cloud fi.Cloud, network *compute.Network, peeringName string) error {
 	c := cloud.(gce.GCECloud)
-	t := r.Obj.(*compute.Firewall)
 
-	klog.V(2).Infof("Deleting GCE FirewallRule %s", t.SelfLink)
-	u, err := gce.ParseGoogleCloudURL(t.SelfLink)
+	klog.V(2).Infof("Removing peering %s from GCE Network %s", peeringName, network.SelfLink)
+	u, err := gce.ParseGoogleCloudURL(network.SelfLink)
 	if err != nil {
 		return err
 	}
 
-	op, err := c.Compute().Firewalls().Delete(u.Project, u.Name)
+	op, err := retryableDelete(func() (*compute.Operation, error) {
+		return c.Compute().Networks().RemovePeering(u.Project, u.Name, &compute.NetworksRemovePeeringRequest{Name: peeringName})
+	})
 	if err != nil {
-		if gce.IsNotFound(err) {
-			klog.Infof("FirewallRule not found, assuming deleted: %q", t.SelfLink)
+		if isAlreadyGoneErr(err) {
+			klog.Infof("Network peering not found or already being deleted: %q on %q", peeringName, network.SelfLink)
 			return nil
 		}
-		return fmt.Errorf("error deleting FirewallRule %s: %v", t.SelfLink, err)
+		return fmt.Errorf("error removing peering %s from Network %s: %v", peeringName, network.SelfLink, err)
 	}
 
-	return c.WaitForOp(op)
+	return waitForOp(deletionContext(c), c, op)
 }
 
-func (d *clusterDiscoveryGCE) listRoutes(resourceMap map[string]*resources.Resource) ([]*resources.Resource, error) {
+func (d *clusterDiscoveryGCE) listRoutes(ctx context.Context, resourceMap map[string]*resources.Resource) ([]*resources.Resource, error) {
 	c := d.gceCloud
 
 	var resourceTrackers []*resources.Resource
 
 	instances := sets.NewString()
+	var forwardingRules []*resources.Resource
 	for _, resource := range resourceMap {
-		if resource.Type == typeInstance {
+		switch resource.Type {
+		case typeInstance:
 			instances.Insert(resource.ID)
+		case typeForwardingRule:
+			forwardingRules = append(forwardingRules, resource)
 		}
 	}
 
-	prefix := gce.SafeClusterName(d.clusterName) + "-"
-
-	ctx := context.Background()
-
 	// TODO: Push-down prefix?
-	routes, err := c.Compute().Routes().List(ctx, c.Project())
+	if err := d.waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	routes, err := d.compute().Routes().List(ctx, c.Project())
 	if err != nil {
 		return nil, fmt.Errorf("error listing Routes: %v", err)
 	}
 	for _, r := range routes {
-		if !strings.HasPrefix(r.Name, prefix) {
+		if !d.matchesCluster(typeRoute, r.Name, nil) {
 			continue
 		}
-		remove := false
-		for _, w := range r.Warnings {
-			switch w.Code {
-			case "NEXT_HOP_INSTANCE_NOT_FOUND":
-				remove = true
-			default:
-				klog.Infof("Unknown warning on route %q: %q", r.Name, w.Code)
-			}
-		}
 
-		if r.NextHopInstance != "" {
-			u, err := gce.ParseGoogleCloudURL(r.NextHopInstance)
+		reason := d.routeRemovalReason(r, instances)
+		var blocks []string
+		if r.NextHopIlb != "" {
+			ilbReason, ilbBlocks, err := d.ilbRouteRemovalReason(r, forwardingRules)
 			if err != nil {
-				klog.Warningf("error parsing URL for NextHopInstance=%q", r.NextHopInstance)
+				return nil, err
 			}
-
-			if instances.Has(u.Zone + "/" + u.Name) {
-				remove = true
+			if reason == "" {
+				reason = ilbReason
 			}
+			blocks = ilbBlocks
+		}
+		if reason == "" {
+			continue
 		}
 
-		if remove {
-			resourceTracker := &resources.Resource{
-				Name:    r.Name,
-				ID:      r.Name,
-				Type:    typeRoute,
-				Deleter: deleteRoute,
-				Obj:     r,
-			}
+		resourceTracker := &resources.Resource{
+			Name:    r.Name,
+			ID:      r.Name,
+			Type:    typeRoute,
+			Deleter: deleteRoute,
+			Dumper:  DumpResource,
+			Obj:     r,
+			Blocks:  blocks,
+		}
 
-			// We don't need to block
-			//if r.NextHopInstance != "" {
-			//	resourceTracker.Blocked = append(resourceTracker.Blocks, typeInstance+":"+gce.LastComponent(r.NextHopInstance))
-			//}
+		// We don't need to block
+		//if r.NextHopInstance != "" {
+		//	resourceTracker.Blocked = append(resourceTracker.Blocks, typeInstance+":"+gce.LastComponent(r.NextHopInstance))
+		//}
 
-			klog.V(4).Infof("Found resource: %s", r.SelfLink)
-			resourceTrackers = append(resourceTrackers, resourceTracker)
-		}
+		klog.V(4).Infof("Found resource: %s (%s)", r.SelfLink, reason)
+		resourceTrackers = append(resourceTrackers, resourceTracker)
 	}
 
 	return resourceTrackers, nil
 }
 
+// routeRemovalReason returns why the route, named for the cluster, should be removed, or "" if it should be kept.
+func (d *clusterDiscoveryGCE) routeRemovalReason(r *compute.Route, instances sets.String) string {
+	for _, w := range r.Warnings {
+		switch w.Code {
+		case "NEXT_HOP_INSTANCE_NOT_FOUND":
+			return "next hop instance not found"
+		default:
+			klog.Infof("Unknown warning on route %q: %q", r.Name, w.Code)
+		}
+	}
+
+	if r.NextHopInstance != "" {
+		u, err := gce.ParseGoogleCloudURL(r.NextHopInstance)
+		if err != nil {
+			klog.Warningf("error parsing URL for NextHopInstance=%q", r.NextHopInstance)
+		} else if instances.Has(u.Zone + "/" + u.Name) {
+			return "next hop is a cluster instance"
+		}
+	}
+
+	if d.isGeneratedRouteName(r.Name) {
+		return "route is named for a cluster node"
+	}
+
+	return ""
+}
+
+// routeNodeUIDRegex matches the node UID that the route controller appends to the cluster name to name the pod routes
+var routeNodeUIDRegex = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// isGeneratedRouteName checks if the name is one the route controller gives the pod routes of the cluster: the cluster
+// part of object names followed by a node UID. Other clusters' names may start with ours, so a prefix is not enough.
+// The route controller truncates long cluster names, so the routes of those clusters are only matched by their next hop.
+func (d *clusterDiscoveryGCE) isGeneratedRouteName(name string) bool {
+	for _, clusterName := range d.safeClusterNames() {
+		if uid := strings.TrimPrefix(name, clusterName+"-"); uid != name && routeNodeUIDRegex.MatchString(uid) {
+			return true
+		}
+	}
+	return false
+}
+
+// ilbRouteRemovalReason returns why the route, named for the cluster, should be removed because of its internal load
+// balancer next hop, or "" if it should be kept. If the next hop is one of the cluster forwarding rules, the route must
+// be deleted first, and the keys of the forwarding rule are returned so the route Blocks it.
+func (d *clusterDiscoveryGCE) ilbRouteRemovalReason(r *compute.Route, forwardingRules []*resources.Resource) (string, []string, error) {
+	region, name, isURL := parseILBNextHop(r.NextHopIlb)
+
+	for _, resource := range forwardingRules {
+		fr, ok := resource.Obj.(*compute.ForwardingRule)
+		if !ok {
+			continue
+		}
+		if (isURL && fr.Name == name && gce.LastComponent(fr.Region) == region) || (!isURL && fr.IPAddress == r.NextHopIlb) {
+			return "next hop is a cluster internal load balancer", []string{resource.Type + ":" + resource.ID}, nil
+		}
+	}
+
+	// We can only look up forwarding rules by name; the forwarding rules with the next hop IP address may be elsewhere
+	if !isURL {
+		return "", nil, nil
+	}
+
+	project := d.gceCloud.Project()
+	if u, err := gce.ParseGoogleCloudURL(r.NextHopIlb); err == nil && u.Project != "" {
+		project = u.Project
+	}
+	if _, err := d.compute().ForwardingRules().Get(project, region, name); err != nil {
+		if gce.IsNotFound(err) {
+			return "next hop internal load balancer not found", nil, nil
+		}
+		return "", nil, fmt.Errorf("error getting ForwardingRule %s for Route %s: %v", r.NextHopIlb, r.Name, err)
+	}
+	return "", nil, nil
+}
+
+// parseILBNextHop returns the region and name of the forwarding rule of a route's NextHopIlb, if it is a full or partial
+// URL (e.g. regions/us-test1/forwardingRules/api) rather than an IP address
+func parseILBNextHop(nextHop string) (string, string, bool) {
+	var region, name string
+	tokens := strings.Split(nextHop, "/")
+	for i := 0; i+1 < len(tokens); i++ {
+		switch tokens[i] {
+		case "regions":
+			region = tokens[i+1]
+		case "forwardingRules":
+			name = tokens[i+1]
+		}
+	}
+	if region == "" || name == "" {
+		return "", "", false
+	}
+	return region, name, true
+}
+
 func deleteRoute(cloud fi.Cloud, r *resources.Resource) error {
 	c := cloud.(gce.GCECloud)
 	t := r.Obj.(*compute.Route)
@@ -624,41 +3718,138 @@ func deleteRoute(cloud fi.Cloud, r *resources.Resource) error {
 		return err
 	}
 
-	op, err := c.Compute().Routes().Delete(u.Project, u.Name)
+	op, err := retryableDelete(func() (*compute.Operation, error) {
+		return c.Compute().Routes().Delete(u.Project, u.Name)
+	})
 	if err != nil {
-		if gce.IsNotFound(err) {
-			klog.Infof("Route not found, assuming deleted: %q", t.SelfLink)
+		if isAlreadyGoneErr(err) {
+			klog.Infof("Route not found or already being deleted: %q", t.SelfLink)
 			return nil
 		}
 		return fmt.Errorf("error deleting Route %s: %v", t.SelfLink, err)
 	}
 
-	return c.WaitForOp(op)
+	return waitForOp(deletionContext(c), c, op)
+}
+
+func (d *clusterDiscoveryGCE) listAddresses(ctx context.Context) ([]*resources.Resource, error) {
+	c := d.gceCloud
+
+	var resourceTrackers []*resources.Resource
+
+	var addrs []*compute.Address
+	for _, region := range d.scanRegions() {
+		if err := d.waitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+		l, err := d.compute().Addresses().List(ctx, c.Project(), region)
+		if err != nil {
+			return nil, fmt.Errorf("error listing Addresses: %v", err)
+		}
+		addrs = append(addrs, l...)
+	}
+
+	// Both EXTERNAL addresses and the INTERNAL addresses reserved for internal load balancers are listed
+	for _, a := range addrs {
+		if !d.matchesClusterNameMultipart(a.Name, maxRoleNameTokens) {
+			klog.V(8).Infof("Skipping Address with name %q", a.Name)
+			continue
+		}
+
+		resourceTracker := &resources.Resource{
+			Name:    a.Name,
+			ID:      regionalObjectID(a.Name, a.SelfLink),
+			Type:    typeAddress,
+			Deleter: deleteAddress,
+			Dumper:  DumpResource,
+			Obj:     a,
+		}
+
+		klog.V(4).Infof("Found resource: %s", a.SelfLink)
+		resourceTrackers = append(resourceTrackers, resourceTracker)
+	}
+
+	return resourceTrackers, nil
+}
+
+func deleteAddress(cloud fi.Cloud, r *resources.Resource) error {
+	c := cloud.(gce.GCECloud)
+	t := r.Obj.(*compute.Address)
+
+	klog.V(2).Infof("Deleting GCE Address %s", t.SelfLink)
+	u, err := gce.ParseGoogleCloudURL(t.SelfLink)
+	if err != nil {
+		return err
+	}
+
+	op, err := retryableDelete(func() (*compute.Operation, error) {
+		return c.Compute().Addresses().Delete(u.Project, u.Region, u.Name)
+	})
+	if err != nil {
+		if isAlreadyGoneErr(err) {
+			klog.Infof("Address not found or already being deleted: %q", t.SelfLink)
+			return nil
+		}
+		if isResourceInUse(err) {
+			return addressInUseError(t, err)
+		}
+		return fmt.Errorf("error deleting Address %s: %v", t.SelfLink, err)
+	}
+
+	return waitForOp(deletionContext(c), c, op)
+}
+
+// addressInUseError describes an address that cannot be deleted because it is still assigned,
+// naming the user reported by GCE, or else the users recorded on the address
+func addressInUseError(a *compute.Address, err error) error {
+	msg := err.Error()
+	if apiErr, ok := err.(*googleapi.Error); ok && apiErr.Message != "" {
+		msg = apiErr.Message
+	}
+	if m := inUseByRegex.FindStringSubmatch(msg); m != nil {
+		return fmt.Errorf("%s address %s (%s) is still in use by %s; delete it and retry", addressPurpose(a), a.Name, a.Address, m[1])
+	}
+	if len(a.Users) != 0 {
+		return fmt.Errorf("%s address %s (%s) is still in use by %s; delete them and retry", addressPurpose(a), a.Name, a.Address, strings.Join(a.Users, ", "))
+	}
+	return fmt.Errorf("%s address %s (%s) is still in use, likely by a forwarding rule or instance not managed by kops; delete it and retry: %v", addressPurpose(a), a.Name, a.Address, err)
+}
+
+// addressPurpose returns the AddressType of the address, which GCE defaults to EXTERNAL
+func addressPurpose(a *compute.Address) string {
+	if a.AddressType == "" {
+		return "EXTERNAL"
+	}
+	return a.AddressType
 }
 
-func (d *clusterDiscoveryGCE) listAddresses() ([]*resources.Resource, error) {
+// listGlobalAddresses discovers the global static IPs reserved for the cluster's global load balancers.
+// They share the Address type with regional addresses, so that forwarding rules block either kind.
+func (d *clusterDiscoveryGCE) listGlobalAddresses(ctx context.Context) ([]*resources.Resource, error) {
 	c := d.gceCloud
 
 	var resourceTrackers []*resources.Resource
 
-	ctx := context.Background()
-
-	addrs, err := c.Compute().Addresses().List(ctx, c.Project(), c.Region())
+	if err := d.waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	addrs, err := d.compute().GlobalAddresses().List(ctx, c.Project())
 	if err != nil {
-		return nil, fmt.Errorf("error listing Addresses: %v", err)
+		return nil, fmt.Errorf("error listing global Addresses: %v", err)
 	}
 
 	for _, a := range addrs {
-		if !d.matchesClusterName(a.Name) {
-			klog.V(8).Infof("Skipping Address with name %q", a.Name)
+		if !d.matchesClusterNameMultipart(a.Name, maxRoleNameTokens) {
+			klog.V(8).Infof("Skipping global Address with name %q", a.Name)
 			continue
 		}
 
 		resourceTracker := &resources.Resource{
 			Name:    a.Name,
-			ID:      a.Name,
+			ID:      regionalObjectID(a.Name, a.SelfLink),
 			Type:    typeAddress,
-			Deleter: deleteAddress,
+			Deleter: deleteGlobalAddress,
+			Dumper:  DumpResource,
 			Obj:     a,
 		}
 
@@ -669,31 +3860,33 @@ func (d *clusterDiscoveryGCE) listAddresses() ([]*resources.Resource, error) {
 	return resourceTrackers, nil
 }
 
-func deleteAddress(cloud fi.Cloud, r *resources.Resource) error {
+func deleteGlobalAddress(cloud fi.Cloud, r *resources.Resource) error {
 	c := cloud.(gce.GCECloud)
 	t := r.Obj.(*compute.Address)
 
-	klog.V(2).Infof("Deleting GCE Address %s", t.SelfLink)
+	klog.V(2).Infof("Deleting GCE global Address %s", t.SelfLink)
 	u, err := gce.ParseGoogleCloudURL(t.SelfLink)
 	if err != nil {
 		return err
 	}
 
-	op, err := c.Compute().Addresses().Delete(u.Project, u.Region, u.Name)
+	op, err := retryableDelete(func() (*compute.Operation, error) {
+		return c.Compute().GlobalAddresses().Delete(u.Project, u.Name)
+	})
 	if err != nil {
-		if gce.IsNotFound(err) {
-			klog.Infof("Address not found, assuming deleted: %q", t.SelfLink)
+		if isAlreadyGoneErr(err) {
+			klog.Infof("global Address not found or already being deleted: %q", t.SelfLink)
 			return nil
 		}
-		return fmt.Errorf("error deleting Address %s: %v", t.SelfLink, err)
+		return fmt.Errorf("error deleting global Address %s: %v", t.SelfLink, err)
 	}
 
-	return c.WaitForOp(op)
+	return waitForOp(deletionContext(c), c, op)
 }
 
-func (d *clusterDiscoveryGCE) listSubnets() ([]*resources.Resource, error) {
+func (d *clusterDiscoveryGCE) listSubnets(ctx context.Context) ([]*resources.Resource, error) {
 	// Templates are very accurate because of the metadata, so use those as the sanity check
-	templates, err := d.findInstanceTemplates()
+	templates, err := d.findInstanceTemplates(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -706,18 +3899,21 @@ func (d *clusterDiscoveryGCE) listSubnets() ([]*resources.Resource, error) {
 		}
 	}
 
-	c := d.gceCloud
-
 	var resourceTrackers []*resources.Resource
-	ctx := context.Background()
-
-	subnets, err := c.Compute().Subnetworks().List(ctx, c.Project(), c.Region())
-	if err != nil {
-		return nil, fmt.Errorf("error listing subnetworks: %v", err)
+	var subnets []*compute.Subnetwork
+	for _, region := range d.scanRegions() {
+		if err := d.waitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+		l, err := d.compute().Subnetworks().List(ctx, d.networkProject(), region)
+		if err != nil {
+			return nil, fmt.Errorf("error listing subnetworks: %v", err)
+		}
+		subnets = append(subnets, l...)
 	}
 
 	for _, o := range subnets {
-		if !d.matchesClusterName(o.Name) {
+		if !d.matchesClusterNameMultipart(o.Name, maxRoleNameTokens) {
 			klog.V(8).Infof("skipping Subnet with name %q", o.Name)
 			continue
 		}
@@ -729,9 +3925,10 @@ func (d *clusterDiscoveryGCE) listSubnets() ([]*resources.Resource, error) {
 
 		resourceTracker := &resources.Resource{
 			Name:    o.Name,
-			ID:      o.Name,
+			ID:      regionalObjectID(o.Name, o.SelfLink),
 			Type:    typeSubnet,
 			Deleter: deleteSubnet,
+			Dumper:  DumpSubnet,
 			Obj:     o,
 		}
 
@@ -752,40 +3949,138 @@ func deleteSubnet(cloud fi.Cloud, r *resources.Resource) error {
 		return err
 	}
 
-	op, err := c.Compute().Subnetworks().Delete(u.Project, u.Region, u.Name)
+	op, err := retryableDelete(func() (*compute.Operation, error) {
+		return c.Compute().Subnetworks().Delete(u.Project, u.Region, u.Name)
+	})
 	if err != nil {
-		if gce.IsNotFound(err) {
-			klog.Infof("subnetwork not found, assuming deleted: %q", o.SelfLink)
+		if isAlreadyGoneErr(err) {
+			klog.Infof("subnetwork not found or already being deleted: %q", o.SelfLink)
 			return nil
 		}
+		if isResourceInUse(err) {
+			return subnetInUseError(o, err)
+		}
 		return fmt.Errorf("error deleting subnetwork %s: %v", o.SelfLink, err)
 	}
 
-	return c.WaitForOp(op)
+	return waitForOp(deletionContext(c), c, op)
 }
 
-func (d *clusterDiscoveryGCE) listRouters() ([]*resources.Resource, error) {
-	c := d.gceCloud
+// inUseByRegex extracts the resource that GCE reports is still using the resource being deleted
+var inUseByRegex = regexp.MustCompile(`already being used by '([^']+)'`)
 
-	var resourceTrackers []*resources.Resource
-	ctx := context.Background()
+// subnetInUseError describes a subnetwork that cannot be deleted because other resources still reference it,
+// naming the blocking resource when GCE reports it
+func subnetInUseError(o *compute.Subnetwork, err error) error {
+	msg := err.Error()
+	if apiErr, ok := err.(*googleapi.Error); ok && apiErr.Message != "" {
+		msg = apiErr.Message
+	}
+	if m := inUseByRegex.FindStringSubmatch(msg); m != nil {
+		return fmt.Errorf("subnetwork %s is still in use by %s; delete it (or move it to another subnetwork) and retry", o.Name, m[1])
+	}
+	return fmt.Errorf("subnetwork %s is still in use, likely by forwarding rules, instances or addresses not managed by kops; delete them and retry: %v", o.Name, err)
+}
 
-	routers, err := c.Compute().Routers().List(ctx, c.Project(), c.Region())
-	if err != nil {
-		return nil, fmt.Errorf("error listing routers: %v", err)
+func (d *clusterDiscoveryGCE) listRouters(ctx context.Context) ([]*resources.Resource, error) {
+	advertisedIPRanges := sets.NewString(d.advertisedIPRanges...)
+
+	var resourceTrackers []*resources.Resource
+	var routers []*compute.Router
+	for _, region := range d.scanRegions() {
+		if err := d.waitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+		l, err := d.compute().Routers().List(ctx, d.networkProject(), region)
+		if err != nil {
+			return nil, fmt.Errorf("error listing routers: %v", err)
+		}
+		routers = append(routers, l...)
 	}
 
 	for _, o := range routers {
-		if !d.matchesClusterName(o.Name) {
+		if !d.matchesClusterNameMultipart(o.Name, maxRoleNameTokens) {
+			// The router may be shared, but still carry a NAT we created
+			for _, nat := range o.Nats {
+				if !d.matchesClusterNameMultipart(nat.Name, maxRoleNameTokens) {
+					continue
+				}
+
+				router := o
+				natName := nat.Name
+				resourceTracker := &resources.Resource{
+					Name: natName,
+					ID:   regionalObjectID(o.Name, o.SelfLink) + "/" + natName,
+					Type: typeRouterNAT,
+					Deleter: func(cloud fi.Cloud, r *resources.Resource) error {
+						return deleteRouterNAT(cloud, router, natName)
+					},
+					Dumper: DumpResource,
+					Obj:    nat,
+				}
+
+				klog.V(4).Infof("found resource: %s (NAT %s)", o.SelfLink, natName)
+				resourceTrackers = append(resourceTrackers, resourceTracker)
+			}
+
+			// Interconnect and VPN setups may also have added BGP peers for the cluster to a shared router
+			for _, peer := range o.BgpPeers {
+				if !d.matchesClusterNameMultipart(peer.Name, maxRoleNameTokens) {
+					continue
+				}
+
+				router := o
+				peerName := peer.Name
+				resourceTracker := &resources.Resource{
+					Name: peerName,
+					ID:   regionalObjectID(o.Name, o.SelfLink) + "/" + peerName,
+					Type: typeRouterPeer,
+					Deleter: func(cloud fi.Cloud, r *resources.Resource) error {
+						return deleteRouterPeer(cloud, router, peerName)
+					},
+					Dumper: DumpResource,
+					Obj:    peer,
+				}
+
+				klog.V(4).Infof("found resource: %s (BGP peer %s)", o.SelfLink, peerName)
+				resourceTrackers = append(resourceTrackers, resourceTracker)
+			}
+
+			// The router may also have been configured to advertise the cluster ranges, e.g. the pod CIDR
+			if o.Bgp != nil {
+				for _, advertised := range o.Bgp.AdvertisedIpRanges {
+					if !advertisedIPRanges.Has(advertised.Range) {
+						continue
+					}
+
+					router := o
+					ipRange := advertised.Range
+					resourceTracker := &resources.Resource{
+						Name: ipRange,
+						ID:   regionalObjectID(o.Name, o.SelfLink) + "/" + ipRange,
+						Type: typeRouterAdvertisement,
+						Deleter: func(cloud fi.Cloud, r *resources.Resource) error {
+							return deleteRouterAdvertisement(cloud, router, ipRange)
+						},
+						Dumper: DumpResource,
+						Obj:    advertised,
+					}
+
+					klog.V(4).Infof("found resource: %s (advertised IP range %s)", o.SelfLink, ipRange)
+					resourceTrackers = append(resourceTrackers, resourceTracker)
+				}
+			}
+
 			klog.V(8).Infof("skipping Router with name %q", o.Name)
 			continue
 		}
 
 		resourceTracker := &resources.Resource{
 			Name:    o.Name,
-			ID:      o.Name,
+			ID:      regionalObjectID(o.Name, o.SelfLink),
 			Type:    typeRouter,
 			Deleter: deleteRouter,
+			Dumper:  DumpResource,
 			Obj:     o,
 		}
 
@@ -806,24 +4101,209 @@ func deleteRouter(cloud fi.Cloud, r *resources.Resource) error {
 		return err
 	}
 
-	op, err := c.Compute().Routers().Delete(u.Project, u.Region, u.Name)
+	op, err := retryableDelete(func() (*compute.Operation, error) {
+		return c.Compute().Routers().Delete(u.Project, u.Region, u.Name)
+	})
 	if err != nil {
-		if gce.IsNotFound(err) {
-			klog.Infof("router not found, assuming deleted: %q", o.SelfLink)
+		if isAlreadyGoneErr(err) {
+			klog.Infof("router not found or already being deleted: %q", o.SelfLink)
 			return nil
 		}
 		return fmt.Errorf("error deleting router %s: %v", o.SelfLink, err)
 	}
 
-	return c.WaitForOp(op)
+	return waitForOp(deletionContext(c), c, op)
+}
+
+// deleteRouterNAT removes a single NAT from a router, leaving the router and any other NATs in place.
+func deleteRouterNAT(cloud fi.Cloud, router *compute.Router, natName string) error {
+	c := cloud.(gce.GCECloud)
+
+	klog.V(2).Infof("deleting NAT %s from GCE router %s", natName, router.SelfLink)
+	u, err := gce.ParseGoogleCloudURL(router.SelfLink)
+	if err != nil {
+		return err
+	}
+
+	// Re-read the router so we don't clobber concurrent changes to its other NATs
+	current, err := c.Compute().Routers().Get(u.Project, u.Region, u.Name)
+	if err != nil {
+		if gce.IsNotFound(err) {
+			klog.Infof("router not found, assuming NAT deleted: %q", router.SelfLink)
+			return nil
+		}
+		return fmt.Errorf("error getting router %s: %v", router.SelfLink, err)
+	}
+
+	var nats []*compute.RouterNat
+	found := false
+	for _, nat := range current.Nats {
+		if nat.Name == natName {
+			found = true
+			continue
+		}
+		nats = append(nats, nat)
+	}
+	if !found {
+		klog.Infof("NAT %q not found on router, assuming deleted: %q", natName, router.SelfLink)
+		return nil
+	}
+
+	patch := &compute.Router{
+		Nats: nats,
+		// Nats must be sent even when empty, otherwise removing the last NAT is a no-op
+		ForceSendFields: []string{"Nats"},
+	}
+	op, err := retryableDelete(func() (*compute.Operation, error) {
+		return c.Compute().Routers().Patch(u.Project, u.Region, u.Name, patch)
+	})
+	if err != nil {
+		return fmt.Errorf("error removing NAT %s from router %s: %v", natName, router.SelfLink, err)
+	}
+
+	return waitForOp(deletionContext(c), c, op)
+}
+
+// deleteRouterPeer removes a single BGP peer from a router, leaving the router, its interfaces and any other peers in place.
+func deleteRouterPeer(cloud fi.Cloud, router *compute.Router, peerName string) error {
+	c := cloud.(gce.GCECloud)
+
+	klog.V(2).Infof("deleting BGP peer %s from GCE router %s", peerName, router.SelfLink)
+	u, err := gce.ParseGoogleCloudURL(router.SelfLink)
+	if err != nil {
+		return err
+	}
+
+	// Re-read the router so we don't clobber concurrent changes to its other peers
+	current, err := c.Compute().Routers().Get(u.Project, u.Region, u.Name)
+	if err != nil {
+		if gce.IsNotFound(err) {
+			klog.Infof("router not found, assuming BGP peer deleted: %q", router.SelfLink)
+			return nil
+		}
+		return fmt.Errorf("error getting router %s: %v", router.SelfLink, err)
+	}
+
+	var peers []*compute.RouterBgpPeer
+	found := false
+	for _, peer := range current.BgpPeers {
+		if peer.Name == peerName {
+			found = true
+			continue
+		}
+		peers = append(peers, peer)
+	}
+	if !found {
+		klog.Infof("BGP peer %q not found on router, assuming deleted: %q", peerName, router.SelfLink)
+		return nil
+	}
+
+	patch := &compute.Router{
+		BgpPeers: peers,
+		// BgpPeers must be sent even when empty, otherwise removing the last peer is a no-op
+		ForceSendFields: []string{"BgpPeers"},
+	}
+	op, err := retryableDelete(func() (*compute.Operation, error) {
+		return c.Compute().Routers().Patch(u.Project, u.Region, u.Name, patch)
+	})
+	if err != nil {
+		return fmt.Errorf("error removing BGP peer %s from router %s: %v", peerName, router.SelfLink, err)
+	}
+
+	return waitForOp(deletionContext(c), c, op)
+}
+
+// deleteRouterAdvertisement removes a single IP range from the ranges a router advertises, leaving the router,
+// its advertisement mode and any other ranges in place.
+func deleteRouterAdvertisement(cloud fi.Cloud, router *compute.Router, ipRange string) error {
+	c := cloud.(gce.GCECloud)
+
+	klog.V(2).Infof("deleting advertised IP range %s from GCE router %s", ipRange, router.SelfLink)
+	u, err := gce.ParseGoogleCloudURL(router.SelfLink)
+	if err != nil {
+		return err
+	}
+
+	// Re-read the router so we don't clobber concurrent changes to its other ranges
+	current, err := c.Compute().Routers().Get(u.Project, u.Region, u.Name)
+	if err != nil {
+		if gce.IsNotFound(err) {
+			klog.Infof("router not found, assuming advertised IP range deleted: %q", router.SelfLink)
+			return nil
+		}
+		return fmt.Errorf("error getting router %s: %v", router.SelfLink, err)
+	}
+
+	var ranges []*compute.RouterAdvertisedIpRange
+	found := false
+	if current.Bgp != nil {
+		for _, advertised := range current.Bgp.AdvertisedIpRanges {
+			if advertised.Range == ipRange {
+				found = true
+				continue
+			}
+			ranges = append(ranges, advertised)
+		}
+	}
+	if !found {
+		klog.Infof("advertised IP range %q not found on router, assuming deleted: %q", ipRange, router.SelfLink)
+		return nil
+	}
+
+	bgp := *current.Bgp
+	bgp.AdvertisedIpRanges = ranges
+	// AdvertisedIpRanges must be sent even when empty, otherwise removing the last range is a no-op
+	bgp.ForceSendFields = append(bgp.ForceSendFields, "AdvertisedIpRanges")
+	patch := &compute.Router{
+		Bgp: &bgp,
+	}
+	op, err := retryableDelete(func() (*compute.Operation, error) {
+		return c.Compute().Routers().Patch(u.Project, u.Region, u.Name, patch)
+	})
+	if err != nil {
+		return fmt.Errorf("error removing advertised IP range %s from router %s: %v", ipRange, router.SelfLink, err)
+	}
+
+	return waitForOp(deletionContext(c), c, op)
+}
+
+// matchesClusterLabelOrName matches on the cluster label if the resource carries it,
+// falling back to the name for resources created before kops labelled them.
+// Note that not all GCE resources support labels in the v1 API (e.g. addresses and subnetworks).
+func (d *clusterDiscoveryGCE) matchesClusterLabelOrName(labels map[string]string, name string, maxTokens int) bool {
+	if _, found := labels[gce.GceLabelNameKubernetesCluster]; found {
+		return d.matchesClusterLabel(labels)
+	}
+	return d.matchesClusterNameMultipart(name, maxTokens)
 }
 
-func (d *clusterDiscoveryGCE) matchesClusterName(name string) bool {
-	return d.matchesClusterNameMultipart(name, 1)
+// matchesGeneratedName checks if the name could have been generated by our cluster, like matchesClusterNameMultipart,
+// but the id may also end with one of the suffixes, which does not count towards maxTokens.
+func (d *clusterDiscoveryGCE) matchesGeneratedName(name string, maxTokens int, suffixes []string) bool {
+	if d.matchesClusterNameMultipart(name, maxTokens) {
+		return true
+	}
+
+	for _, clusterName := range d.safeClusterNames() {
+		clusterSuffix := "-" + clusterName
+		if !strings.HasSuffix(name, clusterSuffix) {
+			continue
+		}
+		id := strings.TrimSuffix(name, clusterSuffix)
+		for _, suffix := range suffixes {
+			base := strings.TrimSuffix(id, "-"+suffix)
+			if base != id && d.matchesClusterNameMultipart(base+clusterSuffix, maxTokens) {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 // matchesClusterNameMultipart checks if the name could have been generated by our cluster
 // considering all the prefixes separated by `-`.  maxParts limits the number of parts we consider.
+// Names longer than maxGCENameLength are matched in their truncated and hashed form (see gce.LimitedLengthName),
+// so matching remains reliable as long as the prefix itself survives the truncation.
 func (d *clusterDiscoveryGCE) matchesClusterNameMultipart(name string, maxParts int) bool {
 	tokens := strings.Split(name, "-")
 
@@ -836,7 +4316,37 @@ func (d *clusterDiscoveryGCE) matchesClusterNameMultipart(name string, maxParts
 		if id == "" {
 			continue
 		}
-		if name == gce.SafeObjectName(id, d.clusterName) {
+		for _, clusterName := range d.safeClusterNames() {
+			expected := gce.SafeObjectName(id, clusterName)
+			if name == expected {
+				return true
+			}
+			// Names too long for GCE are truncated and suffixed with a hash, as for MIGs
+			if len(expected) > maxGCENameLength && name == gce.LimitedLengthName(expected, maxGCENameLength) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// namePrefixRegex matches the NamePrefix values we accept, which must be usable as part of a GCE name
+var namePrefixRegex = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// safeClusterNames returns the names that form the cluster part of object names:
+// the sanitized cluster name, and the custom name prefix if one is set
+func (d *clusterDiscoveryGCE) safeClusterNames() []string {
+	names := []string{gce.SafeClusterName(d.clusterName)}
+	if d.namePrefix != "" && d.namePrefix != names[0] {
+		names = append(names, d.namePrefix)
+	}
+	return names
+}
+
+// hasClusterNamePrefix checks if the name (or tag) starts with the cluster part of object names, as routes and tags do
+func (d *clusterDiscoveryGCE) hasClusterNamePrefix(name string) bool {
+	for _, clusterName := range d.safeClusterNames() {
+		if strings.HasPrefix(name, clusterName+"-") {
 			return true
 		}
 	}
@@ -844,20 +4354,60 @@ func (d *clusterDiscoveryGCE) matchesClusterNameMultipart(name string, maxParts
 }
 
 func (d *clusterDiscoveryGCE) clusterDNSName() string {
-	return d.clusterName + "."
+	return normalizeDNSName(d.clusterName)
+}
+
+// normalizeDNSName lowercases the name and ends it with a single dot, as Cloud DNS may return names in either form
+func normalizeDNSName(name string) string {
+	return strings.ToLower(strings.TrimRight(name, ".")) + "."
 }
 
+// maxDNSDeletionsPerChange keeps each DNS change under the Cloud DNS limit on record sets per change
+var maxDNSDeletionsPerChange = 100
+
+const (
+	// dnsChangePollInterval is how often we check whether a DNS change has been applied
+	dnsChangePollInterval = 2 * time.Second
+	// dnsChangeTimeout is how long we wait for a DNS change to be applied
+	dnsChangeTimeout = 5 * time.Minute
+)
+
+// kopsManagedDNSNamePrefixes are the names, relative to the cluster DNS name, of the records kops publishes for every cluster
+var kopsManagedDNSNamePrefixes = []string{`api`, `api.internal`, `bastion`, `kops-controller.internal`}
+
+// isKopsManagedDNSName only matches exact record names, so we never delete user records in the same zone
 func (d *clusterDiscoveryGCE) isKopsManagedDNSName(name string) bool {
-	prefix := []string{`api`, `api.internal`, `bastion`}
-	for _, p := range prefix {
-		if name == p+"."+d.clusterDNSName() {
+	name = normalizeDNSName(name)
+	for _, p := range append(kopsManagedDNSNamePrefixes, d.dnsNamePrefixes...) {
+		if name == normalizeDNSName(p+"."+d.clusterDNSName()) {
 			return true
 		}
 	}
 	return false
 }
 
-func (d *clusterDiscoveryGCE) listGCEDNSZone() ([]*resources.Resource, error) {
+// DNSNamePrefixesForCluster returns the names, relative to the cluster DNS name, of the records
+// published for the cluster that depend on its spec, i.e. the peer records of the etcd members.
+func DNSNamePrefixesForCluster(cluster *kops.Cluster) []string {
+	var prefixes []string
+	for _, etcdCluster := range cluster.Spec.EtcdClusters {
+		// This mirrors the naming in the etcdmanager model
+		clusterName := "etcd-" + etcdCluster.Name
+		if etcdCluster.Name == "main" {
+			clusterName = "etcd"
+		}
+		for _, member := range etcdCluster.Members {
+			prefixes = append(prefixes, clusterName+"-"+member.Name+".internal")
+		}
+	}
+	return prefixes
+}
+
+func (d *clusterDiscoveryGCE) listGCEDNSZone(ctx context.Context) ([]*resources.Resource, error) {
+	if d.skipDNS {
+		klog.V(2).Infof("skipping DNS records, as DNS is managed outside kops")
+		return nil, nil
+	}
 
 	if dns.IsGossipHostname(d.clusterName) {
 		return nil, nil
@@ -865,33 +4415,78 @@ func (d *clusterDiscoveryGCE) listGCEDNSZone() ([]*resources.Resource, error) {
 
 	var resourceTrackers []*resources.Resource
 
-	managedZones, err := d.gceCloud.CloudDNS().ManagedZones().List(d.gceCloud.Project())
+	if err := d.waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	managedZones, err := d.gceCloud.CloudDNS().ManagedZones().List(ctx, d.gceCloud.Project())
 	if err != nil {
 		return nil, fmt.Errorf("error getting GCE DNS zones %v", err)
 	}
 
+	// Private zones are scoped to VPC networks; we only want the private zone(s) visible to the cluster network
+	clusterNetworks, err := d.findClusterNetworks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var matchingZones []*clouddns.ManagedZone
 	for _, zone := range managedZones {
-		if !strings.HasSuffix(d.clusterDNSName(), zone.DnsName) {
+		if !isDNSNameInZone(d.clusterDNSName(), zone.DnsName) {
+			continue
+		}
+		if zone.Visibility == "private" && !isVisibleToNetworks(zone, clusterNetworks) {
+			klog.V(4).Infof("skipping private DNS zone %q, which is not visible to the cluster network", zone.Name)
+			continue
+		}
+		matchingZones = append(matchingZones, zone)
+	}
+
+	// When the cluster DNS name is delegated to a sub-zone, the records are in the sub-zone, not in the parent zone.
+	// Public and private zones are resolved separately, so we pick the most specific zone of each visibility.
+	mostSpecific := make(map[string]int)
+	for _, zone := range matchingZones {
+		if n := len(normalizeDNSName(zone.DnsName)); n > mostSpecific[dnsZoneVisibility(zone)] {
+			mostSpecific[dnsZoneVisibility(zone)] = n
+		}
+	}
+
+	for _, zone := range matchingZones {
+		if len(normalizeDNSName(zone.DnsName)) != mostSpecific[dnsZoneVisibility(zone)] {
+			klog.V(4).Infof("skipping DNS zone %q, as the cluster DNS name is in a more specific zone", zone.Name)
 			continue
 		}
-		rrsets, err := d.gceCloud.CloudDNS().ResourceRecordSets().List(d.gceCloud.Project(), zone.Name)
+
+		// A zone dedicated to the cluster is delegated to from its parent zones; we remove the delegation with the records
+		if normalizeDNSName(zone.DnsName) == d.clusterDNSName() {
+			delegations, err := d.findDNSDelegations(ctx, zone, matchingZones)
+			if err != nil {
+				return nil, err
+			}
+			resourceTrackers = append(resourceTrackers, delegations...)
+		}
+
+		if err := d.waitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+		rrsets, err := d.gceCloud.CloudDNS().ResourceRecordSets().List(ctx, d.gceCloud.Project(), zone.Name)
 		if err != nil {
 			return nil, fmt.Errorf("error getting GCE DNS zone data %v", err)
 		}
 
 		for _, record := range rrsets {
 			// adapted from AWS implementation
-			if record.Type != "A" {
+			if record.Type != "A" && record.Type != "AAAA" {
 				continue
 			}
 
 			if d.isKopsManagedDNSName(record.Name) {
 				resource := resources.Resource{
 					Name:         record.Name,
-					ID:           record.Name,
+					ID:           zone.Name + "/" + record.Type + "/" + record.Name,
 					Type:         typeDNSRecord,
 					GroupDeleter: deleteDNSRecords,
 					GroupKey:     zone.Name,
+					Dumper:       DumpResource,
 					Obj:          record,
 				}
 				resourceTrackers = append(resourceTrackers, &resource)
@@ -902,8 +4497,136 @@ func (d *clusterDiscoveryGCE) listGCEDNSZone() ([]*resources.Resource, error) {
 	return resourceTrackers, nil
 }
 
+// findDNSDelegations finds the NS records delegating to the sub-zone in its parent zones of the same visibility.
+// Only records whose name servers are those of the sub-zone are matched, so delegations to other name servers are kept.
+func (d *clusterDiscoveryGCE) findDNSDelegations(ctx context.Context, subZone *clouddns.ManagedZone, zones []*clouddns.ManagedZone) ([]*resources.Resource, error) {
+	var resourceTrackers []*resources.Resource
+
+	nameServers := sets.NewString()
+	for _, ns := range subZone.NameServers {
+		nameServers.Insert(normalizeDNSName(ns))
+	}
+	if nameServers.Len() == 0 {
+		return nil, nil
+	}
+
+	for _, zone := range zones {
+		if zone == subZone || dnsZoneVisibility(zone) != dnsZoneVisibility(subZone) || normalizeDNSName(zone.DnsName) == normalizeDNSName(subZone.DnsName) {
+			continue
+		}
+		if err := d.waitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+		rrsets, err := d.gceCloud.CloudDNS().ResourceRecordSets().List(ctx, d.gceCloud.Project(), zone.Name)
+		if err != nil {
+			return nil, fmt.Errorf("error getting GCE DNS zone data %v", err)
+		}
+		for _, record := range rrsets {
+			if record.Type != "NS" || normalizeDNSName(record.Name) != normalizeDNSName(subZone.DnsName) {
+				continue
+			}
+			delegatedTo := sets.NewString()
+			for _, ns := range record.Rrdatas {
+				delegatedTo.Insert(normalizeDNSName(ns))
+			}
+			if !delegatedTo.Equal(nameServers) {
+				klog.V(4).Infof("skipping NS record %q in DNS zone %q, which delegates to other name servers", record.Name, zone.Name)
+				continue
+			}
+			resourceTrackers = append(resourceTrackers, &resources.Resource{
+				Name:         record.Name,
+				ID:           zone.Name + "/" + record.Type + "/" + record.Name,
+				Type:         typeDNSRecord,
+				GroupDeleter: deleteDNSRecords,
+				GroupKey:     zone.Name,
+				Dumper:       DumpResource,
+				Obj:          record,
+			})
+		}
+	}
+	return resourceTrackers, nil
+}
+
+// isDNSNameInZone returns true if the name is the DNS name of the zone, or a name below it
+func isDNSNameInZone(name string, zoneDNSName string) bool {
+	name = normalizeDNSName(name)
+	zoneDNSName = normalizeDNSName(zoneDNSName)
+	return name == zoneDNSName || zoneDNSName == "." || strings.HasSuffix(name, "."+zoneDNSName)
+}
+
+// dnsZoneVisibility returns the visibility of the zone; zones are public unless set otherwise
+func dnsZoneVisibility(zone *clouddns.ManagedZone) string {
+	if zone.Visibility == "" {
+		return "public"
+	}
+	return zone.Visibility
+}
+
+// findClusterNetworks returns the names of the networks used by the cluster instance templates
+func (d *clusterDiscoveryGCE) findClusterNetworks(ctx context.Context) (sets.String, error) {
+	networkURLs, err := d.findClusterNetworkURLs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	networks := sets.NewString()
+	for _, u := range networkURLs.List() {
+		networks.Insert(gce.LastComponent(u))
+	}
+	return networks, nil
+}
+
+// networkProject returns the project of the cluster network: the Shared VPC host project if there is one,
+// or else the project of the cloud
+func (d *clusterDiscoveryGCE) networkProject() string {
+	if d.hostProject != "" {
+		return d.hostProject
+	}
+	return d.gceCloud.Project()
+}
+
+// findClusterNetworkURLs returns the self-links of the networks used by the cluster instance templates
+func (d *clusterDiscoveryGCE) findClusterNetworkURLs(ctx context.Context) (sets.String, error) {
+	templates, err := d.findInstanceTemplates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	networkURLs := sets.NewString()
+	if d.network != "" {
+		u := &gce.GoogleCloudURL{Project: d.networkProject(), Type: "networks", Name: d.network, Global: true}
+		networkURLs.Insert(u.BuildURL())
+	}
+	for _, t := range templates {
+		for _, ni := range t.Properties.NetworkInterfaces {
+			if ni.Network != "" {
+				networkURLs.Insert(ni.Network)
+			}
+		}
+	}
+	return networkURLs, nil
+}
+
+// isVisibleToNetworks checks if a private zone is visible to any of the networks.
+// If we don't know the networks (e.g. the instance templates are already gone) we assume it is.
+func isVisibleToNetworks(zone *clouddns.ManagedZone, networks sets.String) bool {
+	if networks.Len() == 0 {
+		return true
+	}
+	if zone.PrivateVisibilityConfig == nil {
+		return false
+	}
+	for _, n := range zone.PrivateVisibilityConfig.Networks {
+		if networks.Has(gce.LastComponent(n.NetworkUrl)) {
+			return true
+		}
+	}
+	return false
+}
+
 func deleteDNSRecords(cloud fi.Cloud, r []*resources.Resource) error {
 	c := cloud.(gce.GCECloud)
+	ctx := deletionContext(cloud)
 	var records []*clouddns.ResourceRecordSet
 	var zoneName string
 
@@ -913,10 +4636,41 @@ func deleteDNSRecords(cloud fi.Cloud, r []*resources.Resource) error {
 		records = append(records, r)
 	}
 
-	change := clouddns.Change{Deletions: records, Kind: "dns#change", IsServing: true}
-	_, err := c.CloudDNS().Changes().Create(c.Project(), zoneName, &change)
-	if err != nil {
-		return fmt.Errorf("error deleting GCE DNS resource record set %v", err)
+	// Cloud DNS limits the size of a change, so we delete the records in batches, one change at a time
+	for len(records) > 0 {
+		n := len(records)
+		if n > maxDNSDeletionsPerChange {
+			n = maxDNSDeletionsPerChange
+		}
+
+		change := clouddns.Change{Deletions: records[:n], Kind: "dns#change", IsServing: true}
+		created, err := c.CloudDNS().Changes().Create(ctx, c.Project(), zoneName, &change)
+		if err != nil {
+			return fmt.Errorf("error deleting GCE DNS resource record set %v", err)
+		}
+		if err := waitForDNSChange(ctx, c, zoneName, created); err != nil {
+			return err
+		}
+
+		records = records[n:]
 	}
 	return nil
 }
+
+// waitForDNSChange waits until Cloud DNS has applied the change, for at most dnsChangeTimeout or until the context is done
+func waitForDNSChange(ctx context.Context, c gce.GCECloud, zoneName string, change *clouddns.Change) error {
+	ctx, cancel := context.WithTimeout(ctx, dnsChangeTimeout)
+	defer cancel()
+
+	return wait.PollImmediateUntilWithContext(ctx, dnsChangePollInterval, func(ctx context.Context) (bool, error) {
+		if change.Status == "done" {
+			return true, nil
+		}
+		latest, err := c.CloudDNS().Changes().Get(ctx, c.Project(), zoneName, change.Id)
+		if err != nil {
+			return false, fmt.Errorf("error getting GCE DNS change %s: %v", change.Id, err)
+		}
+		change = latest
+		return change.Status == "done", nil
+	})
+}
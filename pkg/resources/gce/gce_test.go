@@ -16,7 +16,42 @@ limitations under the License.
 
 package gce
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+	computebeta "google.golang.org/api/compute/v0.beta"
+	compute "google.golang.org/api/compute/v1"
+	clouddns "google.golang.org/api/dns/v1"
+	"google.golang.org/api/googleapi"
+	gcemock "k8s.io/kops/cloudmock/gce"
+	"k8s.io/kops/cloudmock/gce/mockcompute"
+	"k8s.io/kops/cloudmock/gce/mockdns"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/model"
+	"k8s.io/kops/pkg/model/gcemodel"
+	"k8s.io/kops/pkg/model/iam"
+	"k8s.io/kops/pkg/resources"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gcetasks"
+)
+
+// testQPS is the discovery QPS used by the tests, so they are not held up by the rate limiter
+const testQPS = 1000
+
+// listResourcesForTest lists the resources as ListResourcesGCE does, with the testQPS rate limit
+func listResourcesForTest(cloud gce.GCECloud, clusterName string, region string) (map[string]*resources.Resource, error) {
+	return ListResourcesGCEWithOptions(context.Background(), cloud, clusterName, region, ListResourcesGCEOptions{QPS: testQPS})
+}
 
 func TestNameMatch(t *testing.T) {
 	grid := []struct {
@@ -56,9 +91,3803 @@ func TestNameMatch(t *testing.T) {
 		d := &clusterDiscoveryGCE{
 			clusterName: "cluster.example.com",
 		}
-		match := d.matchesClusterNameMultipart(g.Name, maxPrefixTokens)
+		match := d.matchesClusterNameMultipart(g.Name, maxFirewallRuleTokens)
+		if match != g.Match {
+			t.Errorf("unexpected match value for %q, got %v, expected %v", g.Name, match, g.Match)
+		}
+	}
+}
+
+func TestGeneratedNameMatch(t *testing.T) {
+	grid := []struct {
+		Name  string
+		Match bool
+	}{
+		{
+			Name:  "nodeport-external-to-node-cluster-example-com",
+			Match: true,
+		},
+		{
+			Name:  "nodeport-external-to-node-ipv6-cluster-example-com",
+			Match: true,
+		},
+		{
+			// 6 tokens, only matched because the -ipv6 suffix is not counted
+			Name:  "ssh-external-to-master-bastion-ipv6-cluster-example-com",
+			Match: true,
+		},
+		{
+			Name:  "ssh-external-to-master-bastion-extra-cluster-example-com",
+			Match: false,
+		},
+		{
+			Name:  "ipv6-cluster-example-com",
+			Match: true,
+		},
+		{
+			Name:  "nodeport-external-to-node-ipv6-other-example-com",
+			Match: false,
+		},
+	}
+	for _, g := range grid {
+		d := &clusterDiscoveryGCE{
+			clusterName: "cluster.example.com",
+		}
+		match := d.matchesGeneratedName(g.Name, maxFirewallRuleTokens, firewallRuleNameSuffixes)
+		if match != g.Match {
+			t.Errorf("unexpected match value for %q, got %v, expected %v", g.Name, match, g.Match)
+		}
+	}
+}
+
+func TestLongClusterNameMatch(t *testing.T) {
+	clusterName := "a-very-long-cluster-name-for-testing-truncation.k8s.example.com"
+	if len(clusterName) < 60 {
+		t.Fatalf("cluster name %q is too short for this test", clusterName)
+	}
+
+	cluster := &kops.Cluster{}
+	cluster.ObjectMeta.Name = clusterName
+
+	d := &clusterDiscoveryGCE{
+		clusterName: clusterName,
+	}
+
+	for _, igName := range []string{"nodes", "master-us-test1-a"} {
+		ig := &kops.InstanceGroup{}
+		ig.ObjectMeta.Name = igName
+
+		migName := gce.NameForInstanceGroupManager(cluster, ig, "us-test1-a")
+		if len(migName) > maxGCENameLength {
+			t.Errorf("MIG name %q is longer than %d characters", migName, maxGCENameLength)
+		}
+		if !d.matchesClusterNameMultipart(migName, maxInstanceGroupManagerTokens) {
+			t.Errorf("expected MIG name %q to match cluster %q", migName, clusterName)
+		}
+	}
+
+	grid := []struct {
+		Name  string
+		Match bool
+	}{
+		{
+			Name:  gce.SafeObjectName("api", clusterName),
+			Match: true,
+		},
+		{
+			Name:  gce.LimitedLengthName(gce.SafeObjectName("nodeport-external-to-node", clusterName), maxGCENameLength),
+			Match: true,
+		},
+		{
+			// Truncated without the hash
+			Name:  gce.SafeObjectName("nodeport-external-to-node", clusterName)[:maxGCENameLength],
+			Match: false,
+		},
+		{
+			Name:  gce.LimitedLengthName(gce.SafeObjectName("nodeport-external-to-node", clusterName+"x"), maxGCENameLength),
+			Match: false,
+		},
+	}
+	for _, g := range grid {
+		match := d.matchesClusterNameMultipart(g.Name, maxFirewallRuleTokens)
 		if match != g.Match {
 			t.Errorf("unexpected match value for %q, got %v, expected %v", g.Name, match, g.Match)
 		}
 	}
 }
+
+func TestClusterLabelMatch(t *testing.T) {
+	grid := []struct {
+		Labels map[string]string
+		Match  bool
+	}{
+		{
+			Labels: map[string]string{"k8s-io-cluster-name": "cluster-example-com"},
+			Match:  true,
+		},
+		{
+			Labels: map[string]string{"k8s-io-cluster-name": "cluster-example-com", "k8s-io-role-master": "master"},
+			Match:  true,
+		},
+		{
+			Labels: map[string]string{"k8s-io-cluster-name": "other-example-com"},
+			Match:  false,
+		},
+		{
+			Labels: map[string]string{"k8s-io-role-master": "master"},
+			Match:  false,
+		},
+		{
+			Labels: nil,
+			Match:  false,
+		},
+	}
+	for _, g := range grid {
+		d := &clusterDiscoveryGCE{
+			clusterName: "cluster.example.com",
+		}
+		match := d.matchesClusterLabel(g.Labels)
+		if match != g.Match {
+			t.Errorf("unexpected match value for %v, got %v, expected %v", g.Labels, match, g.Match)
+		}
+	}
+}
+
+func TestClusterLabelOrNameMatch(t *testing.T) {
+	grid := []struct {
+		Name   string
+		Labels map[string]string
+		Match  bool
+	}{
+		{
+			Name:   "api-cluster-example-com",
+			Labels: nil,
+			Match:  true,
+		},
+		{
+			Name:   "api-other-example-com",
+			Labels: nil,
+			Match:  false,
+		},
+		{
+			Name:   "a-very-long-name-cluster-example-com",
+			Labels: map[string]string{"k8s-io-cluster-name": "cluster-example-com"},
+			Match:  true,
+		},
+		{
+			Name:   "api-cluster-example-com",
+			Labels: map[string]string{"k8s-io-cluster-name": "other-example-com"},
+			Match:  false,
+		},
+		{
+			Name:   "api-cluster-example-com",
+			Labels: map[string]string{"owner": "someone"},
+			Match:  true,
+		},
+	}
+	for _, g := range grid {
+		d := &clusterDiscoveryGCE{
+			clusterName: "cluster.example.com",
+		}
+		match := d.matchesClusterLabelOrName(g.Labels, g.Name, maxRoleNameTokens)
+		if match != g.Match {
+			t.Errorf("unexpected match value for %q with labels %v, got %v, expected %v", g.Name, g.Labels, match, g.Match)
+		}
+	}
+}
+
+func TestListForwardingRules(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	if _, err := cloud.Compute().ForwardingRules().Insert("testproject", "us-test1", &compute.ForwardingRule{
+		Name:   "api-cluster-example-com",
+		Target: "https://www.googleapis.com/compute/v1/projects/testproject/regions/us-test1/targetPools/api-cluster-example-com",
+	}); err != nil {
+		t.Fatalf("error creating regional forwarding rule: %v", err)
+	}
+	if _, err := cloud.Compute().GlobalForwardingRules().Insert("testproject", &compute.ForwardingRule{
+		Name:      "ingress-cluster-example-com",
+		Target:    "https://www.googleapis.com/compute/v1/projects/testproject/global/targetHttpProxies/ingress-cluster-example-com",
+		IPAddress: "https://www.googleapis.com/compute/v1/projects/testproject/global/addresses/ingress-cluster-example-com",
+	}); err != nil {
+		t.Fatalf("error creating global forwarding rule: %v", err)
+	}
+	if _, err := cloud.Compute().GlobalForwardingRules().Insert("testproject", &compute.ForwardingRule{
+		Name:   "tcp-cluster-example-com",
+		Target: "https://www.googleapis.com/compute/v1/projects/testproject/global/targetTcpProxies/tcp-cluster-example-com",
+	}); err != nil {
+		t.Fatalf("error creating global forwarding rule: %v", err)
+	}
+	if _, err := cloud.Compute().GlobalForwardingRules().Insert("testproject", &compute.ForwardingRule{
+		Name: "ingress-other-example-com",
+	}); err != nil {
+		t.Fatalf("error creating global forwarding rule: %v", err)
+	}
+
+	resourceMap, err := listResourcesForTest(cloud, "cluster.example.com", "us-test1")
+	if err != nil {
+		t.Fatalf("error listing resources: %v", err)
+	}
+
+	grid := []struct {
+		Key    string
+		Blocks []string
+	}{
+		{
+			Key:    "ForwardingRule:us-test1/api-cluster-example-com",
+			Blocks: []string{"TargetPool:us-test1/api-cluster-example-com"},
+		},
+		{
+			Key:    "ForwardingRule:ingress-cluster-example-com",
+			Blocks: []string{"TargetHttpProxy:ingress-cluster-example-com", "Address:ingress-cluster-example-com"},
+		},
+		{
+			// We don't discover TCP proxies, so there is nothing to block
+			Key:    "ForwardingRule:tcp-cluster-example-com",
+			Blocks: nil,
+		},
+	}
+	for _, g := range grid {
+		r := resourceMap[g.Key]
+		if r == nil {
+			t.Errorf("expected %q to be tracked, got %v", g.Key, resourceMap)
+			continue
+		}
+		if !reflect.DeepEqual(r.Blocks, g.Blocks) {
+			t.Errorf("unexpected blocks for %q, got %v, expected %v", g.Key, r.Blocks, g.Blocks)
+		}
+	}
+
+	if _, found := resourceMap["ForwardingRule:ingress-other-example-com"]; found {
+		t.Errorf("forwarding rule for another cluster should not be tracked")
+	}
+}
+
+func TestListTargetInstances(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	if _, err := cloud.Compute().TargetInstances().Insert("testproject", "us-test1-a", &compute.TargetInstance{
+		Name:     "bastion-cluster-example-com",
+		Instance: "https://www.googleapis.com/compute/v1/projects/testproject/zones/us-test1-a/instances/bastion-1234",
+	}); err != nil {
+		t.Fatalf("error creating target instance: %v", err)
+	}
+	if _, err := cloud.Compute().TargetInstances().Insert("testproject", "us-test1-a", &compute.TargetInstance{
+		Name: "bastion-other-example-com",
+	}); err != nil {
+		t.Fatalf("error creating target instance: %v", err)
+	}
+	if _, err := cloud.Compute().ForwardingRules().Insert("testproject", "us-test1", &compute.ForwardingRule{
+		Name:   "bastion-cluster-example-com",
+		Target: "https://www.googleapis.com/compute/v1/projects/testproject/zones/us-test1-a/targetInstances/bastion-cluster-example-com",
+	}); err != nil {
+		t.Fatalf("error creating forwarding rule: %v", err)
+	}
+
+	resourceMap, err := listResourcesForTest(cloud, "cluster.example.com", "us-test1")
+	if err != nil {
+		t.Fatalf("error listing resources: %v", err)
+	}
+
+	fr := resourceMap["ForwardingRule:us-test1/bastion-cluster-example-com"]
+	if fr == nil {
+		t.Fatalf("expected forwarding rule to be tracked, got %v", resourceMap)
+	}
+	if expected := []string{"TargetInstance:us-test1-a/bastion-cluster-example-com"}; !reflect.DeepEqual(fr.Blocks, expected) {
+		t.Errorf("unexpected blocks for forwarding rule, got %v, expected %v", fr.Blocks, expected)
+	}
+
+	ti := resourceMap["TargetInstance:us-test1-a/bastion-cluster-example-com"]
+	if ti == nil {
+		t.Fatalf("expected target instance to be tracked, got %v", resourceMap)
+	}
+	if expected := []string{"Instance:us-test1-a/bastion-1234"}; !reflect.DeepEqual(ti.Blocks, expected) {
+		t.Errorf("unexpected blocks for target instance, got %v, expected %v", ti.Blocks, expected)
+	}
+	if _, found := resourceMap["TargetInstance:us-test1-a/bastion-other-example-com"]; found {
+		t.Errorf("target instance for another cluster should not be tracked")
+	}
+
+	if err := ti.Deleter(cloud, ti); err != nil {
+		t.Fatalf("error deleting target instance: %v", err)
+	}
+	if _, err := cloud.Compute().TargetInstances().Get("testproject", "us-test1-a", "bastion-cluster-example-com"); err == nil {
+		t.Errorf("expected target instance to be deleted")
+	}
+}
+
+func TestListInternalAddresses(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	region := "https://www.googleapis.com/compute/v1/projects/testproject/regions/us-test1"
+	for _, a := range []*compute.Address{
+		{Name: "api-cluster-example-com", Address: "10.0.16.5", AddressType: "INTERNAL", Purpose: "GCE_ENDPOINT", Region: region},
+		{Name: "nat-cluster-example-com", Address: "203.0.113.7", AddressType: "EXTERNAL", Region: region},
+	} {
+		if _, err := cloud.Compute().Addresses().Insert("testproject", "us-test1", a); err != nil {
+			t.Fatalf("error creating address: %v", err)
+		}
+	}
+	// An internal load balancer refers to its address by IP, not by URL
+	if _, err := cloud.Compute().ForwardingRules().Insert("testproject", "us-test1", &compute.ForwardingRule{
+		Name:                "api-cluster-example-com",
+		IPAddress:           "10.0.16.5",
+		LoadBalancingScheme: "INTERNAL",
+		BackendService:      "https://www.googleapis.com/compute/v1/projects/testproject/regions/us-test1/backendServices/api-cluster-example-com",
+		Region:              region,
+	}); err != nil {
+		t.Fatalf("error creating forwarding rule: %v", err)
+	}
+
+	resourceMap, err := listResourcesForTest(cloud, "cluster.example.com", "us-test1")
+	if err != nil {
+		t.Fatalf("error listing resources: %v", err)
+	}
+
+	for _, k := range []string{"Address:us-test1/api-cluster-example-com", "Address:us-test1/nat-cluster-example-com"} {
+		if resourceMap[k] == nil {
+			t.Errorf("expected %s to be tracked, got %v", k, resourceMap)
+		}
+	}
+
+	fr := resourceMap["ForwardingRule:us-test1/api-cluster-example-com"]
+	if fr == nil {
+		t.Fatalf("expected forwarding rule to be tracked, got %v", resourceMap)
+	}
+	expected := []string{"BackendService:us-test1/api-cluster-example-com", "Address:us-test1/api-cluster-example-com"}
+	if !reflect.DeepEqual(fr.Blocks, expected) {
+		t.Errorf("unexpected blocks for forwarding rule, got %v, expected %v", fr.Blocks, expected)
+	}
+}
+
+func TestListForwardingRulesEphemeralIP(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	region := "https://www.googleapis.com/compute/v1/projects/testproject/regions/us-test1"
+	if _, err := cloud.Compute().Addresses().Insert("testproject", "us-test1", &compute.Address{
+		Name:    "api-cluster-example-com",
+		Address: "203.0.113.7",
+		Region:  region,
+	}); err != nil {
+		t.Fatalf("error creating address: %v", err)
+	}
+	for _, name := range []string{"api-cluster-example-com", "bastion-cluster-example-com"} {
+		if _, err := cloud.Compute().TargetPools().Insert("testproject", "us-test1", &compute.TargetPool{Name: name}); err != nil {
+			t.Fatalf("error creating target pool: %v", err)
+		}
+	}
+	for _, fr := range []*compute.ForwardingRule{
+		// Uses the reserved address
+		{
+			Name:      "api-cluster-example-com",
+			IPAddress: "203.0.113.7",
+			Target:    region + "/targetPools/api-cluster-example-com",
+			Region:    region,
+		},
+		// Uses an ephemeral IP, which is not an address resource
+		{
+			Name:      "bastion-cluster-example-com",
+			IPAddress: "198.51.100.20",
+			Target:    region + "/targetPools/bastion-cluster-example-com",
+			Region:    region,
+		},
+	} {
+		if _, err := cloud.Compute().ForwardingRules().Insert("testproject", "us-test1", fr); err != nil {
+			t.Fatalf("error creating forwarding rule: %v", err)
+		}
+	}
+
+	resourceMap, err := listResourcesForTest(cloud, "cluster.example.com", "us-test1")
+	if err != nil {
+		t.Fatalf("error listing resources: %v", err)
+	}
+
+	grid := []struct {
+		Key    string
+		Blocks []string
+	}{
+		{
+			Key:    "ForwardingRule:us-test1/api-cluster-example-com",
+			Blocks: []string{"TargetPool:us-test1/api-cluster-example-com", "Address:us-test1/api-cluster-example-com"},
+		},
+		{
+			Key:    "ForwardingRule:us-test1/bastion-cluster-example-com",
+			Blocks: []string{"TargetPool:us-test1/bastion-cluster-example-com"},
+		},
+	}
+	for _, g := range grid {
+		r := resourceMap[g.Key]
+		if r == nil {
+			t.Errorf("expected %q to be tracked, got %v", g.Key, resourceMap)
+			continue
+		}
+		if !reflect.DeepEqual(r.Blocks, g.Blocks) {
+			t.Errorf("unexpected blocks for %q, got %v, expected %v", g.Key, r.Blocks, g.Blocks)
+		}
+		for _, k := range r.Blocks {
+			if resourceMap[k] == nil {
+				t.Errorf("%q blocks %q, which is not tracked", g.Key, k)
+			}
+		}
+	}
+}
+
+func TestAddressInUseError(t *testing.T) {
+	a := &compute.Address{
+		Name:        "api-cluster-example-com",
+		Address:     "10.0.16.5",
+		AddressType: "INTERNAL",
+	}
+
+	grid := []struct {
+		Name     string
+		Users    []string
+		Err      *googleapi.Error
+		Expected string
+	}{
+		{
+			Name: "user reported by GCE",
+			Err: &googleapi.Error{
+				Code:    400,
+				Message: "The address resource 'projects/testproject/regions/us-test1/addresses/api-cluster-example-com' is already being used by 'projects/testproject/regions/us-test1/forwardingRules/my-ilb'",
+				Errors:  []googleapi.ErrorItem{{Reason: "resourceInUseByAnotherResource"}},
+			},
+			Expected: "INTERNAL address api-cluster-example-com (10.0.16.5) is still in use by projects/testproject/regions/us-test1/forwardingRules/my-ilb; delete it and retry",
+		},
+		{
+			Name:  "users recorded on the address",
+			Users: []string{"https://www.googleapis.com/compute/v1/projects/testproject/regions/us-test1/forwardingRules/my-ilb"},
+			Err: &googleapi.Error{
+				Code:    400,
+				Message: "resource in use",
+				Errors:  []googleapi.ErrorItem{{Reason: "resourceInUseByAnotherResource"}},
+			},
+			Expected: "INTERNAL address api-cluster-example-com (10.0.16.5) is still in use by https://www.googleapis.com/compute/v1/projects/testproject/regions/us-test1/forwardingRules/my-ilb; delete them and retry",
+		},
+		{
+			Name: "user not known",
+			Err: &googleapi.Error{
+				Code:    400,
+				Message: "resource in use",
+				Errors:  []googleapi.ErrorItem{{Reason: "resourceInUseByAnotherResource"}},
+			},
+			Expected: "INTERNAL address api-cluster-example-com (10.0.16.5) is still in use, likely by a forwarding rule or instance not managed by kops",
+		},
+	}
+	for _, g := range grid {
+		t.Run(g.Name, func(t *testing.T) {
+			if !isResourceInUse(g.Err) {
+				t.Fatalf("expected error to be classified as resource in use")
+			}
+			a.Users = g.Users
+			err := addressInUseError(a, g.Err)
+			if !strings.HasPrefix(err.Error(), g.Expected) {
+				t.Errorf("unexpected error, got %q, expected it to start with %q", err.Error(), g.Expected)
+			}
+		})
+	}
+}
+
+func TestListResourcePolicies(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	for _, name := range []string{"snapshots-cluster-example-com", "snapshots-other-example-com"} {
+		if _, err := cloud.Compute().ResourcePolicies().Insert("testproject", "us-test1", &compute.ResourcePolicy{Name: name}); err != nil {
+			t.Fatalf("error creating resource policy: %v", err)
+		}
+	}
+	if _, err := cloud.Compute().Disks().Insert("testproject", "us-test1-a", &compute.Disk{
+		Name:             "a-etcd-main-cluster-example-com",
+		Labels:           map[string]string{"k8s-io-cluster-name": "cluster-example-com"},
+		ResourcePolicies: []string{"https://www.googleapis.com/compute/v1/projects/testproject/regions/us-test1/resourcePolicies/snapshots-cluster-example-com"},
+	}); err != nil {
+		t.Fatalf("error creating disk: %v", err)
+	}
+
+	resourceMap, err := listResourcesForTest(cloud, "cluster.example.com", "us-test1")
+	if err != nil {
+		t.Fatalf("error listing resources: %v", err)
+	}
+
+	policy := resourceMap["ResourcePolicy:us-test1/snapshots-cluster-example-com"]
+	if policy == nil {
+		t.Fatalf("expected resource policy to be tracked, got %v", resourceMap)
+	}
+	if _, found := resourceMap["ResourcePolicy:snapshots-other-example-com"]; found {
+		t.Errorf("resource policy for another cluster should not be tracked")
+	}
+
+	// The disk must be deleted before the policy it uses
+	var order []string
+	for _, r := range SortedResources(resourceMap) {
+		order = append(order, r.Type+":"+r.ID)
+	}
+	expected := []string{"Disk:us-test1-a/a-etcd-main-cluster-example-com", "ResourcePolicy:us-test1/snapshots-cluster-example-com"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("unexpected deletion order, got %v, expected %v", order, expected)
+	}
+
+	if err := policy.Deleter(cloud, policy); err != nil {
+		t.Fatalf("error deleting resource policy: %v", err)
+	}
+	if _, err := cloud.Compute().ResourcePolicies().Get("testproject", "us-test1", "snapshots-cluster-example-com"); err == nil {
+		t.Errorf("expected resource policy to be deleted")
+	}
+}
+
+func TestListNetworkEndpointGroups(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	// The ingress controller names NEGs after the service, not the cluster
+	for _, name := range []string{"k8s1-1234-default-web-80-abcd", "k8s1-5678-default-other-80-efgh"} {
+		if _, err := cloud.Compute().NetworkEndpointGroups().Insert("testproject", "us-test1-a", &compute.NetworkEndpointGroup{Name: name}); err != nil {
+			t.Fatalf("error creating network endpoint group: %v", err)
+		}
+	}
+	if _, err := cloud.Compute().BackendServices().Insert("testproject", &compute.BackendService{
+		Name: "web-cluster-example-com",
+		Backends: []*compute.Backend{
+			{Group: "https://www.googleapis.com/compute/beta/projects/testproject/zones/us-test1-a/networkEndpointGroups/k8s1-1234-default-web-80-abcd"},
+		},
+	}); err != nil {
+		t.Fatalf("error creating backend service: %v", err)
+	}
+
+	resourceMap, err := listResourcesForTest(cloud, "cluster.example.com", "us-test1")
+	if err != nil {
+		t.Fatalf("error listing resources: %v", err)
+	}
+
+	neg := resourceMap["NetworkEndpointGroup:us-test1-a/k8s1-1234-default-web-80-abcd"]
+	if neg == nil {
+		t.Fatalf("expected network endpoint group to be tracked, got %v", resourceMap)
+	}
+	if _, found := resourceMap["NetworkEndpointGroup:us-test1-a/k8s1-5678-default-other-80-efgh"]; found {
+		t.Errorf("network endpoint group not used by the cluster should not be tracked")
+	}
+
+	bs := resourceMap["BackendService:web-cluster-example-com"]
+	if bs == nil {
+		t.Fatalf("expected backend service to be tracked, got %v", resourceMap)
+	}
+	if expected := []string{"NetworkEndpointGroup:us-test1-a/k8s1-1234-default-web-80-abcd"}; !reflect.DeepEqual(bs.Blocks, expected) {
+		t.Errorf("unexpected blocks for backend service, got %v, expected %v", bs.Blocks, expected)
+	}
+
+	if err := neg.Deleter(cloud, neg); err != nil {
+		t.Fatalf("error deleting network endpoint group: %v", err)
+	}
+	if _, err := cloud.Compute().NetworkEndpointGroups().Get("testproject", "us-test1-a", "k8s1-1234-default-web-80-abcd"); err == nil {
+		t.Errorf("expected network endpoint group to be deleted")
+	}
+}
+
+func TestListGlobalNetworkEndpointGroups(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	// A zonal and a global NEG with the same name must not collide
+	if _, err := cloud.Compute().NetworkEndpointGroups().Insert("testproject", "us-test1-a", &compute.NetworkEndpointGroup{Name: "k8s1-1234-default-web-80-abcd"}); err != nil {
+		t.Fatalf("error creating network endpoint group: %v", err)
+	}
+	if _, err := cloud.Compute().GlobalNetworkEndpointGroups().Insert("testproject", &compute.NetworkEndpointGroup{
+		Name:                "k8s1-1234-default-web-80-abcd",
+		NetworkEndpointType: "INTERNET_FQDN_PORT",
+	}); err != nil {
+		t.Fatalf("error creating global network endpoint group: %v", err)
+	}
+	if _, err := cloud.Compute().BackendServices().Insert("testproject", &compute.BackendService{
+		Name: "web-cluster-example-com",
+		Backends: []*compute.Backend{
+			{Group: "https://www.googleapis.com/compute/v1/projects/testproject/zones/us-test1-a/networkEndpointGroups/k8s1-1234-default-web-80-abcd"},
+			{Group: "https://www.googleapis.com/compute/v1/projects/testproject/global/networkEndpointGroups/k8s1-1234-default-web-80-abcd"},
+		},
+	}); err != nil {
+		t.Fatalf("error creating backend service: %v", err)
+	}
+
+	resourceMap, err := listResourcesForTest(cloud, "cluster.example.com", "us-test1")
+	if err != nil {
+		t.Fatalf("error listing resources: %v", err)
+	}
+
+	zonal := resourceMap["NetworkEndpointGroup:us-test1-a/k8s1-1234-default-web-80-abcd"]
+	if zonal == nil {
+		t.Fatalf("expected zonal network endpoint group to be tracked, got %v", resourceMap)
+	}
+	global := resourceMap["NetworkEndpointGroup:global/k8s1-1234-default-web-80-abcd"]
+	if global == nil {
+		t.Fatalf("expected global network endpoint group to be tracked, got %v", resourceMap)
+	}
+
+	bs := resourceMap["BackendService:web-cluster-example-com"]
+	if bs == nil {
+		t.Fatalf("expected backend service to be tracked, got %v", resourceMap)
+	}
+	expected := []string{
+		"NetworkEndpointGroup:us-test1-a/k8s1-1234-default-web-80-abcd",
+		"NetworkEndpointGroup:global/k8s1-1234-default-web-80-abcd",
+	}
+	if !reflect.DeepEqual(bs.Blocks, expected) {
+		t.Errorf("unexpected blocks for backend service, got %v, expected %v", bs.Blocks, expected)
+	}
+
+	if err := global.Deleter(cloud, global); err != nil {
+		t.Fatalf("error deleting global network endpoint group: %v", err)
+	}
+	if _, err := cloud.Compute().GlobalNetworkEndpointGroups().Get("testproject", "k8s1-1234-default-web-80-abcd"); err == nil {
+		t.Errorf("expected global network endpoint group to be deleted")
+	}
+	if _, err := cloud.Compute().NetworkEndpointGroups().Get("testproject", "us-test1-a", "k8s1-1234-default-web-80-abcd"); err != nil {
+		t.Errorf("expected zonal network endpoint group to be kept, got %v", err)
+	}
+}
+
+func TestListSecurityPolicies(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	for _, name := range []string{"armor-cluster-example-com", "armor-other-example-com"} {
+		if _, err := cloud.Compute().SecurityPolicies().Insert("testproject", &compute.SecurityPolicy{Name: name}); err != nil {
+			t.Fatalf("error creating security policy: %v", err)
+		}
+	}
+	if _, err := cloud.Compute().BackendServices().Insert("testproject", &compute.BackendService{
+		Name:           "web-cluster-example-com",
+		SecurityPolicy: "https://www.googleapis.com/compute/v1/projects/testproject/global/securityPolicies/armor-cluster-example-com",
+	}); err != nil {
+		t.Fatalf("error creating backend service: %v", err)
+	}
+
+	resourceMap, err := listResourcesForTest(cloud, "cluster.example.com", "us-test1")
+	if err != nil {
+		t.Fatalf("error listing resources: %v", err)
+	}
+
+	sp := resourceMap["SecurityPolicy:armor-cluster-example-com"]
+	if sp == nil {
+		t.Fatalf("expected security policy to be tracked, got %v", resourceMap)
+	}
+	if _, found := resourceMap["SecurityPolicy:armor-other-example-com"]; found {
+		t.Errorf("security policy of another cluster should not be tracked")
+	}
+	if expected := []string{"BackendService:web-cluster-example-com"}; !reflect.DeepEqual(sp.Blocked, expected) {
+		t.Errorf("unexpected blocked for security policy, got %v, expected %v", sp.Blocked, expected)
+	}
+
+	bs := resourceMap["BackendService:web-cluster-example-com"]
+	if bs == nil {
+		t.Fatalf("expected backend service to be tracked, got %v", resourceMap)
+	}
+	if expected := []string{"SecurityPolicy:armor-cluster-example-com"}; !reflect.DeepEqual(bs.Blocks, expected) {
+		t.Errorf("unexpected blocks for backend service, got %v, expected %v", bs.Blocks, expected)
+	}
+
+	// The backend service must be deleted first, so the policy is no longer in use
+	var order []string
+	for _, r := range SortedResources(resourceMap) {
+		order = append(order, r.Type+":"+r.ID)
+	}
+	if expected := []string{"BackendService:web-cluster-example-com", "SecurityPolicy:armor-cluster-example-com"}; !reflect.DeepEqual(order, expected) {
+		t.Errorf("unexpected deletion order, got %v, expected %v", order, expected)
+	}
+
+	if err := sp.Deleter(cloud, sp); err != nil {
+		t.Fatalf("error deleting security policy: %v", err)
+	}
+	if _, err := cloud.Compute().SecurityPolicies().Get("testproject", "armor-cluster-example-com"); err == nil {
+		t.Errorf("expected security policy to be deleted")
+	}
+}
+
+func TestListSslPolicies(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	// modern-tls is a shared policy, which is not named for the cluster
+	for _, name := range []string{"tls-cluster-example-com", "tls-other-example-com", "modern-tls"} {
+		if _, err := cloud.Compute().SslPolicies().Insert("testproject", &compute.SslPolicy{Name: name}); err != nil {
+			t.Fatalf("error creating SSL policy: %v", err)
+		}
+	}
+	if _, err := cloud.Compute().TargetHttpsProxies().Insert("testproject", &compute.TargetHttpsProxy{
+		Name:      "https-cluster-example-com",
+		SslPolicy: "https://www.googleapis.com/compute/v1/projects/testproject/global/sslPolicies/tls-cluster-example-com",
+	}); err != nil {
+		t.Fatalf("error creating target HTTPS proxy: %v", err)
+	}
+	if _, err := cloud.Compute().TargetHttpsProxies().Insert("testproject", &compute.TargetHttpsProxy{
+		Name:      "https-shared",
+		SslPolicy: "https://www.googleapis.com/compute/v1/projects/testproject/global/sslPolicies/modern-tls",
+	}); err != nil {
+		t.Fatalf("error creating target HTTPS proxy: %v", err)
+	}
+
+	resourceMap, err := listResourcesForTest(cloud, "cluster.example.com", "us-test1")
+	if err != nil {
+		t.Fatalf("error listing resources: %v", err)
+	}
+
+	sp := resourceMap["SslPolicy:tls-cluster-example-com"]
+	if sp == nil {
+		t.Fatalf("expected SSL policy to be tracked, got %v", resourceMap)
+	}
+	for _, k := range []string{"SslPolicy:tls-other-example-com", "SslPolicy:modern-tls"} {
+		if _, found := resourceMap[k]; found {
+			t.Errorf("%s should not be tracked", k)
+		}
+	}
+	if expected := []string{"TargetHttpsProxy:https-cluster-example-com"}; !reflect.DeepEqual(sp.Blocked, expected) {
+		t.Errorf("unexpected blocked for SSL policy, got %v, expected %v", sp.Blocked, expected)
+	}
+
+	// The proxy must be deleted first, so the policy is no longer in use
+	var order []string
+	for _, r := range SortedResources(resourceMap) {
+		order = append(order, r.Type+":"+r.ID)
+	}
+	if expected := []string{"TargetHttpsProxy:https-cluster-example-com", "SslPolicy:tls-cluster-example-com"}; !reflect.DeepEqual(order, expected) {
+		t.Errorf("unexpected deletion order, got %v, expected %v", order, expected)
+	}
+
+	if err := sp.Deleter(cloud, sp); err != nil {
+		t.Fatalf("error deleting SSL policy: %v", err)
+	}
+	if _, err := cloud.Compute().SslPolicies().Get("testproject", "tls-cluster-example-com"); err == nil {
+		t.Errorf("expected SSL policy to be deleted")
+	}
+}
+
+func TestListRegionalHealthChecks(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	// A global and a regional HealthCheck with the same name, for an external and an internal load balancer
+	for _, name := range []string{"", "us-test1"} {
+		hc := &compute.HealthCheck{Name: "api-cluster-example-com", Type: "TCP"}
+		var err error
+		if name == "" {
+			_, err = cloud.Compute().HealthChecks().Insert("testproject", hc)
+		} else {
+			_, err = cloud.Compute().RegionHealthChecks().Insert("testproject", name, hc)
+		}
+		if err != nil {
+			t.Fatalf("error creating health check: %v", err)
+		}
+	}
+	if _, err := cloud.Compute().RegionBackendServices().Insert("testproject", "us-test1", &compute.BackendService{
+		Name:                "ilb-cluster-example-com",
+		LoadBalancingScheme: "INTERNAL",
+		HealthChecks:        []string{"https://www.googleapis.com/compute/v1/projects/testproject/regions/us-test1/healthChecks/api-cluster-example-com"},
+	}); err != nil {
+		t.Fatalf("error creating regional backend service: %v", err)
+	}
+	if _, err := cloud.Compute().BackendServices().Insert("testproject", &compute.BackendService{
+		Name:         "api-cluster-example-com",
+		HealthChecks: []string{"https://www.googleapis.com/compute/v1/projects/testproject/global/healthChecks/api-cluster-example-com"},
+	}); err != nil {
+		t.Fatalf("error creating backend service: %v", err)
+	}
+
+	d := &clusterDiscoveryGCE{
+		cloud:       cloud,
+		gceCloud:    cloud,
+		clusterName: "cluster.example.com",
+	}
+	resourceMap, err := runListFunctions(context.Background(), []gceListFn{d.listHealthChecks, d.listBackendServices}, 1)
+	if err != nil {
+		t.Fatalf("error listing resources: %v", err)
+	}
+
+	for _, k := range []string{"HealthCheck:api-cluster-example-com", "HealthCheck:us-test1/api-cluster-example-com"} {
+		if resourceMap[k] == nil {
+			t.Errorf("expected %s to be discovered, got %v", k, resourceMap)
+		}
+	}
+
+	g, err := BuildDependencyGraph(resourceMap)
+	if err != nil {
+		t.Fatalf("error building dependency graph: %v", err)
+	}
+	edges := make(map[Edge]bool)
+	for _, e := range g.Edges {
+		edges[e] = true
+	}
+	for _, e := range []Edge{
+		{From: "BackendService:us-test1/ilb-cluster-example-com", To: "HealthCheck:us-test1/api-cluster-example-com"},
+		{From: "BackendService:api-cluster-example-com", To: "HealthCheck:api-cluster-example-com"},
+	} {
+		if !edges[e] {
+			t.Errorf("expected edge %v, got edges %v", e, g.Edges)
+		}
+	}
+	if edges[Edge{From: "BackendService:us-test1/ilb-cluster-example-com", To: "HealthCheck:api-cluster-example-com"}] {
+		t.Errorf("unexpected edge from the regional backend service to the global health check")
+	}
+}
+
+func TestListRegionalBackendServices(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	// A global and a regional BackendService with the same name, for an external and an internal load balancer
+	if _, err := cloud.Compute().BackendServices().Insert("testproject", &compute.BackendService{Name: "api-cluster-example-com"}); err != nil {
+		t.Fatalf("error creating backend service: %v", err)
+	}
+	if _, err := cloud.Compute().RegionBackendServices().Insert("testproject", "us-test1", &compute.BackendService{
+		Name:                "api-cluster-example-com",
+		LoadBalancingScheme: "INTERNAL",
+	}); err != nil {
+		t.Fatalf("error creating regional backend service: %v", err)
+	}
+	if _, err := cloud.Compute().ForwardingRules().Insert("testproject", "us-test1", &compute.ForwardingRule{
+		Name:                "ilb-cluster-example-com",
+		LoadBalancingScheme: "INTERNAL",
+		BackendService:      "https://www.googleapis.com/compute/v1/projects/testproject/regions/us-test1/backendServices/api-cluster-example-com",
+	}); err != nil {
+		t.Fatalf("error creating internal forwarding rule: %v", err)
+	}
+
+	d := &clusterDiscoveryGCE{
+		cloud:       cloud,
+		gceCloud:    cloud,
+		clusterName: "cluster.example.com",
+	}
+	resourceMap, err := runListFunctions(context.Background(), []gceListFn{d.listBackendServices, d.listForwardingRules}, 1)
+	if err != nil {
+		t.Fatalf("error listing resources: %v", err)
+	}
+
+	for _, k := range []string{"BackendService:api-cluster-example-com", "BackendService:us-test1/api-cluster-example-com"} {
+		if resourceMap[k] == nil {
+			t.Errorf("expected %s to be discovered, got %v", k, resourceMap)
+		}
+	}
+	fr := resourceMap["ForwardingRule:us-test1/ilb-cluster-example-com"]
+	if fr == nil {
+		t.Fatalf("expected the internal forwarding rule to be discovered, got %v", resourceMap)
+	}
+	if expected := []string{"BackendService:us-test1/api-cluster-example-com"}; !reflect.DeepEqual(fr.Blocks, expected) {
+		t.Errorf("unexpected blocks for forwarding rule, got %v, expected %v", fr.Blocks, expected)
+	}
+}
+
+func TestListInternalLoadBalancerForwardingRules(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	if _, err := cloud.Compute().RegionBackendServices().Insert("testproject", "us-test1", &compute.BackendService{
+		Name:                "ilb-cluster-example-com",
+		LoadBalancingScheme: "INTERNAL",
+	}); err != nil {
+		t.Fatalf("error creating regional backend service: %v", err)
+	}
+	if _, err := cloud.Compute().ForwardingRules().Insert("testproject", "us-test1", &compute.ForwardingRule{
+		Name:                "ilb-cluster-example-com",
+		LoadBalancingScheme: "INTERNAL",
+		BackendService:      "https://www.googleapis.com/compute/v1/projects/testproject/regions/us-test1/backendServices/ilb-cluster-example-com",
+	}); err != nil {
+		t.Fatalf("error creating internal forwarding rule: %v", err)
+	}
+	if _, err := cloud.Compute().ForwardingRules().Insert("testproject", "us-test1", &compute.ForwardingRule{
+		Name:   "api-cluster-example-com",
+		Target: "https://www.googleapis.com/compute/v1/projects/testproject/regions/us-test1/targetPools/api-cluster-example-com",
+	}); err != nil {
+		t.Fatalf("error creating forwarding rule: %v", err)
+	}
+
+	resourceMap, err := listResourcesForTest(cloud, "cluster.example.com", "us-test1")
+	if err != nil {
+		t.Fatalf("error listing resources: %v", err)
+	}
+
+	g, err := BuildDependencyGraph(resourceMap)
+	if err != nil {
+		t.Fatalf("error building dependency graph: %v", err)
+	}
+	ilbEdge := Edge{From: "ForwardingRule:us-test1/ilb-cluster-example-com", To: "BackendService:us-test1/ilb-cluster-example-com"}
+	found := false
+	for _, e := range g.Edges {
+		if e == ilbEdge {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected the internal forwarding rule to be deleted before its backend service, got edges %v", g.Edges)
+	}
+
+	r := resourceMap["ForwardingRule:us-test1/api-cluster-example-com"]
+	if r == nil {
+		t.Fatalf("expected target pool forwarding rule to be tracked, got %v", resourceMap)
+	}
+	if expected := []string{"TargetPool:us-test1/api-cluster-example-com"}; !reflect.DeepEqual(r.Blocks, expected) {
+		t.Errorf("unexpected blocks for target pool forwarding rule, got %v, expected %v", r.Blocks, expected)
+	}
+}
+
+func TestRunListFunctionsMatchesSequential(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	for _, name := range []string{"api-cluster-example-com", "nodes-cluster-example-com", "api-other-example-com"} {
+		if _, err := cloud.Compute().TargetPools().Insert("testproject", "us-test1", &compute.TargetPool{Name: name}); err != nil {
+			t.Fatalf("error creating target pool: %v", err)
+		}
+		if _, err := cloud.Compute().ForwardingRules().Insert("testproject", "us-test1", &compute.ForwardingRule{
+			Name:   name,
+			Target: "https://www.googleapis.com/compute/v1/projects/testproject/regions/us-test1/targetPools/" + name,
+		}); err != nil {
+			t.Fatalf("error creating forwarding rule: %v", err)
+		}
+		if _, err := cloud.Compute().Addresses().Insert("testproject", "us-test1", &compute.Address{Name: name}); err != nil {
+			t.Fatalf("error creating address: %v", err)
+		}
+	}
+	if _, err := cloud.Compute().Disks().Insert("testproject", "us-test1-a", &compute.Disk{
+		Name:   "a-etcd-main-cluster-example-com",
+		Labels: map[string]string{"k8s-io-cluster-name": "cluster-example-com"},
+	}); err != nil {
+		t.Fatalf("error creating disk: %v", err)
+	}
+
+	list := func(concurrency int) map[string][]string {
+		d := &clusterDiscoveryGCE{
+			cloud:       cloud,
+			gceCloud:    cloud,
+			clusterName: "cluster.example.com",
+			zones:       []string{"us-test1-a"},
+		}
+		resourceMap, err := runListFunctions(context.Background(), d.listFunctions(), concurrency)
+		if err != nil {
+			t.Fatalf("error listing resources: %v", err)
+		}
+		summary := make(map[string][]string)
+		for k, r := range resourceMap {
+			deps := append(append([]string{}, r.Blocks...), r.Blocked...)
+			sort.Strings(deps)
+			summary[k] = deps
+		}
+		return summary
+	}
+
+	sequential := list(1)
+	if len(sequential) != 7 {
+		t.Errorf("expected 7 resources, got %v", sequential)
+	}
+	for i := 0; i < 10; i++ {
+		parallel := list(maxConcurrentListCalls)
+		if !reflect.DeepEqual(sequential, parallel) {
+			t.Fatalf("parallel discovery differs from sequential discovery, got %v, expected %v", parallel, sequential)
+		}
+	}
+}
+
+func TestRunListFunctionsPartialFailure(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	if _, err := cloud.Compute().TargetPools().Insert("testproject", "us-test1", &compute.TargetPool{Name: "api-cluster-example-com"}); err != nil {
+		t.Fatalf("error creating target pool: %v", err)
+	}
+
+	d := &clusterDiscoveryGCE{
+		cloud:       cloud,
+		gceCloud:    cloud,
+		clusterName: "cluster.example.com",
+	}
+	failing := func(ctx context.Context) ([]*resources.Resource, error) {
+		return nil, fmt.Errorf("error listing firewall rules: quota exceeded")
+	}
+	resourceMap, err := runListFunctions(context.Background(), []gceListFn{d.listTargetPools, failing}, 1)
+	if err == nil {
+		t.Fatalf("expected error from failing list function")
+	}
+	if !strings.Contains(err.Error(), "quota exceeded") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, found := resourceMap["TargetPool:us-test1/api-cluster-example-com"]; !found || len(resourceMap) != 1 {
+		t.Errorf("expected successfully listed target pool to be returned, got %v", resourceMap)
+	}
+}
+
+func TestListTargetPoolsNameMatching(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	targetPools := &fakeTargetPoolClient{
+		targetPools: map[string][]*compute.TargetPool{
+			"us-test1": {
+				{Name: "api-cluster-example-com"},
+				{Name: "api-other-example-com"},
+				{Name: "api-extra-cluster-example-com"},
+				{Name: "cluster-example-com"},
+			},
+			"us-test2": {
+				{Name: "nodes-cluster-example-com"},
+			},
+		},
+	}
+	d := &clusterDiscoveryGCE{
+		cloud:         cloud,
+		gceCloud:      cloud,
+		clusterName:   "cluster.example.com",
+		computeClient: &fakeComputeClient{targetPools: targetPools},
+		regions:       []string{"us-test1", "us-test2"},
+	}
+	resourceTrackers, err := d.listTargetPools(context.Background())
+	if err != nil {
+		t.Fatalf("error listing target pools: %v", err)
+	}
+
+	var actual []string
+	for _, r := range resourceTrackers {
+		actual = append(actual, r.Type+":"+r.ID)
+	}
+	sort.Strings(actual)
+	expected := []string{"TargetPool:api-cluster-example-com", "TargetPool:nodes-cluster-example-com"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected target pools, got %v, expected %v", actual, expected)
+	}
+	if expected := []string{"us-test1", "us-test2"}; !reflect.DeepEqual(targetPools.listed, expected) {
+		t.Errorf("unexpected regions listed, got %v, expected %v", targetPools.listed, expected)
+	}
+}
+
+func TestListTargetPoolHttpHealthChecks(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	for _, name := range []string{"api-cluster-example-com", "shared-health-check"} {
+		if _, err := cloud.Compute().HttpHealthChecks().Insert("testproject", &compute.HttpHealthCheck{Name: name}); err != nil {
+			t.Fatalf("error creating http health check: %v", err)
+		}
+	}
+	if _, err := cloud.Compute().TargetPools().Insert("testproject", "us-test1", &compute.TargetPool{
+		Name: "api-cluster-example-com",
+		HealthChecks: []string{
+			"https://www.googleapis.com/compute/v1/projects/testproject/global/httpHealthChecks/api-cluster-example-com",
+			"https://www.googleapis.com/compute/v1/projects/testproject/global/httpHealthChecks/shared-health-check",
+			"https://www.googleapis.com/compute/v1/projects/testproject/global/httpHealthChecks/deleted-cluster-example-com",
+		},
+	}); err != nil {
+		t.Fatalf("error creating target pool: %v", err)
+	}
+
+	d := &clusterDiscoveryGCE{
+		cloud:       cloud,
+		gceCloud:    cloud,
+		clusterName: "cluster.example.com",
+	}
+	resourceMap, err := runListFunctions(context.Background(), []gceListFn{d.listTargetPools}, 1)
+	if err != nil {
+		t.Fatalf("error listing target pools: %v", err)
+	}
+
+	expected := map[string][]string{
+		"TargetPool:us-test1/api-cluster-example-com": nil,
+		"HttpHealthCheck:api-cluster-example-com":     {"TargetPool:us-test1/api-cluster-example-com"},
+	}
+	actual := make(map[string][]string)
+	for k, r := range resourceMap {
+		actual[k] = r.Blocked
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("unexpected resources, got %v, expected %v", actual, expected)
+	}
+
+	hc := resourceMap["HttpHealthCheck:api-cluster-example-com"]
+	if obj, ok := hc.Obj.(*compute.HttpHealthCheck); !ok || obj.SelfLink == "" {
+		t.Errorf("expected the http health check object to be tracked, got %#v", hc.Obj)
+	}
+	if err := hc.Deleter(cloud, hc); err != nil {
+		t.Fatalf("error deleting http health check: %v", err)
+	}
+	if _, err := cloud.Compute().HttpHealthChecks().Get("testproject", "shared-health-check"); err != nil {
+		t.Errorf("shared http health check should not be deleted: %v", err)
+	}
+}
+
+func TestListTargetPoolInstances(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	// Only the first instance is labelled for the cluster; the legacy pool of the cluster references it and a shared one
+	clusterLabels := map[string]string{"k8s-io-cluster-name": "cluster-example-com"}
+	for name, labels := range map[string]map[string]string{"legacy-backend": clusterLabels, "shared-backend": nil, "unrelated": nil} {
+		if _, err := cloud.Compute().Instances().Insert("testproject", "us-test1-a", &compute.Instance{Name: name, Labels: labels}); err != nil {
+			t.Fatalf("error creating instance: %v", err)
+		}
+	}
+	if _, err := cloud.Compute().TargetPools().Insert("testproject", "us-test1", &compute.TargetPool{
+		Name: "api-cluster-example-com",
+		Instances: []string{
+			"https://www.googleapis.com/compute/v1/projects/testproject/zones/us-test1-a/instances/legacy-backend",
+			"https://www.googleapis.com/compute/v1/projects/testproject/zones/us-test1-a/instances/shared-backend",
+		},
+	}); err != nil {
+		t.Fatalf("error creating target pool: %v", err)
+	}
+	if _, err := cloud.Compute().TargetPools().Insert("testproject", "us-test1", &compute.TargetPool{
+		Name:      "other-pool",
+		Instances: []string{"https://www.googleapis.com/compute/v1/projects/testproject/zones/us-test1-a/instances/unrelated"},
+	}); err != nil {
+		t.Fatalf("error creating target pool: %v", err)
+	}
+
+	d := &clusterDiscoveryGCE{
+		cloud:       cloud,
+		gceCloud:    cloud,
+		clusterName: "cluster.example.com",
+		zones:       []string{"us-test1-a"},
+	}
+	resourceMap, err := runListFunctions(context.Background(), []gceListFn{d.listTargetPools, d.listInstanceGroupManagersAndInstances}, 1)
+	if err != nil {
+		t.Fatalf("error listing resources: %v", err)
+	}
+
+	expected := map[string][]string{
+		"TargetPool:us-test1/api-cluster-example-com": nil,
+		"Instance:us-test1-a/legacy-backend":          {"TargetPool:us-test1/api-cluster-example-com"},
+	}
+	actual := make(map[string][]string)
+	for k, r := range resourceMap {
+		actual[k] = r.Blocked
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("unexpected resources, got %v, expected %v", actual, expected)
+	}
+
+	var order []string
+	for _, r := range SortedResources(resourceMap) {
+		order = append(order, r.Type+":"+r.ID)
+	}
+	if expected := []string{"TargetPool:us-test1/api-cluster-example-com", "Instance:us-test1-a/legacy-backend"}; !reflect.DeepEqual(order, expected) {
+		t.Errorf("unexpected deletion order, got %v, expected %v", order, expected)
+	}
+}
+
+func TestListDisks(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	clusterLabels := map[string]string{"k8s-io-cluster-name": "cluster-example-com"}
+	if _, err := cloud.Compute().Disks().Insert("testproject", "us-test1-a", &compute.Disk{
+		Name:   "a-etcd-main-cluster-example-com",
+		Zone:   "https://www.googleapis.com/compute/v1/projects/testproject/zones/us-test1-a",
+		Labels: clusterLabels,
+		Users:  []string{"https://www.googleapis.com/compute/v1/projects/testproject/zones/us-test1-a/instances/master-us-test1-a-abcd"},
+	}); err != nil {
+		t.Fatalf("error creating disk: %v", err)
+	}
+	if _, err := cloud.Compute().RegionDisks().Insert("testproject", "us-test1", &compute.Disk{
+		Name:   "pvc-1234",
+		Region: "https://www.googleapis.com/compute/v1/projects/testproject/regions/us-test1",
+		Labels: clusterLabels,
+		Users:  []string{"https://www.googleapis.com/compute/v1/projects/testproject/zones/us-test1-b/instances/nodes-us-test1-b-efgh"},
+	}); err != nil {
+		t.Fatalf("error creating regional disk: %v", err)
+	}
+	if _, err := cloud.Compute().RegionDisks().Insert("testproject", "us-test1", &compute.Disk{
+		Name:   "pvc-5678",
+		Labels: map[string]string{"k8s-io-cluster-name": "other-example-com"},
+	}); err != nil {
+		t.Fatalf("error creating regional disk: %v", err)
+	}
+
+	d := &clusterDiscoveryGCE{
+		cloud:       cloud,
+		gceCloud:    cloud,
+		clusterName: "cluster.example.com",
+	}
+	resourceMap, err := runListFunctions(context.Background(), []gceListFn{d.listGCEDisks, d.listGCERegionDisks}, 1)
+	if err != nil {
+		t.Fatalf("error listing disks: %v", err)
+	}
+
+	expected := map[string][]string{
+		"Disk:us-test1-a/a-etcd-main-cluster-example-com": {"Instance:us-test1-a/master-us-test1-a-abcd"},
+		"Disk:us-test1/pvc-1234":                          {"Instance:us-test1-b/nodes-us-test1-b-efgh"},
+	}
+	actual := make(map[string][]string)
+	for k, r := range resourceMap {
+		actual[k] = r.Blocked
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected disks, got %v, expected %v", actual, expected)
+	}
+}
+
+func TestListDisksCSIDriverLabel(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	for _, disk := range []*compute.Disk{
+		{
+			// Provisioned by the GCE PD CSI driver for a PV that was not deleted
+			Name:   "pvc-1234",
+			Zone:   "https://www.googleapis.com/compute/v1/projects/testproject/zones/us-test1-a",
+			Labels: map[string]string{"goog-k8s-cluster-name": "cluster-example-com"},
+		},
+		{
+			Name:   "pvc-5678",
+			Zone:   "https://www.googleapis.com/compute/v1/projects/testproject/zones/us-test1-a",
+			Labels: map[string]string{"goog-k8s-cluster-name": "other-example-com"},
+		},
+		{
+			// The labels disagree, so we can't tell which cluster the disk belongs to
+			Name: "pvc-9012",
+			Zone: "https://www.googleapis.com/compute/v1/projects/testproject/zones/us-test1-a",
+			Labels: map[string]string{
+				"k8s-io-cluster-name":   "cluster-example-com",
+				"goog-k8s-cluster-name": "other-example-com",
+			},
+		},
+	} {
+		if _, err := cloud.Compute().Disks().Insert("testproject", "us-test1-a", disk); err != nil {
+			t.Fatalf("error creating disk: %v", err)
+		}
+	}
+
+	d := &clusterDiscoveryGCE{
+		cloud:       cloud,
+		gceCloud:    cloud,
+		clusterName: "cluster.example.com",
+	}
+	resourceMap, err := runListFunctions(context.Background(), []gceListFn{d.listGCEDisks}, 1)
+	if err != nil {
+		t.Fatalf("error listing disks: %v", err)
+	}
+
+	var keys []string
+	for k := range resourceMap {
+		keys = append(keys, k)
+	}
+	if expected := []string{"Disk:us-test1-a/pvc-1234"}; !reflect.DeepEqual(keys, expected) {
+		t.Errorf("unexpected disks, got %v, expected %v", keys, expected)
+	}
+}
+
+func TestListMachineImages(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	for _, mi := range []*computebeta.MachineImage{
+		{
+			Name:                     "nodes-cluster-example-com-image",
+			SourceInstanceProperties: &computebeta.SourceInstanceProperties{Labels: map[string]string{"k8s-io-cluster-name": "cluster-example-com"}},
+		},
+		{
+			Name:                     "nodes-other-example-com-image",
+			SourceInstanceProperties: &computebeta.SourceInstanceProperties{Labels: map[string]string{"k8s-io-cluster-name": "other-example-com"}},
+		},
+		{
+			Name: "unlabelled-image",
+		},
+	} {
+		if _, err := cloud.Compute().MachineImages().Insert("testproject", mi); err != nil {
+			t.Fatalf("error creating machine image: %v", err)
+		}
+	}
+
+	d := &clusterDiscoveryGCE{
+		cloud:       cloud,
+		gceCloud:    cloud,
+		clusterName: "cluster.example.com",
+	}
+	resourceTrackers, err := d.listGCEMachineImages(context.Background())
+	if err != nil {
+		t.Fatalf("error listing machine images: %v", err)
+	}
+
+	var actual []string
+	for _, r := range resourceTrackers {
+		actual = append(actual, r.Type+":"+r.ID)
+	}
+	expected := []string{"MachineImage:nodes-cluster-example-com-image"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("unexpected machine images, got %v, expected %v", actual, expected)
+	}
+
+	if err := resourceTrackers[0].Deleter(cloud, resourceTrackers[0]); err != nil {
+		t.Fatalf("error deleting machine image: %v", err)
+	}
+	if _, err := cloud.Compute().MachineImages().Get("testproject", "nodes-cluster-example-com-image"); err == nil {
+		t.Errorf("expected machine image to be deleted")
+	}
+}
+
+func TestListImages(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	for _, image := range []*compute.Image{
+		{Name: "kops-cluster-example-com", Labels: map[string]string{"k8s-io-cluster-name": "cluster-example-com"}},
+		{Name: "kops-cluster-example-com-other", Labels: map[string]string{"k8s-io-cluster-name": "cluster-example-com-other"}},
+		{Name: "kops-shared"},
+	} {
+		if _, err := cloud.Compute().Images().Insert("testproject", image); err != nil {
+			t.Fatalf("error creating image: %v", err)
+		}
+	}
+
+	d := &clusterDiscoveryGCE{
+		cloud:       cloud,
+		gceCloud:    cloud,
+		clusterName: "cluster.example.com",
+	}
+	resourceTrackers, err := d.listGCEImages(context.Background())
+	if err != nil {
+		t.Fatalf("error listing images: %v", err)
+	}
+
+	var actual []string
+	for _, r := range resourceTrackers {
+		actual = append(actual, r.Type+":"+r.ID)
+	}
+	expected := []string{"Image:kops-cluster-example-com"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("unexpected images, got %v, expected %v", actual, expected)
+	}
+
+	if err := resourceTrackers[0].Deleter(cloud, resourceTrackers[0]); err != nil {
+		t.Fatalf("error deleting image: %v", err)
+	}
+	if _, err := cloud.Compute().Images().Get("testproject", "kops-cluster-example-com"); err == nil {
+		t.Errorf("expected image to be deleted")
+	}
+	// Deleting an image that is already gone is not an error
+	if err := resourceTrackers[0].Deleter(cloud, resourceTrackers[0]); err != nil {
+		t.Errorf("error deleting image that was already deleted: %v", err)
+	}
+}
+
+func TestListInstanceTemplates(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	clusterName := "cluster.example.com"
+	otherClusterName := "other.example.com"
+	metadata := func(values ...*string) *compute.Metadata {
+		m := &compute.Metadata{}
+		for _, v := range values {
+			m.Items = append(m.Items, &compute.MetadataItems{Key: "cluster-name", Value: v})
+		}
+		return m
+	}
+	for _, template := range []*compute.InstanceTemplate{
+		{
+			Name:       "nodes-cluster-example-com-1234",
+			Properties: &compute.InstanceProperties{Metadata: metadata(&clusterName)},
+		},
+		{
+			// Named as if for our cluster, but created for another cluster
+			Name:       "nodes-cluster-example-com-5678",
+			Properties: &compute.InstanceProperties{Metadata: metadata(&otherClusterName)},
+		},
+		{
+			Name: "nodes-cluster-example-com-conflicting",
+			Properties: &compute.InstanceProperties{
+				Metadata: metadata(&clusterName),
+				Labels:   map[string]string{"k8s-io-cluster-name": "other-example-com"},
+			},
+		},
+		{
+			Name:       "nodes-cluster-example-com-ambiguous",
+			Properties: &compute.InstanceProperties{Metadata: metadata(&clusterName, &otherClusterName)},
+		},
+		{
+			Name:       "nodes-cluster-example-com-no-metadata",
+			Properties: &compute.InstanceProperties{},
+		},
+	} {
+		if _, err := cloud.Compute().InstanceTemplates().Insert("testproject", template); err != nil {
+			t.Fatalf("error creating instance template: %v", err)
+		}
+	}
+
+	d := &clusterDiscoveryGCE{
+		cloud:       cloud,
+		gceCloud:    cloud,
+		clusterName: clusterName,
+	}
+	resourceMap, err := runListFunctions(context.Background(), []gceListFn{d.listGCEInstanceTemplates}, 1)
+	if err != nil {
+		t.Fatalf("error listing instance templates: %v", err)
+	}
+
+	var keys []string
+	for k := range resourceMap {
+		keys = append(keys, k)
+	}
+	if expected := []string{"InstanceTemplate:nodes-cluster-example-com-1234"}; !reflect.DeepEqual(keys, expected) {
+		t.Errorf("unexpected instance templates, got %v, expected %v", keys, expected)
+	}
+}
+
+func TestForceDeleteDiskInUse(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	if _, err := cloud.Compute().Disks().Insert("testproject", "us-test1-a", &compute.Disk{
+		Name:   "a-etcd-main-cluster-example-com",
+		Labels: map[string]string{"k8s-io-cluster-name": "cluster-example-com"},
+		Users:  []string{"https://www.googleapis.com/compute/v1/projects/testproject/zones/us-test1-a/instances/debug-vm"},
+	}); err != nil {
+		t.Fatalf("error creating disk: %v", err)
+	}
+	if _, err := cloud.Compute().Instances().Insert("testproject", "us-test1-a", &compute.Instance{
+		Name: "debug-vm",
+		Disks: []*compute.AttachedDisk{
+			{DeviceName: "boot", Boot: true, Source: "https://www.googleapis.com/compute/v1/projects/testproject/zones/us-test1-a/disks/debug-vm"},
+			{DeviceName: "etcd", Source: "https://www.googleapis.com/compute/v1/projects/testproject/zones/us-test1-a/disks/a-etcd-main-cluster-example-com"},
+		},
+	}); err != nil {
+		t.Fatalf("error creating instance: %v", err)
+	}
+
+	// The deleters use a cloud whose disk client refuses to delete attached disks
+	deleteCloud := &fakeComputeCloud{
+		GCECloud: cloud,
+		compute: &fakeComputeClient{
+			ComputeClient: cloud.Compute(),
+			disks:         &fakeInUseDiskClient{DiskClient: cloud.Compute().Disks(), instances: cloud.Compute().Instances()},
+		},
+	}
+
+	for _, force := range []bool{false, true} {
+		d := &clusterDiscoveryGCE{
+			cloud:       cloud,
+			gceCloud:    cloud,
+			clusterName: "cluster.example.com",
+			force:       force,
+		}
+		resourceMap, err := runListFunctions(context.Background(), []gceListFn{d.listGCEDisks}, 1)
+		if err != nil {
+			t.Fatalf("error listing disks: %v", err)
+		}
+		r := resourceMap["Disk:us-test1-a/a-etcd-main-cluster-example-com"]
+		if r == nil {
+			t.Fatalf("expected disk to be tracked, got %v", resourceMap)
+		}
+
+		err = r.Deleter(deleteCloud, r)
+		if !force {
+			if err == nil {
+				t.Fatalf("expected an error deleting a disk in use without force")
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("error deleting disk in use with force: %v", err)
+		}
+	}
+
+	if _, err := cloud.Compute().Disks().Get("testproject", "us-test1-a", "a-etcd-main-cluster-example-com"); err == nil {
+		t.Errorf("expected disk to be deleted")
+	}
+	instance, err := cloud.Compute().Instances().Get("testproject", "us-test1-a", "debug-vm")
+	if err != nil {
+		t.Fatalf("expected the instance using the disk to be kept, got %v", err)
+	}
+	var deviceNames []string
+	for _, attached := range instance.Disks {
+		deviceNames = append(deviceNames, attached.DeviceName)
+	}
+	if expected := []string{"boot"}; !reflect.DeepEqual(deviceNames, expected) {
+		t.Errorf("unexpected disks attached to the instance, got %v, expected %v", deviceNames, expected)
+	}
+}
+
+func TestListDisksReferencedByInstanceTemplate(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	clusterName := "cluster.example.com"
+	if _, err := cloud.Compute().InstanceTemplates().Insert("testproject", &compute.InstanceTemplate{
+		Name: "master-us-test1-a-cluster-example-com-1234",
+		Properties: &compute.InstanceProperties{
+			Metadata: &compute.Metadata{
+				Items: []*compute.MetadataItems{{Key: "cluster-name", Value: &clusterName}},
+			},
+			Disks: []*compute.AttachedDisk{
+				{Boot: true, AutoDelete: true, Source: "master-us-test1-a-boot"},
+				{Source: "legacy-etcd-cluster-example-com"},
+			},
+		},
+	}); err != nil {
+		t.Fatalf("error creating instance template: %v", err)
+	}
+	for _, disk := range []*compute.Disk{
+		{Name: "a-etcd-main-cluster-example-com", Labels: map[string]string{"k8s-io-cluster-name": "cluster-example-com"}},
+		{Name: "legacy-etcd-cluster-example-com"},
+		{Name: "master-us-test1-a-boot"},
+		{Name: "unrelated-disk"},
+	} {
+		if _, err := cloud.Compute().Disks().Insert("testproject", "us-test1-a", disk); err != nil {
+			t.Fatalf("error creating disk: %v", err)
+		}
+	}
+
+	d := &clusterDiscoveryGCE{
+		cloud:       cloud,
+		gceCloud:    cloud,
+		clusterName: clusterName,
+	}
+	resourceMap, err := runListFunctions(context.Background(), []gceListFn{d.listGCEDisks}, 1)
+	if err != nil {
+		t.Fatalf("error listing disks: %v", err)
+	}
+
+	var keys []string
+	for k := range resourceMap {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	expected := []string{"Disk:us-test1-a/a-etcd-main-cluster-example-com", "Disk:us-test1-a/legacy-etcd-cluster-example-com"}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("unexpected disks, got %v, expected %v", keys, expected)
+	}
+}
+
+func TestListDNSZonesWithVisibility(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	clusterName := "cluster.example.com"
+	if _, err := cloud.Compute().InstanceTemplates().Insert("testproject", &compute.InstanceTemplate{
+		Name: "nodes-cluster-example-com-1234",
+		Properties: &compute.InstanceProperties{
+			Metadata: &compute.Metadata{
+				Items: []*compute.MetadataItems{{Key: "cluster-name", Value: &clusterName}},
+			},
+			NetworkInterfaces: []*compute.NetworkInterface{
+				{Network: "https://www.googleapis.com/compute/v1/projects/testproject/global/networks/cluster-network"},
+			},
+		},
+	}); err != nil {
+		t.Fatalf("error creating instance template: %v", err)
+	}
+
+	dnsClient := cloud.CloudDNS().(*mockdns.MockClient)
+	zones := []*clouddns.ManagedZone{
+		{
+			Name:       "example-com-public",
+			DnsName:    "example.com.",
+			Visibility: "public",
+		},
+		{
+			Name:       "example-com-private",
+			DnsName:    "example.com.",
+			Visibility: "private",
+			PrivateVisibilityConfig: &clouddns.ManagedZonePrivateVisibilityConfig{
+				Networks: []*clouddns.ManagedZonePrivateVisibilityConfigNetwork{
+					{NetworkUrl: "https://www.googleapis.com/compute/v1/projects/testproject/global/networks/cluster-network"},
+				},
+			},
+		},
+		{
+			Name:       "example-com-private-other",
+			DnsName:    "example.com.",
+			Visibility: "private",
+			PrivateVisibilityConfig: &clouddns.ManagedZonePrivateVisibilityConfig{
+				Networks: []*clouddns.ManagedZonePrivateVisibilityConfigNetwork{
+					{NetworkUrl: "https://www.googleapis.com/compute/v1/projects/testproject/global/networks/other-network"},
+				},
+			},
+		},
+	}
+	for _, zone := range zones {
+		dnsClient.InsertManagedZone("testproject", zone)
+		dnsClient.InsertResourceRecordSet("testproject", zone.Name, &clouddns.ResourceRecordSet{Name: "api.cluster.example.com.", Type: "A"})
+	}
+
+	d := &clusterDiscoveryGCE{
+		cloud:       cloud,
+		gceCloud:    cloud,
+		clusterName: clusterName,
+	}
+	resourceTrackers, err := d.listGCEDNSZone(context.Background())
+	if err != nil {
+		t.Fatalf("error listing DNS records: %v", err)
+	}
+
+	var actual []string
+	for _, r := range resourceTrackers {
+		actual = append(actual, r.GroupKey+" "+r.Type+":"+r.ID)
+	}
+	sort.Strings(actual)
+	expected := []string{
+		"example-com-private DNSRecord:example-com-private/A/api.cluster.example.com.",
+		"example-com-public DNSRecord:example-com-public/A/api.cluster.example.com.",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected DNS records, got %v, expected %v", actual, expected)
+	}
+}
+
+func TestListDNSRecordsInDelegatedSubZone(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	dnsClient := cloud.CloudDNS().(*mockdns.MockClient)
+	dnsClient.InsertManagedZone("testproject", &clouddns.ManagedZone{
+		Name:    "example-com",
+		DnsName: "example.com.",
+	})
+	dnsClient.InsertManagedZone("testproject", &clouddns.ManagedZone{
+		Name:        "cluster-example-com",
+		DnsName:     "cluster.example.com.",
+		NameServers: []string{"ns-cloud-a1.googledomains.com.", "ns-cloud-a2.googledomains.com."},
+	})
+	for _, record := range []*clouddns.ResourceRecordSet{
+		// The delegation to the cluster sub-zone
+		{Name: "cluster.example.com.", Type: "NS", Rrdatas: []string{"ns-cloud-a2.googledomains.com.", "ns-cloud-a1.googledomains.com."}},
+		// A delegation of another cluster
+		{Name: "other.example.com.", Type: "NS", Rrdatas: []string{"ns-cloud-b1.googledomains.com."}},
+		// Shadowed by the sub-zone, so not served
+		{Name: "api.cluster.example.com.", Type: "A"},
+	} {
+		dnsClient.InsertResourceRecordSet("testproject", "example-com", record)
+	}
+	for _, record := range []*clouddns.ResourceRecordSet{
+		{Name: "cluster.example.com.", Type: "NS", Rrdatas: []string{"ns-cloud-a1.googledomains.com.", "ns-cloud-a2.googledomains.com."}},
+		{Name: "api.cluster.example.com.", Type: "A"},
+		{Name: "api.internal.cluster.example.com.", Type: "A"},
+	} {
+		dnsClient.InsertResourceRecordSet("testproject", "cluster-example-com", record)
+	}
+
+	d := &clusterDiscoveryGCE{
+		cloud:       cloud,
+		gceCloud:    cloud,
+		clusterName: "cluster.example.com",
+	}
+	resourceTrackers, err := d.listGCEDNSZone(context.Background())
+	if err != nil {
+		t.Fatalf("error listing DNS records: %v", err)
+	}
+
+	var actual []string
+	for _, r := range resourceTrackers {
+		actual = append(actual, r.GroupKey+" "+r.Type+":"+r.ID)
+	}
+	sort.Strings(actual)
+	expected := []string{
+		"cluster-example-com DNSRecord:cluster-example-com/A/api.cluster.example.com.",
+		"cluster-example-com DNSRecord:cluster-example-com/A/api.internal.cluster.example.com.",
+		"example-com DNSRecord:example-com/NS/cluster.example.com.",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected DNS records, got %v, expected %v", actual, expected)
+	}
+}
+
+func TestIsDNSNameInZone(t *testing.T) {
+	grid := []struct {
+		Name     string
+		Zone     string
+		Expected bool
+	}{
+		{Name: "cluster.example.com.", Zone: "example.com.", Expected: true},
+		{Name: "cluster.example.com", Zone: "Cluster.Example.com.", Expected: true},
+		{Name: "cluster.example.com.", Zone: "ample.com.", Expected: false},
+		{Name: "example.com.", Zone: "cluster.example.com.", Expected: false},
+	}
+	for _, g := range grid {
+		if actual := isDNSNameInZone(g.Name, g.Zone); actual != g.Expected {
+			t.Errorf("isDNSNameInZone(%q, %q) = %v, expected %v", g.Name, g.Zone, actual, g.Expected)
+		}
+	}
+}
+
+func TestListDNSRecordsIPv6(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	dnsClient := cloud.CloudDNS().(*mockdns.MockClient)
+	dnsClient.InsertManagedZone("testproject", &clouddns.ManagedZone{
+		Name:    "example-com",
+		DnsName: "example.com.",
+	})
+	for _, record := range []*clouddns.ResourceRecordSet{
+		{Name: "api.cluster.example.com.", Type: "A", Rrdatas: []string{"203.0.113.1"}},
+		{Name: "api.cluster.example.com.", Type: "AAAA", Rrdatas: []string{"2001:db8::1"}},
+		{Name: "bastion.cluster.example.com.", Type: "AAAA", Rrdatas: []string{"2001:db8::2"}},
+		{Name: "api.cluster.example.com.", Type: "TXT", Rrdatas: []string{"heritage=kops"}},
+		{Name: "www.example.com.", Type: "AAAA", Rrdatas: []string{"2001:db8::3"}},
+	} {
+		dnsClient.InsertResourceRecordSet("testproject", "example-com", record)
+	}
+
+	d := &clusterDiscoveryGCE{
+		cloud:       cloud,
+		gceCloud:    cloud,
+		clusterName: "cluster.example.com",
+	}
+	resourceTrackers, err := d.listGCEDNSZone(context.Background())
+	if err != nil {
+		t.Fatalf("error listing DNS records: %v", err)
+	}
+
+	var actual []string
+	for _, r := range resourceTrackers {
+		record := r.Obj.(*clouddns.ResourceRecordSet)
+		actual = append(actual, record.Type+" "+record.Name)
+	}
+	sort.Strings(actual)
+	expected := []string{
+		"A api.cluster.example.com.",
+		"AAAA api.cluster.example.com.",
+		"AAAA bastion.cluster.example.com.",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected DNS records, got %v, expected %v", actual, expected)
+	}
+}
+
+func TestListDNSRecordsNormalizesNames(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	dnsClient := cloud.CloudDNS().(*mockdns.MockClient)
+	dnsClient.InsertManagedZone("testproject", &clouddns.ManagedZone{
+		Name:    "example-com",
+		DnsName: "Example.COM.",
+	})
+	for _, record := range []*clouddns.ResourceRecordSet{
+		{Name: "API.Cluster.Example.com.", Type: "A", Rrdatas: []string{"203.0.113.1"}},
+		{Name: "bastion.cluster.example.com", Type: "A", Rrdatas: []string{"203.0.113.2"}},
+		{Name: "api.internal.cluster.example.com..", Type: "A", Rrdatas: []string{"10.0.0.1"}},
+		{Name: "API.other.example.com.", Type: "A", Rrdatas: []string{"203.0.113.3"}},
+	} {
+		dnsClient.InsertResourceRecordSet("testproject", "example-com", record)
+	}
+
+	d := &clusterDiscoveryGCE{
+		cloud:       cloud,
+		gceCloud:    cloud,
+		clusterName: "Cluster.example.com",
+	}
+	resourceTrackers, err := d.listGCEDNSZone(context.Background())
+	if err != nil {
+		t.Fatalf("error listing DNS records: %v", err)
+	}
+
+	var actual []string
+	for _, r := range resourceTrackers {
+		actual = append(actual, r.Name)
+	}
+	sort.Strings(actual)
+	expected := []string{
+		"API.Cluster.Example.com.",
+		"api.internal.cluster.example.com..",
+		"bastion.cluster.example.com",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected DNS records, got %v, expected %v", actual, expected)
+	}
+}
+
+func TestDeleteDNSRecordsInBatches(t *testing.T) {
+	defer func(n int) { maxDNSDeletionsPerChange = n }(maxDNSDeletionsPerChange)
+	maxDNSDeletionsPerChange = 2
+
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	var trackers []*resources.Resource
+	for i := 0; i < 5; i++ {
+		name := fmt.Sprintf("etcd-%d.internal.cluster.example.com.", i)
+		trackers = append(trackers, &resources.Resource{
+			Name:     name,
+			ID:       "example-com/A/" + name,
+			Type:     typeDNSRecord,
+			GroupKey: "example-com",
+			Obj:      &clouddns.ResourceRecordSet{Name: name, Type: "A"},
+		})
+	}
+
+	if err := deleteDNSRecords(cloud, trackers); err != nil {
+		t.Fatalf("error deleting DNS records: %v", err)
+	}
+
+	var actual [][]string
+	for _, change := range cloud.CloudDNS().(*mockdns.MockClient).CreatedChanges("testproject", "example-com") {
+		var names []string
+		for _, r := range change.Deletions {
+			names = append(names, r.Name)
+		}
+		actual = append(actual, names)
+	}
+	expected := [][]string{
+		{"etcd-0.internal.cluster.example.com.", "etcd-1.internal.cluster.example.com."},
+		{"etcd-2.internal.cluster.example.com.", "etcd-3.internal.cluster.example.com."},
+		{"etcd-4.internal.cluster.example.com."},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected DNS changes, got %v, expected %v", actual, expected)
+	}
+}
+
+func TestWaitForDNSChangeCancelled(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	created, err := cloud.CloudDNS().Changes().Create(context.Background(), "testproject", "example-com", &clouddns.Change{})
+	if err != nil {
+		t.Fatalf("error creating DNS change: %v", err)
+	}
+	// The change is never applied
+	created.Status = "pending"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	err = waitForDNSChange(ctx, cloud, "example-com", created)
+	if err == nil {
+		t.Fatalf("expected waiting for the DNS change to fail once the context is cancelled")
+	}
+	if elapsed := time.Since(start); elapsed >= dnsChangePollInterval {
+		t.Errorf("expected waiting for the DNS change to stop when the context is cancelled, took %v", elapsed)
+	}
+}
+
+func TestListResourcesSkipDNS(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	dnsClient := cloud.CloudDNS().(*mockdns.MockClient)
+	dnsClient.InsertManagedZone("testproject", &clouddns.ManagedZone{
+		Name:    "example-com",
+		DnsName: "example.com.",
+	})
+	dnsClient.InsertResourceRecordSet("testproject", "example-com", &clouddns.ResourceRecordSet{Name: "api.cluster.example.com.", Type: "A", Rrdatas: []string{"203.0.113.1"}})
+
+	for _, skipDNS := range []bool{false, true} {
+		resourceMap, err := ListResourcesGCEWithOptions(context.Background(), cloud, "cluster.example.com", "us-test1", ListResourcesGCEOptions{SkipDNS: skipDNS, QPS: testQPS})
+		if err != nil {
+			t.Fatalf("error listing resources: %v", err)
+		}
+
+		found := 0
+		for _, r := range resourceMap {
+			if r.Type == typeDNSRecord {
+				found++
+			}
+		}
+		expected := 1
+		if skipDNS {
+			expected = 0
+		}
+		if found != expected {
+			t.Errorf("unexpected number of DNS records with SkipDNS=%v, got %d, expected %d", skipDNS, found, expected)
+		}
+	}
+}
+
+func TestListDNSRecordsEtcdMembers(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	dnsClient := cloud.CloudDNS().(*mockdns.MockClient)
+	dnsClient.InsertManagedZone("testproject", &clouddns.ManagedZone{
+		Name:    "example-com",
+		DnsName: "example.com.",
+	})
+	for _, record := range []*clouddns.ResourceRecordSet{
+		{Name: "etcd-a.internal.cluster.example.com.", Type: "A", Rrdatas: []string{"10.0.0.1"}},
+		{Name: "etcd-b.internal.cluster.example.com.", Type: "A", Rrdatas: []string{"10.0.0.2"}},
+		{Name: "etcd-events-a.internal.cluster.example.com.", Type: "A", Rrdatas: []string{"10.0.0.1"}},
+		{Name: "kops-controller.internal.cluster.example.com.", Type: "A", Rrdatas: []string{"10.0.0.1"}},
+		{Name: "etcd-z.internal.cluster.example.com.", Type: "A", Rrdatas: []string{"10.0.0.9"}},
+		{Name: "etcd-a.internal.other.example.com.", Type: "A", Rrdatas: []string{"10.0.1.1"}},
+		{Name: "myapp.etcd-a.internal.cluster.example.com.", Type: "A", Rrdatas: []string{"10.0.0.10"}},
+	} {
+		dnsClient.InsertResourceRecordSet("testproject", "example-com", record)
+	}
+
+	cluster := &kops.Cluster{
+		Spec: kops.ClusterSpec{
+			EtcdClusters: []kops.EtcdClusterSpec{
+				{Name: "main", Members: []kops.EtcdMemberSpec{{Name: "a"}, {Name: "b"}}},
+				{Name: "events", Members: []kops.EtcdMemberSpec{{Name: "a"}}},
+			},
+		},
+	}
+
+	d := &clusterDiscoveryGCE{
+		cloud:           cloud,
+		gceCloud:        cloud,
+		clusterName:     "cluster.example.com",
+		dnsNamePrefixes: DNSNamePrefixesForCluster(cluster),
+	}
+	resourceTrackers, err := d.listGCEDNSZone(context.Background())
+	if err != nil {
+		t.Fatalf("error listing DNS records: %v", err)
+	}
+
+	var actual []string
+	for _, r := range resourceTrackers {
+		actual = append(actual, r.Name)
+	}
+	sort.Strings(actual)
+	expected := []string{
+		"etcd-a.internal.cluster.example.com.",
+		"etcd-b.internal.cluster.example.com.",
+		"etcd-events-a.internal.cluster.example.com.",
+		"kops-controller.internal.cluster.example.com.",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected DNS records, got %v, expected %v", actual, expected)
+	}
+}
+
+func TestListRouters(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	if _, err := cloud.Compute().Routers().Insert("testproject", "us-test1", &compute.Router{
+		Name: "nat-cluster-example-com",
+		Nats: []*compute.RouterNat{{Name: "nat-cluster-example-com"}},
+	}); err != nil {
+		t.Fatalf("error creating router: %v", err)
+	}
+	if _, err := cloud.Compute().Routers().Insert("testproject", "us-test1", &compute.Router{
+		Name: "shared-router",
+		Nats: []*compute.RouterNat{
+			{Name: "nat-cluster-example-com"},
+			{Name: "nat-other-example-com"},
+		},
+	}); err != nil {
+		t.Fatalf("error creating router: %v", err)
+	}
+
+	d := &clusterDiscoveryGCE{
+		cloud:       cloud,
+		gceCloud:    cloud,
+		clusterName: "cluster.example.com",
+	}
+	resourceMap, err := runListFunctions(context.Background(), []gceListFn{d.listRouters}, 1)
+	if err != nil {
+		t.Fatalf("error listing routers: %v", err)
+	}
+
+	var keys []string
+	for k := range resourceMap {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	expected := []string{
+		"Router:us-test1/nat-cluster-example-com",
+		"RouterNAT:us-test1/shared-router/nat-cluster-example-com",
+	}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Fatalf("unexpected routers, got %v, expected %v", keys, expected)
+	}
+
+	nat := resourceMap["RouterNAT:us-test1/shared-router/nat-cluster-example-com"]
+	if err := nat.Deleter(cloud, nat); err != nil {
+		t.Fatalf("error deleting NAT: %v", err)
+	}
+
+	router, err := cloud.Compute().Routers().Get("testproject", "us-test1", "shared-router")
+	if err != nil {
+		t.Fatalf("error getting router: %v", err)
+	}
+	var remaining []string
+	for _, n := range router.Nats {
+		remaining = append(remaining, n.Name)
+	}
+	if !reflect.DeepEqual(remaining, []string{"nat-other-example-com"}) {
+		t.Errorf("unexpected NATs remaining on shared router: %v", remaining)
+	}
+}
+
+func TestListRouterPeers(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	if _, err := cloud.Compute().Routers().Insert("testproject", "us-test1", &compute.Router{
+		Name: "interconnect-router",
+		Nats: []*compute.RouterNat{{Name: "shared-nat"}},
+		BgpPeers: []*compute.RouterBgpPeer{
+			{Name: "peer-cluster-example-com", InterfaceName: "if-cluster-example-com"},
+			{Name: "peer-other-example-com", InterfaceName: "if-other-example-com"},
+		},
+	}); err != nil {
+		t.Fatalf("error creating router: %v", err)
+	}
+	if _, err := cloud.Compute().Routers().Insert("testproject", "us-test1", &compute.Router{
+		Name:     "vpn-cluster-example-com",
+		BgpPeers: []*compute.RouterBgpPeer{{Name: "peer-cluster-example-com"}},
+	}); err != nil {
+		t.Fatalf("error creating router: %v", err)
+	}
+
+	d := &clusterDiscoveryGCE{
+		cloud:       cloud,
+		gceCloud:    cloud,
+		clusterName: "cluster.example.com",
+	}
+	resourceMap, err := runListFunctions(context.Background(), []gceListFn{d.listRouters}, 1)
+	if err != nil {
+		t.Fatalf("error listing routers: %v", err)
+	}
+
+	var keys []string
+	for k := range resourceMap {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	// The peers of a cluster-owned router go away with the router
+	expected := []string{
+		"Router:us-test1/vpn-cluster-example-com",
+		"RouterPeer:us-test1/interconnect-router/peer-cluster-example-com",
+	}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Fatalf("unexpected routers, got %v, expected %v", keys, expected)
+	}
+
+	peer := resourceMap["RouterPeer:us-test1/interconnect-router/peer-cluster-example-com"]
+	if err := peer.Deleter(cloud, peer); err != nil {
+		t.Fatalf("error deleting BGP peer: %v", err)
+	}
+
+	router, err := cloud.Compute().Routers().Get("testproject", "us-test1", "interconnect-router")
+	if err != nil {
+		t.Fatalf("error getting router: %v", err)
+	}
+	var remaining []string
+	for _, p := range router.BgpPeers {
+		remaining = append(remaining, p.Name)
+	}
+	if !reflect.DeepEqual(remaining, []string{"peer-other-example-com"}) {
+		t.Errorf("unexpected BGP peers remaining on shared router: %v", remaining)
+	}
+	if len(router.Nats) != 1 {
+		t.Errorf("expected the NAT on the shared router to be kept, got %v", router.Nats)
+	}
+}
+
+func TestListRouterAdvertisements(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	if _, err := cloud.Compute().Routers().Insert("testproject", "us-test1", &compute.Router{
+		Name: "shared-router",
+		Bgp: &compute.RouterBgp{
+			Asn:           64512,
+			AdvertiseMode: "CUSTOM",
+			AdvertisedIpRanges: []*compute.RouterAdvertisedIpRange{
+				{Range: "100.96.0.0/11", Description: "pods"},
+				{Range: "10.0.0.0/16", Description: "shared"},
+			},
+		},
+	}); err != nil {
+		t.Fatalf("error creating router: %v", err)
+	}
+	if _, err := cloud.Compute().Routers().Insert("testproject", "us-test1", &compute.Router{
+		Name: "nat-cluster-example-com",
+		Bgp: &compute.RouterBgp{
+			AdvertisedIpRanges: []*compute.RouterAdvertisedIpRange{{Range: "100.96.0.0/11"}},
+		},
+	}); err != nil {
+		t.Fatalf("error creating router: %v", err)
+	}
+
+	d := &clusterDiscoveryGCE{
+		cloud:              cloud,
+		gceCloud:           cloud,
+		clusterName:        "cluster.example.com",
+		advertisedIPRanges: []string{"100.96.0.0/11"},
+	}
+	resourceMap, err := runListFunctions(context.Background(), []gceListFn{d.listRouters}, 1)
+	if err != nil {
+		t.Fatalf("error listing routers: %v", err)
+	}
+
+	var keys []string
+	for k := range resourceMap {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	// The advertisements of a cluster-owned router go away with the router
+	expected := []string{
+		"Router:us-test1/nat-cluster-example-com",
+		"RouterAdvertisement:us-test1/shared-router/100.96.0.0/11",
+	}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Fatalf("unexpected routers, got %v, expected %v", keys, expected)
+	}
+
+	advertisement := resourceMap["RouterAdvertisement:us-test1/shared-router/100.96.0.0/11"]
+	if err := advertisement.Deleter(cloud, advertisement); err != nil {
+		t.Fatalf("error deleting advertised IP range: %v", err)
+	}
+
+	router, err := cloud.Compute().Routers().Get("testproject", "us-test1", "shared-router")
+	if err != nil {
+		t.Fatalf("error getting router: %v", err)
+	}
+	var remaining []string
+	for _, r := range router.Bgp.AdvertisedIpRanges {
+		remaining = append(remaining, r.Range)
+	}
+	if !reflect.DeepEqual(remaining, []string{"10.0.0.0/16"}) {
+		t.Errorf("unexpected advertised IP ranges remaining on shared router: %v", remaining)
+	}
+	if router.Bgp.Asn != 64512 || router.Bgp.AdvertiseMode != "CUSTOM" {
+		t.Errorf("expected the BGP settings of the shared router to be kept, got %+v", router.Bgp)
+	}
+}
+
+func TestDryRunDoesNotDelete(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	if _, err := cloud.Compute().TargetPools().Insert("testproject", "us-test1", &compute.TargetPool{Name: "api-cluster-example-com"}); err != nil {
+		t.Fatalf("error creating target pool: %v", err)
+	}
+	if _, err := cloud.Compute().Addresses().Insert("testproject", "us-test1", &compute.Address{Name: "api-cluster-example-com"}); err != nil {
+		t.Fatalf("error creating address: %v", err)
+	}
+	dnsClient := cloud.CloudDNS().(*mockdns.MockClient)
+	dnsClient.InsertManagedZone("testproject", &clouddns.ManagedZone{
+		Name:    "example-com",
+		DnsName: "example.com.",
+	})
+	dnsClient.InsertResourceRecordSet("testproject", "example-com", &clouddns.ResourceRecordSet{
+		Name: "api.cluster.example.com.", Type: "A", Rrdatas: []string{"203.0.113.1"},
+	})
+
+	resourceMap, err := ListResourcesGCEWithOptions(context.Background(), cloud, "cluster.example.com", "us-test1", ListResourcesGCEOptions{DryRun: true, QPS: testQPS})
+	if err != nil {
+		t.Fatalf("error listing resources: %v", err)
+	}
+	if len(resourceMap) != 3 {
+		t.Fatalf("expected 3 resources, got %v", resourceMap)
+	}
+
+	before := len(cloud.Compute().(*mockcompute.MockClient).AllResources())
+	for _, r := range resourceMap {
+		if r.GroupDeleter != nil {
+			err = r.GroupDeleter(cloud, []*resources.Resource{r})
+		} else {
+			err = r.Deleter(cloud, r)
+		}
+		if err != nil {
+			t.Fatalf("error from dry-run deleter for %s:%s: %v", r.Type, r.ID, err)
+		}
+	}
+
+	if after := len(cloud.Compute().(*mockcompute.MockClient).AllResources()); after != before {
+		t.Errorf("dry-run deleted compute resources, had %d, now %d", before, after)
+	}
+	records, err := dnsClient.ResourceRecordSets().List(context.Background(), "testproject", "example-com")
+	if err != nil {
+		t.Fatalf("error listing DNS records: %v", err)
+	}
+	if len(records) != 1 {
+		t.Errorf("dry-run deleted DNS records, now have %v", records)
+	}
+}
+
+func TestListInstanceGroups(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	for _, name := range []string{"nodes-cluster-example-com", "legacy-cluster-example-com", "legacy-other-example-com"} {
+		if _, err := cloud.Compute().InstanceGroups().Insert("testproject", "us-test1-a", &compute.InstanceGroup{Name: name}); err != nil {
+			t.Fatalf("error creating instance group: %v", err)
+		}
+	}
+	if _, err := cloud.Compute().InstanceGroupManagers().Insert("testproject", "us-test1-a", &compute.InstanceGroupManager{Name: "nodes-cluster-example-com"}); err != nil {
+		t.Fatalf("error creating instance group manager: %v", err)
+	}
+	if _, err := cloud.Compute().BackendServices().Insert("testproject", &compute.BackendService{
+		Name: "api-cluster-example-com",
+		Backends: []*compute.Backend{
+			{Group: "https://www.googleapis.com/compute/v1/projects/testproject/zones/us-test1-a/instanceGroups/legacy-cluster-example-com"},
+		},
+	}); err != nil {
+		t.Fatalf("error creating backend service: %v", err)
+	}
+
+	d := &clusterDiscoveryGCE{
+		cloud:       cloud,
+		gceCloud:    cloud,
+		clusterName: "cluster.example.com",
+		zones:       []string{"us-test1-a"},
+	}
+	resourceMap, err := runListFunctions(context.Background(), []gceListFn{d.listInstanceGroups}, 1)
+	if err != nil {
+		t.Fatalf("error listing instance groups: %v", err)
+	}
+
+	expected := map[string][]string{
+		"InstanceGroup:us-test1-a/legacy-cluster-example-com": {"BackendService:api-cluster-example-com"},
+	}
+	actual := make(map[string][]string)
+	for k, r := range resourceMap {
+		actual[k] = r.Blocked
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected instance groups, got %v, expected %v", actual, expected)
+	}
+}
+
+func TestListGlobalAddresses(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	if _, err := cloud.Compute().GlobalAddresses().Insert("testproject", &compute.Address{Name: "ingress-cluster-example-com"}); err != nil {
+		t.Fatalf("error creating global address: %v", err)
+	}
+	if _, err := cloud.Compute().GlobalAddresses().Insert("testproject", &compute.Address{Name: "ingress-other-example-com"}); err != nil {
+		t.Fatalf("error creating global address: %v", err)
+	}
+	if _, err := cloud.Compute().GlobalForwardingRules().Insert("testproject", &compute.ForwardingRule{
+		Name:      "ingress-cluster-example-com",
+		IPAddress: "https://www.googleapis.com/compute/v1/projects/testproject/global/addresses/ingress-cluster-example-com",
+	}); err != nil {
+		t.Fatalf("error creating global forwarding rule: %v", err)
+	}
+
+	resourceMap, err := listResourcesForTest(cloud, "cluster.example.com", "us-test1")
+	if err != nil {
+		t.Fatalf("error listing resources: %v", err)
+	}
+
+	address := resourceMap["Address:ingress-cluster-example-com"]
+	if address == nil {
+		t.Fatalf("expected global address to be tracked, got %v", resourceMap)
+	}
+	if _, found := resourceMap["Address:ingress-other-example-com"]; found {
+		t.Errorf("global address for another cluster should not be tracked")
+	}
+
+	fr := resourceMap["ForwardingRule:ingress-cluster-example-com"]
+	if fr == nil {
+		t.Fatalf("expected global forwarding rule to be tracked, got %v", resourceMap)
+	}
+	if !reflect.DeepEqual(fr.Blocks, []string{"Address:ingress-cluster-example-com"}) {
+		t.Errorf("unexpected blocks for global forwarding rule: %v", fr.Blocks)
+	}
+
+	if err := address.Deleter(cloud, address); err != nil {
+		t.Fatalf("error deleting global address: %v", err)
+	}
+	if _, err := cloud.Compute().GlobalAddresses().Get("testproject", "ingress-cluster-example-com"); err == nil {
+		t.Errorf("expected global address to be deleted")
+	}
+}
+
+func TestListFirewallRules(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	for _, fr := range []*compute.Firewall{
+		// Sorted first: the name matches, but the rule does not apply to the cluster instances
+		{Name: "a-cluster-example-com", TargetTags: []string{"other-example-com-k8s-io-role-node"}},
+		{Name: "node-to-node-cluster-example-com", TargetTags: []string{"cluster-example-com-k8s-io-role-node"}},
+		{Name: "ssh-external-to-node-cluster-example-com", SourceTags: []string{"cluster-example-com-k8s-io-role-node"}},
+		{Name: "untagged-cluster-example-com"},
+	} {
+		if _, err := cloud.Compute().Firewalls().Insert("testproject", fr); err != nil {
+			t.Fatalf("error creating firewall rule: %v", err)
+		}
+	}
+
+	d := &clusterDiscoveryGCE{
+		cloud:       cloud,
+		gceCloud:    cloud,
+		clusterName: "cluster.example.com",
+	}
+	resourceTrackers, err := d.listFirewallRules(context.Background())
+	if err != nil {
+		t.Fatalf("error listing firewall rules: %v", err)
+	}
+
+	var actual []string
+	for _, r := range resourceTrackers {
+		actual = append(actual, r.Type+":"+r.ID)
+	}
+	sort.Strings(actual)
+	expected := []string{
+		"FirewallRule:node-to-node-cluster-example-com",
+		"FirewallRule:ssh-external-to-node-cluster-example-com",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected firewall rules, got %v, expected %v", actual, expected)
+	}
+}
+
+func TestListFirewallRulesInClusterNetwork(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	clusterName := "cluster.example.com"
+	clusterNetwork := "https://www.googleapis.com/compute/v1/projects/testproject/global/networks/cluster-network"
+	if _, err := cloud.Compute().InstanceTemplates().Insert("testproject", &compute.InstanceTemplate{
+		Name: "nodes-cluster-example-com-1234",
+		Properties: &compute.InstanceProperties{
+			Metadata: &compute.Metadata{
+				Items: []*compute.MetadataItems{{Key: "cluster-name", Value: &clusterName}},
+			},
+			NetworkInterfaces: []*compute.NetworkInterface{
+				{Network: clusterNetwork},
+			},
+		},
+	}); err != nil {
+		t.Fatalf("error creating instance template: %v", err)
+	}
+
+	for _, fr := range []*compute.Firewall{
+		{Name: "allow-monitoring", Network: clusterNetwork, TargetTags: []string{"cluster-example-com-k8s-io-role-node"}},
+		{Name: "allow-shared", Network: clusterNetwork, TargetTags: []string{"cluster-example-com-k8s-io-role-node", "bastion"}},
+		// The nodes of another cluster, whose name starts with ours, in the same network
+		{Name: "allow-other-cluster", Network: clusterNetwork, TargetTags: []string{"cluster-example-com-au-k8s-io-role-node"}},
+		{Name: "allow-everything", Network: clusterNetwork},
+		{Name: "allow-elsewhere", Network: "https://www.googleapis.com/compute/v1/projects/testproject/global/networks/default", TargetTags: []string{"cluster-example-com-k8s-io-role-node"}},
+	} {
+		if _, err := cloud.Compute().Firewalls().Insert("testproject", fr); err != nil {
+			t.Fatalf("error creating firewall rule: %v", err)
+		}
+	}
+
+	d := &clusterDiscoveryGCE{
+		cloud:       cloud,
+		gceCloud:    cloud,
+		clusterName: clusterName,
+	}
+	resourceTrackers, err := d.listFirewallRules(context.Background())
+	if err != nil {
+		t.Fatalf("error listing firewall rules: %v", err)
+	}
+
+	var actual []string
+	for _, r := range resourceTrackers {
+		actual = append(actual, r.Type+":"+r.ID)
+	}
+	if expected := []string{"FirewallRule:allow-monitoring"}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected firewall rules, got %v, expected %v", actual, expected)
+	}
+}
+
+func TestListFirewallRulesEgress(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	clusterName := "cluster.example.com"
+	clusterNetwork := "https://www.googleapis.com/compute/v1/projects/testproject/global/networks/cluster-network"
+	if _, err := cloud.Compute().InstanceTemplates().Insert("testproject", &compute.InstanceTemplate{
+		Name: "nodes-cluster-example-com-1234",
+		Properties: &compute.InstanceProperties{
+			Metadata: &compute.Metadata{
+				Items: []*compute.MetadataItems{{Key: "cluster-name", Value: &clusterName}},
+			},
+			NetworkInterfaces: []*compute.NetworkInterface{
+				{Network: clusterNetwork},
+			},
+		},
+	}); err != nil {
+		t.Fatalf("error creating instance template: %v", err)
+	}
+
+	for _, fr := range []*compute.Firewall{
+		{Name: "egress-to-internet-cluster-example-com", Network: clusterNetwork, Direction: "EGRESS", DestinationRanges: []string{"0.0.0.0/0"}},
+		{Name: "egress-elsewhere-cluster-example-com", Network: "https://www.googleapis.com/compute/v1/projects/testproject/global/networks/default", Direction: "EGRESS"},
+		{Name: "deny-egress-cluster-example-com", Network: clusterNetwork, Direction: "EGRESS", TargetTags: []string{"other-example-com-k8s-io-role-node"}},
+		{Name: "ingress-untagged-cluster-example-com", Network: clusterNetwork, Direction: "INGRESS"},
+	} {
+		if _, err := cloud.Compute().Firewalls().Insert("testproject", fr); err != nil {
+			t.Fatalf("error creating firewall rule: %v", err)
+		}
+	}
+
+	d := &clusterDiscoveryGCE{
+		cloud:       cloud,
+		gceCloud:    cloud,
+		clusterName: clusterName,
+	}
+	resourceTrackers, err := d.listFirewallRules(context.Background())
+	if err != nil {
+		t.Fatalf("error listing firewall rules: %v", err)
+	}
+
+	var actual []string
+	for _, r := range resourceTrackers {
+		actual = append(actual, r.Type+":"+r.ID)
+	}
+	if expected := []string{"FirewallRule:egress-to-internet-cluster-example-com"}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected firewall rules, got %v, expected %v", actual, expected)
+	}
+}
+
+// TestListFirewallRulesFromModel checks that we match every firewall rule the GCE model creates for a cluster
+func TestListFirewallRulesFromModel(t *testing.T) {
+	for _, clusterName := range []string{
+		"cluster.example.com",
+		"dev.k8s.example.com",
+	} {
+		t.Run(clusterName, func(t *testing.T) {
+			cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+			cluster := &kops.Cluster{}
+			cluster.ObjectMeta.Name = clusterName
+			cluster.Spec.NonMasqueradeCIDR = "100.64.0.0/10"
+			cluster.Spec.SSHAccess = []string{"0.0.0.0/0", "::/0"}
+			cluster.Spec.KubernetesAPIAccess = []string{"0.0.0.0/0"}
+			cluster.Spec.NodePortAccess = []string{"1.2.3.4/32"}
+			cluster.Spec.API = &kops.AccessSpec{
+				LoadBalancer: &kops.LoadBalancerAccessSpec{Type: kops.LoadBalancerTypePublic},
+			}
+
+			modelContext := &gcemodel.GCEModelContext{
+				KopsModelContext: &model.KopsModelContext{
+					IAMModelContext: iam.IAMModelContext{Cluster: cluster},
+					Region:          "us-test1",
+				},
+			}
+			c := &fi.ModelBuilderContext{Tasks: make(map[string]fi.Task)}
+			for _, builder := range []fi.ModelBuilder{
+				&gcemodel.FirewallModelBuilder{GCEModelContext: modelContext},
+				&gcemodel.ExternalAccessModelBuilder{GCEModelContext: modelContext},
+				&gcemodel.APILoadBalancerBuilder{GCEModelContext: modelContext},
+			} {
+				if err := builder.Build(c); err != nil {
+					t.Fatalf("error building model: %v", err)
+				}
+			}
+
+			var expected []string
+			target := gce.NewGCEAPITarget(cloud)
+			for _, task := range c.Tasks {
+				fr, ok := task.(*gcetasks.FirewallRule)
+				if !ok {
+					continue
+				}
+				if err := fr.RenderGCE(target, nil, fr, nil); err != nil {
+					t.Fatalf("error creating firewall rule: %v", err)
+				}
+				expected = append(expected, typeFirewallRule+":"+*fr.Name)
+			}
+			if len(expected) == 0 {
+				t.Fatalf("model did not create any firewall rules")
+			}
+			sort.Strings(expected)
+
+			d := &clusterDiscoveryGCE{
+				cloud:       cloud,
+				gceCloud:    cloud,
+				clusterName: clusterName,
+			}
+			resourceTrackers, err := d.listFirewallRules(context.Background())
+			if err != nil {
+				t.Fatalf("error listing firewall rules: %v", err)
+			}
+
+			var actual []string
+			for _, r := range resourceTrackers {
+				actual = append(actual, r.Type+":"+r.ID)
+			}
+			sort.Strings(actual)
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("unexpected firewall rules, got %v, expected %v", actual, expected)
+			}
+		})
+	}
+}
+
+func TestListResourcesTypeSelection(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	if _, err := cloud.Compute().Disks().Insert("testproject", "us-test1-a", &compute.Disk{
+		Name:   "a-etcd-main-cluster-example-com",
+		Labels: map[string]string{"k8s-io-cluster-name": "cluster-example-com"},
+	}); err != nil {
+		t.Fatalf("error creating disk: %v", err)
+	}
+	if _, err := cloud.Compute().TargetPools().Insert("testproject", "us-test1", &compute.TargetPool{Name: "api-cluster-example-com"}); err != nil {
+		t.Fatalf("error creating target pool: %v", err)
+	}
+	if _, err := cloud.Compute().Addresses().Insert("testproject", "us-test1", &compute.Address{Name: "api-cluster-example-com"}); err != nil {
+		t.Fatalf("error creating address: %v", err)
+	}
+
+	grid := []struct {
+		Name     string
+		Options  ListResourcesGCEOptions
+		Expected []string
+	}{
+		{
+			Name:     "all types",
+			Expected: []string{"Address:us-test1/api-cluster-example-com", "Disk:us-test1-a/a-etcd-main-cluster-example-com", "TargetPool:us-test1/api-cluster-example-com"},
+		},
+		{
+			Name:     "include only",
+			Options:  ListResourcesGCEOptions{IncludeTypes: []string{"Disk"}},
+			Expected: []string{"Disk:us-test1-a/a-etcd-main-cluster-example-com"},
+		},
+		{
+			Name:     "exclude only",
+			Options:  ListResourcesGCEOptions{ExcludeTypes: []string{"Disk", "Address"}},
+			Expected: []string{"TargetPool:us-test1/api-cluster-example-com"},
+		},
+	}
+	for _, g := range grid {
+		t.Run(g.Name, func(t *testing.T) {
+			g.Options.QPS = testQPS
+			resourceMap, err := ListResourcesGCEWithOptions(context.Background(), cloud, "cluster.example.com", "us-test1", g.Options)
+			if err != nil {
+				t.Fatalf("error listing resources: %v", err)
+			}
+			var keys []string
+			for k := range resourceMap {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			if !reflect.DeepEqual(keys, g.Expected) {
+				t.Errorf("unexpected resources, got %v, expected %v", keys, g.Expected)
+			}
+		})
+	}
+
+	_, err := ListResourcesGCEWithOptions(context.Background(), cloud, "cluster.example.com", "us-test1", ListResourcesGCEOptions{IncludeTypes: []string{"Disks"}, QPS: testQPS})
+	if err == nil || !strings.Contains(err.Error(), `unknown resource type "Disks"`) || !strings.Contains(err.Error(), "Disk, ") {
+		t.Errorf("expected error listing valid types, got %v", err)
+	}
+}
+
+func TestListSslCertificates(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	if _, err := cloud.Compute().SslCertificates().Insert("testproject", &compute.SslCertificate{Name: "ingress-cluster-example-com"}); err != nil {
+		t.Fatalf("error creating ssl certificate: %v", err)
+	}
+	if _, err := cloud.Compute().RegionSslCertificates().Insert("testproject", "us-test1", &compute.SslCertificate{Name: "internal-cluster-example-com"}); err != nil {
+		t.Fatalf("error creating regional ssl certificate: %v", err)
+	}
+	// Named as the global certificate, which it must not be confused with
+	if _, err := cloud.Compute().RegionSslCertificates().Insert("testproject", "us-test1", &compute.SslCertificate{Name: "ingress-cluster-example-com"}); err != nil {
+		t.Fatalf("error creating regional ssl certificate: %v", err)
+	}
+	if _, err := cloud.Compute().SslCertificates().Insert("testproject", &compute.SslCertificate{Name: "ingress-other-example-com"}); err != nil {
+		t.Fatalf("error creating ssl certificate: %v", err)
+	}
+	if _, err := cloud.Compute().TargetHttpsProxies().Insert("testproject", &compute.TargetHttpsProxy{
+		Name:            "ingress-cluster-example-com",
+		SslCertificates: []string{"https://www.googleapis.com/compute/v1/projects/testproject/global/sslCertificates/ingress-cluster-example-com"},
+	}); err != nil {
+		t.Fatalf("error creating target https proxy: %v", err)
+	}
+
+	d := &clusterDiscoveryGCE{
+		cloud:       cloud,
+		gceCloud:    cloud,
+		clusterName: "cluster.example.com",
+	}
+	resourceMap, err := runListFunctions(context.Background(), []gceListFn{d.listTargetHttpsProxies, d.listSslCertificates}, 1)
+	if err != nil {
+		t.Fatalf("error listing ssl certificates: %v", err)
+	}
+
+	expected := map[string][]string{
+		"TargetHttpsProxy:ingress-cluster-example-com":         nil,
+		"SslCertificate:ingress-cluster-example-com":           {"TargetHttpsProxy:ingress-cluster-example-com"},
+		"SslCertificate:us-test1/ingress-cluster-example-com":  nil,
+		"SslCertificate:us-test1/internal-cluster-example-com": nil,
+	}
+	actual := make(map[string][]string)
+	for k, r := range resourceMap {
+		actual[k] = r.Blocked
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("unexpected resources, got %v, expected %v", actual, expected)
+	}
+
+	proxy := resourceMap["TargetHttpsProxy:ingress-cluster-example-com"]
+	if !reflect.DeepEqual(proxy.Blocks, []string{"SslCertificate:ingress-cluster-example-com"}) {
+		t.Errorf("unexpected blocks for target https proxy: %v", proxy.Blocks)
+	}
+
+	cert := resourceMap["SslCertificate:us-test1/internal-cluster-example-com"]
+	if err := cert.Deleter(cloud, cert); err != nil {
+		t.Fatalf("error deleting regional ssl certificate: %v", err)
+	}
+	if _, err := cloud.Compute().RegionSslCertificates().Get("testproject", "us-test1", "internal-cluster-example-com"); err == nil {
+		t.Errorf("expected regional ssl certificate to be deleted")
+	}
+}
+
+func TestListNetworks(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	for _, name := range []string{"default", "cluster-example-com"} {
+		if _, err := cloud.Compute().Networks().Insert("testproject", &compute.Network{Name: name}); err != nil {
+			t.Fatalf("error creating network: %v", err)
+		}
+	}
+	if _, err := cloud.Compute().Firewalls().Insert("testproject", &compute.Firewall{
+		Name:       "nodeport-external-to-node-cluster-example-com",
+		TargetTags: []string{"cluster-example-com-k8s-io-role-node"},
+	}); err != nil {
+		t.Fatalf("error creating firewall: %v", err)
+	}
+	if _, err := cloud.Compute().Routers().Insert("testproject", "us-test1", &compute.Router{Name: "nat-cluster-example-com"}); err != nil {
+		t.Fatalf("error creating router: %v", err)
+	}
+
+	resourceMap, err := listResourcesForTest(cloud, "cluster.example.com", "us-test1")
+	if err != nil {
+		t.Fatalf("error listing resources: %v", err)
+	}
+
+	if _, found := resourceMap["Network:default"]; found {
+		t.Errorf("default network should not be tracked")
+	}
+	network := resourceMap["Network:cluster-example-com"]
+	if network == nil {
+		t.Fatalf("expected cluster network to be tracked, got %v", resourceMap)
+	}
+	expected := []string{"FirewallRule:nodeport-external-to-node-cluster-example-com", "Router:us-test1/nat-cluster-example-com"}
+	if !reflect.DeepEqual(network.Blocked, expected) {
+		t.Errorf("unexpected blocked for network, got %v, expected %v", network.Blocked, expected)
+	}
+	for k, r := range resourceMap {
+		for _, b := range r.Blocks {
+			if b == "Network:cluster-example-com" {
+				t.Errorf("network should be deleted last, but must be deleted before %q", k)
+			}
+		}
+	}
+}
+
+func TestListResourcesHostProject(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	// The cluster network is in the Shared VPC host project
+	network := &compute.Network{Name: "cluster-example-com"}
+	if _, err := cloud.Compute().Networks().Insert("hostproject", network); err != nil {
+		t.Fatalf("error creating network: %v", err)
+	}
+	subnet := &compute.Subnetwork{Name: "subnet-cluster-example-com", Network: network.SelfLink}
+	if _, err := cloud.Compute().Subnetworks().Insert("hostproject", "us-test1", subnet); err != nil {
+		t.Fatalf("error creating subnetwork: %v", err)
+	}
+	if _, err := cloud.Compute().Firewalls().Insert("hostproject", &compute.Firewall{
+		Name:       "node-to-node-cluster-example-com",
+		Network:    network.SelfLink,
+		TargetTags: []string{"cluster-example-com-k8s-io-role-node"},
+	}); err != nil {
+		t.Fatalf("error creating firewall rule: %v", err)
+	}
+	if _, err := cloud.Compute().Routers().Insert("hostproject", "us-test1", &compute.Router{
+		Name:    "nat-cluster-example-com",
+		Network: network.SelfLink,
+	}); err != nil {
+		t.Fatalf("error creating router: %v", err)
+	}
+
+	// The instances and their disks are in the service project
+	clusterName := "cluster.example.com"
+	if _, err := cloud.Compute().InstanceTemplates().Insert("testproject", &compute.InstanceTemplate{
+		Name: "nodes-cluster-example-com-1234",
+		Properties: &compute.InstanceProperties{
+			Metadata: &compute.Metadata{
+				Items: []*compute.MetadataItems{{Key: "cluster-name", Value: &clusterName}},
+			},
+			NetworkInterfaces: []*compute.NetworkInterface{{Network: network.SelfLink, Subnetwork: subnet.SelfLink}},
+		},
+	}); err != nil {
+		t.Fatalf("error creating instance template: %v", err)
+	}
+	if _, err := cloud.Compute().Disks().Insert("testproject", "us-test1-a", &compute.Disk{
+		Name:   "a-etcd-main-cluster-example-com",
+		Labels: map[string]string{"k8s-io-cluster-name": "cluster-example-com"},
+	}); err != nil {
+		t.Fatalf("error creating disk: %v", err)
+	}
+
+	options := ListResourcesGCEOptions{
+		QPS:          testQPS,
+		HostProject:  "hostproject",
+		IncludeTypes: []string{typeDisk, typeFirewallRule, typeInstanceTemplate, typeNetwork, typeRouter, typeSubnet},
+	}
+	resourceMap, err := ListResourcesGCEWithOptions(context.Background(), cloud, clusterName, "us-test1", options)
+	if err != nil {
+		t.Fatalf("error listing resources: %v", err)
+	}
+
+	var keys []string
+	for k := range resourceMap {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	expected := []string{
+		"Disk:us-test1-a/a-etcd-main-cluster-example-com",
+		"FirewallRule:node-to-node-cluster-example-com",
+		"InstanceTemplate:nodes-cluster-example-com-1234",
+		"Network:cluster-example-com",
+		"Router:us-test1/nat-cluster-example-com",
+		"Subnet:us-test1/subnet-cluster-example-com",
+	}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Fatalf("unexpected resources, got %v, expected %v", keys, expected)
+	}
+
+	if err := DeleteResources(context.Background(), cloud, resourceMap); err != nil {
+		t.Fatalf("error deleting resources: %v", err)
+	}
+	if _, err := cloud.Compute().Networks().Get("hostproject", network.Name); !gce.IsNotFound(err) {
+		t.Errorf("expected network to be deleted from the host project, got %v", err)
+	}
+	if _, err := cloud.Compute().Firewalls().Get("hostproject", "node-to-node-cluster-example-com"); !gce.IsNotFound(err) {
+		t.Errorf("expected firewall rule to be deleted from the host project, got %v", err)
+	}
+}
+
+func TestListNetworkPeerings(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	if _, err := cloud.Compute().Networks().Insert("testproject", &compute.Network{
+		Name: "cluster-example-com",
+		Peerings: []*compute.NetworkPeering{
+			{Name: "sql-cluster-example-com", Network: "https://www.googleapis.com/compute/v1/projects/services/global/networks/sql"},
+			// Private service access peerings are shared by everything in the network
+			{Name: "servicenetworking-googleapis-com", Network: "https://www.googleapis.com/compute/v1/projects/services/global/networks/servicenetworking"},
+		},
+	}); err != nil {
+		t.Fatalf("error creating network: %v", err)
+	}
+
+	resourceMap, err := listResourcesForTest(cloud, "cluster.example.com", "us-test1")
+	if err != nil {
+		t.Fatalf("error listing resources: %v", err)
+	}
+
+	peering := resourceMap["NetworkPeering:cluster-example-com/sql-cluster-example-com"]
+	if peering == nil {
+		t.Fatalf("expected network peering to be tracked, got %v", resourceMap)
+	}
+	if _, found := resourceMap["NetworkPeering:cluster-example-com/servicenetworking-googleapis-com"]; found {
+		t.Errorf("shared network peering should not be tracked")
+	}
+
+	// The peering must be removed before the network
+	var order []string
+	for _, r := range SortedResources(resourceMap) {
+		order = append(order, r.Type+":"+r.ID)
+	}
+	expected := []string{"NetworkPeering:cluster-example-com/sql-cluster-example-com", "Network:cluster-example-com"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("unexpected deletion order, got %v, expected %v", order, expected)
+	}
+
+	if err := peering.Deleter(cloud, peering); err != nil {
+		t.Fatalf("error removing network peering: %v", err)
+	}
+	network, err := cloud.Compute().Networks().Get("testproject", "cluster-example-com")
+	if err != nil {
+		t.Fatalf("error getting network: %v", err)
+	}
+	var remaining []string
+	for _, p := range network.Peerings {
+		remaining = append(remaining, p.Name)
+	}
+	if !reflect.DeepEqual(remaining, []string{"servicenetworking-googleapis-com"}) {
+		t.Errorf("unexpected peerings remaining on network: %v", remaining)
+	}
+}
+
+func TestListResourcesProgress(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	clusterLabels := map[string]string{"k8s-io-cluster-name": "cluster-example-com"}
+	if _, err := cloud.Compute().Disks().Insert("testproject", "us-test1-a", &compute.Disk{Name: "a-etcd-main-cluster-example-com", Labels: clusterLabels}); err != nil {
+		t.Fatalf("error creating disk: %v", err)
+	}
+	if _, err := cloud.Compute().RegionDisks().Insert("testproject", "us-test1", &compute.Disk{Name: "pvc-1234", Labels: clusterLabels}); err != nil {
+		t.Fatalf("error creating regional disk: %v", err)
+	}
+	if _, err := cloud.Compute().TargetPools().Insert("testproject", "us-test1", &compute.TargetPool{Name: "api-cluster-example-com"}); err != nil {
+		t.Fatalf("error creating target pool: %v", err)
+	}
+
+	var mutex sync.Mutex
+	calls := make(map[string][]int)
+	options := ListResourcesGCEOptions{
+		QPS: testQPS,
+		Progress: func(resourceType string, found int) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			calls[resourceType] = append(calls[resourceType], found)
+		},
+	}
+	if _, err := ListResourcesGCEWithOptions(context.Background(), cloud, "cluster.example.com", "us-test1", options); err != nil {
+		t.Fatalf("error listing resources: %v", err)
+	}
+
+	d := &clusterDiscoveryGCE{}
+	for _, resourceType := range d.resourceTypes().List() {
+		expected := 0
+		switch resourceType {
+		case "Disk":
+			expected = 2
+		case "TargetPool":
+			expected = 1
+		}
+		if !reflect.DeepEqual(calls[resourceType], []int{expected}) {
+			t.Errorf("unexpected progress for %s, got %v, expected [%d]", resourceType, calls[resourceType], expected)
+		}
+	}
+}
+
+func TestListResourcesProtected(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	for _, name := range []string{"api-cluster-example-com", "nat-cluster-example-com"} {
+		if _, err := cloud.Compute().Addresses().Insert("testproject", "us-test1", &compute.Address{Name: name}); err != nil {
+			t.Fatalf("error creating address: %v", err)
+		}
+	}
+
+	options := ListResourcesGCEOptions{
+		QPS:       testQPS,
+		Protected: []string{"Address:us-test1/nat-cluster-example-com", "Network:not-found"},
+	}
+	resourceMap, err := ListResourcesGCEWithOptions(context.Background(), cloud, "cluster.example.com", "us-test1", options)
+	if err != nil {
+		t.Fatalf("error listing resources: %v", err)
+	}
+
+	if _, found := resourceMap["Address:us-test1/api-cluster-example-com"]; !found {
+		t.Errorf("expected unprotected address to be tracked, got %v", resourceMap)
+	}
+	if _, found := resourceMap["Address:us-test1/nat-cluster-example-com"]; found {
+		t.Errorf("protected address should not be tracked")
+	}
+}
+
+func TestListResourcesNameFilter(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	clusterLabels := map[string]string{"k8s-io-cluster-name": "cluster-example-com"}
+	for _, name := range []string{"a-etcd-main-cluster-example-com", "a-etcd-events-cluster-example-com"} {
+		if _, err := cloud.Compute().Disks().Insert("testproject", "us-test1-a", &compute.Disk{Name: name, Labels: clusterLabels}); err != nil {
+			t.Fatalf("error creating disk: %v", err)
+		}
+	}
+	for _, name := range []string{"api-cluster-example-com", "nat-cluster-example-com", "api-other-example-com"} {
+		if _, err := cloud.Compute().Addresses().Insert("testproject", "us-test1", &compute.Address{Name: name}); err != nil {
+			t.Fatalf("error creating address: %v", err)
+		}
+	}
+
+	grid := []struct {
+		NameFilter   string
+		IncludeTypes []string
+		Expected     []string
+	}{
+		{
+			NameFilter: "api",
+			Expected:   []string{"Address:us-test1/api-cluster-example-com"},
+		},
+		{
+			NameFilter: "etcd",
+			Expected:   []string{"Disk:us-test1-a/a-etcd-events-cluster-example-com", "Disk:us-test1-a/a-etcd-main-cluster-example-com"},
+		},
+		{
+			NameFilter:   "etcd-main",
+			IncludeTypes: []string{typeDisk},
+			Expected:     []string{"Disk:us-test1-a/a-etcd-main-cluster-example-com"},
+		},
+		{
+			NameFilter:   "api",
+			IncludeTypes: []string{typeDisk},
+		},
+	}
+	for _, g := range grid {
+		options := ListResourcesGCEOptions{NameFilter: g.NameFilter, IncludeTypes: g.IncludeTypes, QPS: testQPS}
+		resourceMap, err := ListResourcesGCEWithOptions(context.Background(), cloud, "cluster.example.com", "us-test1", options)
+		if err != nil {
+			t.Fatalf("error listing resources: %v", err)
+		}
+
+		var actual []string
+		for k := range resourceMap {
+			actual = append(actual, k)
+		}
+		sort.Strings(actual)
+		if !reflect.DeepEqual(actual, g.Expected) {
+			t.Errorf("unexpected resources for name filter %q and types %v, got %v, expected %v", g.NameFilter, g.IncludeTypes, actual, g.Expected)
+		}
+	}
+}
+
+func TestListResourcesNamePrefix(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	for _, name := range []string{"api-prod-k8s", "api-cluster-example-com", "api-other-example-com"} {
+		if _, err := cloud.Compute().Addresses().Insert("testproject", "us-test1", &compute.Address{Name: name}); err != nil {
+			t.Fatalf("error creating address: %v", err)
+		}
+	}
+	if _, err := cloud.Compute().ForwardingRules().Insert("testproject", "us-test1", &compute.ForwardingRule{Name: "api-prod-k8s"}); err != nil {
+		t.Fatalf("error creating forwarding rule: %v", err)
+	}
+	for _, fr := range []*compute.Firewall{
+		{Name: "node-to-node-prod-k8s", TargetTags: []string{"prod-k8s-k8s-io-role-node"}},
+		{Name: "node-to-node-other-k8s", TargetTags: []string{"other-k8s-k8s-io-role-node"}},
+	} {
+		if _, err := cloud.Compute().Firewalls().Insert("testproject", fr); err != nil {
+			t.Fatalf("error creating firewall rule: %v", err)
+		}
+	}
+	for _, name := range []string{"prod-k8s", "other-k8s"} {
+		if _, err := cloud.Compute().Networks().Insert("testproject", &compute.Network{Name: name}); err != nil {
+			t.Fatalf("error creating network: %v", err)
+		}
+	}
+	for _, route := range []*compute.Route{
+		{Name: "prod-k8s-1234", Warnings: []*compute.RouteWarnings{{Code: "NEXT_HOP_INSTANCE_NOT_FOUND"}}},
+		{Name: "other-k8s-1234", Warnings: []*compute.RouteWarnings{{Code: "NEXT_HOP_INSTANCE_NOT_FOUND"}}},
+	} {
+		if _, err := cloud.Compute().Routes().Insert("testproject", route); err != nil {
+			t.Fatalf("error creating route: %v", err)
+		}
+	}
+
+	options := ListResourcesGCEOptions{NamePrefix: "prod.k8s", QPS: testQPS}
+	resourceMap, err := ListResourcesGCEWithOptions(context.Background(), cloud, "cluster.example.com", "us-test1", options)
+	if err != nil {
+		t.Fatalf("error listing resources: %v", err)
+	}
+
+	var actual []string
+	for k := range resourceMap {
+		actual = append(actual, k)
+	}
+	sort.Strings(actual)
+	expected := []string{
+		"Address:us-test1/api-cluster-example-com",
+		"Address:us-test1/api-prod-k8s",
+		"FirewallRule:node-to-node-prod-k8s",
+		"ForwardingRule:us-test1/api-prod-k8s",
+		"Network:prod-k8s",
+		"Route:prod-k8s-1234",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected resources for name prefix, got %v, expected %v", actual, expected)
+	}
+}
+
+func TestListResourcesInvalidNamePrefix(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	for _, namePrefix := range []string{" ", "-", "prod-", "Prod", "prod_k8s"} {
+		options := ListResourcesGCEOptions{NamePrefix: namePrefix, QPS: testQPS}
+		if _, err := ListResourcesGCEWithOptions(context.Background(), cloud, "cluster.example.com", "us-test1", options); err == nil {
+			t.Errorf("expected an error for name prefix %q", namePrefix)
+		}
+	}
+}
+
+func TestListResourcesMinAge(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	clusterLabels := map[string]string{"k8s-io-cluster-name": "cluster-example-com"}
+	for name, created := range map[string]time.Time{
+		"a-etcd-main-cluster-example-com": time.Now().Add(-48 * time.Hour),
+		"b-etcd-main-cluster-example-com": time.Now().Add(-time.Minute),
+	} {
+		disk := &compute.Disk{
+			Name:              name,
+			Labels:            clusterLabels,
+			CreationTimestamp: created.Format(time.RFC3339),
+		}
+		if _, err := cloud.Compute().Disks().Insert("testproject", "us-test1-a", disk); err != nil {
+			t.Fatalf("error creating disk: %v", err)
+		}
+	}
+	if _, err := cloud.Compute().Disks().Insert("testproject", "us-test1-a", &compute.Disk{Name: "c-etcd-main-cluster-example-com", Labels: clusterLabels}); err != nil {
+		t.Fatalf("error creating disk: %v", err)
+	}
+
+	options := ListResourcesGCEOptions{MinAge: time.Hour, QPS: testQPS}
+	resourceMap, err := ListResourcesGCEWithOptions(context.Background(), cloud, "cluster.example.com", "us-test1", options)
+	if err != nil {
+		t.Fatalf("error listing resources: %v", err)
+	}
+
+	var actual []string
+	for k := range resourceMap {
+		actual = append(actual, k)
+	}
+	sort.Strings(actual)
+	// The disk without a creation timestamp is kept, as we can't tell how old it is
+	expected := []string{"Disk:us-test1-a/a-etcd-main-cluster-example-com", "Disk:us-test1-a/c-etcd-main-cluster-example-com"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected resources, got %v, expected %v", actual, expected)
+	}
+}
+
+func TestListResourcesNoZonesInRegion(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+	cloud.Compute().(*mockcompute.MockClient).AddRegion("testproject", "us-test2", "us-test2-a")
+
+	_, err := listResourcesForTest(cloud, "cluster.example.com", "us-tset1")
+	if err == nil {
+		t.Fatalf("expected an error listing resources in an unknown region")
+	}
+	var noZonesErr *NoZonesInRegionError
+	if !errors.As(err, &noZonesErr) {
+		t.Fatalf("expected a NoZonesInRegionError, got %T: %v", err, err)
+	}
+	if noZonesErr.Region != "us-tset1" {
+		t.Errorf("unexpected region, got %q", noZonesErr.Region)
+	}
+	if expected := []string{"us-test1", "us-test2"}; !reflect.DeepEqual(noZonesErr.AvailableRegions, expected) {
+		t.Errorf("unexpected available regions, got %v, expected %v", noZonesErr.AvailableRegions, expected)
+	}
+	if expected := `unable to determine zones in region "us-tset1"; available regions are us-test1, us-test2`; err.Error() != expected {
+		t.Errorf("unexpected error message, got %q, expected %q", err.Error(), expected)
+	}
+}
+
+func TestSetZones(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	// The fake compute client doesn't implement Zones, so listing the zones of the region would panic
+	d := &clusterDiscoveryGCE{
+		cloud:         cloud,
+		gceCloud:      cloud,
+		clusterName:   "cluster.example.com",
+		computeClient: &fakeComputeClient{},
+	}
+	if err := d.setZones("us-test1", []string{"us-test1-b", "us-test1-a", "us-test1-b"}); err != nil {
+		t.Fatalf("error setting zones: %v", err)
+	}
+	if expected := []string{"us-test1-a", "us-test1-b"}; !reflect.DeepEqual(d.zones, expected) {
+		t.Errorf("unexpected zones, got %v, expected %v", d.zones, expected)
+	}
+	if expected := []string{"us-test1"}; !reflect.DeepEqual(d.scanRegions(), expected) {
+		t.Errorf("unexpected regions, got %v, expected %v", d.scanRegions(), expected)
+	}
+
+	for _, zone := range []string{"us-test2-a", "us-test1", "us-test1-a-b", "a"} {
+		if err := d.setZones("us-test1", []string{"us-test1-a", zone}); err == nil {
+			t.Errorf("expected an error setting zone %q in region us-test1", zone)
+		}
+	}
+}
+
+func TestListResourcesForCluster(t *testing.T) {
+	mockCloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	if _, err := mockCloud.Compute().Disks().Insert("testproject", "us-test1-a", &compute.Disk{
+		Name:   "a-etcd-main-cluster-example-com",
+		Labels: map[string]string{"k8s-io-cluster-name": "cluster-example-com"},
+	}); err != nil {
+		t.Fatalf("error creating disk: %v", err)
+	}
+
+	grid := []struct {
+		Name          string
+		Subnets       []kops.ClusterSubnetSpec
+		ExpectedLists int
+	}{
+		{
+			Name:          "regional subnets",
+			Subnets:       []kops.ClusterSubnetSpec{{Name: "us-test1", Region: "us-test1"}},
+			ExpectedLists: 1,
+		},
+		{
+			Name:          "explicit zones",
+			Subnets:       []kops.ClusterSubnetSpec{{Name: "us-test1-a", Region: "us-test1", Zone: "us-test1-a"}},
+			ExpectedLists: 0,
+		},
+	}
+	for _, g := range grid {
+		t.Run(g.Name, func(t *testing.T) {
+			zones := &fakeZoneClient{ZoneClient: mockCloud.Compute().Zones()}
+			cloud := &fakeComputeCloud{
+				GCECloud: mockCloud,
+				compute:  &fakeComputeClient{ComputeClient: mockCloud.Compute(), zones: zones},
+			}
+
+			cluster := &kops.Cluster{}
+			cluster.Name = "cluster.example.com"
+			cluster.Spec.Subnets = g.Subnets
+
+			resourceMap, err := ListResourcesForCluster(context.Background(), cloud, cluster)
+			if err != nil {
+				t.Fatalf("error listing resources: %v", err)
+			}
+			if _, found := resourceMap["Disk:us-test1-a/a-etcd-main-cluster-example-com"]; !found {
+				t.Errorf("expected disk to be tracked, got %v", resourceMap)
+			}
+			if zones.listed != g.ExpectedLists {
+				t.Errorf("unexpected number of zone lists, got %d, expected %d", zones.listed, g.ExpectedLists)
+			}
+		})
+	}
+
+	cluster := &kops.Cluster{}
+	cluster.Name = "cluster.example.com"
+	cluster.Spec.Subnets = []kops.ClusterSubnetSpec{{Name: "us-test1-a", Region: "us-test1", Zone: "us-test2-a"}}
+	if _, err := ListResourcesForCluster(context.Background(), mockCloud, cluster); err == nil {
+		t.Errorf("expected an error listing resources for a cluster with a zone outside its region")
+	}
+}
+
+func TestListResourcesAllRegions(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+	cloud.Compute().(*mockcompute.MockClient).AddRegion("testproject", "us-test2", "us-test2-a")
+
+	clusterLabels := map[string]string{"k8s-io-cluster-name": "cluster-example-com"}
+	for _, zone := range []string{"us-test1-a", "us-test2-a"} {
+		if _, err := cloud.Compute().Disks().Insert("testproject", zone, &compute.Disk{Name: zone + "-etcd-main-cluster-example-com", Labels: clusterLabels}); err != nil {
+			t.Fatalf("error creating disk: %v", err)
+		}
+		// A disk with the same name in another zone is a different resource
+		if _, err := cloud.Compute().Disks().Insert("testproject", zone, &compute.Disk{Name: "etcd-events-cluster-example-com", Labels: clusterLabels}); err != nil {
+			t.Fatalf("error creating disk: %v", err)
+		}
+	}
+	for region, name := range map[string]string{"us-test1": "api-cluster-example-com", "us-test2": "nat-cluster-example-com"} {
+		if _, err := cloud.Compute().Addresses().Insert("testproject", region, &compute.Address{Name: name}); err != nil {
+			t.Fatalf("error creating address: %v", err)
+		}
+	}
+	// An address with the same name in another region is a different resource
+	if _, err := cloud.Compute().Addresses().Insert("testproject", "us-test1", &compute.Address{Name: "nat-cluster-example-com"}); err != nil {
+		t.Fatalf("error creating address: %v", err)
+	}
+	if _, err := cloud.Compute().GlobalAddresses().Insert("testproject", &compute.Address{Name: "ingress-cluster-example-com"}); err != nil {
+		t.Fatalf("error creating global address: %v", err)
+	}
+	if _, err := cloud.Compute().Firewalls().Insert("testproject", &compute.Firewall{
+		Name:       "node-to-node-cluster-example-com",
+		TargetTags: []string{"cluster-example-com-k8s-io-role-node"},
+	}); err != nil {
+		t.Fatalf("error creating firewall rule: %v", err)
+	}
+
+	var mutex sync.Mutex
+	calls := make(map[string][]int)
+	options := ListResourcesGCEOptions{
+		QPS: testQPS,
+		Progress: func(resourceType string, found int) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			calls[resourceType] = append(calls[resourceType], found)
+		},
+	}
+	resourceMap, err := ListResourcesGCEAllRegions(context.Background(), cloud, "cluster.example.com", options)
+	if err != nil {
+		t.Fatalf("error listing resources: %v", err)
+	}
+
+	var actual []string
+	for k := range resourceMap {
+		actual = append(actual, k)
+	}
+	sort.Strings(actual)
+	expected := []string{
+		"Address:ingress-cluster-example-com",
+		"Address:us-test1/api-cluster-example-com",
+		"Address:us-test1/nat-cluster-example-com",
+		"Address:us-test2/nat-cluster-example-com",
+		"Disk:us-test1-a/etcd-events-cluster-example-com",
+		"Disk:us-test1-a/us-test1-a-etcd-main-cluster-example-com",
+		"Disk:us-test2-a/etcd-events-cluster-example-com",
+		"Disk:us-test2-a/us-test2-a-etcd-main-cluster-example-com",
+		"FirewallRule:node-to-node-cluster-example-com",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected resources, got %v, expected %v", actual, expected)
+	}
+
+	// Global resources are discovered once, not once per region
+	if !reflect.DeepEqual(calls["FirewallRule"], []int{1}) {
+		t.Errorf("unexpected progress for FirewallRule, got %v, expected [1]", calls["FirewallRule"])
+	}
+	if !reflect.DeepEqual(calls["Address"], []int{4}) {
+		t.Errorf("unexpected progress for Address, got %v, expected [4]", calls["Address"])
+	}
+}
+
+func TestListAutoscalers(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	clusterName := "cluster.example.com"
+	template := &compute.InstanceTemplate{
+		Name: "nodes-cluster-example-com-1234",
+		Properties: &compute.InstanceProperties{
+			Metadata: &compute.Metadata{
+				Items: []*compute.MetadataItems{{Key: "cluster-name", Value: &clusterName}},
+			},
+		},
+	}
+	if _, err := cloud.Compute().InstanceTemplates().Insert("testproject", template); err != nil {
+		t.Fatalf("error creating instance template: %v", err)
+	}
+	mig := &compute.InstanceGroupManager{
+		Name:             "a-nodes-cluster-example-com",
+		Zone:             "us-test1-a",
+		InstanceTemplate: template.SelfLink,
+	}
+	if _, err := cloud.Compute().InstanceGroupManagers().Insert("testproject", "us-test1-a", mig); err != nil {
+		t.Fatalf("error creating instance group manager: %v", err)
+	}
+	if _, err := cloud.Compute().Autoscalers().Insert("testproject", "us-test1-a", &compute.Autoscaler{
+		Name:   "a-nodes-cluster-example-com-autoscaler",
+		Target: mig.SelfLink,
+	}); err != nil {
+		t.Fatalf("error creating autoscaler: %v", err)
+	}
+	if _, err := cloud.Compute().Autoscalers().Insert("testproject", "us-test1-a", &compute.Autoscaler{
+		Name:   "other-autoscaler",
+		Target: "https://www.googleapis.com/compute/v1/projects/testproject/zones/us-test1-a/instanceGroupManagers/other",
+	}); err != nil {
+		t.Fatalf("error creating autoscaler: %v", err)
+	}
+
+	d := &clusterDiscoveryGCE{
+		cloud:       cloud,
+		gceCloud:    cloud,
+		clusterName: clusterName,
+		zones:       []string{"us-test1-a"},
+	}
+	resourceMap, err := runListFunctions(context.Background(), []gceListFn{d.listInstanceGroupManagersAndInstances}, 1)
+	if err != nil {
+		t.Fatalf("error listing instance group managers: %v", err)
+	}
+
+	expected := map[string][]string{
+		"InstanceGroupManager:us-test1-a/a-nodes-cluster-example-com":  {"InstanceTemplate:nodes-cluster-example-com-1234"},
+		"Autoscaler:us-test1-a/a-nodes-cluster-example-com-autoscaler": {"InstanceGroupManager:us-test1-a/a-nodes-cluster-example-com"},
+	}
+	actual := make(map[string][]string)
+	for k, r := range resourceMap {
+		actual[k] = r.Blocks
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected resources, got %v, expected %v", actual, expected)
+	}
+}
+
+func TestListRegionalInstanceGroupManagers(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	clusterName := "cluster.example.com"
+	template := &compute.InstanceTemplate{
+		Name: "nodes-cluster-example-com-1234",
+		Properties: &compute.InstanceProperties{
+			Metadata: &compute.Metadata{
+				Items: []*compute.MetadataItems{{Key: "cluster-name", Value: &clusterName}},
+			},
+		},
+	}
+	if _, err := cloud.Compute().InstanceTemplates().Insert("testproject", template); err != nil {
+		t.Fatalf("error creating instance template: %v", err)
+	}
+	mig := &compute.InstanceGroupManager{
+		Name:             "nodes-cluster-example-com",
+		InstanceTemplate: template.SelfLink,
+	}
+	if _, err := cloud.Compute().RegionInstanceGroupManagers().Insert("testproject", "us-test1", mig); err != nil {
+		t.Fatalf("error creating regional instance group manager: %v", err)
+	}
+	mockCompute := cloud.Compute().(*mockcompute.MockClient)
+	for _, instance := range []string{
+		"https://www.googleapis.com/compute/v1/projects/testproject/zones/us-test1-a/instances/nodes-abcd",
+		"https://www.googleapis.com/compute/v1/projects/testproject/zones/us-test1-b/instances/nodes-efgh",
+	} {
+		mockCompute.AddRegionManagedInstance(mig, &compute.ManagedInstance{Instance: instance})
+	}
+
+	d := &clusterDiscoveryGCE{
+		cloud:       cloud,
+		gceCloud:    cloud,
+		clusterName: clusterName,
+		zones:       []string{"us-test1-a"},
+	}
+	resourceMap, err := runListFunctions(context.Background(), []gceListFn{d.listInstanceGroupManagersAndInstances}, 1)
+	if err != nil {
+		t.Fatalf("error listing instance group managers: %v", err)
+	}
+
+	var keys []string
+	for k := range resourceMap {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	expected := []string{
+		"Instance:us-test1-a/nodes-abcd",
+		"Instance:us-test1-b/nodes-efgh",
+		"InstanceGroupManager:us-test1/nodes-cluster-example-com",
+	}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Fatalf("unexpected resources, got %v, expected %v", keys, expected)
+	}
+
+	r := resourceMap["InstanceGroupManager:us-test1/nodes-cluster-example-com"]
+	if err := r.Deleter(cloud, r); err != nil {
+		t.Fatalf("error deleting regional instance group manager: %v", err)
+	}
+	if _, err := cloud.Compute().RegionInstanceGroupManagers().Get("testproject", "us-test1", "nodes-cluster-example-com"); err == nil {
+		t.Errorf("expected regional instance group manager to be deleted")
+	}
+}
+
+func TestBackendServiceBlocksInstanceGroupManagers(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	// The MIGs are ours by name, as their templates are gone
+	if _, err := cloud.Compute().InstanceGroupManagers().Insert("testproject", "us-test1-a", &compute.InstanceGroupManager{
+		Name:             "a-nodes-cluster-example-com",
+		InstanceTemplate: "https://www.googleapis.com/compute/v1/projects/testproject/global/instanceTemplates/nodes-cluster-example-com-1234",
+	}); err != nil {
+		t.Fatalf("error creating instance group manager: %v", err)
+	}
+	if _, err := cloud.Compute().RegionInstanceGroupManagers().Insert("testproject", "us-test1", &compute.InstanceGroupManager{
+		Name:             "ingress-cluster-example-com",
+		InstanceTemplate: "https://www.googleapis.com/compute/v1/projects/testproject/global/instanceTemplates/ingress-cluster-example-com-1234",
+	}); err != nil {
+		t.Fatalf("error creating regional instance group manager: %v", err)
+	}
+	if _, err := cloud.Compute().BackendServices().Insert("testproject", &compute.BackendService{
+		Name: "web-cluster-example-com",
+		Backends: []*compute.Backend{
+			{Group: "https://www.googleapis.com/compute/v1/projects/testproject/zones/us-test1-a/instanceGroups/a-nodes-cluster-example-com"},
+			// The API version of the link does not matter
+			{Group: "https://www.googleapis.com/compute/beta/projects/testproject/regions/us-test1/instanceGroups/ingress-cluster-example-com"},
+		},
+	}); err != nil {
+		t.Fatalf("error creating backend service: %v", err)
+	}
+
+	resourceMap, err := listResourcesForTest(cloud, "cluster.example.com", "us-test1")
+	if err != nil {
+		t.Fatalf("error listing resources: %v", err)
+	}
+
+	bs := resourceMap["BackendService:web-cluster-example-com"]
+	if bs == nil {
+		t.Fatalf("expected backend service to be tracked, got %v", resourceMap)
+	}
+	blocks := append([]string{}, bs.Blocks...)
+	sort.Strings(blocks)
+	if expected := []string{"InstanceGroupManager:us-test1-a/a-nodes-cluster-example-com", "InstanceGroupManager:us-test1/ingress-cluster-example-com"}; !reflect.DeepEqual(blocks, expected) {
+		t.Errorf("unexpected blocks for backend service, got %v, expected %v", blocks, expected)
+	}
+
+	// The backend service must be deleted first, so it no longer refers to the instance groups of the MIGs
+	var order []string
+	for _, r := range SortedResources(resourceMap) {
+		order = append(order, r.Type+":"+r.ID)
+	}
+	expected := []string{
+		"BackendService:web-cluster-example-com",
+		"InstanceGroupManager:us-test1-a/a-nodes-cluster-example-com",
+		"InstanceGroupManager:us-test1/ingress-cluster-example-com",
+	}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("unexpected deletion order, got %v, expected %v", order, expected)
+	}
+}
+
+func TestListInstanceGroupManagersMissingTemplate(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	// The templates were deleted out-of-band, so they are not found
+	for _, mig := range []*compute.InstanceGroupManager{
+		{
+			Name:             "a-nodes-cluster-example-com",
+			InstanceTemplate: "https://www.googleapis.com/compute/v1/projects/testproject/global/instanceTemplates/nodes-cluster-example-com-1234",
+		},
+		{
+			Name:             "a-nodes-other-example-com",
+			InstanceTemplate: "https://www.googleapis.com/compute/v1/projects/testproject/global/instanceTemplates/nodes-other-example-com-1234",
+		},
+	} {
+		if _, err := cloud.Compute().InstanceGroupManagers().Insert("testproject", "us-test1-a", mig); err != nil {
+			t.Fatalf("error creating instance group manager: %v", err)
+		}
+	}
+
+	d := &clusterDiscoveryGCE{
+		cloud:       cloud,
+		gceCloud:    cloud,
+		clusterName: "cluster.example.com",
+		zones:       []string{"us-test1-a"},
+	}
+	resourceMap, err := runListFunctions(context.Background(), []gceListFn{d.listInstanceGroupManagersAndInstances}, 1)
+	if err != nil {
+		t.Fatalf("error listing instance group managers: %v", err)
+	}
+
+	var keys []string
+	for k := range resourceMap {
+		keys = append(keys, k)
+	}
+	if expected := []string{"InstanceGroupManager:us-test1-a/a-nodes-cluster-example-com"}; !reflect.DeepEqual(keys, expected) {
+		t.Fatalf("unexpected resources, got %v, expected %v", keys, expected)
+	}
+	r := resourceMap["InstanceGroupManager:us-test1-a/a-nodes-cluster-example-com"]
+	if len(r.Blocks) != 0 {
+		t.Errorf("expected no blocks for the missing template, got %v", r.Blocks)
+	}
+
+	if err := r.Deleter(cloud, r); err != nil {
+		t.Fatalf("error deleting instance group manager: %v", err)
+	}
+	if _, err := cloud.Compute().InstanceGroupManagers().Get("testproject", "us-test1-a", "a-nodes-cluster-example-com"); err == nil {
+		t.Errorf("expected instance group manager to be deleted")
+	}
+}
+
+func TestDiscoveryRateLimit(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	const qps = 20
+	const calls = 5
+	d := &clusterDiscoveryGCE{
+		cloud:       cloud,
+		gceCloud:    cloud,
+		clusterName: "cluster.example.com",
+		limiter:     rate.NewLimiter(qps, 1),
+	}
+
+	var listFunctions []gceListFn
+	for i := 0; i < calls; i++ {
+		listFunctions = append(listFunctions, d.listGCESnapshots)
+	}
+
+	start := time.Now()
+	if _, err := runListFunctions(context.Background(), listFunctions, maxConcurrentListCalls); err != nil {
+		t.Fatalf("error listing resources: %v", err)
+	}
+	elapsed := time.Since(start)
+
+	// The first call uses the initial token, each of the others has to wait for a new one
+	minimum := time.Duration(calls-1) * time.Second / qps
+	if elapsed < minimum {
+		t.Errorf("expected %d calls at %d QPS to take at least %v, took %v", calls, qps, minimum, elapsed)
+	}
+}
+
+// TestDiscoveryRateLimitCancelled checks that the discovery calls made outside the list functions of the
+// compute API, e.g. for instance templates and DNS zones, also wait for the rate limiter and stop when cancelled
+func TestDiscoveryRateLimitCancelled(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	d := &clusterDiscoveryGCE{
+		cloud:       cloud,
+		gceCloud:    cloud,
+		clusterName: "cluster.example.com",
+		limiter:     rate.NewLimiter(testQPS, 1),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for name, list := range map[string]gceListFn{
+		"InstanceTemplate": d.listGCEInstanceTemplates,
+		"DNSZone":          d.listGCEDNSZone,
+	} {
+		if _, err := list(ctx); !errors.Is(err, context.Canceled) {
+			t.Errorf("expected listing %s to be cancelled, got %v", name, err)
+		}
+	}
+}
+
+func TestListStandaloneInstances(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	clusterName := "cluster.example.com"
+	template := &compute.InstanceTemplate{
+		Name: "nodes-cluster-example-com-1234",
+		Properties: &compute.InstanceProperties{
+			Metadata: &compute.Metadata{
+				Items: []*compute.MetadataItems{{Key: "cluster-name", Value: &clusterName}},
+			},
+		},
+	}
+	if _, err := cloud.Compute().InstanceTemplates().Insert("testproject", template); err != nil {
+		t.Fatalf("error creating instance template: %v", err)
+	}
+	mig := &compute.InstanceGroupManager{
+		Name:             "nodes-cluster-example-com",
+		InstanceTemplate: template.SelfLink,
+	}
+	if _, err := cloud.Compute().RegionInstanceGroupManagers().Insert("testproject", "us-test1", mig); err != nil {
+		t.Fatalf("error creating regional instance group manager: %v", err)
+	}
+	cloud.Compute().(*mockcompute.MockClient).AddRegionManagedInstance(mig, &compute.ManagedInstance{
+		Instance: "https://www.googleapis.com/compute/v1/projects/testproject/zones/us-test1-a/instances/nodes-abcd",
+	})
+
+	createdByMIG := "projects/1234/regions/us-test1/instanceGroupManagers/nodes-cluster-example-com"
+	clusterLabels := map[string]string{"k8s-io-cluster-name": "cluster-example-com"}
+	for _, i := range []*compute.Instance{
+		// The MIG instance, which we already track via the MIG
+		{Name: "nodes-abcd", Labels: clusterLabels, Metadata: &compute.Metadata{Items: []*compute.MetadataItems{{Key: "created-by", Value: &createdByMIG}}}},
+		// An instance the MIG is still creating, which it would recreate if we deleted it
+		{Name: "nodes-efgh", Labels: clusterLabels, Metadata: &compute.Metadata{Items: []*compute.MetadataItems{{Key: "created-by", Value: &createdByMIG}}}},
+		{Name: "bastion-cluster-example-com"},
+		{Name: "bastion-other-example-com"},
+	} {
+		if _, err := cloud.Compute().Instances().Insert("testproject", "us-test1-a", i); err != nil {
+			t.Fatalf("error creating instance: %v", err)
+		}
+	}
+
+	resourceMap, err := listResourcesForTest(cloud, clusterName, "us-test1")
+	if err != nil {
+		t.Fatalf("error listing resources: %v", err)
+	}
+
+	var instances []string
+	for k, r := range resourceMap {
+		if r.Type == typeInstance {
+			instances = append(instances, k)
+		}
+	}
+	sort.Strings(instances)
+	expected := []string{"Instance:us-test1-a/bastion-cluster-example-com", "Instance:us-test1-a/nodes-abcd"}
+	if !reflect.DeepEqual(instances, expected) {
+		t.Fatalf("unexpected instances, got %v, expected %v", instances, expected)
+	}
+	if _, ok := resourceMap["Instance:us-test1-a/nodes-abcd"].Obj.(*compute.ManagedInstance); !ok {
+		t.Errorf("expected the MIG instance to be tracked via the MIG")
+	}
+
+	bastion := resourceMap["Instance:us-test1-a/bastion-cluster-example-com"]
+	if err := bastion.Deleter(cloud, bastion); err != nil {
+		t.Fatalf("error deleting instance: %v", err)
+	}
+	if _, err := cloud.Compute().Instances().Get("testproject", "us-test1-a", "bastion-cluster-example-com"); err == nil {
+		t.Errorf("expected standalone instance to be deleted")
+	}
+}
+
+func TestListStatefulInstanceGroupManagers(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	clusterName := "cluster.example.com"
+	template := &compute.InstanceTemplate{
+		Name: "master-cluster-example-com-1234",
+		Properties: &compute.InstanceProperties{
+			Metadata: &compute.Metadata{
+				Items: []*compute.MetadataItems{{Key: "cluster-name", Value: &clusterName}},
+			},
+		},
+	}
+	if _, err := cloud.Compute().InstanceTemplates().Insert("testproject", template); err != nil {
+		t.Fatalf("error creating instance template: %v", err)
+	}
+	mig := &compute.InstanceGroupManager{
+		Name:             "master-cluster-example-com",
+		Region:           "us-test1",
+		InstanceTemplate: template.SelfLink,
+		StatefulPolicy: &compute.StatefulPolicy{
+			PreservedState: &compute.StatefulPolicyPreservedState{
+				Disks: map[string]compute.StatefulPolicyPreservedStateDiskDevice{
+					"etcd-main": {AutoDelete: "never"},
+				},
+			},
+		},
+	}
+	if _, err := cloud.Compute().RegionInstanceGroupManagers().Insert("testproject", "us-test1", mig); err != nil {
+		t.Fatalf("error creating instance group manager: %v", err)
+	}
+	cloud.Compute().(*mockcompute.MockClient).AddRegionManagedInstance(mig, &compute.ManagedInstance{
+		Instance: "https://www.googleapis.com/compute/v1/projects/testproject/zones/us-test1-a/instances/master-abcd",
+		PreservedStateFromPolicy: &compute.PreservedState{
+			Disks: map[string]compute.PreservedStatePreservedDisk{
+				"etcd-main": {Source: "https://www.googleapis.com/compute/v1/projects/testproject/zones/us-test1-a/disks/etcd-main-master-abcd"},
+			},
+		},
+	})
+
+	d := &clusterDiscoveryGCE{
+		cloud:       cloud,
+		gceCloud:    cloud,
+		clusterName: clusterName,
+	}
+	resourceMap, err := runListFunctions(context.Background(), []gceListFn{d.listInstanceGroupManagersAndInstances}, 1)
+	if err != nil {
+		t.Fatalf("error listing instance group managers: %v", err)
+	}
+
+	r := resourceMap["InstanceGroupManager:us-test1/master-cluster-example-com"]
+	if r == nil {
+		t.Fatalf("instance group manager not found, got %v", resourceMap)
+	}
+	expected := []string{"InstanceTemplate:master-cluster-example-com-1234", "Disk:us-test1-a/etcd-main-master-abcd"}
+	if !reflect.DeepEqual(r.Blocks, expected) {
+		t.Errorf("unexpected blocks, got %v, expected %v", r.Blocks, expected)
+	}
+}
+
+func TestListRoutes(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	clusterName := "cluster.example.com"
+	template := &compute.InstanceTemplate{
+		Name: "nodes-cluster-example-com-1234",
+		Properties: &compute.InstanceProperties{
+			Metadata: &compute.Metadata{
+				Items: []*compute.MetadataItems{{Key: "cluster-name", Value: &clusterName}},
+			},
+			NetworkInterfaces: []*compute.NetworkInterface{
+				{Network: "https://www.googleapis.com/compute/v1/projects/testproject/global/networks/shared"},
+			},
+		},
+	}
+	if _, err := cloud.Compute().InstanceTemplates().Insert("testproject", template); err != nil {
+		t.Fatalf("error creating instance template: %v", err)
+	}
+
+	for _, route := range []*compute.Route{
+		{
+			Name:            "cluster-example-com-missing-hop",
+			Network:         "https://www.googleapis.com/compute/v1/projects/testproject/global/networks/other",
+			NextHopInstance: "https://www.googleapis.com/compute/v1/projects/testproject/zones/us-test1-a/instances/gone",
+			Warnings:        []*compute.RouteWarnings{{Code: "NEXT_HOP_INSTANCE_NOT_FOUND"}},
+		},
+		{
+			Name:            "cluster-example-com-instance-hop",
+			Network:         "https://www.googleapis.com/compute/v1/projects/testproject/global/networks/other",
+			NextHopInstance: "https://www.googleapis.com/compute/v1/projects/testproject/zones/us-test1-a/instances/nodes-abcd",
+		},
+		{
+			Name:            "cluster-example-com-6f1d9a52-0c4e-4b8a-9d2e-3b7c5a1e8f40",
+			Network:         "https://www.googleapis.com/compute/v1/projects/testproject/global/networks/other",
+			NextHopInstance: "https://www.googleapis.com/compute/v1/projects/testproject/zones/us-test1-a/instances/unmanaged",
+		},
+		{
+			// Another cluster whose name starts with ours, in the same network
+			Name:            "cluster-example-com-au-6f1d9a52-0c4e-4b8a-9d2e-3b7c5a1e8f40",
+			Network:         "https://www.googleapis.com/compute/v1/projects/testproject/global/networks/shared",
+			NextHopInstance: "https://www.googleapis.com/compute/v1/projects/testproject/zones/us-test1-a/instances/unmanaged",
+		},
+		{
+			Name:            "cluster-example-com-network",
+			Network:         "https://www.googleapis.com/compute/v1/projects/testproject/global/networks/shared",
+			NextHopInstance: "https://www.googleapis.com/compute/v1/projects/testproject/zones/us-test1-a/instances/unmanaged",
+		},
+		{
+			Name:            "cluster-example-com-elsewhere",
+			Network:         "https://www.googleapis.com/compute/v1/projects/testproject/global/networks/other",
+			NextHopInstance: "https://www.googleapis.com/compute/v1/projects/testproject/zones/us-test1-a/instances/unmanaged",
+		},
+		{
+			Name:    "other-example-com-network",
+			Network: "https://www.googleapis.com/compute/v1/projects/testproject/global/networks/shared",
+		},
+	} {
+		if _, err := cloud.Compute().Routes().Insert("testproject", route); err != nil {
+			t.Fatalf("error creating route: %v", err)
+		}
+	}
+
+	d := &clusterDiscoveryGCE{
+		cloud:       cloud,
+		gceCloud:    cloud,
+		clusterName: clusterName,
+	}
+	resourceMap := map[string]*resources.Resource{
+		"Instance:us-test1-a/nodes-abcd": {Type: typeInstance, ID: "us-test1-a/nodes-abcd"},
+	}
+	resourceTrackers, err := d.listRoutes(context.Background(), resourceMap)
+	if err != nil {
+		t.Fatalf("error listing routes: %v", err)
+	}
+
+	var actual []string
+	for _, r := range resourceTrackers {
+		actual = append(actual, r.ID)
+	}
+	sort.Strings(actual)
+	expected := []string{
+		"cluster-example-com-6f1d9a52-0c4e-4b8a-9d2e-3b7c5a1e8f40",
+		"cluster-example-com-instance-hop",
+		"cluster-example-com-missing-hop",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected routes, got %v, expected %v", actual, expected)
+	}
+}
+
+func TestListRoutesNextHopIlb(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	if _, err := cloud.Compute().ForwardingRules().Insert("testproject", "us-test1", &compute.ForwardingRule{Name: "shared-ilb"}); err != nil {
+		t.Fatalf("error creating forwarding rule: %v", err)
+	}
+
+	otherNetwork := "https://www.googleapis.com/compute/v1/projects/testproject/global/networks/other"
+	for _, route := range []*compute.Route{
+		{
+			Name:       "cluster-example-com-ilb-gone",
+			Network:    otherNetwork,
+			NextHopIlb: "https://www.googleapis.com/compute/v1/projects/testproject/regions/us-test1/forwardingRules/deleted-ilb",
+		},
+		{
+			Name:       "cluster-example-com-ilb-cluster",
+			Network:    otherNetwork,
+			NextHopIlb: "regions/us-test1/forwardingRules/internal-cluster-example-com",
+		},
+		{
+			Name:       "cluster-example-com-ilb-cluster-ip",
+			Network:    otherNetwork,
+			NextHopIlb: "10.0.0.5",
+		},
+		{
+			Name:       "cluster-example-com-ilb-live",
+			Network:    otherNetwork,
+			NextHopIlb: "https://www.googleapis.com/compute/v1/projects/testproject/regions/us-test1/forwardingRules/shared-ilb",
+		},
+		{
+			Name:       "cluster-example-com-ilb-other-ip",
+			Network:    otherNetwork,
+			NextHopIlb: "10.0.0.6",
+		},
+		{
+			Name:       "other-example-com-ilb-gone",
+			Network:    otherNetwork,
+			NextHopIlb: "https://www.googleapis.com/compute/v1/projects/testproject/regions/us-test1/forwardingRules/deleted-ilb",
+		},
+	} {
+		if _, err := cloud.Compute().Routes().Insert("testproject", route); err != nil {
+			t.Fatalf("error creating route: %v", err)
+		}
+	}
+
+	d := &clusterDiscoveryGCE{
+		cloud:       cloud,
+		gceCloud:    cloud,
+		clusterName: "cluster.example.com",
+	}
+	resourceMap := map[string]*resources.Resource{
+		"ForwardingRule:internal-cluster-example-com": {
+			Type: typeForwardingRule,
+			ID:   "internal-cluster-example-com",
+			Obj: &compute.ForwardingRule{
+				Name:      "internal-cluster-example-com",
+				Region:    "https://www.googleapis.com/compute/v1/projects/testproject/regions/us-test1",
+				IPAddress: "10.0.0.5",
+			},
+		},
+	}
+	resourceTrackers, err := d.listRoutes(context.Background(), resourceMap)
+	if err != nil {
+		t.Fatalf("error listing routes: %v", err)
+	}
+
+	actual := make(map[string][]string)
+	for _, r := range resourceTrackers {
+		actual[r.ID] = r.Blocks
+	}
+	expected := map[string][]string{
+		"cluster-example-com-ilb-gone":       nil,
+		"cluster-example-com-ilb-cluster":    {"ForwardingRule:internal-cluster-example-com"},
+		"cluster-example-com-ilb-cluster-ip": {"ForwardingRule:internal-cluster-example-com"},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected routes, got %v, expected %v", actual, expected)
+	}
+}
+
+func TestSubnetInUseError(t *testing.T) {
+	subnet := &compute.Subnetwork{
+		Name:     "us-test1-cluster-example-com",
+		SelfLink: "https://www.googleapis.com/compute/v1/projects/testproject/regions/us-test1/subnetworks/us-test1-cluster-example-com",
+	}
+
+	grid := []struct {
+		Name     string
+		Err      *googleapi.Error
+		Expected string
+	}{
+		{
+			Name: "blocker reported by GCE",
+			Err: &googleapi.Error{
+				Code:    400,
+				Message: "The subnetwork resource 'projects/testproject/regions/us-test1/subnetworks/us-test1-cluster-example-com' is already being used by 'projects/testproject/regions/us-test1/forwardingRules/my-ilb'",
+				Errors:  []googleapi.ErrorItem{{Reason: "resourceInUseByAnotherResource"}},
+			},
+			Expected: "subnetwork us-test1-cluster-example-com is still in use by projects/testproject/regions/us-test1/forwardingRules/my-ilb; delete it (or move it to another subnetwork) and retry",
+		},
+		{
+			Name: "blocker not reported",
+			Err: &googleapi.Error{
+				Code:    400,
+				Message: "resource in use",
+				Errors:  []googleapi.ErrorItem{{Reason: "resourceInUseByAnotherResource"}},
+			},
+			Expected: "subnetwork us-test1-cluster-example-com is still in use, likely by forwarding rules, instances or addresses not managed by kops; delete them and retry",
+		},
+	}
+	for _, g := range grid {
+		t.Run(g.Name, func(t *testing.T) {
+			if !isResourceInUse(g.Err) {
+				t.Fatalf("expected error to be classified as resource in use")
+			}
+			err := subnetInUseError(subnet, g.Err)
+			if !strings.HasPrefix(err.Error(), g.Expected) {
+				t.Errorf("unexpected error, got %q, expected it to start with %q", err.Error(), g.Expected)
+			}
+		})
+	}
+}
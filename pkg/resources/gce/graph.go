@@ -0,0 +1,179 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/kops/pkg/resources"
+)
+
+// Graph is the deletion ordering of a set of resources.
+// Nodes are resource keys (Type:ID); an edge From -> To means From must be deleted before To.
+type Graph struct {
+	Nodes []string
+	Edges []Edge
+}
+
+// Edge is a dependency between two resources, From must be deleted before To
+type Edge struct {
+	From string
+	To   string
+}
+
+// BuildDependencyGraph builds the deletion ordering encoded in the Blocks and Blocked fields of the resources.
+// References to resources that are not in the map are ignored.
+// An error naming the participants is returned if the resources block each other in a cycle,
+// as such resources would never be deleted.
+func BuildDependencyGraph(resourceMap map[string]*resources.Resource) (*Graph, error) {
+	g := &Graph{}
+
+	edges := make(map[Edge]bool)
+	for k, r := range resourceMap {
+		g.Nodes = append(g.Nodes, k)
+
+		// r blocks the deletion of b, so r is deleted first
+		for _, b := range r.Blocks {
+			if _, found := resourceMap[b]; found {
+				edges[Edge{From: k, To: b}] = true
+			}
+		}
+		// r is blocked by b, so b is deleted first
+		for _, b := range r.Blocked {
+			if _, found := resourceMap[b]; found {
+				edges[Edge{From: b, To: k}] = true
+			}
+		}
+	}
+	for e := range edges {
+		g.Edges = append(g.Edges, e)
+	}
+
+	sort.Strings(g.Nodes)
+	sort.Slice(g.Edges, func(i, j int) bool {
+		if g.Edges[i].From != g.Edges[j].From {
+			return g.Edges[i].From < g.Edges[j].From
+		}
+		return g.Edges[i].To < g.Edges[j].To
+	})
+
+	if cycle := g.findCycle(); cycle != nil {
+		return g, fmt.Errorf("resources block each other and cannot be deleted: %s", strings.Join(cycle, " -> "))
+	}
+
+	return g, nil
+}
+
+// findCycle returns the nodes of a cycle in the graph, starting and ending with the same node, or nil if there is none
+func (g *Graph) findCycle() []string {
+	next := make(map[string][]string)
+	for _, e := range g.Edges {
+		next[e.From] = append(next[e.From], e.To)
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int)
+	var path []string
+
+	var visit func(node string) []string
+	visit = func(node string) []string {
+		state[node] = visiting
+		path = append(path, node)
+		for _, n := range next[node] {
+			switch state[n] {
+			case visiting:
+				for i, p := range path {
+					if p == n {
+						cycle := append([]string{}, path[i:]...)
+						return append(cycle, n)
+					}
+				}
+			case unvisited:
+				if cycle := visit(n); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[node] = visited
+		return nil
+	}
+
+	for _, node := range g.Nodes {
+		if state[node] == unvisited {
+			if cycle := visit(node); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// SortedResources returns the resources in a deterministic deletion order: each resource comes after
+// the resources that must be deleted before it, and ties are broken by Type:ID.
+// Resources in a cycle (see BuildDependencyGraph) are appended at the end, ordered by Type:ID.
+func SortedResources(resourceMap map[string]*resources.Resource) []*resources.Resource {
+	// We handle cycles below, so we don't need the error
+	g, _ := BuildDependencyGraph(resourceMap)
+
+	next := make(map[string][]string)
+	waitingFor := make(map[string]int)
+	for _, e := range g.Edges {
+		next[e.From] = append(next[e.From], e.To)
+		waitingFor[e.To]++
+	}
+
+	// ready is kept sorted, so we always take the smallest key that can be deleted next
+	var ready []string
+	for _, node := range g.Nodes {
+		if waitingFor[node] == 0 {
+			ready = append(ready, node)
+		}
+	}
+
+	done := make(map[string]bool)
+	sorted := make([]*resources.Resource, 0, len(g.Nodes))
+	for len(ready) != 0 {
+		node := ready[0]
+		ready = ready[1:]
+		done[node] = true
+		sorted = append(sorted, resourceMap[node])
+
+		for _, n := range next[node] {
+			waitingFor[n]--
+			if waitingFor[n] == 0 {
+				i := sort.SearchStrings(ready, n)
+				ready = append(ready, "")
+				copy(ready[i+1:], ready[i:])
+				ready[i] = n
+			}
+		}
+	}
+
+	for _, node := range g.Nodes {
+		if !done[node] {
+			sorted = append(sorted, resourceMap[node])
+		}
+	}
+	return sorted
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/kops/pkg/resources"
+)
+
+func TestBuildDependencyGraphChain(t *testing.T) {
+	resourceMap := map[string]*resources.Resource{
+		"Instance:a":             {Type: "Instance", ID: "a", Blocks: []string{"Subnet:s", "Network:n"}},
+		"Subnet:s":               {Type: "Subnet", ID: "s", Blocks: []string{"Network:n"}},
+		"Network:n":              {Type: "Network", ID: "n", Blocked: []string{"Subnet:s", "FirewallRule:missing"}},
+		"InstanceTemplate:t":     {Type: "InstanceTemplate", ID: "t", Blocked: []string{"InstanceGroupManager:m"}},
+		"InstanceGroupManager:m": {Type: "InstanceGroupManager", ID: "m", Blocks: []string{"Instance:missing"}},
+	}
+
+	g, err := BuildDependencyGraph(resourceMap)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedNodes := []string{"Instance:a", "InstanceGroupManager:m", "InstanceTemplate:t", "Network:n", "Subnet:s"}
+	if !reflect.DeepEqual(g.Nodes, expectedNodes) {
+		t.Errorf("unexpected nodes, got %v, expected %v", g.Nodes, expectedNodes)
+	}
+	expectedEdges := []Edge{
+		{From: "Instance:a", To: "Network:n"},
+		{From: "Instance:a", To: "Subnet:s"},
+		{From: "InstanceGroupManager:m", To: "InstanceTemplate:t"},
+		{From: "Subnet:s", To: "Network:n"},
+	}
+	if !reflect.DeepEqual(g.Edges, expectedEdges) {
+		t.Errorf("unexpected edges, got %v, expected %v", g.Edges, expectedEdges)
+	}
+}
+
+func TestBuildDependencyGraphCycle(t *testing.T) {
+	resourceMap := map[string]*resources.Resource{
+		"BackendService:b":  {Type: "BackendService", ID: "b", Blocked: []string{"UrlMap:u"}},
+		"UrlMap:u":          {Type: "UrlMap", ID: "u", Blocked: []string{"TargetHttpProxy:p"}},
+		"TargetHttpProxy:p": {Type: "TargetHttpProxy", ID: "p", Blocked: []string{"BackendService:b"}},
+		"Address:a":         {Type: "Address", ID: "a"},
+	}
+
+	_, err := BuildDependencyGraph(resourceMap)
+	if err == nil {
+		t.Fatalf("expected an error for the cycle")
+	}
+	for _, k := range []string{"BackendService:b", "UrlMap:u", "TargetHttpProxy:p"} {
+		if !strings.Contains(err.Error(), k) {
+			t.Errorf("expected error to name %s, got %v", k, err)
+		}
+	}
+	if strings.Contains(err.Error(), "Address:a") {
+		t.Errorf("expected error not to name resources outside the cycle, got %v", err)
+	}
+}
+
+func TestSortedResources(t *testing.T) {
+	resourceMap := map[string]*resources.Resource{
+		"ForwardingRule:fr":  {Type: "ForwardingRule", ID: "fr", Blocks: []string{"TargetPool:tp", "Address:a"}},
+		"TargetPool:tp":      {Type: "TargetPool", ID: "tp", Blocks: []string{"HttpHealthCheck:hc"}},
+		"HttpHealthCheck:hc": {Type: "HttpHealthCheck", ID: "hc"},
+		"Address:a":          {Type: "Address", ID: "a"},
+		"Instance:i":         {Type: "Instance", ID: "i", Blocks: []string{"Disk:d"}},
+		"Disk:d":             {Type: "Disk", ID: "d"},
+		"Subnet:s":           {Type: "Subnet", ID: "s", Blocked: []string{"Instance:i"}},
+		"Network:n":          {Type: "Network", ID: "n", Blocked: []string{"Subnet:s", "FirewallRule:missing"}},
+	}
+
+	var actual []string
+	for _, r := range SortedResources(resourceMap) {
+		actual = append(actual, r.Type+":"+r.ID)
+	}
+	expected := []string{
+		"ForwardingRule:fr",
+		"Address:a",
+		"Instance:i",
+		"Disk:d",
+		"Subnet:s",
+		"Network:n",
+		"TargetPool:tp",
+		"HttpHealthCheck:hc",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected order, got %v, expected %v", actual, expected)
+	}
+}
+
+func TestSortedResourcesCycle(t *testing.T) {
+	resourceMap := map[string]*resources.Resource{
+		"BackendService:b":  {Type: "BackendService", ID: "b", Blocked: []string{"UrlMap:u"}},
+		"UrlMap:u":          {Type: "UrlMap", ID: "u", Blocked: []string{"BackendService:b"}},
+		"Address:a":         {Type: "Address", ID: "a", Blocked: []string{"TargetHttpProxy:p"}},
+		"TargetHttpProxy:p": {Type: "TargetHttpProxy", ID: "p"},
+	}
+
+	var actual []string
+	for _, r := range SortedResources(resourceMap) {
+		actual = append(actual, r.Type+":"+r.ID)
+	}
+	// The resources in the cycle come last
+	expected := []string{"TargetHttpProxy:p", "Address:a", "BackendService:b", "UrlMap:u"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected order, got %v, expected %v", actual, expected)
+	}
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"context"
+	"fmt"
+
+	compute "google.golang.org/api/compute/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/kops/pkg/apis/kops"
+	nodeidentitygce "k8s.io/kops/pkg/nodeidentity/gce"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+// instanceGroupResourceTypes are the types discovered when discovery is scoped to a single instance group
+var instanceGroupResourceTypes = []string{typeInstanceTemplate, typeInstanceGroupManager, typeInstance, typeAutoscaler, typeDisk}
+
+// instanceGroupOfMetadata returns the kops instance group named in the metadata of an instance or instance template
+func instanceGroupOfMetadata(metadata *compute.Metadata) string {
+	if metadata == nil {
+		return ""
+	}
+	for _, item := range metadata.Items {
+		if item.Key == nodeidentitygce.MetadataKeyInstanceGroupName && item.Value != nil {
+			return *item.Value
+		}
+	}
+	return ""
+}
+
+// templateInInstanceGroup checks if the instance template is for the instance group discovery is scoped to, if any
+func (d *clusterDiscoveryGCE) templateInInstanceGroup(t *compute.InstanceTemplate) bool {
+	if d.instanceGroup == "" {
+		return true
+	}
+	return t.Properties != nil && instanceGroupOfMetadata(t.Properties.Metadata) == d.instanceGroup
+}
+
+// instanceInInstanceGroup checks if the instance is in the instance group discovery is scoped to, if any
+func (d *clusterDiscoveryGCE) instanceInInstanceGroup(i *compute.Instance) bool {
+	if d.instanceGroup == "" {
+		return true
+	}
+	return instanceGroupOfMetadata(i.Metadata) == d.instanceGroup
+}
+
+// migInInstanceGroup checks if the MIG is for the instance group discovery is scoped to, if any.
+// We go by its instance template if it is one of ours, or else by its name, see gce.NameForInstanceGroupManager.
+func (d *clusterDiscoveryGCE) migInInstanceGroup(mig *compute.InstanceGroupManager, instanceTemplate *compute.InstanceTemplate) bool {
+	if d.instanceGroup == "" {
+		return true
+	}
+	if instanceTemplate != nil {
+		return d.templateInInstanceGroup(instanceTemplate)
+	}
+
+	cluster := &kops.Cluster{}
+	cluster.ObjectMeta.Name = d.clusterName
+	ig := &kops.InstanceGroup{}
+	ig.ObjectMeta.Name = d.instanceGroup
+	location := gce.LastComponent(mig.Zone)
+	if location == "" {
+		location = gce.LastComponent(mig.Region)
+	}
+	return mig.Name == gce.NameForInstanceGroupManager(cluster, ig, location)
+}
+
+// findInstanceGroupDisks returns the names of the disks of the instance group discovery is scoped to:
+// the disks attached to its instances, and the existing disks its instance templates attach
+func (d *clusterDiscoveryGCE) findInstanceGroupDisks(ctx context.Context) (sets.String, error) {
+	disks := sets.NewString()
+
+	templates, err := d.findInstanceTemplates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range templates {
+		if !d.templateInInstanceGroup(t) {
+			continue
+		}
+		for _, attached := range t.Properties.Disks {
+			if attached.Source != "" {
+				disks.Insert(gce.LastComponent(attached.Source))
+			}
+		}
+	}
+
+	for _, zoneName := range d.zones {
+		if err := d.waitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+		instances, err := d.compute().Instances().List(ctx, d.gceCloud.Project(), zoneName)
+		if err != nil {
+			return nil, fmt.Errorf("error listing Instances: %v", err)
+		}
+		for _, i := range instances {
+			if !d.instanceInInstanceGroup(i) {
+				continue
+			}
+			for _, attached := range i.Disks {
+				if attached.Source != "" {
+					disks.Insert(gce.LastComponent(attached.Source))
+				}
+			}
+		}
+	}
+
+	return disks, nil
+}
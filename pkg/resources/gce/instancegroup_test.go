@@ -0,0 +1,156 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	compute "google.golang.org/api/compute/v1"
+	gcemock "k8s.io/kops/cloudmock/gce"
+	nodeidentitygce "k8s.io/kops/pkg/nodeidentity/gce"
+)
+
+func TestListInstanceGroupResources(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	clusterName := "cluster.example.com"
+	clusterLabels := map[string]string{"k8s-io-cluster-name": "cluster-example-com"}
+	metadata := func(instanceGroup string) *compute.Metadata {
+		return &compute.Metadata{
+			Items: []*compute.MetadataItems{
+				{Key: "cluster-name", Value: &clusterName},
+				{Key: nodeidentitygce.MetadataKeyInstanceGroupName, Value: &instanceGroup},
+			},
+		}
+	}
+
+	templates := make(map[string]*compute.InstanceTemplate)
+	for _, ig := range []string{"nodes", "master-us-test1-a"} {
+		template := &compute.InstanceTemplate{
+			Name:       ig + "-cluster-example-com-1234",
+			Properties: &compute.InstanceProperties{Metadata: metadata(ig)},
+		}
+		if _, err := cloud.Compute().InstanceTemplates().Insert("testproject", template); err != nil {
+			t.Fatalf("error creating instance template: %v", err)
+		}
+		templates[ig] = template
+	}
+
+	for _, mig := range []*compute.InstanceGroupManager{
+		{
+			Name:             "b-nodes-cluster-example-com",
+			Zone:             "us-test1-b",
+			InstanceTemplate: templates["nodes"].SelfLink,
+		},
+		{
+			Name:             "b-master-us-test1-a-cluster-example-com",
+			Zone:             "us-test1-b",
+			InstanceTemplate: templates["master-us-test1-a"].SelfLink,
+		},
+	} {
+		if _, err := cloud.Compute().InstanceGroupManagers().Insert("testproject", "us-test1-b", mig); err != nil {
+			t.Fatalf("error creating instance group manager: %v", err)
+		}
+	}
+	// The template of these MIGs is gone (or not ours), so they are matched by their names
+	for _, name := range []string{"a-nodes-cluster-example-com", "a-master-us-test1-a-cluster-example-com"} {
+		if _, err := cloud.Compute().InstanceGroupManagers().Insert("testproject", "us-test1-a", &compute.InstanceGroupManager{
+			Name:             name,
+			Zone:             "us-test1-a",
+			InstanceTemplate: "https://www.googleapis.com/compute/v1/projects/testproject/global/instanceTemplates/unknown",
+		}); err != nil {
+			t.Fatalf("error creating instance group manager: %v", err)
+		}
+	}
+
+	for _, instance := range []struct {
+		Name          string
+		InstanceGroup string
+		Disk          string
+	}{
+		{Name: "nodes-abcd", InstanceGroup: "nodes", Disk: "nodes-abcd"},
+		{Name: "master-us-test1-a-efgh", InstanceGroup: "master-us-test1-a", Disk: "a-etcd-main-cluster-example-com"},
+	} {
+		diskSelfLink := "https://www.googleapis.com/compute/v1/projects/testproject/zones/us-test1-a/disks/" + instance.Disk
+		if _, err := cloud.Compute().Instances().Insert("testproject", "us-test1-a", &compute.Instance{
+			Name:     instance.Name,
+			Labels:   clusterLabels,
+			Metadata: metadata(instance.InstanceGroup),
+			Disks:    []*compute.AttachedDisk{{Source: diskSelfLink}},
+		}); err != nil {
+			t.Fatalf("error creating instance: %v", err)
+		}
+		if _, err := cloud.Compute().Disks().Insert("testproject", "us-test1-a", &compute.Disk{
+			Name:   instance.Disk,
+			Zone:   "https://www.googleapis.com/compute/v1/projects/testproject/zones/us-test1-a",
+			Labels: clusterLabels,
+			Users:  []string{"https://www.googleapis.com/compute/v1/projects/testproject/zones/us-test1-a/instances/" + instance.Name},
+		}); err != nil {
+			t.Fatalf("error creating disk: %v", err)
+		}
+	}
+
+	d := &clusterDiscoveryGCE{
+		cloud:         cloud,
+		gceCloud:      cloud,
+		clusterName:   clusterName,
+		zones:         []string{"us-test1-a", "us-test1-b"},
+		instanceGroup: "nodes",
+	}
+	resourceMap, err := runListFunctions(context.Background(), []gceListFn{
+		d.listGCEInstanceTemplates,
+		d.listInstanceGroupManagersAndInstances,
+		d.listGCEDisks,
+	}, 1)
+	if err != nil {
+		t.Fatalf("error listing resources: %v", err)
+	}
+
+	var keys []string
+	for k := range resourceMap {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	expected := []string{
+		"Disk:us-test1-a/nodes-abcd",
+		"Instance:us-test1-a/nodes-abcd",
+		"InstanceGroupManager:us-test1-a/a-nodes-cluster-example-com",
+		"InstanceGroupManager:us-test1-b/b-nodes-cluster-example-com",
+		"InstanceTemplate:nodes-cluster-example-com-1234",
+	}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("unexpected resources, got %v, expected %v", keys, expected)
+	}
+}
+
+func TestSelectResourceTypesInstanceGroup(t *testing.T) {
+	d := &clusterDiscoveryGCE{}
+	selected, err := d.selectResourceTypes(ListResourcesGCEOptions{
+		InstanceGroup: "nodes",
+		ExcludeTypes:  []string{typeAutoscaler},
+	})
+	if err != nil {
+		t.Fatalf("error selecting resource types: %v", err)
+	}
+	expected := []string{typeDisk, typeInstance, typeInstanceGroupManager, typeInstanceTemplate}
+	if !reflect.DeepEqual(selected.List(), expected) {
+		t.Errorf("unexpected resource types, got %v, expected %v", selected.List(), expected)
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"k8s.io/kops/pkg/resources"
+)
+
+// resourceJSON is the serialized form of a discovered resource.
+// The Deleter, GroupDeleter and Dumper functions and the underlying GCE object are not included.
+type resourceJSON struct {
+	Type     string   `json:"type"`
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Shared   bool     `json:"shared,omitempty"`
+	GroupKey string   `json:"groupKey,omitempty"`
+	Blocks   []string `json:"blocks,omitempty"`
+	Blocked  []string `json:"blocked,omitempty"`
+}
+
+// MarshalResources serializes the discovered resources to JSON, for use by scripts.
+// Resources are sorted by key and their Blocks and Blocked are sorted, so the output is stable across runs.
+func MarshalResources(resourceMap map[string]*resources.Resource) ([]byte, error) {
+	var keys []string
+	for k := range resourceMap {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	l := make([]resourceJSON, 0, len(keys))
+	for _, k := range keys {
+		r := resourceMap[k]
+		l = append(l, resourceJSON{
+			Type:     r.Type,
+			ID:       r.ID,
+			Name:     r.Name,
+			Shared:   r.Shared,
+			GroupKey: r.GroupKey,
+			Blocks:   sortedCopy(r.Blocks),
+			Blocked:  sortedCopy(r.Blocked),
+		})
+	}
+
+	b, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling resources: %v", err)
+	}
+	return b, nil
+}
+
+// sortedCopy returns a sorted copy of l, so the caller's slice is not modified
+func sortedCopy(l []string) []string {
+	if len(l) == 0 {
+		return nil
+	}
+	c := append([]string(nil), l...)
+	sort.Strings(c)
+	return c
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"path/filepath"
+	"testing"
+
+	compute "google.golang.org/api/compute/v1"
+	"k8s.io/kops/pkg/resources"
+	"k8s.io/kops/pkg/testutils/golden"
+)
+
+func TestMarshalResources(t *testing.T) {
+	resourceMap := make(map[string]*resources.Resource)
+	for _, r := range []*resources.Resource{
+		{
+			Name:    "nodes-cluster-example-com",
+			ID:      "us-test1-a/nodes-cluster-example-com",
+			Type:    typeInstanceGroupManager,
+			Blocks:  []string{typeInstanceTemplate + ":nodes-cluster-example-com-1234"},
+			Deleter: deleteInstanceGroup,
+			Dumper:  DumpResource,
+			Obj:     &compute.InstanceGroupManager{Name: "nodes-cluster-example-com"},
+		},
+		{
+			Name:     "nodes-cluster-example-com-1234",
+			ID:       "nodes-cluster-example-com-1234",
+			Type:     typeInstanceTemplate,
+			GroupKey: "nodes",
+			Obj:      &compute.InstanceTemplate{Name: "nodes-cluster-example-com-1234"},
+		},
+		{
+			Name:    "default",
+			ID:      "default",
+			Type:    typeNetwork,
+			Shared:  true,
+			Blocked: []string{typeSubnet + ":us-test1-cluster-example-com", typeFirewallRule + ":node-to-node-cluster-example-com"},
+		},
+	} {
+		resourceMap[r.Type+":"+r.ID] = r
+	}
+
+	actual, err := MarshalResources(resourceMap)
+	if err != nil {
+		t.Fatalf("error marshaling resources: %v", err)
+	}
+	golden.AssertMatchesFile(t, string(actual), filepath.Join("testdata", "marshal", "resources.json"))
+
+	// The output must not depend on the order of the input
+	again, err := MarshalResources(resourceMap)
+	if err != nil {
+		t.Fatalf("error marshaling resources: %v", err)
+	}
+	if string(again) != string(actual) {
+		t.Errorf("output is not stable, got %s then %s", actual, again)
+	}
+	if blocked := resourceMap[typeNetwork+":default"].Blocked; blocked[0] != typeSubnet+":us-test1-cluster-example-com" {
+		t.Errorf("MarshalResources modified Blocked: %v", blocked)
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"strings"
+
+	compute "google.golang.org/api/compute/v1"
+
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+// diskClusterLabelKeys are the label keys that tie a disk to a cluster, with the value gce.SafeClusterName.
+// As well as our own label, the GCE PD CSI driver can label the disks it provisions for PVs with the cluster name,
+// which we need to recognize as those disks are leaked if the PVs were not deleted before the cluster.
+var diskClusterLabelKeys = []string{
+	gce.GceLabelNameKubernetesCluster,
+	"goog-k8s-cluster-name",
+}
+
+// MatchesCluster checks if a resource of the given type (e.g. "Disk" or "FirewallRule", as in resources.Resource Type),
+// with the given name and labels, belongs to the cluster, using the same name and label heuristics as discovery.
+// Discovery also matches some resources by their relationships (e.g. disks referenced by the cluster instance templates),
+// and checks more than the name for some types (e.g. the target tags of firewall rules); those checks are not made here.
+func MatchesCluster(clusterName string, resourceType string, name string, labels map[string]string) bool {
+	d := &clusterDiscoveryGCE{
+		clusterName: clusterName,
+	}
+	return d.matchesCluster(resourceType, name, labels)
+}
+
+// matchesCluster checks if the name and labels of a resource of the given type match the cluster, see MatchesCluster
+func (d *clusterDiscoveryGCE) matchesCluster(resourceType string, name string, labels map[string]string) bool {
+	switch resourceType {
+	// Resources created with the cluster label, whose names are not tied to the cluster
+	case typeSnapshot, typeMachineImage, typeImage, typeTPUNode:
+		return d.matchesClusterLabel(labels)
+	case typeDisk:
+		return d.matchesDiskClusterLabel(labels)
+
+	// Routes and their tags start with the cluster name, see gce.GCETagForRole
+	case typeRoute:
+		return d.hasClusterNamePrefix(name)
+
+	case typeFirewallRule:
+		return d.matchesGeneratedName(name, maxFirewallRuleTokens, firewallRuleNameSuffixes)
+	case typeHealthCheck:
+		return d.matchesClusterNameMultipart(name, maxHealthCheckTokens)
+	case typeInstanceGroupManager:
+		return d.matchesClusterNameMultipart(name, maxInstanceGroupManagerTokens)
+
+	default:
+		return d.matchesClusterLabelOrName(labels, name, maxRoleNameTokens)
+	}
+}
+
+// hasDiskClusterLabel checks if the labels include any of the diskClusterLabelKeys, whatever their values
+func hasDiskClusterLabel(labels map[string]string) bool {
+	for _, k := range diskClusterLabelKeys {
+		if _, found := labels[k]; found {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesDiskClusterLabel checks if the labels include at least one of the diskClusterLabelKeys,
+// and that all of them that are present have the value for our cluster
+func (d *clusterDiscoveryGCE) matchesDiskClusterLabel(labels map[string]string) bool {
+	clusterTag := gce.SafeClusterName(d.clusterName)
+
+	match := false
+	for _, k := range diskClusterLabelKeys {
+		v, found := labels[k]
+		if !found {
+			continue
+		}
+		if v != clusterTag {
+			return false
+		}
+		match = true
+	}
+	return match
+}
+
+// matchesInstanceTemplate checks if an instance template belongs to the cluster.
+// Its instance metadata must have key='cluster-name' with the value of our cluster name, and no other value for that key.
+// Template names are not considered, as another cluster may use the same names.
+// If the template also carries the cluster label, the label must be for our cluster as well.
+func (d *clusterDiscoveryGCE) matchesInstanceTemplate(t *compute.InstanceTemplate) bool {
+	findClusterName := strings.TrimSpace(d.clusterName)
+
+	if t.Properties == nil || t.Properties.Metadata == nil {
+		return false
+	}
+
+	match := false
+	for _, item := range t.Properties.Metadata.Items {
+		if item.Key != "cluster-name" {
+			continue
+		}
+		if strings.TrimSpace(fi.StringValue(item.Value)) != findClusterName {
+			return false
+		}
+		match = true
+	}
+	if !match {
+		return false
+	}
+
+	if v, ok := t.Properties.Labels[gce.GceLabelNameKubernetesCluster]; ok && v != gce.SafeClusterName(findClusterName) {
+		return false
+	}
+	return true
+}
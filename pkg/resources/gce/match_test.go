@@ -0,0 +1,63 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"testing"
+)
+
+func TestMatchesCluster(t *testing.T) {
+	clusterLabel := map[string]string{"k8s-io-cluster-name": "cluster-example-com"}
+	otherLabel := map[string]string{"k8s-io-cluster-name": "other-example-com"}
+
+	grid := []struct {
+		Type   string
+		Name   string
+		Labels map[string]string
+		Match  bool
+	}{
+		// Name only
+		{Type: typeAddress, Name: "api-cluster-example-com", Match: true},
+		{Type: typeAddress, Name: "api-other-example-com", Match: false},
+		{Type: typeFirewallRule, Name: "nodeport-external-to-node-ipv6-cluster-example-com", Match: true},
+		{Type: typeHealthCheck, Name: "nodeport-external-to-node-cluster-example-com", Match: true},
+		{Type: typeInstanceGroupManager, Name: "a-master-us-test1-a-cluster-example-com", Match: true},
+		{Type: typeRoute, Name: "cluster-example-com-abcd", Match: true},
+		{Type: typeRoute, Name: "other-example-com-abcd", Match: false},
+
+		// Label only
+		{Type: typeDisk, Name: "a-etcd-main", Labels: clusterLabel, Match: true},
+		{Type: typeDisk, Name: "a-etcd-main-cluster-example-com", Match: false},
+		{Type: typeDisk, Name: "pvc-1234", Labels: map[string]string{"goog-k8s-cluster-name": "cluster-example-com"}, Match: true},
+		{Type: typeDisk, Name: "pvc-1234", Labels: map[string]string{"goog-k8s-cluster-name": "other-example-com"}, Match: false},
+		{Type: typeSnapshot, Name: "snapshot", Labels: map[string]string{"goog-k8s-cluster-name": "cluster-example-com"}, Match: false},
+		{Type: typeSnapshot, Name: "snapshot", Labels: otherLabel, Match: false},
+		{Type: typeForwardingRule, Name: "ingress", Labels: clusterLabel, Match: true},
+
+		// Both: the label takes precedence over the name
+		{Type: typeForwardingRule, Name: "api-cluster-example-com", Labels: clusterLabel, Match: true},
+		{Type: typeForwardingRule, Name: "api-cluster-example-com", Labels: otherLabel, Match: false},
+		{Type: typeInstance, Name: "nodes-cluster-example-com", Labels: clusterLabel, Match: true},
+		{Type: typeDisk, Name: "a-etcd-main-other-example-com", Labels: clusterLabel, Match: true},
+	}
+	for _, g := range grid {
+		match := MatchesCluster("cluster.example.com", g.Type, g.Name, g.Labels)
+		if match != g.Match {
+			t.Errorf("unexpected match value for %s %q with labels %v, got %v, expected %v", g.Type, g.Name, g.Labels, match, g.Match)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/resources"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+// populateMetadata sets the Region, Zone and CreationTimestamp of the resource from its Compute API object,
+// unless the list function already set them (e.g. instances, whose object doesn't carry them)
+func populateMetadata(r *resources.Resource) {
+	if r.Zone == "" {
+		if zone, ok := computeStringField(r.Obj, "Zone"); ok && zone != "" {
+			r.Zone = gce.LastComponent(zone)
+		}
+	}
+	if r.Region == "" {
+		if region, ok := computeStringField(r.Obj, "Region"); ok && region != "" {
+			r.Region = gce.LastComponent(region)
+		} else if r.Zone != "" {
+			r.Region = regionOfZone(r.Zone)
+		}
+	}
+	if r.CreationTimestamp.IsZero() {
+		if s, ok := computeStringField(r.Obj, "CreationTimestamp"); ok && s != "" {
+			t, err := time.Parse(time.RFC3339, s)
+			if err != nil {
+				klog.Warningf("ignoring creation timestamp %q of %s:%s: %v", s, r.Type, r.ID, err)
+			} else {
+				r.CreationTimestamp = t
+			}
+		}
+	}
+}
+
+// regionOfZone returns the region of a zone, e.g. us-central1 for us-central1-a
+func regionOfZone(zone string) string {
+	if i := strings.LastIndex(zone, "-"); i > 0 {
+		return zone[:i]
+	}
+	return ""
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"testing"
+	"time"
+
+	compute "google.golang.org/api/compute/v1"
+	gcemock "k8s.io/kops/cloudmock/gce"
+	"k8s.io/kops/cloudmock/gce/mockcompute"
+)
+
+func TestListResourcesMetadata(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	clusterName := "cluster.example.com"
+	template := &compute.InstanceTemplate{
+		Name: "nodes-cluster-example-com-1234",
+		Properties: &compute.InstanceProperties{
+			Metadata: &compute.Metadata{
+				Items: []*compute.MetadataItems{{Key: "cluster-name", Value: &clusterName}},
+			},
+		},
+	}
+	if _, err := cloud.Compute().InstanceTemplates().Insert("testproject", template); err != nil {
+		t.Fatalf("error creating instance template: %v", err)
+	}
+	mig := &compute.InstanceGroupManager{
+		Name:             "nodes-cluster-example-com",
+		InstanceTemplate: template.SelfLink,
+	}
+	if _, err := cloud.Compute().RegionInstanceGroupManagers().Insert("testproject", "us-test1", mig); err != nil {
+		t.Fatalf("error creating regional instance group manager: %v", err)
+	}
+	cloud.Compute().(*mockcompute.MockClient).AddRegionManagedInstance(mig, &compute.ManagedInstance{
+		Instance: "https://www.googleapis.com/compute/v1/projects/testproject/zones/us-test1-a/instances/nodes-abcd",
+	})
+
+	if _, err := cloud.Compute().Disks().Insert("testproject", "us-test1-a", &compute.Disk{
+		Name:              "a-etcd-main-cluster-example-com",
+		Labels:            map[string]string{"k8s-io-cluster-name": "cluster-example-com"},
+		CreationTimestamp: "2021-06-01T10:00:00.000-07:00",
+	}); err != nil {
+		t.Fatalf("error creating disk: %v", err)
+	}
+	if _, err := cloud.Compute().Addresses().Insert("testproject", "us-test1", &compute.Address{
+		Name:              "api-cluster-example-com",
+		Region:            "https://www.googleapis.com/compute/v1/projects/testproject/regions/us-test1",
+		CreationTimestamp: "2021-06-02T10:00:00.000-07:00",
+	}); err != nil {
+		t.Fatalf("error creating address: %v", err)
+	}
+
+	resourceMap, err := listResourcesForTest(cloud, clusterName, "us-test1")
+	if err != nil {
+		t.Fatalf("error listing resources: %v", err)
+	}
+
+	grid := []struct {
+		Key               string
+		Region            string
+		Zone              string
+		CreationTimestamp time.Time
+	}{
+		{
+			Key:    "Instance:us-test1-a/nodes-abcd",
+			Region: "us-test1",
+			Zone:   "us-test1-a",
+		},
+		{
+			Key:               "Disk:us-test1-a/a-etcd-main-cluster-example-com",
+			Region:            "us-test1",
+			Zone:              "us-test1-a",
+			CreationTimestamp: time.Date(2021, 6, 1, 17, 0, 0, 0, time.UTC),
+		},
+		{
+			Key:               "Address:us-test1/api-cluster-example-com",
+			Region:            "us-test1",
+			CreationTimestamp: time.Date(2021, 6, 2, 17, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, g := range grid {
+		r := resourceMap[g.Key]
+		if r == nil {
+			t.Errorf("expected %q to be tracked, got %v", g.Key, resourceMap)
+			continue
+		}
+		if r.Region != g.Region || r.Zone != g.Zone {
+			t.Errorf("unexpected location for %q, got region %q zone %q, expected region %q zone %q", g.Key, r.Region, r.Zone, g.Region, g.Zone)
+		}
+		if !r.CreationTimestamp.Equal(g.CreationTimestamp) {
+			t.Errorf("unexpected creation timestamp for %q, got %v, expected %v", g.Key, r.CreationTimestamp, g.CreationTimestamp)
+		}
+	}
+}
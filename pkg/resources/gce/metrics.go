@@ -0,0 +1,67 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/kops/pkg/resources"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+// Metrics receives how long discovery and deletion take, e.g. so the CLI can print a timing breakdown by resource type.
+// Its methods may be called concurrently.
+type Metrics interface {
+	// ObserveList is called once for each list function, with the resource types it discovers
+	ObserveList(resourceTypes []string, duration time.Duration, err error)
+	// ObserveDelete is called each time a resource is deleted; resources deleted as a group are each observed with the time of the group
+	ObserveDelete(resourceType string, id string, duration time.Duration, err error)
+}
+
+// timedList wraps the list function so that its duration is reported to the metrics
+func timedList(metrics Metrics, resourceTypes []string, list gceListFn) gceListFn {
+	return func(ctx context.Context) ([]*resources.Resource, error) {
+		start := time.Now()
+		resourceTrackers, err := list(ctx)
+		metrics.ObserveList(resourceTypes, time.Since(start), err)
+		return resourceTrackers, err
+	}
+}
+
+// withMetrics wraps the deleters of the resource so that their duration is reported to the metrics
+func withMetrics(t *resources.Resource, metrics Metrics) {
+	if deleter := t.Deleter; deleter != nil {
+		t.Deleter = func(cloud fi.Cloud, r *resources.Resource) error {
+			start := time.Now()
+			err := deleter(cloud, r)
+			metrics.ObserveDelete(r.Type, r.ID, time.Since(start), err)
+			return err
+		}
+	}
+	if groupDeleter := t.GroupDeleter; groupDeleter != nil {
+		t.GroupDeleter = func(cloud fi.Cloud, trackers []*resources.Resource) error {
+			start := time.Now()
+			err := groupDeleter(cloud, trackers)
+			duration := time.Since(start)
+			for _, r := range trackers {
+				metrics.ObserveDelete(r.Type, r.ID, duration, err)
+			}
+			return err
+		}
+	}
+}
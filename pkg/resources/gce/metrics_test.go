@@ -0,0 +1,91 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	compute "google.golang.org/api/compute/v1"
+	gcemock "k8s.io/kops/cloudmock/gce"
+)
+
+// fakeMetrics records the observations it receives
+type fakeMetrics struct {
+	mutex   sync.Mutex
+	lists   []string
+	deletes []string
+}
+
+var _ Metrics = &fakeMetrics{}
+
+func (m *fakeMetrics) ObserveList(resourceTypes []string, duration time.Duration, err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.lists = append(m.lists, strings.Join(resourceTypes, ","))
+}
+
+func (m *fakeMetrics) ObserveDelete(resourceType string, id string, duration time.Duration, err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.deletes = append(m.deletes, resourceType+":"+id)
+}
+
+func TestListResourcesMetrics(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+	if _, err := cloud.Compute().Disks().Insert("testproject", "us-test1-a", &compute.Disk{
+		Name:   "a-etcd-main-cluster-example-com",
+		Labels: map[string]string{"k8s-io-cluster-name": "cluster-example-com"},
+	}); err != nil {
+		t.Fatalf("error creating disk: %v", err)
+	}
+
+	metrics := &fakeMetrics{}
+	resourceMap, err := ListResourcesGCEWithOptions(context.Background(), cloud, "cluster.example.com", "us-test1", ListResourcesGCEOptions{Metrics: metrics, QPS: testQPS})
+	if err != nil {
+		t.Fatalf("error listing resources: %v", err)
+	}
+
+	// One timing for each list function, including the routes and network, which are listed last
+	d := &clusterDiscoveryGCE{}
+	var expected []string
+	for _, f := range d.typedListFunctions() {
+		expected = append(expected, strings.Join(f.types, ","))
+	}
+	expected = append(expected, typeRoute, typeNetwork)
+	sort.Strings(expected)
+	sort.Strings(metrics.lists)
+	if !reflect.DeepEqual(metrics.lists, expected) {
+		t.Errorf("unexpected list timings, got %v, expected %v", metrics.lists, expected)
+	}
+
+	r := resourceMap["Disk:us-test1-a/a-etcd-main-cluster-example-com"]
+	if r == nil {
+		t.Fatalf("expected disk to be tracked, got %v", resourceMap)
+	}
+	if err := r.Deleter(cloud, r); err != nil {
+		t.Fatalf("error deleting disk: %v", err)
+	}
+	if expected := []string{"Disk:us-test1-a/a-etcd-main-cluster-example-com"}; !reflect.DeepEqual(metrics.deletes, expected) {
+		t.Errorf("unexpected delete timings, got %v, expected %v", metrics.deletes, expected)
+	}
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	compute "google.golang.org/api/compute/v1"
+	"google.golang.org/api/googleapi"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+var (
+	// opPollInterval is how long we wait before first polling an operation.
+	// The interval grows by opPollBackoff after each poll, up to maxOpPollInterval.
+	opPollInterval    = 2 * time.Second
+	maxOpPollInterval = 20 * time.Second
+)
+
+const (
+	opPollBackoff = 1.5
+	// opPollJitter spreads the polls of operations that started together, so mass deletion doesn't poll GCE in bursts
+	opPollJitter = 0.5
+)
+
+// waitForOp waits for the operation to complete, polling it with a jittered backoff until the context is done.
+// If the cloud bounds how long we wait for operations (see withOpTimeout), that bound applies as well.
+func waitForOp(ctx context.Context, cloud gce.GCECloud, op *compute.Operation) error {
+	if c, ok := cloud.(*opTimeoutCloud); ok {
+		return c.waitForOp(ctx, op)
+	}
+	return pollOp(ctx, cloud, op)
+}
+
+// pollOp polls the operation until it is done or the context is done
+func pollOp(ctx context.Context, cloud gce.GCECloud, op *compute.Operation) error {
+	if op == nil {
+		return fmt.Errorf("operation must not be nil")
+	}
+
+	interval := opPollInterval
+	for op.Status != "DONE" {
+		timer := time.NewTimer(wait.Jitter(interval, opPollJitter))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("error waiting for operation %q: %v", op.Name, ctx.Err())
+		case <-timer.C:
+		}
+
+		latest, err := cloud.Compute().Operations().Get(ctx, op)
+		if err != nil {
+			// As in gce.WaitForOp, a failed poll is retried
+			klog.Warningf("error polling GCE operation %q: %v", op.Name, err)
+		} else {
+			op = latest
+		}
+
+		interval = time.Duration(float64(interval) * opPollBackoff)
+		if interval > maxOpPollInterval {
+			interval = maxOpPollInterval
+		}
+	}
+
+	if op.Error != nil && len(op.Error.Errors) != 0 {
+		apiErr := &googleapi.Error{
+			Code:    int(op.HttpErrorStatusCode),
+			Message: op.Error.Errors[0].Message,
+		}
+		// Keep the details, so the errors of operations are classified like those of API calls (e.g. isResourceInUse)
+		for _, e := range op.Error.Errors {
+			apiErr.Errors = append(apiErr.Errors, googleapi.ErrorItem{
+				Reason:  operationErrorReason(e.Code),
+				Message: e.Message,
+			})
+		}
+		return apiErr
+	}
+	return nil
+}
+
+// operationErrorReason converts the code of an operation error (e.g. RESOURCE_IN_USE_BY_ANOTHER_RESOURCE)
+// to the reason the API returns for the same error (e.g. resourceInUseByAnotherResource)
+func operationErrorReason(code string) string {
+	var reason strings.Builder
+	for i, word := range strings.Split(strings.ToLower(code), "_") {
+		if i > 0 && word != "" {
+			word = strings.ToUpper(word[:1]) + word[1:]
+		}
+		reason.WriteString(word)
+	}
+	return reason.String()
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	compute "google.golang.org/api/compute/v1"
+	gcemock "k8s.io/kops/cloudmock/gce"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+// pendingOpCloud is a GCECloud whose disk deletions return a running operation,
+// which is done once it has been polled donePolls times; it is never done if donePolls is 0
+type pendingOpCloud struct {
+	gce.GCECloud
+
+	mutex     sync.Mutex
+	polls     int
+	donePolls int
+}
+
+func (c *pendingOpCloud) Compute() gce.ComputeClient {
+	return &pendingOpCompute{ComputeClient: c.GCECloud.Compute(), cloud: c}
+}
+
+func (c *pendingOpCloud) pollCount() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.polls
+}
+
+type pendingOpCompute struct {
+	gce.ComputeClient
+	cloud *pendingOpCloud
+}
+
+func (c *pendingOpCompute) Disks() gce.DiskClient {
+	return &pendingOpDisks{DiskClient: c.ComputeClient.Disks()}
+}
+
+func (c *pendingOpCompute) Operations() gce.OperationClient {
+	return c
+}
+
+func (c *pendingOpCompute) Get(ctx context.Context, op *compute.Operation) (*compute.Operation, error) {
+	c.cloud.mutex.Lock()
+	defer c.cloud.mutex.Unlock()
+	c.cloud.polls++
+	if c.cloud.donePolls != 0 && c.cloud.polls >= c.cloud.donePolls {
+		return &compute.Operation{Name: op.Name, Status: "DONE"}, nil
+	}
+	return &compute.Operation{Name: op.Name, Status: "RUNNING"}, nil
+}
+
+type pendingOpDisks struct {
+	gce.DiskClient
+}
+
+func (c *pendingOpDisks) Delete(project, zone, name string) (*compute.Operation, error) {
+	if _, err := c.DiskClient.Delete(project, zone, name); err != nil {
+		return nil, err
+	}
+	return &compute.Operation{Name: "operation-delete-" + name, Status: "RUNNING"}, nil
+}
+
+func TestWaitForOpPollsUntilDone(t *testing.T) {
+	defer func(d time.Duration) { opPollInterval = d }(opPollInterval)
+	opPollInterval = time.Millisecond
+
+	cloud := &pendingOpCloud{GCECloud: gcemock.InstallMockGCECloud("us-test1", "testproject"), donePolls: 3}
+	op := &compute.Operation{Name: "operation-1", Status: "RUNNING"}
+	if err := waitForOp(context.Background(), cloud, op); err != nil {
+		t.Fatalf("error waiting for operation: %v", err)
+	}
+	if polls := cloud.pollCount(); polls != 3 {
+		t.Errorf("expected the operation to be polled 3 times, got %d", polls)
+	}
+}
+
+func TestWaitForOpCancelled(t *testing.T) {
+	cloud := &pendingOpCloud{GCECloud: gcemock.InstallMockGCECloud("us-test1", "testproject")}
+	op := &compute.Operation{Name: "operation-1", Status: "RUNNING"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- waitForOp(ctx, cloud, op)
+	}()
+	cancel()
+
+	// The default poll interval is seconds, so an abort within it shows we don't wait for the next poll
+	select {
+	case err := <-errCh:
+		if err == nil {
+			t.Fatalf("expected an error when the context is cancelled")
+		}
+	case <-time.After(opPollInterval / 2):
+		t.Fatalf("waitForOp did not abort when the context was cancelled")
+	}
+}
+
+func TestWaitForOpErrorDetails(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+	op := &compute.Operation{
+		Name:                "operation-1",
+		Status:              "DONE",
+		HttpErrorStatusCode: 400,
+		Error: &compute.OperationError{
+			Errors: []*compute.OperationErrorErrors{
+				{Code: "RESOURCE_IN_USE_BY_ANOTHER_RESOURCE", Message: "The subnetwork resource is already being used by an instance"},
+			},
+		},
+	}
+	err := waitForOp(context.Background(), cloud, op)
+	if err == nil {
+		t.Fatalf("expected the error of the operation")
+	}
+	if !isResourceInUse(err) {
+		t.Errorf("expected the error of the operation to be classified as in use, got %#v", err)
+	}
+	if isAlreadyGoneErr(err) {
+		t.Errorf("did not expect the error of the operation to be classified as gone, got %#v", err)
+	}
+}
+
+func TestOperationErrorReason(t *testing.T) {
+	grid := map[string]string{
+		"RESOURCE_IN_USE_BY_ANOTHER_RESOURCE": "resourceInUseByAnotherResource",
+		"RESOURCE_NOT_READY":                  "resourceNotReady",
+		"NOT_FOUND":                           "notFound",
+	}
+	for code, expected := range grid {
+		if actual := operationErrorReason(code); actual != expected {
+			t.Errorf("unexpected reason for %q, got %q, expected %q", code, actual, expected)
+		}
+	}
+}
+
+func TestDeleteResourcesCancelsOperations(t *testing.T) {
+	defer func(d time.Duration) { opPollInterval = d }(opPollInterval)
+	opPollInterval = time.Millisecond
+
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+	if _, err := cloud.Compute().Disks().Insert("testproject", "us-test1-a", &compute.Disk{
+		Name:   "a-etcd-main-cluster-example-com",
+		Labels: map[string]string{"k8s-io-cluster-name": "cluster-example-com"},
+	}); err != nil {
+		t.Fatalf("error creating disk: %v", err)
+	}
+	resourceMap, err := listResourcesForTest(cloud, "cluster.example.com", "us-test1")
+	if err != nil {
+		t.Fatalf("error listing resources: %v", err)
+	}
+
+	// The deletion operation never completes, so the deleter only returns once the context is cancelled
+	pending := &pendingOpCloud{GCECloud: cloud}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- DeleteResources(ctx, pending, resourceMap)
+	}()
+	for pending.pollCount() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+
+	select {
+	case err := <-errCh:
+		if err == nil {
+			t.Fatalf("expected an error when the context is cancelled")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("DeleteResources did not abort the operation when the context was cancelled")
+	}
+}
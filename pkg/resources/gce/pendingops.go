@@ -0,0 +1,166 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"context"
+	"fmt"
+
+	compute "google.golang.org/api/compute/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/resources"
+)
+
+// pendingOperationFilter selects the operations that have not completed
+const pendingOperationFilter = `status != "DONE"`
+
+// operationLister is the subset of the compute API we use to find the pending operations
+type operationLister interface {
+	ListZone(ctx context.Context, project, zone string) ([]*compute.Operation, error)
+	ListRegion(ctx context.Context, project, region string) ([]*compute.Operation, error)
+	ListGlobal(ctx context.Context, project string) ([]*compute.Operation, error)
+}
+
+type operationListerImpl struct {
+	srv *compute.Service
+}
+
+var _ operationLister = &operationListerImpl{}
+
+func newOperationLister(ctx context.Context) (*operationListerImpl, error) {
+	srv, err := compute.NewService(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error building compute API client: %v", err)
+	}
+	return &operationListerImpl{srv: srv}, nil
+}
+
+func (c *operationListerImpl) ListZone(ctx context.Context, project, zone string) ([]*compute.Operation, error) {
+	var l []*compute.Operation
+	if err := c.srv.ZoneOperations.List(project, zone).Filter(pendingOperationFilter).Pages(ctx, func(p *compute.OperationList) error {
+		l = append(l, p.Items...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (c *operationListerImpl) ListRegion(ctx context.Context, project, region string) ([]*compute.Operation, error) {
+	var l []*compute.Operation
+	if err := c.srv.RegionOperations.List(project, region).Filter(pendingOperationFilter).Pages(ctx, func(p *compute.OperationList) error {
+		l = append(l, p.Items...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (c *operationListerImpl) ListGlobal(ctx context.Context, project string) ([]*compute.Operation, error) {
+	var l []*compute.Operation
+	if err := c.srv.GlobalOperations.List(project).Filter(pendingOperationFilter).Pages(ctx, func(p *compute.OperationList) error {
+		l = append(l, p.Items...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// listPendingOperations finds the operations that have not completed on the discovered resources,
+// e.g. deletions started by an earlier run of kops delete cluster, which can make new deletes of those resources fail.
+// This is diagnostic: the operations are reported, not changed.
+func (d *clusterDiscoveryGCE) listPendingOperations(ctx context.Context, resourceMap map[string]*resources.Resource) ([]*compute.Operation, error) {
+	if d.operations == nil {
+		return nil, nil
+	}
+
+	// targets are the discovered resources, keyed by their self link without the API version
+	targets := make(map[string]string)
+	for k, r := range resourceMap {
+		selfLink, ok := computeSelfLink(r.Obj)
+		if !ok {
+			continue
+		}
+		if key := computeResourceKey(selfLink); key != "" {
+			targets[key] = k
+		}
+	}
+
+	project := d.gceCloud.Project()
+	var ops []*compute.Operation
+	for _, zoneName := range d.zones {
+		if err := d.waitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+		l, err := d.operations.ListZone(ctx, project, zoneName)
+		if err != nil {
+			return nil, fmt.Errorf("error listing zone operations: %v", err)
+		}
+		ops = append(ops, l...)
+	}
+	for _, region := range d.scanRegions() {
+		if err := d.waitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+		l, err := d.operations.ListRegion(ctx, project, region)
+		if err != nil {
+			return nil, fmt.Errorf("error listing region operations: %v", err)
+		}
+		ops = append(ops, l...)
+	}
+	{
+		if err := d.waitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+		l, err := d.operations.ListGlobal(ctx, project)
+		if err != nil {
+			return nil, fmt.Errorf("error listing global operations: %v", err)
+		}
+		ops = append(ops, l...)
+	}
+
+	var pending []*compute.Operation
+	for _, op := range ops {
+		if op.Status == "DONE" {
+			continue
+		}
+		k, found := targets[computeResourceKey(op.TargetLink)]
+		if !found {
+			continue
+		}
+		klog.Warningf("%s operation %q on %s is still %s, which may stop %s from being deleted", op.OperationType, op.Name, k, op.Status, k)
+		pending = append(pending, op)
+	}
+	return pending, nil
+}
+
+// waitForPendingOperations waits for the pending operations to complete. An operation that fails is only logged,
+// as it is the deletion that follows that matters.
+func (d *clusterDiscoveryGCE) waitForPendingOperations(ctx context.Context, ops []*compute.Operation) error {
+	for _, op := range ops {
+		klog.Infof("waiting for %s operation %q on %s", op.OperationType, op.Name, op.TargetLink)
+		if err := pollOp(ctx, d.gceCloud, op); err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			klog.Warningf("%s operation %q on %s failed: %v", op.OperationType, op.Name, op.TargetLink, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	compute "google.golang.org/api/compute/v1"
+	gcemock "k8s.io/kops/cloudmock/gce"
+)
+
+type fakeOperationLister struct {
+	zoneOps   map[string][]*compute.Operation
+	regionOps map[string][]*compute.Operation
+	globalOps []*compute.Operation
+}
+
+func (l *fakeOperationLister) ListZone(ctx context.Context, project, zone string) ([]*compute.Operation, error) {
+	return l.zoneOps[zone], nil
+}
+
+func (l *fakeOperationLister) ListRegion(ctx context.Context, project, region string) ([]*compute.Operation, error) {
+	return l.regionOps[region], nil
+}
+
+func (l *fakeOperationLister) ListGlobal(ctx context.Context, project string) ([]*compute.Operation, error) {
+	return l.globalOps, nil
+}
+
+func TestListPendingOperations(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	clusterLabels := map[string]string{"k8s-io-cluster-name": "cluster-example-com"}
+	if _, err := cloud.Compute().Disks().Insert("testproject", "us-test1-a", &compute.Disk{
+		Name:   "a-etcd-main-cluster-example-com",
+		Labels: clusterLabels,
+	}); err != nil {
+		t.Fatalf("error creating disk: %v", err)
+	}
+
+	d := &clusterDiscoveryGCE{
+		cloud:       cloud,
+		gceCloud:    cloud,
+		clusterName: "cluster.example.com",
+		zones:       []string{"us-test1-a"},
+		operations: &fakeOperationLister{
+			zoneOps: map[string][]*compute.Operation{
+				"us-test1-a": {
+					{
+						Name:          "operation-delete-etcd",
+						OperationType: "delete",
+						Status:        "RUNNING",
+						// Operations may target a different API version than the self link of the resource
+						TargetLink: "https://www.googleapis.com/compute/beta/projects/testproject/zones/us-test1-a/disks/a-etcd-main-cluster-example-com",
+					},
+					{
+						Name:          "operation-delete-other",
+						OperationType: "delete",
+						Status:        "PENDING",
+						TargetLink:    "https://www.googleapis.com/compute/v1/projects/testproject/zones/us-test1-a/disks/a-etcd-main-other-example-com",
+					},
+				},
+			},
+		},
+	}
+	resourceMap, err := runListFunctions(context.Background(), []gceListFn{d.listGCEDisks}, 1)
+	if err != nil {
+		t.Fatalf("error listing disks: %v", err)
+	}
+
+	ops, err := d.listPendingOperations(context.Background(), resourceMap)
+	if err != nil {
+		t.Fatalf("error listing pending operations: %v", err)
+	}
+	var actual []string
+	for _, op := range ops {
+		actual = append(actual, op.Name)
+	}
+	if expected := []string{"operation-delete-etcd"}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected pending operations, got %v, expected %v", actual, expected)
+	}
+	if _, found := resourceMap["Disk:us-test1-a/a-etcd-main-cluster-example-com"]; !found {
+		t.Errorf("disk with a pending operation should still be discovered")
+	}
+}
+
+func TestListPendingOperationsNotConfigured(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	d := &clusterDiscoveryGCE{
+		cloud:       cloud,
+		gceCloud:    cloud,
+		clusterName: "cluster.example.com",
+	}
+	ops, err := d.listPendingOperations(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("error listing pending operations: %v", err)
+	}
+	if len(ops) != 0 {
+		t.Errorf("expected no pending operations without an operations client, got %d", len(ops))
+	}
+}
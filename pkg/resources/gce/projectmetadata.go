@@ -0,0 +1,132 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"context"
+	"fmt"
+
+	compute "google.golang.org/api/compute/v1"
+	"google.golang.org/api/googleapi"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/resources"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+// maxProjectMetadataAttempts bounds the retries of a project metadata change that races with other changes
+const maxProjectMetadataAttempts = 5
+
+// listProjectMetadataKeys discovers the project-wide metadata entries kops added for the cluster,
+// e.g. SSH keys or OS Login settings shared by the cluster instances.
+// Like other kops objects, their keys are named <id>-<cluster-name>; entries with other keys are never matched.
+// The project metadata is shared by every instance in the project, so this is opt-in, see ListResourcesGCEOptions.
+func (d *clusterDiscoveryGCE) listProjectMetadataKeys(ctx context.Context) ([]*resources.Resource, error) {
+	if !d.deleteProjectMetadata {
+		return nil, nil
+	}
+
+	c := d.gceCloud
+
+	if err := d.waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	project, err := d.compute().Projects().Get(c.Project())
+	if err != nil {
+		return nil, fmt.Errorf("error getting project %q: %v", c.Project(), err)
+	}
+	if project.CommonInstanceMetadata == nil {
+		return nil, nil
+	}
+
+	var resourceTrackers []*resources.Resource
+	for _, item := range project.CommonInstanceMetadata.Items {
+		if !d.matchesClusterNameMultipart(item.Key, maxRoleNameTokens) {
+			continue
+		}
+
+		resourceTracker := &resources.Resource{
+			Name:         item.Key,
+			ID:           item.Key,
+			Type:         typeProjectMetadata,
+			GroupKey:     c.Project(),
+			GroupDeleter: deleteProjectMetadataKeys,
+			Dumper:       DumpResource,
+			// The value is left out, as it may hold credentials; the deleter only needs the key
+			Obj: &compute.MetadataItems{Key: item.Key},
+		}
+
+		klog.V(4).Infof("Found project metadata entry: %s", item.Key)
+		resourceTrackers = append(resourceTrackers, resourceTracker)
+	}
+
+	return resourceTrackers, nil
+}
+
+// deleteProjectMetadataKeys removes the entries from the project metadata, keeping every other entry.
+// The change is made against the fingerprint of the metadata we read, so a concurrent change is never overwritten;
+// if the metadata changed in between, we read it again and retry.
+func deleteProjectMetadataKeys(cloud fi.Cloud, trackers []*resources.Resource) error {
+	c := cloud.(gce.GCECloud)
+
+	keys := sets.NewString()
+	for _, t := range trackers {
+		keys.Insert(t.Obj.(*compute.MetadataItems).Key)
+	}
+
+	for attempt := 1; ; attempt++ {
+		project, err := c.Compute().Projects().Get(c.Project())
+		if err != nil {
+			return fmt.Errorf("error getting project %q: %v", c.Project(), err)
+		}
+		if project.CommonInstanceMetadata == nil {
+			return nil
+		}
+
+		metadata := &compute.Metadata{Fingerprint: project.CommonInstanceMetadata.Fingerprint}
+		removed := 0
+		for _, item := range project.CommonInstanceMetadata.Items {
+			if keys.Has(item.Key) {
+				klog.V(2).Infof("Deleting project metadata entry %s", item.Key)
+				removed++
+				continue
+			}
+			metadata.Items = append(metadata.Items, item)
+		}
+		if removed == 0 {
+			klog.Infof("project metadata entries not found: %v", keys.List())
+			return nil
+		}
+
+		op, err := c.Compute().Projects().SetCommonInstanceMetadata(c.Project(), metadata)
+		if err != nil {
+			if isFingerprintMismatch(err) && attempt < maxProjectMetadataAttempts {
+				klog.V(2).Infof("project metadata changed while deleting entries, will retry: %v", err)
+				continue
+			}
+			return fmt.Errorf("error deleting project metadata entries: %v", err)
+		}
+		return waitForOp(deletionContext(c), c, op)
+	}
+}
+
+// isFingerprintMismatch returns true if the error is GCE rejecting a change made against an outdated fingerprint
+func isFingerprintMismatch(err error) bool {
+	apiErr, ok := err.(*googleapi.Error)
+	return ok && apiErr.Code == 412
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	compute "google.golang.org/api/compute/v1"
+	gcemock "k8s.io/kops/cloudmock/gce"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+func TestListProjectMetadataKeys(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	items := []*compute.MetadataItems{
+		{Key: "ssh-keys", Value: fi.String("admin: ssh-rsa AAAA user@example.com")},
+		{Key: "sshkeys-cluster-example-com", Value: fi.String("admin: ssh-rsa BBBB kops")},
+		{Key: "sshkeys-other-example-com", Value: fi.String("admin: ssh-rsa CCCC kops")},
+		{Key: "enable-oslogin", Value: fi.String("TRUE")},
+	}
+	if _, err := cloud.Compute().Projects().SetCommonInstanceMetadata("testproject", &compute.Metadata{Items: items}); err != nil {
+		t.Fatalf("error setting project metadata: %v", err)
+	}
+
+	d := &clusterDiscoveryGCE{
+		cloud:       cloud,
+		gceCloud:    cloud,
+		clusterName: "cluster.example.com",
+	}
+	resourceTrackers, err := d.listProjectMetadataKeys(context.Background())
+	if err != nil {
+		t.Fatalf("error listing project metadata: %v", err)
+	}
+	if len(resourceTrackers) != 0 {
+		t.Fatalf("expected no project metadata entries unless opted in, got %d", len(resourceTrackers))
+	}
+
+	d.deleteProjectMetadata = true
+	resourceTrackers, err = d.listProjectMetadataKeys(context.Background())
+	if err != nil {
+		t.Fatalf("error listing project metadata: %v", err)
+	}
+	var actual []string
+	for _, r := range resourceTrackers {
+		actual = append(actual, r.Type+":"+r.ID)
+	}
+	if expected := []string{"ProjectMetadata:sshkeys-cluster-example-com"}; !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("unexpected project metadata entries, got %v, expected %v", actual, expected)
+	}
+
+	// A change made by someone else after discovery must be kept
+	project, err := cloud.Compute().Projects().Get("testproject")
+	if err != nil {
+		t.Fatalf("error getting project: %v", err)
+	}
+	updated := append([]*compute.MetadataItems{}, project.CommonInstanceMetadata.Items...)
+	updated = append(updated, &compute.MetadataItems{Key: "added-later", Value: fi.String("value")})
+	if _, err := cloud.Compute().Projects().SetCommonInstanceMetadata("testproject", &compute.Metadata{
+		Fingerprint: project.CommonInstanceMetadata.Fingerprint,
+		Items:       updated,
+	}); err != nil {
+		t.Fatalf("error setting project metadata: %v", err)
+	}
+
+	if err := resourceTrackers[0].GroupDeleter(cloud, resourceTrackers); err != nil {
+		t.Fatalf("error deleting project metadata entries: %v", err)
+	}
+
+	project, err = cloud.Compute().Projects().Get("testproject")
+	if err != nil {
+		t.Fatalf("error getting project: %v", err)
+	}
+	remaining := make(map[string]string)
+	for _, item := range project.CommonInstanceMetadata.Items {
+		remaining[item.Key] = fi.StringValue(item.Value)
+	}
+	expected := map[string]string{
+		"ssh-keys":                  "admin: ssh-rsa AAAA user@example.com",
+		"sshkeys-other-example-com": "admin: ssh-rsa CCCC kops",
+		"enable-oslogin":            "TRUE",
+		"added-later":               "value",
+	}
+	if !reflect.DeepEqual(remaining, expected) {
+		t.Errorf("unexpected project metadata after deletion, got %v, expected %v", remaining, expected)
+	}
+
+	// Deleting entries that are already gone is not an error
+	if err := resourceTrackers[0].GroupDeleter(cloud, resourceTrackers); err != nil {
+		t.Errorf("error deleting project metadata entries that were already deleted: %v", err)
+	}
+}
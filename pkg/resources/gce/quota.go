@@ -0,0 +1,81 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"context"
+
+	compute "google.golang.org/api/compute/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+)
+
+// quotaWarningThreshold is the fraction of a quota in use above which we warn
+const quotaWarningThreshold = 0.9
+
+// quotaMetricsByType are the project quotas counting the resources of each type we discover.
+// A project with that many resources makes for long listings, which correlates with rateLimitExceeded errors.
+var quotaMetricsByType = map[string][]string{
+	typeFirewallRule:     {"FIREWALLS"},
+	typeRoute:            {"ROUTES"},
+	typeNetwork:          {"NETWORKS"},
+	typeSubnet:           {"SUBNETWORKS"},
+	typeInstanceTemplate: {"INSTANCE_TEMPLATES"},
+	typeImage:            {"IMAGES"},
+	typeSnapshot:         {"SNAPSHOTS"},
+	typeBackendService:   {"BACKEND_SERVICES"},
+	typeHealthCheck:      {"HEALTH_CHECKS"},
+	typeSslCertificate:   {"SSL_CERTIFICATES"},
+	typeSslPolicy:        {"SSL_POLICIES"},
+	typeSecurityPolicy:   {"SECURITY_POLICIES"},
+	typeUrlMap:           {"URL_MAPS"},
+	typeTargetHttpProxy:  {"TARGET_HTTP_PROXIES"},
+	typeTargetHttpsProxy: {"TARGET_HTTPS_PROXIES"},
+	typeAddress:          {"STATIC_ADDRESSES"},
+	typeForwardingRule:   {"FORWARDING_RULES"},
+}
+
+// checkQuotas warns about the project quotas of the resource types we discover that are nearly used up.
+// This is diagnostic, so failing to get the quotas is logged rather than returned.
+// listTypes are the types being discovered, or nil for all types. The quotas we warned about are returned.
+func (d *clusterDiscoveryGCE) checkQuotas(ctx context.Context, listTypes sets.String) []*compute.Quota {
+	metrics := sets.NewString()
+	for resourceType, m := range quotaMetricsByType {
+		if listTypes == nil || listTypes.Has(resourceType) {
+			metrics.Insert(m...)
+		}
+	}
+
+	if err := d.waitForRateLimit(ctx); err != nil {
+		return nil
+	}
+	project, err := d.compute().Projects().Get(d.gceCloud.Project())
+	if err != nil {
+		klog.Warningf("unable to check the quotas of project %q: %v", d.gceCloud.Project(), err)
+		return nil
+	}
+
+	var nearLimit []*compute.Quota
+	for _, q := range project.Quotas {
+		if !metrics.Has(q.Metric) || q.Limit <= 0 || q.Usage < q.Limit*quotaWarningThreshold {
+			continue
+		}
+		klog.Warningf("project %q is using %.0f of its %.0f %s quota; discovery may be slow and hit rateLimitExceeded errors", d.gceCloud.Project(), q.Usage, q.Limit, q.Metric)
+		nearLimit = append(nearLimit, q)
+	}
+	return nearLimit
+}
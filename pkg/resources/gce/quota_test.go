@@ -0,0 +1,77 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	compute "google.golang.org/api/compute/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	gcemock "k8s.io/kops/cloudmock/gce"
+)
+
+func TestCheckQuotas(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	d := &clusterDiscoveryGCE{
+		cloud:       cloud,
+		gceCloud:    cloud,
+		clusterName: "cluster.example.com",
+		computeClient: &fakeComputeClient{
+			projects: &fakeProjectClient{
+				project: &compute.Project{
+					Name: "testproject",
+					Quotas: []*compute.Quota{
+						{Metric: "FIREWALLS", Limit: 100, Usage: 95},
+						{Metric: "ROUTES", Limit: 250, Usage: 10},
+						// Not a quota of a resource type we discover
+						{Metric: "CPUS_ALL_REGIONS", Limit: 24, Usage: 24},
+						{Metric: "SNAPSHOTS", Limit: 0, Usage: 0},
+					},
+				},
+			},
+		},
+	}
+
+	grid := []struct {
+		Name      string
+		ListTypes sets.String
+		Expected  []string
+	}{
+		{
+			Name:     "all types",
+			Expected: []string{"FIREWALLS"},
+		},
+		{
+			Name:      "selected types",
+			ListTypes: sets.NewString(typeDisk, typeRoute),
+		},
+	}
+	for _, g := range grid {
+		t.Run(g.Name, func(t *testing.T) {
+			var actual []string
+			for _, q := range d.checkQuotas(context.Background(), g.ListTypes) {
+				actual = append(actual, q.Metric)
+			}
+			if !reflect.DeepEqual(actual, g.Expected) {
+				t.Errorf("unexpected quota warnings, got %v, expected %v", actual, g.Expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"time"
+
+	compute "google.golang.org/api/compute/v1"
+	"google.golang.org/api/googleapi"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+// deleteBackoff is the backoff strategy for retrying GCE delete calls that fail with a transient error.
+var deleteBackoff = wait.Backoff{
+	Duration: time.Second,
+	Factor:   2,
+	Jitter:   0.1,
+	Steps:    5,
+}
+
+// retryableDelete calls fn, retrying with exponential backoff while it fails with a transient error, or because the
+// resource is busy with another operation (see isResourceBusy). Calling fn again re-checks the resource: if the other
+// operation was its deletion, the call then fails with not found (see isAlreadyGoneErr).
+// Permanent errors (including not found, and resources still in use) are returned immediately.
+func retryableDelete(fn func() (*compute.Operation, error)) (*compute.Operation, error) {
+	var op *compute.Operation
+	var lastErr error
+	err := wait.ExponentialBackoff(deleteBackoff, func() (bool, error) {
+		op, lastErr = fn()
+		if lastErr == nil {
+			return true, nil
+		}
+		if isResourceBusy(lastErr) {
+			klog.V(2).Infof("resource is busy with another operation, will retry: %v", lastErr)
+			return false, nil
+		}
+		if !isTransientError(lastErr) {
+			return false, lastErr
+		}
+		klog.V(2).Infof("transient error from GCE, will retry: %v", lastErr)
+		return false, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return nil, lastErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return op, nil
+}
+
+// isTransientError returns true if the error is one GCE returns for requests that are likely to succeed on retry
+func isTransientError(err error) bool {
+	apiErr, ok := err.(*googleapi.Error)
+	if !ok {
+		return false
+	}
+
+	for _, e := range apiErr.Errors {
+		switch e.Reason {
+		case "resourceInUseByAnotherResource", "resourceNotReady":
+			// We will retry these in the next deletion pass, once dependencies are gone;
+			// retryableDelete also waits for busy resources, see isResourceBusy
+			return false
+		case "rateLimitExceeded", "userRateLimitExceeded", "backendError", "internalError":
+			return true
+		}
+	}
+
+	switch apiErr.Code {
+	case 429, 500, 502, 503, 504:
+		return true
+	}
+	return false
+}
+
+// isResourceInUse returns true if the error is GCE refusing to delete a resource that something else still depends on
+func isResourceInUse(err error) bool {
+	apiErr, ok := err.(*googleapi.Error)
+	if !ok {
+		return false
+	}
+	for _, e := range apiErr.Errors {
+		if e.Reason == "resourceInUseByAnotherResource" {
+			return true
+		}
+	}
+	return false
+}
+
+// isAlreadyGoneErr returns true if the error from a delete call means the resource is gone, e.g. because an earlier,
+// interrupted run of kops delete cluster deleted it, so the delete can be treated as done.
+// Only the code and reasons of the error are considered: a resource that is still being deleted is not gone, as its
+// deletion may yet fail, so retryableDelete retries those until they are (see isResourceBusy).
+func isAlreadyGoneErr(err error) bool {
+	if gce.IsNotFound(err) {
+		return true
+	}
+	apiErr, ok := err.(*googleapi.Error)
+	if !ok {
+		return false
+	}
+	for _, e := range apiErr.Errors {
+		if e.Reason == "notFound" {
+			return true
+		}
+	}
+	return false
+}
+
+// isResourceBusy returns true if the error is GCE refusing to change a resource while another operation on it is in
+// progress (e.g. its creation, a resize, or an earlier deletion)
+func isResourceBusy(err error) bool {
+	apiErr, ok := err.(*googleapi.Error)
+	if !ok {
+		return false
+	}
+	if apiErr.Code == 409 {
+		return true
+	}
+	for _, e := range apiErr.Errors {
+		if e.Reason == "resourceNotReady" {
+			return true
+		}
+	}
+	return false
+}
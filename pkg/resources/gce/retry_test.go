@@ -0,0 +1,218 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	compute "google.golang.org/api/compute/v1"
+	"google.golang.org/api/googleapi"
+	"k8s.io/apimachinery/pkg/util/wait"
+	gcemock "k8s.io/kops/cloudmock/gce"
+)
+
+func TestRetryableDelete(t *testing.T) {
+	defer func(b wait.Backoff) { deleteBackoff = b }(deleteBackoff)
+	deleteBackoff = wait.Backoff{Duration: time.Millisecond, Factor: 1, Steps: 5}
+
+	rateLimited := &googleapi.Error{
+		Code:   403,
+		Errors: []googleapi.ErrorItem{{Reason: "rateLimitExceeded"}},
+	}
+	inUse := &googleapi.Error{
+		Code:   400,
+		Errors: []googleapi.ErrorItem{{Reason: "resourceInUseByAnotherResource"}},
+	}
+	notReady := &googleapi.Error{
+		Code:   400,
+		Errors: []googleapi.ErrorItem{{Reason: "resourceNotReady"}},
+	}
+
+	grid := []struct {
+		Name          string
+		Errors        []error
+		ExpectedCalls int
+		ExpectError   bool
+	}{
+		{
+			Name:          "succeeds after transient errors",
+			Errors:        []error{rateLimited, &googleapi.Error{Code: 503}},
+			ExpectedCalls: 3,
+		},
+		{
+			Name:          "permanent error is not retried",
+			Errors:        []error{inUse},
+			ExpectedCalls: 1,
+			ExpectError:   true,
+		},
+		{
+			Name:          "not found is not retried",
+			Errors:        []error{&googleapi.Error{Code: 404}},
+			ExpectedCalls: 1,
+			ExpectError:   true,
+		},
+		{
+			Name:          "busy resource is retried until it is gone",
+			Errors:        []error{notReady, &googleapi.Error{Code: 409}, &googleapi.Error{Code: 404}},
+			ExpectedCalls: 3,
+			ExpectError:   true,
+		},
+		{
+			Name:          "busy resource is deleted once the other operation is done",
+			Errors:        []error{&googleapi.Error{Code: 409}},
+			ExpectedCalls: 2,
+		},
+		{
+			Name:          "gives up after backoff is exhausted",
+			Errors:        []error{rateLimited, rateLimited, rateLimited, rateLimited, rateLimited, rateLimited},
+			ExpectedCalls: 5,
+			ExpectError:   true,
+		},
+	}
+	for _, g := range grid {
+		t.Run(g.Name, func(t *testing.T) {
+			calls := 0
+			op, err := retryableDelete(func() (*compute.Operation, error) {
+				calls++
+				if calls <= len(g.Errors) {
+					return nil, g.Errors[calls-1]
+				}
+				return &compute.Operation{Status: "DONE"}, nil
+			})
+			if calls != g.ExpectedCalls {
+				t.Errorf("expected %d calls, got %d", g.ExpectedCalls, calls)
+			}
+			if g.ExpectError {
+				if err == nil {
+					t.Errorf("expected error, got op %v", op)
+				}
+			} else if err != nil || op == nil {
+				t.Errorf("unexpected result op=%v err=%v", op, err)
+			}
+		})
+	}
+}
+
+func TestIsAlreadyGoneErr(t *testing.T) {
+	grid := []struct {
+		Name     string
+		Err      error
+		Expected bool
+	}{
+		{
+			Name:     "not found",
+			Err:      &googleapi.Error{Code: 404},
+			Expected: true,
+		},
+		{
+			Name:     "not found reason",
+			Err:      &googleapi.Error{Code: 400, Errors: []googleapi.ErrorItem{{Reason: "notFound"}}},
+			Expected: true,
+		},
+		{
+			// The deletion may still fail, so the resource is not gone yet
+			Name: "not ready while being deleted",
+			Err: &googleapi.Error{
+				Code:    400,
+				Message: "The resource 'projects/testproject/global/networks/cluster-example-com' is not ready",
+				Errors:  []googleapi.ErrorItem{{Reason: "resourceNotReady", Message: "The resource is being deleted"}},
+			},
+			Expected: false,
+		},
+		{
+			Name: "not ready while being created",
+			Err: &googleapi.Error{
+				Code:    400,
+				Message: "The resource 'projects/testproject/global/networks/cluster-example-com' is not ready",
+				Errors:  []googleapi.ErrorItem{{Reason: "resourceNotReady"}},
+			},
+			Expected: false,
+		},
+		{
+			Name: "deletion already in progress",
+			Err: &googleapi.Error{
+				Code:    409,
+				Message: "Operation type [delete] is already in progress on resource 'nodes-cluster-example-com'",
+			},
+			Expected: false,
+		},
+		{
+			Name: "other operation already in progress",
+			Err: &googleapi.Error{
+				Code:    409,
+				Message: "Operation type [resize] is already in progress on resource 'nodes-cluster-example-com'",
+			},
+			Expected: false,
+		},
+		{
+			Name: "in use",
+			Err: &googleapi.Error{
+				Code:    400,
+				Message: "The resource is already being used and cannot be deleted",
+				Errors:  []googleapi.ErrorItem{{Reason: "resourceInUseByAnotherResource"}},
+			},
+			Expected: false,
+		},
+		{
+			Name:     "not an API error",
+			Err:      fmt.Errorf("error deleting resource"),
+			Expected: false,
+		},
+	}
+	for _, g := range grid {
+		t.Run(g.Name, func(t *testing.T) {
+			if actual := isAlreadyGoneErr(g.Err); actual != g.Expected {
+				t.Errorf("unexpected result for %v, got %v, expected %v", g.Err, actual, g.Expected)
+			}
+		})
+	}
+}
+
+func TestDeleteNotReadyIsNotSwallowed(t *testing.T) {
+	defer func(b wait.Backoff) { deleteBackoff = b }(deleteBackoff)
+	deleteBackoff = wait.Backoff{Duration: time.Millisecond, Factor: 1, Steps: 3}
+
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+	if _, err := cloud.Compute().Disks().Insert("testproject", "us-test1-a", &compute.Disk{
+		Name:   "a-etcd-main-cluster-example-com",
+		Labels: map[string]string{"k8s-io-cluster-name": "cluster-example-com"},
+	}); err != nil {
+		t.Fatalf("error creating disk: %v", err)
+	}
+	resourceMap, err := listResourcesForTest(cloud, "cluster.example.com", "us-test1")
+	if err != nil {
+		t.Fatalf("error listing resources: %v", err)
+	}
+	r := resourceMap["Disk:us-test1-a/a-etcd-main-cluster-example-com"]
+	if r == nil {
+		t.Fatalf("expected disk to be tracked, got %v", resourceMap)
+	}
+
+	// The message mentions deletion, but the disk is busy with another operation, so it was not deleted
+	notReady := &fakeComputeCloud{
+		GCECloud: cloud,
+		compute:  &fakeComputeClient{ComputeClient: cloud.Compute(), disks: &fakeNotReadyDiskClient{DiskClient: cloud.Compute().Disks()}},
+	}
+	if err := r.Deleter(notReady, r); err == nil {
+		t.Fatalf("expected an error deleting a disk that is not ready")
+	}
+	if _, err := cloud.Compute().Disks().Get("testproject", "us-test1-a", "a-etcd-main-cluster-example-com"); err != nil {
+		t.Errorf("expected the disk to still exist: %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/kops/pkg/resources"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+// computeSelfLink returns the SelfLink of a Compute API object (v1 or beta), which the deleters parse to find
+// the project, zone or region of the object. ok is false for objects from other APIs, e.g. DNS records.
+func computeSelfLink(obj interface{}) (selfLink string, ok bool) {
+	return computeStringField(obj, "SelfLink")
+}
+
+// computeStringField returns the named string field of a Compute API object (v1 or beta).
+// ok is false if obj is not a Compute API object, or has no such field.
+func computeStringField(obj interface{}, name string) (value string, ok bool) {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return "", false
+	}
+	v = v.Elem()
+	if v.Kind() != reflect.Struct || !strings.HasPrefix(v.Type().PkgPath(), "google.golang.org/api/compute/") {
+		return "", false
+	}
+	f := v.FieldByName(name)
+	if !f.IsValid() || f.Kind() != reflect.String {
+		return "", false
+	}
+	return f.String(), true
+}
+
+// validateSelfLinks checks that the self links of the discovered Compute objects can be parsed,
+// so a malformed self link is reported when discovering resources rather than part way through deleting them
+func validateSelfLinks(resourceMap map[string]*resources.Resource) error {
+	var keys []string
+	for k := range resourceMap {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var errs []error
+	for _, k := range keys {
+		selfLink, ok := computeSelfLink(resourceMap[k].Obj)
+		if !ok {
+			continue
+		}
+		if _, err := gce.ParseGoogleCloudURL(selfLink); err != nil {
+			errs = append(errs, fmt.Errorf("cannot delete %s, as its self link %q cannot be parsed: %v", k, selfLink, err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// computeResourceKey identifies the resource a self link or operation target link points to, ignoring the API version.
+// It is empty if the link cannot be parsed.
+func computeResourceKey(link string) string {
+	u, err := gce.ParseGoogleCloudURL(link)
+	if err != nil {
+		return ""
+	}
+	return u.Project + "/" + u.Zone + "/" + u.Region + "/" + u.Type + "/" + u.Name
+}
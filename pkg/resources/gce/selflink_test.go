@@ -0,0 +1,86 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"strings"
+	"testing"
+
+	compute "google.golang.org/api/compute/v1"
+	clouddns "google.golang.org/api/dns/v1"
+	gcemock "k8s.io/kops/cloudmock/gce"
+	"k8s.io/kops/pkg/resources"
+)
+
+func TestValidateSelfLinks(t *testing.T) {
+	resourceMap := map[string]*resources.Resource{
+		"Disk:a-etcd-main-cluster-example-com": {
+			Type: typeDisk,
+			ID:   "a-etcd-main-cluster-example-com",
+			Obj: &compute.Disk{
+				Name:     "a-etcd-main-cluster-example-com",
+				SelfLink: "https://www.googleapis.com/compute/v1/projects/testproject/zones/us-test1-a/disks/a-etcd-main-cluster-example-com",
+			},
+		},
+		"DNSRecord:example-com/A/api.cluster.example.com.": {
+			Type: typeDNSRecord,
+			ID:   "example-com/A/api.cluster.example.com.",
+			Obj:  &clouddns.ResourceRecordSet{Name: "api.cluster.example.com.", Type: "A"},
+		},
+	}
+	if err := validateSelfLinks(resourceMap); err != nil {
+		t.Fatalf("unexpected error for valid self links: %v", err)
+	}
+
+	resourceMap["Address:api-cluster-example-com"] = &resources.Resource{
+		Type: typeAddress,
+		ID:   "api-cluster-example-com",
+		Obj: &compute.Address{
+			Name:     "api-cluster-example-com",
+			SelfLink: "projects/testproject/regions/us-test1/addresses/api-cluster-example-com",
+		},
+	}
+	err := validateSelfLinks(resourceMap)
+	if err == nil {
+		t.Fatalf("expected an error for a malformed self link")
+	}
+	if !strings.Contains(err.Error(), "cannot delete Address:api-cluster-example-com") {
+		t.Errorf("error does not name the resource: %v", err)
+	}
+}
+
+func TestListResourcesMalformedSelfLink(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	addr := &compute.Address{Name: "api-cluster-example-com"}
+	if _, err := cloud.Compute().Addresses().Insert("testproject", "us-test1", addr); err != nil {
+		t.Fatalf("error creating address: %v", err)
+	}
+	// The mock keeps the object we inserted, so we can corrupt its self link
+	addr.SelfLink = "not-a-url"
+
+	resourceMap, err := listResourcesForTest(cloud, "cluster.example.com", "us-test1")
+	if err == nil {
+		t.Fatalf("expected discovery to fail for a malformed self link")
+	}
+	if !strings.Contains(err.Error(), `Address:api-cluster-example-com, as its self link "not-a-url" cannot be parsed`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, found := resourceMap["Address:api-cluster-example-com"]; !found {
+		t.Errorf("expected the resources that were found to be returned along with the error")
+	}
+}
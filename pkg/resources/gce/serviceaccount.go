@@ -0,0 +1,136 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	iam "google.golang.org/api/iam/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/resources"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+const typeServiceAccount = "ServiceAccount"
+
+// serviceAccountClient is the subset of the IAM API we use to find and delete the cluster service accounts
+type serviceAccountClient interface {
+	List(ctx context.Context, project string) ([]*iam.ServiceAccount, error)
+	// Delete deletes the service account with the given resource name, along with its keys
+	Delete(name string) error
+}
+
+type serviceAccountClientImpl struct {
+	srv *iam.Service
+}
+
+var _ serviceAccountClient = &serviceAccountClientImpl{}
+
+func newServiceAccountClient(ctx context.Context) (*serviceAccountClientImpl, error) {
+	srv, err := iam.NewService(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error building IAM API client: %v", err)
+	}
+	return &serviceAccountClientImpl{srv: srv}, nil
+}
+
+func (c *serviceAccountClientImpl) List(ctx context.Context, project string) ([]*iam.ServiceAccount, error) {
+	var l []*iam.ServiceAccount
+	if err := c.srv.Projects.ServiceAccounts.List("projects/"+project).Pages(ctx, func(p *iam.ListServiceAccountsResponse) error {
+		l = append(l, p.Accounts...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (c *serviceAccountClientImpl) Delete(name string) error {
+	_, err := c.srv.Projects.ServiceAccounts.Delete(name).Do()
+	return err
+}
+
+// matchesServiceAccount checks if the service account was created for the cluster.
+// The account id (the part of the email before the @) must be a name generated for the cluster,
+// or the display name must contain the cluster name as a separate word.
+func (d *clusterDiscoveryGCE) matchesServiceAccount(sa *iam.ServiceAccount) bool {
+	accountID := strings.SplitN(sa.Email, "@", 2)[0]
+	if accountID != "" && d.matchesClusterNameMultipart(accountID, maxRoleNameTokens) {
+		return true
+	}
+	for _, word := range strings.Fields(sa.DisplayName) {
+		if word == d.clusterName {
+			return true
+		}
+	}
+	return false
+}
+
+// listServiceAccounts finds the service accounts created for the cluster
+func (d *clusterDiscoveryGCE) listServiceAccounts(ctx context.Context) ([]*resources.Resource, error) {
+	if d.serviceAccounts == nil {
+		return nil, nil
+	}
+
+	if err := d.waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	client := d.serviceAccounts
+	serviceAccounts, err := client.List(ctx, d.gceCloud.Project())
+	if err != nil {
+		return nil, fmt.Errorf("error listing service accounts: %v", err)
+	}
+
+	var resourceTrackers []*resources.Resource
+	for _, sa := range serviceAccounts {
+		if !d.matchesServiceAccount(sa) {
+			continue
+		}
+
+		resourceTracker := &resources.Resource{
+			Name: sa.Email,
+			ID:   sa.Email,
+			Type: typeServiceAccount,
+			Deleter: func(cloud fi.Cloud, r *resources.Resource) error {
+				return deleteServiceAccount(client, r)
+			},
+			Dumper: DumpResource,
+			Obj:    sa,
+		}
+
+		klog.V(4).Infof("Found resource: %s", sa.Name)
+		resourceTrackers = append(resourceTrackers, resourceTracker)
+	}
+
+	return resourceTrackers, nil
+}
+
+func deleteServiceAccount(client serviceAccountClient, r *resources.Resource) error {
+	sa := r.Obj.(*iam.ServiceAccount)
+
+	klog.V(2).Infof("Deleting service account %s", sa.Email)
+	if err := client.Delete(sa.Name); err != nil {
+		if isAlreadyGoneErr(err) {
+			klog.Infof("service account not found or already being deleted: %q", sa.Email)
+			return nil
+		}
+		return fmt.Errorf("error deleting service account %s: %v", sa.Email, err)
+	}
+	return nil
+}
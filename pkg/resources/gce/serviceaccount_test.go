@@ -0,0 +1,120 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	iam "google.golang.org/api/iam/v1"
+	gcemock "k8s.io/kops/cloudmock/gce"
+)
+
+type fakeServiceAccountClient struct {
+	serviceAccounts map[string][]*iam.ServiceAccount
+	deleted         []string
+}
+
+func (c *fakeServiceAccountClient) List(ctx context.Context, project string) ([]*iam.ServiceAccount, error) {
+	return c.serviceAccounts[project], nil
+}
+
+func (c *fakeServiceAccountClient) Delete(name string) error {
+	c.deleted = append(c.deleted, name)
+	return nil
+}
+
+func TestListServiceAccounts(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	client := &fakeServiceAccountClient{
+		serviceAccounts: map[string][]*iam.ServiceAccount{
+			"testproject": {
+				{
+					Name:  "projects/testproject/serviceAccounts/nodes-cluster-example-com@testproject.iam.gserviceaccount.com",
+					Email: "nodes-cluster-example-com@testproject.iam.gserviceaccount.com",
+				},
+				{
+					Name:        "projects/testproject/serviceAccounts/control-plane@testproject.iam.gserviceaccount.com",
+					Email:       "control-plane@testproject.iam.gserviceaccount.com",
+					DisplayName: "control plane for cluster.example.com",
+				},
+				{
+					Name:        "projects/testproject/serviceAccounts/nodes-other-example-com@testproject.iam.gserviceaccount.com",
+					Email:       "nodes-other-example-com@testproject.iam.gserviceaccount.com",
+					DisplayName: "nodes for other.example.com",
+				},
+				{
+					Name:        "projects/testproject/serviceAccounts/ci@testproject.iam.gserviceaccount.com",
+					Email:       "ci@testproject.iam.gserviceaccount.com",
+					DisplayName: "deploys subcluster.example.com",
+				},
+			},
+		},
+	}
+
+	d := &clusterDiscoveryGCE{
+		cloud:           cloud,
+		gceCloud:        cloud,
+		clusterName:     "cluster.example.com",
+		serviceAccounts: client,
+	}
+	resourceTrackers, err := d.listServiceAccounts(context.Background())
+	if err != nil {
+		t.Fatalf("error listing service accounts: %v", err)
+	}
+
+	var actual []string
+	for _, r := range resourceTrackers {
+		actual = append(actual, r.Type+":"+r.ID)
+		if err := r.Deleter(cloud, r); err != nil {
+			t.Fatalf("error deleting service account: %v", err)
+		}
+	}
+	expected := []string{
+		"ServiceAccount:nodes-cluster-example-com@testproject.iam.gserviceaccount.com",
+		"ServiceAccount:control-plane@testproject.iam.gserviceaccount.com",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected service accounts, got %v, expected %v", actual, expected)
+	}
+	expectedDeleted := []string{
+		"projects/testproject/serviceAccounts/nodes-cluster-example-com@testproject.iam.gserviceaccount.com",
+		"projects/testproject/serviceAccounts/control-plane@testproject.iam.gserviceaccount.com",
+	}
+	if !reflect.DeepEqual(client.deleted, expectedDeleted) {
+		t.Errorf("unexpected deleted service accounts, got %v, expected %v", client.deleted, expectedDeleted)
+	}
+}
+
+func TestListServiceAccountsNotConfigured(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	d := &clusterDiscoveryGCE{
+		cloud:       cloud,
+		gceCloud:    cloud,
+		clusterName: "cluster.example.com",
+	}
+	resourceTrackers, err := d.listServiceAccounts(context.Background())
+	if err != nil {
+		t.Fatalf("error listing service accounts: %v", err)
+	}
+	if len(resourceTrackers) != 0 {
+		t.Errorf("expected no service accounts without an IAM client, got %d", len(resourceTrackers))
+	}
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	storage "google.golang.org/api/storage/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/resources"
+	"k8s.io/kops/upup/pkg/fi"
+)
+
+const typeGCSObject = "GCSObject"
+
+// gcsObjectClient is the subset of the GCS API we use to find and delete the state store objects
+type gcsObjectClient interface {
+	List(ctx context.Context, bucket, prefix string) ([]*storage.Object, error)
+	Delete(bucket, name string) error
+}
+
+type gcsObjectClientImpl struct {
+	srv *storage.Service
+}
+
+var _ gcsObjectClient = &gcsObjectClientImpl{}
+
+func (c *gcsObjectClientImpl) List(ctx context.Context, bucket, prefix string) ([]*storage.Object, error) {
+	var l []*storage.Object
+	if err := c.srv.Objects.List(bucket).Prefix(prefix).Pages(ctx, func(p *storage.Objects) error {
+		l = append(l, p.Items...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (c *gcsObjectClientImpl) Delete(bucket, name string) error {
+	return c.srv.Objects.Delete(bucket, name).Do()
+}
+
+// parseStateStorePath splits a gs://bucket/path state store path into the bucket and the object prefix.
+// The path must end with the cluster name, so we never match objects of other clusters or the bucket root.
+func parseStateStorePath(stateStorePath string, clusterName string) (string, string, error) {
+	if !strings.HasPrefix(stateStorePath, "gs://") {
+		return "", "", fmt.Errorf("state store path %q is not a gs:// path", stateStorePath)
+	}
+	tokens := strings.SplitN(strings.TrimPrefix(stateStorePath, "gs://"), "/", 2)
+	bucket := tokens[0]
+	if bucket == "" {
+		return "", "", fmt.Errorf("state store path %q has no bucket", stateStorePath)
+	}
+	path := ""
+	if len(tokens) == 2 {
+		path = strings.Trim(tokens[1], "/")
+	}
+	if path == "" {
+		return "", "", fmt.Errorf("refusing to delete the root of bucket %q, state store path %q does not name the cluster", bucket, stateStorePath)
+	}
+	if path != clusterName && !strings.HasSuffix(path, "/"+clusterName) {
+		return "", "", fmt.Errorf("state store path %q is not for cluster %q", stateStorePath, clusterName)
+	}
+	return bucket, path + "/", nil
+}
+
+// listGCSStateObjects finds the objects kops stored for the cluster under its state store path
+func (d *clusterDiscoveryGCE) listGCSStateObjects(ctx context.Context) ([]*resources.Resource, error) {
+	if d.stateStorePath == "" {
+		return nil, nil
+	}
+
+	bucket, prefix, err := parseStateStorePath(d.stateStorePath, d.clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.listGCSObjects(ctx, bucket, prefix)
+}
+
+// listGCSObjects finds the objects under the prefix in the bucket
+func (d *clusterDiscoveryGCE) listGCSObjects(ctx context.Context, bucket, prefix string) ([]*resources.Resource, error) {
+	if err := d.waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	objects, err := d.gcsObjects.List(ctx, bucket, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("error listing objects in gs://%s/%s: %v", bucket, prefix, err)
+	}
+
+	client := d.gcsObjects
+	var resourceTrackers []*resources.Resource
+	for _, o := range objects {
+		// The prefix is also enforced by the API, but we do not want to rely on it for deletion
+		if !strings.HasPrefix(o.Name, prefix) {
+			continue
+		}
+
+		resourceTracker := &resources.Resource{
+			Name: "gs://" + bucket + "/" + o.Name,
+			ID:   bucket + "/" + o.Name,
+			Type: typeGCSObject,
+			Deleter: func(cloud fi.Cloud, r *resources.Resource) error {
+				return deleteGCSObject(client, bucket, r)
+			},
+			Obj: o,
+		}
+
+		klog.V(4).Infof("Found resource: %s", resourceTracker.Name)
+		resourceTrackers = append(resourceTrackers, resourceTracker)
+	}
+
+	return resourceTrackers, nil
+}
+
+// blockStateObjects makes the state store objects wait for all the other resources, so the cluster state is only
+// deleted once everything else has been: if a deletion fails, the cluster can still be deleted again with kops.
+func (d *clusterDiscoveryGCE) blockStateObjects(resourceMap map[string]*resources.Resource) {
+	if d.stateStorePath == "" {
+		return
+	}
+	bucket, prefix, err := parseStateStorePath(d.stateStorePath, d.clusterName)
+	if err != nil {
+		// Already reported by listGCSStateObjects, which found no objects
+		return
+	}
+
+	var stateObjects []*resources.Resource
+	var others []string
+	for k, r := range resourceMap {
+		if r.Type == typeGCSObject && strings.HasPrefix(r.ID, bucket+"/"+prefix) {
+			stateObjects = append(stateObjects, r)
+		} else {
+			others = append(others, k)
+		}
+	}
+	sort.Strings(others)
+
+	for _, r := range stateObjects {
+		r.Blocked = append(r.Blocked, others...)
+	}
+}
+
+func deleteGCSObject(client gcsObjectClient, bucket string, r *resources.Resource) error {
+	o := r.Obj.(*storage.Object)
+
+	klog.V(2).Infof("Deleting GCS object gs://%s/%s", bucket, o.Name)
+	if err := client.Delete(bucket, o.Name); err != nil {
+		if isAlreadyGoneErr(err) {
+			klog.Infof("object not found or already being deleted: gs://%s/%s", bucket, o.Name)
+			return nil
+		}
+		return fmt.Errorf("error deleting object gs://%s/%s: %v", bucket, o.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	storage "google.golang.org/api/storage/v1"
+	"k8s.io/kops/pkg/resources"
+)
+
+// fakeObjectClient is a gcsObjectClient that ignores the prefix when listing, like a misbehaving server would
+type fakeObjectClient struct {
+	objects map[string][]string
+	deleted []string
+}
+
+func (c *fakeObjectClient) List(ctx context.Context, bucket, prefix string) ([]*storage.Object, error) {
+	var l []*storage.Object
+	for _, name := range c.objects[bucket] {
+		l = append(l, &storage.Object{Bucket: bucket, Name: name})
+	}
+	return l, nil
+}
+
+func (c *fakeObjectClient) Delete(bucket, name string) error {
+	c.deleted = append(c.deleted, bucket+"/"+name)
+	return nil
+}
+
+func TestListGCSStateObjects(t *testing.T) {
+	client := &fakeObjectClient{
+		objects: map[string][]string{
+			"state": {
+				"cluster.example.com/config",
+				"cluster.example.com/instancegroup/nodes",
+				"cluster.example.com/secrets/admin",
+				"cluster.example.com.other/config",
+				"other.example.com/config",
+				"README",
+			},
+		},
+	}
+
+	d := &clusterDiscoveryGCE{
+		clusterName:    "cluster.example.com",
+		stateStorePath: "gs://state/cluster.example.com",
+		gcsObjects:     client,
+	}
+	resourceTrackers, err := d.listGCSStateObjects(context.Background())
+	if err != nil {
+		t.Fatalf("error listing objects: %v", err)
+	}
+
+	var actual []string
+	for _, r := range resourceTrackers {
+		actual = append(actual, r.Type+":"+r.ID)
+		if err := r.Deleter(nil, r); err != nil {
+			t.Fatalf("error deleting object: %v", err)
+		}
+	}
+	sort.Strings(actual)
+	expected := []string{
+		"GCSObject:state/cluster.example.com/config",
+		"GCSObject:state/cluster.example.com/instancegroup/nodes",
+		"GCSObject:state/cluster.example.com/secrets/admin",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected objects, got %v, expected %v", actual, expected)
+	}
+
+	sort.Strings(client.deleted)
+	expectedDeleted := []string{
+		"state/cluster.example.com/config",
+		"state/cluster.example.com/instancegroup/nodes",
+		"state/cluster.example.com/secrets/admin",
+	}
+	if !reflect.DeepEqual(client.deleted, expectedDeleted) {
+		t.Errorf("unexpected deleted objects, got %v, expected %v", client.deleted, expectedDeleted)
+	}
+}
+
+func TestBlockStateObjects(t *testing.T) {
+	d := &clusterDiscoveryGCE{
+		clusterName:    "cluster.example.com",
+		stateStorePath: "gs://state/cluster.example.com",
+	}
+	resourceMap := map[string]*resources.Resource{
+		"GCSObject:state/cluster.example.com/config": {Type: typeGCSObject, ID: "state/cluster.example.com/config"},
+		"GCSObject:backups/cluster.example.com/etcd": {Type: typeGCSObject, ID: "backups/cluster.example.com/etcd"},
+		"Disk:a-etcd-main-cluster-example-com":       {Type: typeDisk, ID: "a-etcd-main-cluster-example-com"},
+	}
+	d.blockStateObjects(resourceMap)
+
+	expected := []string{"Disk:a-etcd-main-cluster-example-com", "GCSObject:backups/cluster.example.com/etcd"}
+	if blocked := resourceMap["GCSObject:state/cluster.example.com/config"].Blocked; !reflect.DeepEqual(blocked, expected) {
+		t.Errorf("unexpected blocked for state object, got %v, expected %v", blocked, expected)
+	}
+	for _, k := range expected {
+		if blocked := resourceMap[k].Blocked; len(blocked) != 0 {
+			t.Errorf("unexpected blocked for %s, got %v", k, blocked)
+		}
+	}
+
+	sorted := SortedResources(resourceMap)
+	if last := sorted[len(sorted)-1]; last.ID != "state/cluster.example.com/config" {
+		t.Errorf("expected the state object to be deleted last, got %s:%s", last.Type, last.ID)
+	}
+}
+
+func TestListGCSStateObjectsNotEnabled(t *testing.T) {
+	d := &clusterDiscoveryGCE{
+		clusterName: "cluster.example.com",
+	}
+	resourceTrackers, err := d.listGCSStateObjects(context.Background())
+	if err != nil {
+		t.Fatalf("error listing objects: %v", err)
+	}
+	if len(resourceTrackers) != 0 {
+		t.Errorf("expected no objects when the state store path is not set, got %d", len(resourceTrackers))
+	}
+}
+
+func TestParseStateStorePath(t *testing.T) {
+	grid := []struct {
+		path           string
+		expectedBucket string
+		expectedPrefix string
+		expectedError  string
+	}{
+		{path: "gs://state/cluster.example.com", expectedBucket: "state", expectedPrefix: "cluster.example.com/"},
+		{path: "gs://state/kops/cluster.example.com/", expectedBucket: "state", expectedPrefix: "kops/cluster.example.com/"},
+		{path: "gs://state", expectedError: "refusing to delete the root"},
+		{path: "gs://state/", expectedError: "refusing to delete the root"},
+		{path: "gs://state/other.example.com", expectedError: "is not for cluster"},
+		{path: "gs://state/notcluster.example.com", expectedError: "is not for cluster"},
+		{path: "s3://state/cluster.example.com", expectedError: "is not a gs:// path"},
+	}
+	for _, g := range grid {
+		bucket, prefix, err := parseStateStorePath(g.path, "cluster.example.com")
+		if g.expectedError != "" {
+			if err == nil || !strings.Contains(err.Error(), g.expectedError) {
+				t.Errorf("path %q: expected error containing %q, got %v", g.path, g.expectedError, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("path %q: unexpected error: %v", g.path, err)
+			continue
+		}
+		if bucket != g.expectedBucket || prefix != g.expectedPrefix {
+			t.Errorf("path %q: got bucket %q prefix %q, expected bucket %q prefix %q", g.path, bucket, prefix, g.expectedBucket, g.expectedPrefix)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"sort"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/kops/pkg/resources"
+)
+
+// costlyResourceTypes are the resource types that are billed for as long as they exist,
+// and so are worth reviewing before deleting (or when they are left behind)
+var costlyResourceTypes = sets.NewString(
+	typeInstance,
+	typeDisk,
+	typeSnapshot,
+	typeMachineImage,
+	typeImage,
+	typeAddress,
+	typeTPUNode,
+)
+
+// Summary describes the discovered resources, so they can be reviewed before deleting them
+type Summary struct {
+	// Types has an entry for each type of resource found, sorted by type
+	Types []TypeSummary
+}
+
+// TypeSummary describes the discovered resources of a single type
+type TypeSummary struct {
+	Type  string
+	Count int
+	// Names are the sorted names of the resources
+	Names []string
+	// Costly is true if resources of this type are commonly billed while they exist, e.g. disks or static IPs
+	Costly bool
+}
+
+// Costly returns the summaries of the costly resource types that were found
+func (s Summary) Costly() []TypeSummary {
+	var costly []TypeSummary
+	for _, t := range s.Types {
+		if t.Costly {
+			costly = append(costly, t)
+		}
+	}
+	return costly
+}
+
+// Summarize groups the discovered resources by type. It only reads the resources, and makes no GCE calls.
+func Summarize(resourceMap map[string]*resources.Resource) Summary {
+	byType := make(map[string]*TypeSummary)
+	for _, r := range resourceMap {
+		t := byType[r.Type]
+		if t == nil {
+			t = &TypeSummary{
+				Type:   r.Type,
+				Costly: costlyResourceTypes.Has(r.Type),
+			}
+			byType[r.Type] = t
+		}
+		t.Count++
+		t.Names = append(t.Names, r.Name)
+	}
+
+	var s Summary
+	for _, t := range byType {
+		sort.Strings(t.Names)
+		s.Types = append(s.Types, *t)
+	}
+	sort.Slice(s.Types, func(i, j int) bool {
+		return s.Types[i].Type < s.Types[j].Type
+	})
+	return s
+}
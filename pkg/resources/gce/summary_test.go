@@ -0,0 +1,59 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/kops/pkg/resources"
+)
+
+func TestSummarize(t *testing.T) {
+	resourceMap := make(map[string]*resources.Resource)
+	for _, r := range []*resources.Resource{
+		{Type: "Disk", ID: "b-etcd-main-cluster-example-com", Name: "b-etcd-main-cluster-example-com"},
+		{Type: "Disk", ID: "a-etcd-main-cluster-example-com", Name: "a-etcd-main-cluster-example-com"},
+		{Type: "Address", ID: "api-cluster-example-com", Name: "api-cluster-example-com"},
+		{Type: "FirewallRule", ID: "node-to-node-cluster-example-com", Name: "node-to-node-cluster-example-com"},
+		{Type: "Snapshot", ID: "backup-1", Name: "backup-1"},
+		{Type: "InstanceTemplate", ID: "nodes-cluster-example-com-1234", Name: "nodes-cluster-example-com-1234"},
+	} {
+		resourceMap[r.Type+":"+r.ID] = r
+	}
+
+	s := Summarize(resourceMap)
+
+	expected := []TypeSummary{
+		{Type: "Address", Count: 1, Names: []string{"api-cluster-example-com"}, Costly: true},
+		{Type: "Disk", Count: 2, Names: []string{"a-etcd-main-cluster-example-com", "b-etcd-main-cluster-example-com"}, Costly: true},
+		{Type: "FirewallRule", Count: 1, Names: []string{"node-to-node-cluster-example-com"}},
+		{Type: "InstanceTemplate", Count: 1, Names: []string{"nodes-cluster-example-com-1234"}},
+		{Type: "Snapshot", Count: 1, Names: []string{"backup-1"}, Costly: true},
+	}
+	if !reflect.DeepEqual(s.Types, expected) {
+		t.Errorf("unexpected summary, got %+v, expected %+v", s.Types, expected)
+	}
+
+	var costly []string
+	for _, t := range s.Costly() {
+		costly = append(costly, t.Type)
+	}
+	if expected := []string{"Address", "Disk", "Snapshot"}; !reflect.DeepEqual(costly, expected) {
+		t.Errorf("unexpected costly types, got %v, expected %v", costly, expected)
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	compute "google.golang.org/api/compute/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/resources"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+// slowOperationThreshold is how long we wait for a delete operation before warning that it is slow
+var slowOperationThreshold = 30 * time.Second
+
+// opTimeoutCloud wraps a GCECloud so that waiting for an operation warns when it is slow,
+// and gives up once the timeout is reached.
+type opTimeoutCloud struct {
+	gce.GCECloud
+
+	resource *resources.Resource
+	timeout  time.Duration
+}
+
+var _ gce.GCECloud = &opTimeoutCloud{}
+
+// WaitForOp implements GCECloud::WaitForOp
+func (c *opTimeoutCloud) WaitForOp(op *compute.Operation) error {
+	return c.waitForOp(deletionContext(c.GCECloud), op)
+}
+
+// waitForOp polls the operation, warning when it is slow and giving up once the timeout is reached
+func (c *opTimeoutCloud) waitForOp(ctx context.Context, op *compute.Operation) error {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- pollOp(ctx, c.GCECloud, op)
+	}()
+
+	slow := time.NewTimer(slowOperationThreshold)
+	defer slow.Stop()
+
+	for {
+		select {
+		case err := <-done:
+			if err != nil && ctx.Err() == context.DeadlineExceeded && c.timeout > 0 {
+				return fmt.Errorf("timed out after %v waiting for operation %q deleting %s:%s (%s)", c.timeout, op.Name, c.resource.Type, c.resource.ID, op.TargetLink)
+			}
+			return err
+		case <-slow.C:
+			klog.Warningf("deletion of %s:%s is taking longer than %v (operation %q)", c.resource.Type, c.resource.ID, slowOperationThreshold, op.Name)
+		}
+	}
+}
+
+// withOpTimeout wraps the deleters of the resource so that their operations are subject to the timeout.
+// A timeout of 0 means we only warn about slow operations.
+func withOpTimeout(t *resources.Resource, timeout time.Duration) {
+	if deleter := t.Deleter; deleter != nil {
+		t.Deleter = func(cloud fi.Cloud, r *resources.Resource) error {
+			return deleter(&opTimeoutCloud{GCECloud: cloud.(gce.GCECloud), resource: r, timeout: timeout}, r)
+		}
+	}
+	if groupDeleter := t.GroupDeleter; groupDeleter != nil {
+		t.GroupDeleter = func(cloud fi.Cloud, trackers []*resources.Resource) error {
+			return groupDeleter(&opTimeoutCloud{GCECloud: cloud.(gce.GCECloud), resource: trackers[0], timeout: timeout}, trackers)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	compute "google.golang.org/api/compute/v1"
+	gcemock "k8s.io/kops/cloudmock/gce"
+)
+
+func TestDeleteTimeout(t *testing.T) {
+	defer func(d time.Duration) { slowOperationThreshold = d }(slowOperationThreshold)
+	slowOperationThreshold = time.Millisecond
+	defer func(d time.Duration) { opPollInterval = d }(opPollInterval)
+	opPollInterval = time.Millisecond
+
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+	if _, err := cloud.Compute().Disks().Insert("testproject", "us-test1-a", &compute.Disk{
+		Name:   "a-etcd-main-cluster-example-com",
+		Labels: map[string]string{"k8s-io-cluster-name": "cluster-example-com"},
+	}); err != nil {
+		t.Fatalf("error creating disk: %v", err)
+	}
+
+	resourceMap, err := ListResourcesGCEWithOptions(context.Background(), cloud, "cluster.example.com", "us-test1", ListResourcesGCEOptions{DeleteTimeout: 50 * time.Millisecond, QPS: testQPS})
+	if err != nil {
+		t.Fatalf("error listing resources: %v", err)
+	}
+	r := resourceMap["Disk:us-test1-a/a-etcd-main-cluster-example-com"]
+	if r == nil {
+		t.Fatalf("expected disk to be tracked, got %v", resourceMap)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		// The deletion operation never completes
+		errCh <- r.Deleter(&pendingOpCloud{GCECloud: cloud}, r)
+	}()
+
+	select {
+	case err := <-errCh:
+		if err == nil {
+			t.Fatalf("expected timeout error")
+		}
+		if !strings.Contains(err.Error(), "timed out") || !strings.Contains(err.Error(), "Disk:us-test1-a/a-etcd-main-cluster-example-com") {
+			t.Errorf("unexpected error: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("deleter did not time out")
+	}
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tpu "google.golang.org/api/tpu/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/resources"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
+)
+
+const typeTPUNode = "TPUNode"
+
+const (
+	// tpuOperationPollInterval is how often we check whether a TPU node has been deleted
+	tpuOperationPollInterval = 5 * time.Second
+	// tpuOperationTimeout is how long we wait for a TPU node to be deleted
+	tpuOperationTimeout = 10 * time.Minute
+)
+
+// tpuNodeClient is the subset of the TPU API we use to find and delete the TPU nodes
+type tpuNodeClient interface {
+	List(ctx context.Context, project, zone string) ([]*tpu.Node, error)
+	// Delete deletes the node with the given resource name, waiting for the deletion to complete
+	Delete(name string) error
+}
+
+type tpuNodeClientImpl struct {
+	srv *tpu.Service
+}
+
+var _ tpuNodeClient = &tpuNodeClientImpl{}
+
+func newTPUNodeClient(ctx context.Context) (*tpuNodeClientImpl, error) {
+	srv, err := tpu.NewService(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error building TPU API client: %v", err)
+	}
+	return &tpuNodeClientImpl{srv: srv}, nil
+}
+
+func (c *tpuNodeClientImpl) List(ctx context.Context, project, zone string) ([]*tpu.Node, error) {
+	var l []*tpu.Node
+	parent := fmt.Sprintf("projects/%s/locations/%s", project, zone)
+	if err := c.srv.Projects.Locations.Nodes.List(parent).Pages(ctx, func(p *tpu.ListNodesResponse) error {
+		l = append(l, p.Nodes...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (c *tpuNodeClientImpl) Delete(name string) error {
+	op, err := c.srv.Projects.Locations.Nodes.Delete(name).Do()
+	if err != nil {
+		return err
+	}
+	return wait.PollImmediate(tpuOperationPollInterval, tpuOperationTimeout, func() (bool, error) {
+		if !op.Done {
+			op, err = c.srv.Projects.Locations.Operations.Get(op.Name).Do()
+			if err != nil {
+				return false, err
+			}
+		}
+		if !op.Done {
+			return false, nil
+		}
+		if op.Error != nil {
+			return false, fmt.Errorf("operation %s failed: %s", op.Name, op.Error.Message)
+		}
+		return true, nil
+	})
+}
+
+// listTPUNodes finds the TPU nodes that carry the cluster label, in the zones of the cluster
+func (d *clusterDiscoveryGCE) listTPUNodes(ctx context.Context) ([]*resources.Resource, error) {
+	if d.tpuNodes == nil {
+		return nil, nil
+	}
+
+	client := d.tpuNodes
+	var resourceTrackers []*resources.Resource
+	for _, zoneName := range d.zones {
+		if err := d.waitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+		nodes, err := client.List(ctx, d.gceCloud.Project(), zoneName)
+		if err != nil {
+			return nil, fmt.Errorf("error listing TPU nodes: %v", err)
+		}
+
+		for _, node := range nodes {
+			if !d.matchesCluster(typeTPUNode, node.Name, node.Labels) {
+				continue
+			}
+
+			name := gce.LastComponent(node.Name)
+			resourceTracker := &resources.Resource{
+				Name: name,
+				ID:   zoneName + "/" + name,
+				Type: typeTPUNode,
+				Deleter: func(cloud fi.Cloud, r *resources.Resource) error {
+					return deleteTPUNode(client, r)
+				},
+				Dumper: DumpResource,
+				Obj:    node,
+			}
+
+			klog.V(4).Infof("Found resource: %s", node.Name)
+			resourceTrackers = append(resourceTrackers, resourceTracker)
+		}
+	}
+
+	return resourceTrackers, nil
+}
+
+func deleteTPUNode(client tpuNodeClient, r *resources.Resource) error {
+	node := r.Obj.(*tpu.Node)
+
+	klog.V(2).Infof("Deleting TPU node %s", node.Name)
+	if err := client.Delete(node.Name); err != nil {
+		if isAlreadyGoneErr(err) {
+			klog.Infof("TPU node not found or already being deleted: %q", node.Name)
+			return nil
+		}
+		return fmt.Errorf("error deleting TPU node %s: %v", node.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	tpu "google.golang.org/api/tpu/v1"
+	gcemock "k8s.io/kops/cloudmock/gce"
+)
+
+type fakeTPUNodeClient struct {
+	nodes   map[string][]*tpu.Node
+	deleted []string
+}
+
+func (c *fakeTPUNodeClient) List(ctx context.Context, project, zone string) ([]*tpu.Node, error) {
+	return c.nodes[project+"/"+zone], nil
+}
+
+func (c *fakeTPUNodeClient) Delete(name string) error {
+	c.deleted = append(c.deleted, name)
+	return nil
+}
+
+func TestListTPUNodes(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	client := &fakeTPUNodeClient{
+		nodes: map[string][]*tpu.Node{
+			"testproject/us-test1-a": {
+				{
+					Name:   "projects/testproject/locations/us-test1-a/nodes/training",
+					Labels: map[string]string{"k8s-io-cluster-name": "cluster-example-com"},
+				},
+				{
+					Name:   "projects/testproject/locations/us-test1-a/nodes/other-training",
+					Labels: map[string]string{"k8s-io-cluster-name": "other-example-com"},
+				},
+				{
+					Name: "projects/testproject/locations/us-test1-a/nodes/unlabelled",
+				},
+			},
+		},
+	}
+
+	d := &clusterDiscoveryGCE{
+		cloud:       cloud,
+		gceCloud:    cloud,
+		clusterName: "cluster.example.com",
+		zones:       []string{"us-test1-a"},
+		tpuNodes:    client,
+	}
+	resourceTrackers, err := d.listTPUNodes(context.Background())
+	if err != nil {
+		t.Fatalf("error listing TPU nodes: %v", err)
+	}
+
+	var actual []string
+	for _, r := range resourceTrackers {
+		actual = append(actual, r.Type+":"+r.ID)
+		if err := r.Deleter(cloud, r); err != nil {
+			t.Fatalf("error deleting TPU node: %v", err)
+		}
+	}
+	if expected := []string{"TPUNode:us-test1-a/training"}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected TPU nodes, got %v, expected %v", actual, expected)
+	}
+	if expected := []string{"projects/testproject/locations/us-test1-a/nodes/training"}; !reflect.DeepEqual(client.deleted, expected) {
+		t.Errorf("unexpected deleted TPU nodes, got %v, expected %v", client.deleted, expected)
+	}
+}
+
+func TestListTPUNodesNotConfigured(t *testing.T) {
+	cloud := gcemock.InstallMockGCECloud("us-test1", "testproject")
+
+	d := &clusterDiscoveryGCE{
+		cloud:       cloud,
+		gceCloud:    cloud,
+		clusterName: "cluster.example.com",
+		zones:       []string{"us-test1-a"},
+	}
+	resourceTrackers, err := d.listTPUNodes(context.Background())
+	if err != nil {
+		t.Fatalf("error listing TPU nodes: %v", err)
+	}
+	if len(resourceTrackers) != 0 {
+		t.Errorf("expected no TPU nodes without a TPU client, got %d", len(resourceTrackers))
+	}
+}
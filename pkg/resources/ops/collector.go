@@ -17,6 +17,7 @@ limitations under the License.
 package ops
 
 import (
+	"context"
 	"fmt"
 
 	"k8s.io/kops/pkg/apis/kops"
@@ -45,7 +46,10 @@ func ListResources(cloud fi.Cloud, cluster *kops.Cluster, region string) (map[st
 	case kops.CloudProviderDO:
 		return digitalocean.ListResources(cloud.(clouddo.DOCloud), clusterName)
 	case kops.CloudProviderGCE:
-		return gce.ListResourcesGCE(cloud.(cloudgce.GCECloud), clusterName, region)
+		options := gce.ListResourcesGCEOptions{
+			DNSNamePrefixes: gce.DNSNamePrefixesForCluster(cluster),
+		}
+		return gce.ListResourcesGCEWithOptions(context.TODO(), cloud.(cloudgce.GCECloud), clusterName, region, options)
 	case kops.CloudProviderOpenstack:
 		return openstack.ListResources(cloud.(cloudopenstack.OpenstackCloud), clusterName)
 	case kops.CloudProviderALI:
@@ -17,18 +17,26 @@ limitations under the License.
 package ops
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
 
 	"k8s.io/klog/v2"
+	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/resources"
 	awsresources "k8s.io/kops/pkg/resources/aws"
+	"k8s.io/kops/pkg/resources/gce"
 	"k8s.io/kops/upup/pkg/fi"
 )
 
 // DeleteResources deletes the resources, as previously collected by ListResources
 func DeleteResources(cloud fi.Cloud, resourceMap map[string]*resources.Resource) error {
+	// GCE resources are deleted with the retries and backoff that the GCE API needs, see gce.DeleteResources
+	if cloud != nil && cloud.ProviderID() == kops.CloudProviderGCE {
+		return gce.DeleteResources(context.TODO(), cloud, resourceMap)
+	}
+
 	depMap := make(map[string][]string)
 
 	done := make(map[string]*resources.Resource)
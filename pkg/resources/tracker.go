@@ -17,6 +17,8 @@ limitations under the License.
 package resources
 
 import (
+	"time"
+
 	"k8s.io/kops/upup/pkg/fi"
 )
 
@@ -32,6 +34,14 @@ type Resource struct {
 	Blocked []string
 	Done    bool
 
+	// Region and Zone are the location of regional and zonal resources, if the cloud provider sets them
+	Region string
+	Zone   string
+	// CreationTimestamp is when the resource was created, if the cloud provider sets it
+	CreationTimestamp time.Time
+	// Reason is a short explanation of why the resource was matched to the cluster, if the cloud provider sets it
+	Reason string
+
 	Deleter      func(cloud fi.Cloud, tracker *Resource) error
 	GroupKey     string
 	GroupDeleter func(cloud fi.Cloud, trackers []*Resource) error
@@ -18,8 +18,10 @@ package gce
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
+	computebeta "google.golang.org/api/compute/v0.beta"
 	compute "google.golang.org/api/compute/v1"
 )
 
@@ -27,25 +29,54 @@ type ComputeClient interface {
 	Projects() ProjectClient
 	Regions() RegionClient
 	Zones() ZoneClient
+	Operations() OperationClient
 
 	Networks() NetworkClient
 	Subnetworks() SubnetworkClient
 	Routes() RouteClient
 	ForwardingRules() ForwardingRuleClient
+	GlobalForwardingRules() GlobalForwardingRuleClient
 	Addresses() AddressClient
+	GlobalAddresses() GlobalAddressClient
 	Firewalls() FirewallClient
 	Routers() RouterClient
 
 	Instances() InstanceClient
 	InstanceTemplates() InstanceTemplateClient
 	InstanceGroupManagers() InstanceGroupManagerClient
+	RegionInstanceGroupManagers() RegionInstanceGroupManagerClient
+	InstanceGroups() InstanceGroupClient
+	Autoscalers() AutoscalerClient
 	TargetPools() TargetPoolClient
+	TargetInstances() TargetInstanceClient
+	NetworkEndpointGroups() NetworkEndpointGroupClient
+	GlobalNetworkEndpointGroups() GlobalNetworkEndpointGroupClient
 
 	Disks() DiskClient
+	RegionDisks() RegionDiskClient
+	ResourcePolicies() ResourcePolicyClient
+	Snapshots() SnapshotClient
+	MachineImages() MachineImageClient
+	Images() ImageClient
+
+	BackendServices() BackendServiceClient
+	RegionBackendServices() RegionBackendServiceClient
+	SecurityPolicies() SecurityPolicyClient
+	HealthChecks() HealthCheckClient
+	SslCertificates() SslCertificateClient
+	SslPolicies() SslPolicyClient
+	RegionHealthChecks() RegionHealthCheckClient
+	RegionSslCertificates() RegionSslCertificateClient
+	TargetHttpProxies() TargetHttpProxyClient
+	TargetHttpsProxies() TargetHttpsProxyClient
+	UrlMaps() UrlMapClient
+	HttpHealthChecks() HttpHealthCheckClient
 }
 
 type computeClientImpl struct {
 	srv *compute.Service
+	// betaSrv is used for the resources that are only available in the beta API
+	betaSrv *computebeta.Service
 }
 
 var _ ComputeClient = &computeClientImpl{}
@@ -55,8 +86,13 @@ func newComputeClientImpl(ctx context.Context) (*computeClientImpl, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error building compute API client: %v", err)
 	}
+	betaSrv, err := computebeta.NewService(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error building compute beta API client: %v", err)
+	}
 	return &computeClientImpl{
-		srv: srv,
+		srv:     srv,
+		betaSrv: betaSrv,
 	}, nil
 }
 
@@ -78,6 +114,12 @@ func (c *computeClientImpl) Zones() ZoneClient {
 	}
 }
 
+func (c *computeClientImpl) Operations() OperationClient {
+	return &operationClientImpl{
+		srv: c.srv,
+	}
+}
+
 func (c *computeClientImpl) Networks() NetworkClient {
 	return &networkClientImpl{
 		srv: c.srv.Networks,
@@ -102,6 +144,12 @@ func (c *computeClientImpl) ForwardingRules() ForwardingRuleClient {
 	}
 }
 
+func (c *computeClientImpl) GlobalForwardingRules() GlobalForwardingRuleClient {
+	return &globalForwardingRuleClientImpl{
+		srv: c.srv.GlobalForwardingRules,
+	}
+}
+
 func (c *computeClientImpl) Addresses() AddressClient {
 	return &addressClientImpl{
 		srv: c.srv.Addresses,
@@ -138,20 +186,191 @@ func (c *computeClientImpl) InstanceGroupManagers() InstanceGroupManagerClient {
 	}
 }
 
+func (c *computeClientImpl) RegionInstanceGroupManagers() RegionInstanceGroupManagerClient {
+	return &regionInstanceGroupManagerClientImpl{
+		srv: c.srv.RegionInstanceGroupManagers,
+	}
+}
+
+func (c *computeClientImpl) InstanceGroups() InstanceGroupClient {
+	return &instanceGroupClientImpl{
+		srv: c.srv.InstanceGroups,
+	}
+}
+
+func (c *computeClientImpl) Autoscalers() AutoscalerClient {
+	return &autoscalerClientImpl{
+		srv: c.srv.Autoscalers,
+	}
+}
+
 func (c *computeClientImpl) TargetPools() TargetPoolClient {
 	return &targetPoolClientImpl{
 		srv: c.srv.TargetPools,
 	}
 }
 
+func (c *computeClientImpl) TargetInstances() TargetInstanceClient {
+	return &targetInstanceClientImpl{
+		srv: c.srv.TargetInstances,
+	}
+}
+
+func (c *computeClientImpl) NetworkEndpointGroups() NetworkEndpointGroupClient {
+	return &networkEndpointGroupClientImpl{
+		srv: c.srv.NetworkEndpointGroups,
+	}
+}
+
+func (c *computeClientImpl) GlobalNetworkEndpointGroups() GlobalNetworkEndpointGroupClient {
+	return &globalNetworkEndpointGroupClientImpl{
+		srv: c.srv.GlobalNetworkEndpointGroups,
+	}
+}
+
+func (c *computeClientImpl) ResourcePolicies() ResourcePolicyClient {
+	return &resourcePolicyClientImpl{
+		srv: c.srv.ResourcePolicies,
+	}
+}
+
 func (c *computeClientImpl) Disks() DiskClient {
 	return &diskClientImpl{
 		srv: c.srv.Disks,
 	}
 }
 
+func (c *computeClientImpl) RegionDisks() RegionDiskClient {
+	return &regionDiskClientImpl{
+		srv: c.srv.RegionDisks,
+	}
+}
+
+func (c *computeClientImpl) Snapshots() SnapshotClient {
+	return &snapshotClientImpl{
+		srv: c.srv.Snapshots,
+	}
+}
+
+func (c *computeClientImpl) MachineImages() MachineImageClient {
+	return &machineImageClientImpl{
+		srv: c.betaSrv.MachineImages,
+	}
+}
+
+func (c *computeClientImpl) Images() ImageClient {
+	return &imageClientImpl{
+		srv: c.srv.Images,
+	}
+}
+
+func (c *computeClientImpl) BackendServices() BackendServiceClient {
+	return &backendServiceClientImpl{
+		srv: c.srv.BackendServices,
+	}
+}
+
+func (c *computeClientImpl) RegionBackendServices() RegionBackendServiceClient {
+	return &regionBackendServiceClientImpl{
+		srv: c.srv.RegionBackendServices,
+	}
+}
+
+func (c *computeClientImpl) SecurityPolicies() SecurityPolicyClient {
+	return &securityPolicyClientImpl{
+		srv: c.srv.SecurityPolicies,
+	}
+}
+
+func (c *computeClientImpl) HealthChecks() HealthCheckClient {
+	return &healthCheckClientImpl{
+		srv: c.srv.HealthChecks,
+	}
+}
+
+func (c *computeClientImpl) SslCertificates() SslCertificateClient {
+	return &sslCertificateClientImpl{
+		srv: c.srv.SslCertificates,
+	}
+}
+
+func (c *computeClientImpl) SslPolicies() SslPolicyClient {
+	return &sslPolicyClientImpl{
+		srv: c.srv.SslPolicies,
+	}
+}
+
+func (c *computeClientImpl) RegionHealthChecks() RegionHealthCheckClient {
+	return &regionHealthCheckClientImpl{
+		srv: c.srv.RegionHealthChecks,
+	}
+}
+
+func (c *computeClientImpl) RegionSslCertificates() RegionSslCertificateClient {
+	return &regionSslCertificateClientImpl{
+		srv: c.srv.RegionSslCertificates,
+	}
+}
+
+func (c *computeClientImpl) TargetHttpProxies() TargetHttpProxyClient {
+	return &targetHttpProxyClientImpl{
+		srv: c.srv.TargetHttpProxies,
+	}
+}
+
+func (c *computeClientImpl) TargetHttpsProxies() TargetHttpsProxyClient {
+	return &targetHttpsProxyClientImpl{
+		srv: c.srv.TargetHttpsProxies,
+	}
+}
+
+func (c *computeClientImpl) UrlMaps() UrlMapClient {
+	return &urlMapClientImpl{
+		srv: c.srv.UrlMaps,
+	}
+}
+
+func (c *computeClientImpl) GlobalAddresses() GlobalAddressClient {
+	return &globalAddressClientImpl{
+		srv: c.srv.GlobalAddresses,
+	}
+}
+
+func (c *computeClientImpl) HttpHealthChecks() HttpHealthCheckClient {
+	return &httpHealthCheckClientImpl{
+		srv: c.srv.HttpHealthChecks,
+	}
+}
+
+// OperationClient gets the latest state of operations, for callers that poll them rather than use WaitForOp
+type OperationClient interface {
+	// Get returns the latest state of the zonal, regional or global operation
+	Get(ctx context.Context, op *compute.Operation) (*compute.Operation, error)
+}
+
+type operationClientImpl struct {
+	srv *compute.Service
+}
+
+var _ OperationClient = &operationClientImpl{}
+
+func (c *operationClientImpl) Get(ctx context.Context, op *compute.Operation) (*compute.Operation, error) {
+	u, err := ParseGoogleCloudURL(op.SelfLink)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing operation URL %q: %v", op.SelfLink, err)
+	}
+	if u.Zone != "" {
+		return c.srv.ZoneOperations.Get(u.Project, u.Zone, op.Name).Context(ctx).Do()
+	}
+	if u.Region != "" {
+		return c.srv.RegionOperations.Get(u.Project, u.Region, op.Name).Context(ctx).Do()
+	}
+	return c.srv.GlobalOperations.Get(u.Project, op.Name).Context(ctx).Do()
+}
+
 type ProjectClient interface {
 	Get(project string) (*compute.Project, error)
+	SetCommonInstanceMetadata(project string, metadata *compute.Metadata) (*compute.Operation, error)
 }
 
 type projectClientImpl struct {
@@ -164,6 +383,10 @@ func (c *projectClientImpl) Get(project string) (*compute.Project, error) {
 	return c.srv.Get(project).Do()
 }
 
+func (c *projectClientImpl) SetCommonInstanceMetadata(project string, metadata *compute.Metadata) (*compute.Operation, error) {
+	return c.srv.SetCommonInstanceMetadata(project, metadata).Do()
+}
+
 type RegionClient interface {
 	List(ctx context.Context, project string) ([]*compute.Region, error)
 }
@@ -210,7 +433,9 @@ func (c *zoneClientImpl) List(ctx context.Context, project string) ([]*compute.Z
 
 type NetworkClient interface {
 	Insert(project string, nw *compute.Network) (*compute.Operation, error)
+	Delete(project, name string) (*compute.Operation, error)
 	Get(project, name string) (*compute.Network, error)
+	RemovePeering(project, network string, req *compute.NetworksRemovePeeringRequest) (*compute.Operation, error)
 }
 
 type networkClientImpl struct {
@@ -223,10 +448,18 @@ func (c *networkClientImpl) Insert(project string, nw *compute.Network) (*comput
 	return c.srv.Insert(project, nw).Do()
 }
 
+func (c *networkClientImpl) Delete(project, name string) (*compute.Operation, error) {
+	return c.srv.Delete(project, name).Do()
+}
+
 func (c *networkClientImpl) Get(project, name string) (*compute.Network, error) {
 	return c.srv.Get(project, name).Do()
 }
 
+func (c *networkClientImpl) RemovePeering(project, network string, req *compute.NetworksRemovePeeringRequest) (*compute.Operation, error) {
+	return c.srv.RemovePeering(project, network, req).Do()
+}
+
 type SubnetworkClient interface {
 	Insert(project, region string, subnet *compute.Subnetwork) (*compute.Operation, error)
 	Patch(project, region, name string, subnet *compute.Subnetwork) (*compute.Operation, error)
@@ -269,6 +502,7 @@ func (c *subnetworkClientImpl) List(ctx context.Context, project, region string)
 }
 
 type RouteClient interface {
+	Insert(project string, r *compute.Route) (*compute.Operation, error)
 	Delete(project, name string) (*compute.Operation, error)
 	List(ctx context.Context, project string) ([]*compute.Route, error)
 }
@@ -279,6 +513,10 @@ type routeClientImpl struct {
 
 var _ RouteClient = &routeClientImpl{}
 
+func (c *routeClientImpl) Insert(project string, r *compute.Route) (*compute.Operation, error) {
+	return c.srv.Insert(project, r).Do()
+}
+
 func (c *routeClientImpl) Delete(project, name string) (*compute.Operation, error) {
 	return c.srv.Delete(project, name).Do()
 }
@@ -419,6 +657,7 @@ type RouterClient interface {
 	Insert(project, region string, r *compute.Router) (*compute.Operation, error)
 	Delete(project, region, name string) (*compute.Operation, error)
 	Get(project, region, name string) (*compute.Router, error)
+	Patch(project, region, name string, r *compute.Router) (*compute.Operation, error)
 	List(ctx context.Context, project, region string) ([]*compute.Router, error)
 }
 
@@ -440,6 +679,10 @@ func (c *routerClientImpl) Get(project, region, name string) (*compute.Router, e
 	return c.srv.Get(project, region, name).Do()
 }
 
+func (c *routerClientImpl) Patch(project, region, name string, r *compute.Router) (*compute.Operation, error) {
+	return c.srv.Patch(project, region, name, r).Do()
+}
+
 func (c *routerClientImpl) List(ctx context.Context, project, region string) ([]*compute.Router, error) {
 	var rs []*compute.Router
 	if err := c.srv.List(project, region).Pages(ctx, func(p *compute.RouterList) error {
@@ -458,6 +701,7 @@ type InstanceClient interface {
 	Delete(project, zone, name string) (*compute.Operation, error)
 
 	SetMetadata(project, zone, name string, metadata *compute.Metadata) (*compute.Operation, error)
+	DetachDisk(project, zone, name, deviceName string) (*compute.Operation, error)
 }
 
 type instanceClientImpl struct {
@@ -493,6 +737,10 @@ func (c *instanceClientImpl) SetMetadata(project, zone, name string, metadata *c
 	return c.srv.SetMetadata(project, zone, name, metadata).Do()
 }
 
+func (c *instanceClientImpl) DetachDisk(project, zone, name, deviceName string) (*compute.Operation, error) {
+	return c.srv.DetachDisk(project, zone, name, deviceName).Do()
+}
+
 type InstanceTemplateClient interface {
 	Insert(project string, template *compute.InstanceTemplate) (*compute.Operation, error)
 	Delete(project, name string) (*compute.Operation, error)
@@ -640,12 +888,242 @@ func (c *targetPoolClientImpl) List(ctx context.Context, project, region string)
 	return tps, nil
 }
 
+type TargetInstanceClient interface {
+	Insert(project, zone string, ti *compute.TargetInstance) (*compute.Operation, error)
+	Delete(project, zone, name string) (*compute.Operation, error)
+	Get(project, zone, name string) (*compute.TargetInstance, error)
+	List(ctx context.Context, project, zone string) ([]*compute.TargetInstance, error)
+}
+
+type targetInstanceClientImpl struct {
+	srv *compute.TargetInstancesService
+}
+
+var _ TargetInstanceClient = &targetInstanceClientImpl{}
+
+func (c *targetInstanceClientImpl) Insert(project, zone string, ti *compute.TargetInstance) (*compute.Operation, error) {
+	return c.srv.Insert(project, zone, ti).Do()
+}
+
+func (c *targetInstanceClientImpl) Delete(project, zone, name string) (*compute.Operation, error) {
+	return c.srv.Delete(project, zone, name).Do()
+}
+
+func (c *targetInstanceClientImpl) Get(project, zone, name string) (*compute.TargetInstance, error) {
+	return c.srv.Get(project, zone, name).Do()
+}
+
+func (c *targetInstanceClientImpl) List(ctx context.Context, project, zone string) ([]*compute.TargetInstance, error) {
+	var tis []*compute.TargetInstance
+	if err := c.srv.List(project, zone).Pages(ctx, func(p *compute.TargetInstanceList) error {
+		tis = append(tis, p.Items...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return tis, nil
+}
+
+type NetworkEndpointGroupClient interface {
+	Insert(project, zone string, neg *compute.NetworkEndpointGroup) (*compute.Operation, error)
+	Delete(project, zone, name string) (*compute.Operation, error)
+	Get(project, zone, name string) (*compute.NetworkEndpointGroup, error)
+	List(ctx context.Context, project, zone string) ([]*compute.NetworkEndpointGroup, error)
+}
+
+type networkEndpointGroupClientImpl struct {
+	srv *compute.NetworkEndpointGroupsService
+}
+
+var _ NetworkEndpointGroupClient = &networkEndpointGroupClientImpl{}
+
+func (c *networkEndpointGroupClientImpl) Insert(project, zone string, neg *compute.NetworkEndpointGroup) (*compute.Operation, error) {
+	return c.srv.Insert(project, zone, neg).Do()
+}
+
+func (c *networkEndpointGroupClientImpl) Delete(project, zone, name string) (*compute.Operation, error) {
+	return c.srv.Delete(project, zone, name).Do()
+}
+
+func (c *networkEndpointGroupClientImpl) Get(project, zone, name string) (*compute.NetworkEndpointGroup, error) {
+	return c.srv.Get(project, zone, name).Do()
+}
+
+func (c *networkEndpointGroupClientImpl) List(ctx context.Context, project, zone string) ([]*compute.NetworkEndpointGroup, error) {
+	var negs []*compute.NetworkEndpointGroup
+	if err := c.srv.List(project, zone).Pages(ctx, func(p *compute.NetworkEndpointGroupList) error {
+		negs = append(negs, p.Items...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return negs, nil
+}
+
+type GlobalNetworkEndpointGroupClient interface {
+	Insert(project string, neg *compute.NetworkEndpointGroup) (*compute.Operation, error)
+	Delete(project, name string) (*compute.Operation, error)
+	Get(project, name string) (*compute.NetworkEndpointGroup, error)
+	List(ctx context.Context, project string) ([]*compute.NetworkEndpointGroup, error)
+}
+
+type globalNetworkEndpointGroupClientImpl struct {
+	srv *compute.GlobalNetworkEndpointGroupsService
+}
+
+var _ GlobalNetworkEndpointGroupClient = &globalNetworkEndpointGroupClientImpl{}
+
+func (c *globalNetworkEndpointGroupClientImpl) Insert(project string, neg *compute.NetworkEndpointGroup) (*compute.Operation, error) {
+	return c.srv.Insert(project, neg).Do()
+}
+
+func (c *globalNetworkEndpointGroupClientImpl) Delete(project, name string) (*compute.Operation, error) {
+	return c.srv.Delete(project, name).Do()
+}
+
+func (c *globalNetworkEndpointGroupClientImpl) Get(project, name string) (*compute.NetworkEndpointGroup, error) {
+	return c.srv.Get(project, name).Do()
+}
+
+func (c *globalNetworkEndpointGroupClientImpl) List(ctx context.Context, project string) ([]*compute.NetworkEndpointGroup, error) {
+	var negs []*compute.NetworkEndpointGroup
+	if err := c.srv.List(project).Pages(ctx, func(p *compute.NetworkEndpointGroupList) error {
+		negs = append(negs, p.Items...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return negs, nil
+}
+
+type RegionInstanceGroupManagerClient interface {
+	Insert(project, region string, i *compute.InstanceGroupManager) (*compute.Operation, error)
+	Delete(project, region, name string) (*compute.Operation, error)
+	Get(project, region, name string) (*compute.InstanceGroupManager, error)
+	List(ctx context.Context, project, region string) ([]*compute.InstanceGroupManager, error)
+	ListManagedInstances(ctx context.Context, project, region, name string) ([]*compute.ManagedInstance, error)
+}
+
+type regionInstanceGroupManagerClientImpl struct {
+	srv *compute.RegionInstanceGroupManagersService
+}
+
+var _ RegionInstanceGroupManagerClient = &regionInstanceGroupManagerClientImpl{}
+
+func (c *regionInstanceGroupManagerClientImpl) Insert(project, region string, i *compute.InstanceGroupManager) (*compute.Operation, error) {
+	return c.srv.Insert(project, region, i).Do()
+}
+
+func (c *regionInstanceGroupManagerClientImpl) Delete(project, region, name string) (*compute.Operation, error) {
+	return c.srv.Delete(project, region, name).Do()
+}
+
+func (c *regionInstanceGroupManagerClientImpl) Get(project, region, name string) (*compute.InstanceGroupManager, error) {
+	return c.srv.Get(project, region, name).Do()
+}
+
+func (c *regionInstanceGroupManagerClientImpl) List(ctx context.Context, project, region string) ([]*compute.InstanceGroupManager, error) {
+	var ms []*compute.InstanceGroupManager
+	if err := c.srv.List(project, region).Pages(ctx, func(page *compute.RegionInstanceGroupManagerList) error {
+		ms = append(ms, page.Items...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return ms, nil
+}
+
+func (c *regionInstanceGroupManagerClientImpl) ListManagedInstances(ctx context.Context, project, region, name string) ([]*compute.ManagedInstance, error) {
+	var instances []*compute.ManagedInstance
+	if err := c.srv.ListManagedInstances(project, region, name).Pages(ctx, func(page *compute.RegionInstanceGroupManagersListInstancesResponse) error {
+		instances = append(instances, page.ManagedInstances...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return instances, nil
+}
+
+type InstanceGroupClient interface {
+	Insert(project, zone string, ig *compute.InstanceGroup) (*compute.Operation, error)
+	Delete(project, zone, name string) (*compute.Operation, error)
+	Get(project, zone, name string) (*compute.InstanceGroup, error)
+	List(ctx context.Context, project, zone string) ([]*compute.InstanceGroup, error)
+}
+
+type instanceGroupClientImpl struct {
+	srv *compute.InstanceGroupsService
+}
+
+var _ InstanceGroupClient = &instanceGroupClientImpl{}
+
+func (c *instanceGroupClientImpl) Insert(project, zone string, ig *compute.InstanceGroup) (*compute.Operation, error) {
+	return c.srv.Insert(project, zone, ig).Do()
+}
+
+func (c *instanceGroupClientImpl) Delete(project, zone, name string) (*compute.Operation, error) {
+	return c.srv.Delete(project, zone, name).Do()
+}
+
+func (c *instanceGroupClientImpl) Get(project, zone, name string) (*compute.InstanceGroup, error) {
+	return c.srv.Get(project, zone, name).Do()
+}
+
+func (c *instanceGroupClientImpl) List(ctx context.Context, project, zone string) ([]*compute.InstanceGroup, error) {
+	var igs []*compute.InstanceGroup
+	if err := c.srv.List(project, zone).Pages(ctx, func(page *compute.InstanceGroupList) error {
+		igs = append(igs, page.Items...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return igs, nil
+}
+
+type AutoscalerClient interface {
+	Insert(project, zone string, autoscaler *compute.Autoscaler) (*compute.Operation, error)
+	Delete(project, zone, name string) (*compute.Operation, error)
+	Get(project, zone, name string) (*compute.Autoscaler, error)
+	List(ctx context.Context, project, zone string) ([]*compute.Autoscaler, error)
+}
+
+type autoscalerClientImpl struct {
+	srv *compute.AutoscalersService
+}
+
+var _ AutoscalerClient = &autoscalerClientImpl{}
+
+func (c *autoscalerClientImpl) Insert(project, zone string, autoscaler *compute.Autoscaler) (*compute.Operation, error) {
+	return c.srv.Insert(project, zone, autoscaler).Do()
+}
+
+func (c *autoscalerClientImpl) Delete(project, zone, name string) (*compute.Operation, error) {
+	return c.srv.Delete(project, zone, name).Do()
+}
+
+func (c *autoscalerClientImpl) Get(project, zone, name string) (*compute.Autoscaler, error) {
+	return c.srv.Get(project, zone, name).Do()
+}
+
+func (c *autoscalerClientImpl) List(ctx context.Context, project, zone string) ([]*compute.Autoscaler, error) {
+	var autoscalers []*compute.Autoscaler
+	if err := c.srv.List(project, zone).Pages(ctx, func(page *compute.AutoscalerList) error {
+		autoscalers = append(autoscalers, page.Items...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return autoscalers, nil
+}
+
 type DiskClient interface {
 	Insert(project, zone string, disk *compute.Disk) (*compute.Operation, error)
 	Delete(project, zone, name string) (*compute.Operation, error)
 	Get(project, zone, name string) (*compute.Disk, error)
 	List(ctx context.Context, project, zone string) ([]*compute.Disk, error)
-	AggregatedList(ctx context.Context, project string) ([]compute.DisksScopedList, error)
+	// AggregatedList returns the disks in all zones, reading every page.
+	// maxResults is a hint for the page size; 0 uses the server default.
+	AggregatedList(ctx context.Context, project string, maxResults int64) ([]compute.DisksScopedList, error)
 
 	SetLabels(project, zone, name string, req *compute.ZoneSetLabelsRequest) error
 }
@@ -679,9 +1157,13 @@ func (c *diskClientImpl) List(ctx context.Context, project, zone string) ([]*com
 	return disks, nil
 }
 
-func (c *diskClientImpl) AggregatedList(ctx context.Context, project string) ([]compute.DisksScopedList, error) {
+func (c *diskClientImpl) AggregatedList(ctx context.Context, project string, maxResults int64) ([]compute.DisksScopedList, error) {
 	var disks []compute.DisksScopedList
-	if err := c.srv.AggregatedList(project).Pages(ctx, func(page *compute.DiskAggregatedList) error {
+	call := c.srv.AggregatedList(project)
+	if maxResults > 0 {
+		call = call.MaxResults(maxResults)
+	}
+	if err := call.Pages(ctx, func(page *compute.DiskAggregatedList) error {
 		for _, list := range page.Items {
 			disks = append(disks, list)
 		}
@@ -696,3 +1178,717 @@ func (c *diskClientImpl) SetLabels(project, zone, name string, req *compute.Zone
 	_, err := c.srv.SetLabels(project, zone, name, req).Do()
 	return err
 }
+
+type BackendServiceClient interface {
+	Insert(project string, bs *compute.BackendService) (*compute.Operation, error)
+	Delete(project, name string) (*compute.Operation, error)
+	Get(project, name string) (*compute.BackendService, error)
+	List(ctx context.Context, project string) ([]*compute.BackendService, error)
+}
+
+type backendServiceClientImpl struct {
+	srv *compute.BackendServicesService
+}
+
+var _ BackendServiceClient = &backendServiceClientImpl{}
+
+func (c *backendServiceClientImpl) Insert(project string, bs *compute.BackendService) (*compute.Operation, error) {
+	return c.srv.Insert(project, bs).Do()
+}
+
+func (c *backendServiceClientImpl) Delete(project, name string) (*compute.Operation, error) {
+	return c.srv.Delete(project, name).Do()
+}
+
+func (c *backendServiceClientImpl) Get(project, name string) (*compute.BackendService, error) {
+	return c.srv.Get(project, name).Do()
+}
+
+func (c *backendServiceClientImpl) List(ctx context.Context, project string) ([]*compute.BackendService, error) {
+	var l []*compute.BackendService
+	if err := c.srv.List(project).Pages(ctx, func(p *compute.BackendServiceList) error {
+		l = append(l, p.Items...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+type SecurityPolicyClient interface {
+	Insert(project string, sp *compute.SecurityPolicy) (*compute.Operation, error)
+	Delete(project, name string) (*compute.Operation, error)
+	Get(project, name string) (*compute.SecurityPolicy, error)
+	List(ctx context.Context, project string) ([]*compute.SecurityPolicy, error)
+}
+
+type securityPolicyClientImpl struct {
+	srv *compute.SecurityPoliciesService
+}
+
+var _ SecurityPolicyClient = &securityPolicyClientImpl{}
+
+func (c *securityPolicyClientImpl) Insert(project string, sp *compute.SecurityPolicy) (*compute.Operation, error) {
+	return c.srv.Insert(project, sp).Do()
+}
+
+func (c *securityPolicyClientImpl) Delete(project, name string) (*compute.Operation, error) {
+	return c.srv.Delete(project, name).Do()
+}
+
+func (c *securityPolicyClientImpl) Get(project, name string) (*compute.SecurityPolicy, error) {
+	return c.srv.Get(project, name).Do()
+}
+
+func (c *securityPolicyClientImpl) List(ctx context.Context, project string) ([]*compute.SecurityPolicy, error) {
+	var sps []*compute.SecurityPolicy
+	if err := c.srv.List(project).Pages(ctx, func(p *compute.SecurityPolicyList) error {
+		sps = append(sps, p.Items...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return sps, nil
+}
+
+type SslPolicyClient interface {
+	Insert(project string, sp *compute.SslPolicy) (*compute.Operation, error)
+	Delete(project, name string) (*compute.Operation, error)
+	Get(project, name string) (*compute.SslPolicy, error)
+	List(ctx context.Context, project string) ([]*compute.SslPolicy, error)
+}
+
+type sslPolicyClientImpl struct {
+	srv *compute.SslPoliciesService
+}
+
+var _ SslPolicyClient = &sslPolicyClientImpl{}
+
+func (c *sslPolicyClientImpl) Insert(project string, sp *compute.SslPolicy) (*compute.Operation, error) {
+	return c.srv.Insert(project, sp).Do()
+}
+
+func (c *sslPolicyClientImpl) Delete(project, name string) (*compute.Operation, error) {
+	return c.srv.Delete(project, name).Do()
+}
+
+func (c *sslPolicyClientImpl) Get(project, name string) (*compute.SslPolicy, error) {
+	return c.srv.Get(project, name).Do()
+}
+
+func (c *sslPolicyClientImpl) List(ctx context.Context, project string) ([]*compute.SslPolicy, error) {
+	var sps []*compute.SslPolicy
+	if err := c.srv.List(project).Pages(ctx, func(p *compute.SslPoliciesList) error {
+		sps = append(sps, p.Items...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return sps, nil
+}
+
+type RegionBackendServiceClient interface {
+	Insert(project, region string, bs *compute.BackendService) (*compute.Operation, error)
+	Delete(project, region, name string) (*compute.Operation, error)
+	Get(project, region, name string) (*compute.BackendService, error)
+	List(ctx context.Context, project, region string) ([]*compute.BackendService, error)
+}
+
+type regionBackendServiceClientImpl struct {
+	srv *compute.RegionBackendServicesService
+}
+
+var _ RegionBackendServiceClient = &regionBackendServiceClientImpl{}
+
+func (c *regionBackendServiceClientImpl) Insert(project, region string, bs *compute.BackendService) (*compute.Operation, error) {
+	return c.srv.Insert(project, region, bs).Do()
+}
+
+func (c *regionBackendServiceClientImpl) Delete(project, region, name string) (*compute.Operation, error) {
+	return c.srv.Delete(project, region, name).Do()
+}
+
+func (c *regionBackendServiceClientImpl) Get(project, region, name string) (*compute.BackendService, error) {
+	return c.srv.Get(project, region, name).Do()
+}
+
+func (c *regionBackendServiceClientImpl) List(ctx context.Context, project, region string) ([]*compute.BackendService, error) {
+	var l []*compute.BackendService
+	if err := c.srv.List(project, region).Pages(ctx, func(p *compute.BackendServiceList) error {
+		l = append(l, p.Items...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+type HealthCheckClient interface {
+	Insert(project string, hc *compute.HealthCheck) (*compute.Operation, error)
+	Delete(project, name string) (*compute.Operation, error)
+	Get(project, name string) (*compute.HealthCheck, error)
+	List(ctx context.Context, project string) ([]*compute.HealthCheck, error)
+}
+
+type healthCheckClientImpl struct {
+	srv *compute.HealthChecksService
+}
+
+var _ HealthCheckClient = &healthCheckClientImpl{}
+
+func (c *healthCheckClientImpl) Insert(project string, hc *compute.HealthCheck) (*compute.Operation, error) {
+	return c.srv.Insert(project, hc).Do()
+}
+
+func (c *healthCheckClientImpl) Delete(project, name string) (*compute.Operation, error) {
+	return c.srv.Delete(project, name).Do()
+}
+
+func (c *healthCheckClientImpl) Get(project, name string) (*compute.HealthCheck, error) {
+	return c.srv.Get(project, name).Do()
+}
+
+func (c *healthCheckClientImpl) List(ctx context.Context, project string) ([]*compute.HealthCheck, error) {
+	var l []*compute.HealthCheck
+	if err := c.srv.List(project).Pages(ctx, func(p *compute.HealthCheckList) error {
+		l = append(l, p.Items...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+type SslCertificateClient interface {
+	Insert(project string, cert *compute.SslCertificate) (*compute.Operation, error)
+	Delete(project, name string) (*compute.Operation, error)
+	Get(project, name string) (*compute.SslCertificate, error)
+	List(ctx context.Context, project string) ([]*compute.SslCertificate, error)
+}
+
+type sslCertificateClientImpl struct {
+	srv *compute.SslCertificatesService
+}
+
+var _ SslCertificateClient = &sslCertificateClientImpl{}
+
+func (c *sslCertificateClientImpl) Insert(project string, cert *compute.SslCertificate) (*compute.Operation, error) {
+	return c.srv.Insert(project, cert).Do()
+}
+
+func (c *sslCertificateClientImpl) Delete(project, name string) (*compute.Operation, error) {
+	return c.srv.Delete(project, name).Do()
+}
+
+func (c *sslCertificateClientImpl) Get(project, name string) (*compute.SslCertificate, error) {
+	return c.srv.Get(project, name).Do()
+}
+
+func (c *sslCertificateClientImpl) List(ctx context.Context, project string) ([]*compute.SslCertificate, error) {
+	var l []*compute.SslCertificate
+	if err := c.srv.List(project).Pages(ctx, func(p *compute.SslCertificateList) error {
+		l = append(l, p.Items...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+type RegionHealthCheckClient interface {
+	Insert(project, region string, hc *compute.HealthCheck) (*compute.Operation, error)
+	Delete(project, region, name string) (*compute.Operation, error)
+	Get(project, region, name string) (*compute.HealthCheck, error)
+	List(ctx context.Context, project, region string) ([]*compute.HealthCheck, error)
+}
+
+type regionHealthCheckClientImpl struct {
+	srv *compute.RegionHealthChecksService
+}
+
+var _ RegionHealthCheckClient = &regionHealthCheckClientImpl{}
+
+func (c *regionHealthCheckClientImpl) Insert(project, region string, hc *compute.HealthCheck) (*compute.Operation, error) {
+	return c.srv.Insert(project, region, hc).Do()
+}
+
+func (c *regionHealthCheckClientImpl) Delete(project, region, name string) (*compute.Operation, error) {
+	return c.srv.Delete(project, region, name).Do()
+}
+
+func (c *regionHealthCheckClientImpl) Get(project, region, name string) (*compute.HealthCheck, error) {
+	return c.srv.Get(project, region, name).Do()
+}
+
+func (c *regionHealthCheckClientImpl) List(ctx context.Context, project, region string) ([]*compute.HealthCheck, error) {
+	var l []*compute.HealthCheck
+	if err := c.srv.List(project, region).Pages(ctx, func(p *compute.HealthCheckList) error {
+		l = append(l, p.Items...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+type RegionSslCertificateClient interface {
+	Insert(project, region string, cert *compute.SslCertificate) (*compute.Operation, error)
+	Delete(project, region, name string) (*compute.Operation, error)
+	Get(project, region, name string) (*compute.SslCertificate, error)
+	List(ctx context.Context, project, region string) ([]*compute.SslCertificate, error)
+}
+
+type regionSslCertificateClientImpl struct {
+	srv *compute.RegionSslCertificatesService
+}
+
+var _ RegionSslCertificateClient = &regionSslCertificateClientImpl{}
+
+func (c *regionSslCertificateClientImpl) Insert(project, region string, cert *compute.SslCertificate) (*compute.Operation, error) {
+	return c.srv.Insert(project, region, cert).Do()
+}
+
+func (c *regionSslCertificateClientImpl) Delete(project, region, name string) (*compute.Operation, error) {
+	return c.srv.Delete(project, region, name).Do()
+}
+
+func (c *regionSslCertificateClientImpl) Get(project, region, name string) (*compute.SslCertificate, error) {
+	return c.srv.Get(project, region, name).Do()
+}
+
+func (c *regionSslCertificateClientImpl) List(ctx context.Context, project, region string) ([]*compute.SslCertificate, error) {
+	var l []*compute.SslCertificate
+	if err := c.srv.List(project, region).Pages(ctx, func(p *compute.SslCertificateList) error {
+		l = append(l, p.Items...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+type GlobalForwardingRuleClient interface {
+	Insert(project string, fr *compute.ForwardingRule) (*compute.Operation, error)
+	Delete(project, name string) (*compute.Operation, error)
+	Get(project, name string) (*compute.ForwardingRule, error)
+	List(ctx context.Context, project string) ([]*compute.ForwardingRule, error)
+}
+
+type globalForwardingRuleClientImpl struct {
+	srv *compute.GlobalForwardingRulesService
+}
+
+var _ GlobalForwardingRuleClient = &globalForwardingRuleClientImpl{}
+
+func (c *globalForwardingRuleClientImpl) Insert(project string, fr *compute.ForwardingRule) (*compute.Operation, error) {
+	return c.srv.Insert(project, fr).Do()
+}
+
+func (c *globalForwardingRuleClientImpl) Delete(project, name string) (*compute.Operation, error) {
+	return c.srv.Delete(project, name).Do()
+}
+
+func (c *globalForwardingRuleClientImpl) Get(project, name string) (*compute.ForwardingRule, error) {
+	return c.srv.Get(project, name).Do()
+}
+
+func (c *globalForwardingRuleClientImpl) List(ctx context.Context, project string) ([]*compute.ForwardingRule, error) {
+	var l []*compute.ForwardingRule
+	if err := c.srv.List(project).Pages(ctx, func(p *compute.ForwardingRuleList) error {
+		l = append(l, p.Items...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+type TargetHttpProxyClient interface {
+	Insert(project string, p *compute.TargetHttpProxy) (*compute.Operation, error)
+	Delete(project, name string) (*compute.Operation, error)
+	Get(project, name string) (*compute.TargetHttpProxy, error)
+	List(ctx context.Context, project string) ([]*compute.TargetHttpProxy, error)
+}
+
+type targetHttpProxyClientImpl struct {
+	srv *compute.TargetHttpProxiesService
+}
+
+var _ TargetHttpProxyClient = &targetHttpProxyClientImpl{}
+
+func (c *targetHttpProxyClientImpl) Insert(project string, p *compute.TargetHttpProxy) (*compute.Operation, error) {
+	return c.srv.Insert(project, p).Do()
+}
+
+func (c *targetHttpProxyClientImpl) Delete(project, name string) (*compute.Operation, error) {
+	return c.srv.Delete(project, name).Do()
+}
+
+func (c *targetHttpProxyClientImpl) Get(project, name string) (*compute.TargetHttpProxy, error) {
+	return c.srv.Get(project, name).Do()
+}
+
+func (c *targetHttpProxyClientImpl) List(ctx context.Context, project string) ([]*compute.TargetHttpProxy, error) {
+	var l []*compute.TargetHttpProxy
+	if err := c.srv.List(project).Pages(ctx, func(p *compute.TargetHttpProxyList) error {
+		l = append(l, p.Items...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+type TargetHttpsProxyClient interface {
+	Insert(project string, p *compute.TargetHttpsProxy) (*compute.Operation, error)
+	Delete(project, name string) (*compute.Operation, error)
+	Get(project, name string) (*compute.TargetHttpsProxy, error)
+	List(ctx context.Context, project string) ([]*compute.TargetHttpsProxy, error)
+}
+
+type targetHttpsProxyClientImpl struct {
+	srv *compute.TargetHttpsProxiesService
+}
+
+var _ TargetHttpsProxyClient = &targetHttpsProxyClientImpl{}
+
+func (c *targetHttpsProxyClientImpl) Insert(project string, p *compute.TargetHttpsProxy) (*compute.Operation, error) {
+	return c.srv.Insert(project, p).Do()
+}
+
+func (c *targetHttpsProxyClientImpl) Delete(project, name string) (*compute.Operation, error) {
+	return c.srv.Delete(project, name).Do()
+}
+
+func (c *targetHttpsProxyClientImpl) Get(project, name string) (*compute.TargetHttpsProxy, error) {
+	return c.srv.Get(project, name).Do()
+}
+
+func (c *targetHttpsProxyClientImpl) List(ctx context.Context, project string) ([]*compute.TargetHttpsProxy, error) {
+	var l []*compute.TargetHttpsProxy
+	if err := c.srv.List(project).Pages(ctx, func(p *compute.TargetHttpsProxyList) error {
+		l = append(l, p.Items...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+type UrlMapClient interface {
+	Insert(project string, m *compute.UrlMap) (*compute.Operation, error)
+	Delete(project, name string) (*compute.Operation, error)
+	Get(project, name string) (*compute.UrlMap, error)
+	List(ctx context.Context, project string) ([]*compute.UrlMap, error)
+}
+
+type urlMapClientImpl struct {
+	srv *compute.UrlMapsService
+}
+
+var _ UrlMapClient = &urlMapClientImpl{}
+
+func (c *urlMapClientImpl) Insert(project string, m *compute.UrlMap) (*compute.Operation, error) {
+	return c.srv.Insert(project, m).Do()
+}
+
+func (c *urlMapClientImpl) Delete(project, name string) (*compute.Operation, error) {
+	return c.srv.Delete(project, name).Do()
+}
+
+func (c *urlMapClientImpl) Get(project, name string) (*compute.UrlMap, error) {
+	return c.srv.Get(project, name).Do()
+}
+
+func (c *urlMapClientImpl) List(ctx context.Context, project string) ([]*compute.UrlMap, error) {
+	var l []*compute.UrlMap
+	if err := c.srv.List(project).Pages(ctx, func(p *compute.UrlMapList) error {
+		l = append(l, p.Items...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+type HttpHealthCheckClient interface {
+	Insert(project string, hc *compute.HttpHealthCheck) (*compute.Operation, error)
+	Delete(project, name string) (*compute.Operation, error)
+	Get(project, name string) (*compute.HttpHealthCheck, error)
+	List(ctx context.Context, project string) ([]*compute.HttpHealthCheck, error)
+}
+
+type httpHealthCheckClientImpl struct {
+	srv *compute.HttpHealthChecksService
+}
+
+var _ HttpHealthCheckClient = &httpHealthCheckClientImpl{}
+
+func (c *httpHealthCheckClientImpl) Insert(project string, hc *compute.HttpHealthCheck) (*compute.Operation, error) {
+	return c.srv.Insert(project, hc).Do()
+}
+
+func (c *httpHealthCheckClientImpl) Delete(project, name string) (*compute.Operation, error) {
+	return c.srv.Delete(project, name).Do()
+}
+
+func (c *httpHealthCheckClientImpl) Get(project, name string) (*compute.HttpHealthCheck, error) {
+	return c.srv.Get(project, name).Do()
+}
+
+func (c *httpHealthCheckClientImpl) List(ctx context.Context, project string) ([]*compute.HttpHealthCheck, error) {
+	var l []*compute.HttpHealthCheck
+	if err := c.srv.List(project).Pages(ctx, func(p *compute.HttpHealthCheckList) error {
+		l = append(l, p.Items...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+type GlobalAddressClient interface {
+	Insert(project string, addr *compute.Address) (*compute.Operation, error)
+	Delete(project, name string) (*compute.Operation, error)
+	Get(project, name string) (*compute.Address, error)
+	List(ctx context.Context, project string) ([]*compute.Address, error)
+}
+
+type globalAddressClientImpl struct {
+	srv *compute.GlobalAddressesService
+}
+
+var _ GlobalAddressClient = &globalAddressClientImpl{}
+
+func (c *globalAddressClientImpl) Insert(project string, addr *compute.Address) (*compute.Operation, error) {
+	return c.srv.Insert(project, addr).Do()
+}
+
+func (c *globalAddressClientImpl) Delete(project, name string) (*compute.Operation, error) {
+	return c.srv.Delete(project, name).Do()
+}
+
+func (c *globalAddressClientImpl) Get(project, name string) (*compute.Address, error) {
+	return c.srv.Get(project, name).Do()
+}
+
+func (c *globalAddressClientImpl) List(ctx context.Context, project string) ([]*compute.Address, error) {
+	var l []*compute.Address
+	if err := c.srv.List(project).Pages(ctx, func(p *compute.AddressList) error {
+		l = append(l, p.Items...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+type SnapshotClient interface {
+	Delete(project, name string) (*compute.Operation, error)
+	Get(project, name string) (*compute.Snapshot, error)
+	List(ctx context.Context, project string) ([]*compute.Snapshot, error)
+	SetLabels(project, name string, req *compute.GlobalSetLabelsRequest) error
+}
+
+type snapshotClientImpl struct {
+	srv *compute.SnapshotsService
+}
+
+var _ SnapshotClient = &snapshotClientImpl{}
+
+func (c *snapshotClientImpl) Delete(project, name string) (*compute.Operation, error) {
+	return c.srv.Delete(project, name).Do()
+}
+
+func (c *snapshotClientImpl) Get(project, name string) (*compute.Snapshot, error) {
+	return c.srv.Get(project, name).Do()
+}
+
+func (c *snapshotClientImpl) SetLabels(project, name string, req *compute.GlobalSetLabelsRequest) error {
+	_, err := c.srv.SetLabels(project, name, req).Do()
+	return err
+}
+
+func (c *snapshotClientImpl) List(ctx context.Context, project string) ([]*compute.Snapshot, error) {
+	var l []*compute.Snapshot
+	if err := c.srv.List(project).Pages(ctx, func(p *compute.SnapshotList) error {
+		l = append(l, p.Items...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// MachineImageClient manages machine images, which are only available in the beta API.
+// Operations are converted to v1 so they can be waited on like any other operation.
+type MachineImageClient interface {
+	Insert(project string, mi *computebeta.MachineImage) (*compute.Operation, error)
+	Delete(project, name string) (*compute.Operation, error)
+	Get(project, name string) (*computebeta.MachineImage, error)
+	List(ctx context.Context, project string) ([]*computebeta.MachineImage, error)
+}
+
+type machineImageClientImpl struct {
+	srv *computebeta.MachineImagesService
+}
+
+var _ MachineImageClient = &machineImageClientImpl{}
+
+func (c *machineImageClientImpl) Insert(project string, mi *computebeta.MachineImage) (*compute.Operation, error) {
+	op, err := c.srv.Insert(project, mi).Do()
+	if err != nil {
+		return nil, err
+	}
+	return toV1Operation(op)
+}
+
+func (c *machineImageClientImpl) Delete(project, name string) (*compute.Operation, error) {
+	op, err := c.srv.Delete(project, name).Do()
+	if err != nil {
+		return nil, err
+	}
+	return toV1Operation(op)
+}
+
+func (c *machineImageClientImpl) Get(project, name string) (*computebeta.MachineImage, error) {
+	return c.srv.Get(project, name).Do()
+}
+
+func (c *machineImageClientImpl) List(ctx context.Context, project string) ([]*computebeta.MachineImage, error) {
+	var l []*computebeta.MachineImage
+	if err := c.srv.List(project).Pages(ctx, func(p *computebeta.MachineImageList) error {
+		l = append(l, p.Items...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+type ImageClient interface {
+	Insert(project string, image *compute.Image) (*compute.Operation, error)
+	Delete(project, name string) (*compute.Operation, error)
+	Get(project, name string) (*compute.Image, error)
+	List(ctx context.Context, project string) ([]*compute.Image, error)
+	SetLabels(project, name string, req *compute.GlobalSetLabelsRequest) error
+}
+
+type imageClientImpl struct {
+	srv *compute.ImagesService
+}
+
+var _ ImageClient = &imageClientImpl{}
+
+func (c *imageClientImpl) Insert(project string, image *compute.Image) (*compute.Operation, error) {
+	return c.srv.Insert(project, image).Do()
+}
+
+func (c *imageClientImpl) Delete(project, name string) (*compute.Operation, error) {
+	return c.srv.Delete(project, name).Do()
+}
+
+func (c *imageClientImpl) Get(project, name string) (*compute.Image, error) {
+	return c.srv.Get(project, name).Do()
+}
+
+func (c *imageClientImpl) SetLabels(project, name string, req *compute.GlobalSetLabelsRequest) error {
+	_, err := c.srv.SetLabels(project, name, req).Do()
+	return err
+}
+
+func (c *imageClientImpl) List(ctx context.Context, project string) ([]*compute.Image, error) {
+	var l []*compute.Image
+	if err := c.srv.List(project).Pages(ctx, func(p *compute.ImageList) error {
+		l = append(l, p.Items...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// toV1Operation converts a beta operation to a v1 operation; the two share the same JSON representation
+func toV1Operation(op *computebeta.Operation) (*compute.Operation, error) {
+	data, err := json.Marshal(op)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling operation: %v", err)
+	}
+	v1 := &compute.Operation{}
+	if err := json.Unmarshal(data, v1); err != nil {
+		return nil, fmt.Errorf("error unmarshalling operation: %v", err)
+	}
+	return v1, nil
+}
+
+type ResourcePolicyClient interface {
+	Insert(project, region string, rp *compute.ResourcePolicy) (*compute.Operation, error)
+	Delete(project, region, name string) (*compute.Operation, error)
+	Get(project, region, name string) (*compute.ResourcePolicy, error)
+	List(ctx context.Context, project, region string) ([]*compute.ResourcePolicy, error)
+}
+
+type resourcePolicyClientImpl struct {
+	srv *compute.ResourcePoliciesService
+}
+
+var _ ResourcePolicyClient = &resourcePolicyClientImpl{}
+
+func (c *resourcePolicyClientImpl) Insert(project, region string, rp *compute.ResourcePolicy) (*compute.Operation, error) {
+	return c.srv.Insert(project, region, rp).Do()
+}
+
+func (c *resourcePolicyClientImpl) Delete(project, region, name string) (*compute.Operation, error) {
+	return c.srv.Delete(project, region, name).Do()
+}
+
+func (c *resourcePolicyClientImpl) Get(project, region, name string) (*compute.ResourcePolicy, error) {
+	return c.srv.Get(project, region, name).Do()
+}
+
+func (c *resourcePolicyClientImpl) List(ctx context.Context, project, region string) ([]*compute.ResourcePolicy, error) {
+	var rps []*compute.ResourcePolicy
+	if err := c.srv.List(project, region).Pages(ctx, func(p *compute.ResourcePolicyList) error {
+		rps = append(rps, p.Items...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return rps, nil
+}
+
+type RegionDiskClient interface {
+	Insert(project, region string, disk *compute.Disk) (*compute.Operation, error)
+	Delete(project, region, name string) (*compute.Operation, error)
+	Get(project, region, name string) (*compute.Disk, error)
+	List(ctx context.Context, project, region string) ([]*compute.Disk, error)
+}
+
+type regionDiskClientImpl struct {
+	srv *compute.RegionDisksService
+}
+
+var _ RegionDiskClient = &regionDiskClientImpl{}
+
+func (c *regionDiskClientImpl) Insert(project, region string, disk *compute.Disk) (*compute.Operation, error) {
+	return c.srv.Insert(project, region, disk).Do()
+}
+
+func (c *regionDiskClientImpl) Delete(project, region, name string) (*compute.Operation, error) {
+	return c.srv.Delete(project, region, name).Do()
+}
+
+func (c *regionDiskClientImpl) Get(project, region, name string) (*compute.Disk, error) {
+	return c.srv.Get(project, region, name).Do()
+}
+
+func (c *regionDiskClientImpl) List(ctx context.Context, project, region string) ([]*compute.Disk, error) {
+	var l []*compute.Disk
+	if err := c.srv.List(project, region).Pages(ctx, func(p *compute.DiskList) error {
+		l = append(l, p.Items...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
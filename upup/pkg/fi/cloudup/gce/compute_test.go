@@ -0,0 +1,97 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+
+	compute "google.golang.org/api/compute/v1"
+	"google.golang.org/api/option"
+)
+
+// servePages starts a server returning pages[pageToken], where the first page has an empty token
+func servePages(t *testing.T, pages map[string]interface{}, requests *[]*http.Request) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests = append(*requests, r)
+		page, ok := pages[r.URL.Query().Get("pageToken")]
+		if !ok {
+			http.Error(w, "unknown page", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(page); err != nil {
+			t.Errorf("error writing page: %v", err)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDiskAggregatedListReadsAllPages(t *testing.T) {
+	var requests []*http.Request
+	server := servePages(t, map[string]interface{}{
+		"": &compute.DiskAggregatedList{
+			Items: map[string]compute.DisksScopedList{
+				"zones/us-test1-a": {Disks: []*compute.Disk{{Name: "disk-1"}}},
+			},
+			NextPageToken: "page-2",
+		},
+		"page-2": &compute.DiskAggregatedList{
+			Items: map[string]compute.DisksScopedList{
+				"zones/us-test1-b": {Disks: []*compute.Disk{{Name: "disk-2"}}},
+			},
+		},
+	}, &requests)
+
+	ctx := context.Background()
+	srv, err := compute.NewService(ctx, option.WithEndpoint(server.URL+"/"), option.WithHTTPClient(server.Client()))
+	if err != nil {
+		t.Fatalf("error building compute client: %v", err)
+	}
+	c := &diskClientImpl{srv: srv.Disks}
+
+	lists, err := c.AggregatedList(ctx, "testproject", 1)
+	if err != nil {
+		t.Fatalf("error listing disks: %v", err)
+	}
+	var actual []string
+	for _, l := range lists {
+		for _, disk := range l.Disks {
+			actual = append(actual, disk.Name)
+		}
+	}
+	sort.Strings(actual)
+	expected := []string{"disk-1", "disk-2"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected disks, got %v, expected %v", actual, expected)
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(requests))
+	}
+	for _, r := range requests {
+		if maxResults := r.URL.Query().Get("maxResults"); maxResults != "1" {
+			t.Errorf("expected maxResults=1 on every page, got %q", maxResults)
+		}
+	}
+}
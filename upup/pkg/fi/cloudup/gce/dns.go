@@ -64,7 +64,7 @@ func (c *dnsClientImpl) Changes() ChangeClient {
 }
 
 type ManagedZoneClient interface {
-	List(project string) ([]*dns.ManagedZone, error)
+	List(ctx context.Context, project string) ([]*dns.ManagedZone, error)
 }
 
 type managedZoneClientImpl struct {
@@ -73,16 +73,19 @@ type managedZoneClientImpl struct {
 
 var _ ManagedZoneClient = &managedZoneClientImpl{}
 
-func (c *managedZoneClientImpl) List(project string) ([]*dns.ManagedZone, error) {
-	r, err := c.srv.List(project).Do()
-	if err != nil {
+func (c *managedZoneClientImpl) List(ctx context.Context, project string) ([]*dns.ManagedZone, error) {
+	var zones []*dns.ManagedZone
+	if err := c.srv.List(project).Pages(ctx, func(p *dns.ManagedZonesListResponse) error {
+		zones = append(zones, p.ManagedZones...)
+		return nil
+	}); err != nil {
 		return nil, err
 	}
-	return r.ManagedZones, nil
+	return zones, nil
 }
 
 type ResourceRecordSetClient interface {
-	List(project, zone string) ([]*dns.ResourceRecordSet, error)
+	List(ctx context.Context, project, zone string) ([]*dns.ResourceRecordSet, error)
 }
 
 type resourceRecordSetClientImpl struct {
@@ -91,16 +94,20 @@ type resourceRecordSetClientImpl struct {
 
 var _ ResourceRecordSetClient = &resourceRecordSetClientImpl{}
 
-func (c *resourceRecordSetClientImpl) List(project, zone string) ([]*dns.ResourceRecordSet, error) {
-	r, err := c.srv.List(project, zone).Do()
-	if err != nil {
+func (c *resourceRecordSetClientImpl) List(ctx context.Context, project, zone string) ([]*dns.ResourceRecordSet, error) {
+	var rrsets []*dns.ResourceRecordSet
+	if err := c.srv.List(project, zone).Pages(ctx, func(p *dns.ResourceRecordSetsListResponse) error {
+		rrsets = append(rrsets, p.Rrsets...)
+		return nil
+	}); err != nil {
 		return nil, err
 	}
-	return r.Rrsets, nil
+	return rrsets, nil
 }
 
 type ChangeClient interface {
-	Create(project, zone string, ch *dns.Change) (*dns.Change, error)
+	Create(ctx context.Context, project, zone string, ch *dns.Change) (*dns.Change, error)
+	Get(ctx context.Context, project, zone, id string) (*dns.Change, error)
 }
 
 type changeClientImpl struct {
@@ -109,6 +116,10 @@ type changeClientImpl struct {
 
 var _ ChangeClient = &changeClientImpl{}
 
-func (c *changeClientImpl) Create(project, zone string, ch *dns.Change) (*dns.Change, error) {
-	return c.srv.Create(project, zone, ch).Do()
+func (c *changeClientImpl) Create(ctx context.Context, project, zone string, ch *dns.Change) (*dns.Change, error) {
+	return c.srv.Create(project, zone, ch).Context(ctx).Do()
+}
+
+func (c *changeClientImpl) Get(ctx context.Context, project, zone, id string) (*dns.Change, error) {
+	return c.srv.Get(project, zone, id).Context(ctx).Do()
 }
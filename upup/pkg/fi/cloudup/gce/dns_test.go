@@ -0,0 +1,62 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	dns "google.golang.org/api/dns/v1"
+	"google.golang.org/api/option"
+)
+
+func TestResourceRecordSetListReadsAllPages(t *testing.T) {
+	var requests []*http.Request
+	server := servePages(t, map[string]interface{}{
+		"": &dns.ResourceRecordSetsListResponse{
+			Rrsets:        []*dns.ResourceRecordSet{{Name: "api.cluster.example.com.", Type: "A"}},
+			NextPageToken: "page-2",
+		},
+		"page-2": &dns.ResourceRecordSetsListResponse{
+			Rrsets: []*dns.ResourceRecordSet{{Name: "api.internal.cluster.example.com.", Type: "A"}},
+		},
+	}, &requests)
+
+	srv, err := dns.NewService(context.Background(), option.WithEndpoint(server.URL+"/"), option.WithHTTPClient(server.Client()))
+	if err != nil {
+		t.Fatalf("error building DNS client: %v", err)
+	}
+	c := &resourceRecordSetClientImpl{srv: srv.ResourceRecordSets}
+
+	rrsets, err := c.List(context.Background(), "testproject", "example-com")
+	if err != nil {
+		t.Fatalf("error listing record sets: %v", err)
+	}
+	var actual []string
+	for _, rrset := range rrsets {
+		actual = append(actual, rrset.Name)
+	}
+	expected := []string{"api.cluster.example.com.", "api.internal.cluster.example.com."}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected record sets, got %v, expected %v", actual, expected)
+	}
+	if len(requests) != 2 {
+		t.Errorf("expected 2 requests, got %d", len(requests))
+	}
+}
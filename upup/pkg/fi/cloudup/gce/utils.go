@@ -59,6 +59,7 @@ func SafeObjectName(name string, clusterName string) string {
 	gceName := name + "-" + clusterName
 
 	// TODO: If the cluster name > some max size (32?) we should curtail it
+	// Note that cluster cleanup (pkg/resources/gce) reproduces this naming, and that of LimitedLengthName, to match resources
 	return SafeClusterName(gceName)
 }
 